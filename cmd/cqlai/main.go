@@ -7,6 +7,9 @@ import (
 	"strings"
 
 	"github.com/axonops/cqlai/internal/batch"
+	"github.com/axonops/cqlai/internal/config"
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/router"
 	"github.com/axonops/cqlai/internal/ui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/pflag"
@@ -19,45 +22,58 @@ var Version = "dev"
 func main() {
 	// Parse command-line flags using pflag for POSIX/GNU-style flags
 	var (
-		host           string
-		port           int
-		keyspace       string
-		username       string
-		password       string
+		host                  string
+		port                  int
+		localDC               string
+		profile               string
+		keyspace              string
+		username              string
+		password              string
 		noConfirm             bool
 		connectTimeout        int
 		requestTimeout        int
+		protocolVersion       int
 		debug                 bool
 		ssl                   bool
 		sslNoHostVerification bool
 		sslInsecureSkipVerify bool
 		consistency           string
-		execute        string
-		executeFile    string
-		format         string
-		noHeader       bool
-		fieldSep       string
-		pageSize       int
-		configFile     string
-		version        bool
-		help           bool
+		serialConsistency     string
+		execute               string
+		executeFile           string
+		format                string
+		noHeader              bool
+		fieldSep              string
+		pageSize              int
+		pageState             string
+		variables             []string
+		configFile            string
+		schemaFile            string
+		migrationsDir         string
+		version               bool
+		help                  bool
 	)
 
 	// Connection flags
-	pflag.StringVar(&host, "host", "", "Cassandra host (overrides config)")
+	pflag.StringVar(&host, "host", "", "Cassandra host or comma-separated contact points (overrides config)")
 	pflag.IntVar(&port, "port", 0, "Cassandra port (overrides config)")
+	pflag.StringVar(&localDC, "local-dc", "", "Local datacenter for DC-aware load balancing (overrides config)")
 	pflag.StringVarP(&keyspace, "keyspace", "k", "", "Default keyspace (overrides config)")
 	pflag.StringVarP(&username, "username", "u", "", "Username for authentication (overrides config)")
 	pflag.StringVarP(&password, "password", "p", "", "Password for authentication (overrides config)")
 	pflag.BoolVar(&noConfirm, "no-confirm", false, "Disable confirmation prompts for dangerous commands")
 	pflag.IntVar(&connectTimeout, "connect-timeout", 10, "Connection timeout in seconds")
 	pflag.IntVar(&requestTimeout, "request-timeout", 10, "Request timeout in seconds")
+	pflag.IntVar(&protocolVersion, "protocol-version", 0, "Native protocol version to use: 3, 4 or 5 (default: negotiate)")
 	pflag.BoolVar(&debug, "debug", false, "Enable debug logging")
 	pflag.BoolVar(&ssl, "ssl", false, "Enable SSL/TLS connection")
 	pflag.BoolVar(&sslNoHostVerification, "no-ssl-host-verification", false, "Disable SSL hostname verification")
 	pflag.BoolVar(&sslInsecureSkipVerify, "ssl-insecure-skip-verify", false, "Skip SSL certificate verification (not recommended for production)")
 	pflag.StringVar(&consistency, "consistency", "", "Default consistency level (e.g., ONE, QUORUM, LOCAL_QUORUM)")
+	pflag.StringVar(&serialConsistency, "serial-consistency", "", "Serial consistency level for lightweight transactions (SERIAL or LOCAL_SERIAL)")
 	pflag.StringVar(&configFile, "config-file", "", "Path to config file (overrides default locations)")
+	pflag.StringVar(&profile, "profile", "", "Connection profile from the config file")
+	pflag.StringVar(&schemaFile, "schema-file", "", "Load the schema from a SCHEMA SAVE file instead of the cluster; works offline if the cluster is unreachable")
 
 	// Batch mode flags (compatible with cqlsh)
 	pflag.StringVarP(&execute, "execute", "e", "", "Execute CQL statement and exit")
@@ -66,6 +82,9 @@ func main() {
 	pflag.BoolVar(&noHeader, "no-header", false, "Don't output column headers (CSV format)")
 	pflag.StringVar(&fieldSep, "field-separator", ",", "Field separator for CSV output")
 	pflag.IntVar(&pageSize, "page-size", 100, "Pagination size for batch mode")
+	pflag.StringVar(&pageState, "page-state", "", "Resume the first SELECT from a token printed by PAGING STATE")
+	pflag.StringVar(&migrationsDir, "migrations-dir", router.DefaultMigrationsDir, "Directory of numbered .cql files for cqlai migrate")
+	pflag.StringArrayVar(&variables, "var", nil, "Set a query variable as name=value for :name bind markers (repeatable)")
 
 	// Version and help flags
 	pflag.BoolVarP(&version, "version", "V", false, "Print version and exit")
@@ -76,6 +95,21 @@ func main() {
 	// Handle positional arguments for cqlsh compatibility (cqlai [host] [port])
 	args := pflag.Args()
 
+	// cqlai migrate up|down|status [version] runs MIGRATE in batch mode
+	if len(args) > 0 && args[0] == "migrate" {
+		command, err := migrateCommand(args[1:], migrationsDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\nUsage: cqlai [options] migrate up|down|status [version]\n", err)
+			os.Exit(1)
+		}
+		if execute != "" || executeFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: migrate cannot be combined with --execute or --file")
+			os.Exit(1)
+		}
+		execute = command
+		args = nil
+	}
+
 	if len(args) > 2 {
 		fmt.Fprintf(os.Stderr, "Error: unexpected positional arguments: %v\nUsage: cqlai [options] [host [port]]\n", args[2:])
 		os.Exit(1)
@@ -108,6 +142,7 @@ func main() {
 		fmt.Println("cqlai - A modern Cassandra CQL shell with AI assistance")
 		fmt.Println()
 		fmt.Println("Usage: cqlai [options] [host [port]]")
+		fmt.Println("       cqlai [options] migrate up|down|status [version]")
 		fmt.Println()
 		pflag.PrintDefaults()
 		os.Exit(0)
@@ -140,6 +175,33 @@ func main() {
 		}
 	}
 
+	// Validate --serial-consistency value if provided
+	if serialConsistency != "" {
+		switch strings.ToUpper(serialConsistency) {
+		case "SERIAL", "LOCAL_SERIAL":
+			serialConsistency = strings.ToUpper(serialConsistency)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid serial consistency level %q (valid: SERIAL, LOCAL_SERIAL)\n", serialConsistency)
+			os.Exit(1)
+		}
+	}
+
+	// Parse --var name=value flags; values are CQL literals
+	queryVariables := make(map[string]string, len(variables))
+	for _, v := range variables {
+		name, value, ok := strings.Cut(v, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			fmt.Fprintf(os.Stderr, "Error: invalid --var %q (expected name=value)\n", v)
+			os.Exit(1)
+		}
+		if _, err := db.ParseCQLLiteral(value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid value for --var %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		queryVariables[name] = value
+	}
+
 	// Override with environment variables if command-line flags not set
 	// This allows users to set CQLAI_* env vars as an alternative to flags
 	if configFile == "" {
@@ -147,6 +209,11 @@ func main() {
 			configFile = envConfigFile
 		}
 	}
+	if profile == "" {
+		if envProfile := os.Getenv("CQLAI_PROFILE"); envProfile != "" {
+			profile = envProfile
+		}
+	}
 	if host == "" {
 		if envHost := os.Getenv("CQLAI_HOST"); envHost != "" {
 			host = envHost
@@ -188,6 +255,13 @@ func main() {
 			}
 		}
 	}
+	if !pflag.CommandLine.Changed("protocol-version") {
+		if envVersion := os.Getenv("CQLAI_PROTOCOL_VERSION"); envVersion != "" {
+			if v, err := strconv.Atoi(envVersion); err == nil {
+				protocolVersion = v
+			}
+		}
+	}
 	if !noConfirm {
 		if envNoConfirm := os.Getenv("CQLAI_NO_CONFIRM"); envNoConfirm != "" {
 			noConfirm = envNoConfirm == "true" || envNoConfirm == "1"
@@ -227,12 +301,15 @@ func main() {
 		}
 	}
 
-	// Check password environment variable before interactive prompt
-	// Precedence: CLI flag (-p) > env var > interactive prompt
+	// Check password environment variables before interactive prompt
+	// Precedence: CLI flag (-p) > CQLAI_PASSWORD > CQLAI_PASSWORD_FILE > interactive prompt
 	if password == "" {
-		if envPass := os.Getenv("CQLAI_PASSWORD"); envPass != "" {
-			password = envPass
+		envPass, err := config.PasswordFromEnv()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		password = envPass
 	}
 
 	// SSL host verification and insecure skip verify env vars
@@ -248,8 +325,9 @@ func main() {
 		}
 	}
 
-	// Prompt for password interactively only if still empty and username was provided
-	if username != "" && password == "" && isTerminal() {
+	// Prompt for password interactively only if still empty and a username was provided
+	// on the command line or in the configuration
+	if password == "" && isTerminal() && needsPasswordPrompt(configFile, profile, username) {
 		fmt.Fprintf(os.Stderr, "Password: ")
 		passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd())) //nolint:gosec // G115: fd conversion is safe on all supported platforms
 		fmt.Fprintln(os.Stderr) // Print newline after password input
@@ -274,21 +352,28 @@ func main() {
 
 	// Create connection options
 	connOptions := ui.ConnectionOptions{
-		Host:                   host,
-		Port:                   port,
-		Keyspace:               keyspace,
-		Username:               username,
-		Password:               password,
-		RequireConfirmation:    !noConfirm,
-		ConnectTimeout:         connectTimeout,
-		RequestTimeout:         requestTimeout,
-		Debug:                  debug,
-		ConfigFile:             configFile,
-		SSL:                    ssl,
-		SSLHostVerification:    sslNoHostVerificationPtr,
-		SSLInsecureSkipVerify:  sslInsecureSkipVerifyPtr,
-		Consistency:            consistency,
-		PageSize:               pageSize,
+		Host:                  host,
+		Port:                  port,
+		LocalDC:               localDC,
+		Keyspace:              keyspace,
+		Username:              username,
+		Password:              password,
+		RequireConfirmation:   !noConfirm,
+		ConnectTimeout:        connectTimeout,
+		RequestTimeout:        requestTimeout,
+		ProtocolVersion:       protocolVersion,
+		Debug:                 debug,
+		ConfigFile:            configFile,
+		Profile:               profile,
+		SSL:                   ssl,
+		SSLHostVerification:   sslNoHostVerificationPtr,
+		SSLInsecureSkipVerify: sslInsecureSkipVerifyPtr,
+		Consistency:           consistency,
+		SerialConsistency:     serialConsistency,
+		PageSize:              pageSize,
+		Variables:             queryVariables,
+		SchemaFile:            schemaFile,
+		SplitStatements:       batch.SplitForNode, // MIGRATE reads migration files with the batch splitter
 	}
 
 	// Check if we're in batch mode
@@ -303,6 +388,7 @@ func main() {
 			NoHeader:    noHeader,
 			FieldSep:    fieldSep,
 			PageSize:    pageSize,
+			PageState:   pageState,
 			ConnOptions: connOptions,
 		}
 
@@ -358,3 +444,46 @@ func isTerminal() bool {
 	}
 	return fileInfo.Mode()&os.ModeCharDevice != 0
 }
+
+// needsPasswordPrompt reports whether a username is set, on the command line or in the
+// configuration, without a password or password command to go with it
+func needsPasswordPrompt(configFile, profile, username string) bool {
+	if username != "" {
+		return true
+	}
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		// Connecting reports the configuration error
+		return false
+	}
+	if profile != "" && cfg.ApplyProfile(profile) != nil {
+		return false
+	}
+	return cfg.NeedsPasswordPrompt()
+}
+
+// migrateCommand turns the arguments of cqlai migrate into a MIGRATE command
+func migrateCommand(args []string, dir string) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", fmt.Errorf("migrate expects an action and an optional version")
+	}
+	action := strings.ToUpper(args[0])
+	switch action {
+	case "UP", "DOWN":
+	case "STATUS":
+		if len(args) > 1 {
+			return "", fmt.Errorf("migrate status takes no version")
+		}
+	default:
+		return "", fmt.Errorf("unknown migrate action %q", args[0])
+	}
+
+	command := fmt.Sprintf("MIGRATE %s '%s'", action, strings.ReplaceAll(dir, "'", "''"))
+	if len(args) == 2 {
+		if _, err := strconv.ParseInt(args[1], 10, 64); err != nil {
+			return "", fmt.Errorf("invalid migration version %q", args[1])
+		}
+		command += " TO " + args[1]
+	}
+	return command, nil
+}
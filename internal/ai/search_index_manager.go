@@ -24,6 +24,7 @@ type SearchIndexManager struct {
 	cache              *db.SchemaCache
 	tableIndex         map[string]*TableSearchEntry // keyspace.table -> search entry
 	lastIndexBuild     time.Time
+	cacheGeneration    uint64    // Schema cache generation the index was built from
 	lastAccessUpdate   time.Time // Last time we updated access timestamps (for debouncing)
 	indexTTL           time.Duration
 	mu                 sync.RWMutex
@@ -49,10 +50,12 @@ func NewSearchIndexManager(cache *db.SchemaCache) *SearchIndexManager {
 	}
 }
 
-// BuildIndexIfNeeded builds the search index if it's expired or not built
+// BuildIndexIfNeeded builds the search index if it's expired, not built or the
+// schema cache changed since it was built
 func (sim *SearchIndexManager) BuildIndexIfNeeded() error {
 	sim.mu.RLock()
-	needsBuild := time.Since(sim.lastIndexBuild) > sim.indexTTL || len(sim.tableIndex) == 0
+	needsBuild := time.Since(sim.lastIndexBuild) > sim.indexTTL || len(sim.tableIndex) == 0 ||
+		sim.cache.Generation() != sim.cacheGeneration
 	inProgress := sim.buildInProgress
 	sim.mu.RUnlock()
 
@@ -91,6 +94,7 @@ func (sim *SearchIndexManager) BuildIndex() error {
 
 	// Get cached data with read lock
 	sim.cache.Mu.RLock()
+	generation := sim.cache.Generation()
 	for keyspace, tables := range sim.cache.Tables {
 		for _, table := range tables {
 			key := fmt.Sprintf("%s.%s", keyspace, table.TableName)
@@ -119,6 +123,7 @@ func (sim *SearchIndexManager) BuildIndex() error {
 	sim.mu.Lock()
 	sim.tableIndex = newIndex
 	sim.lastIndexBuild = time.Now()
+	sim.cacheGeneration = generation
 	sim.mu.Unlock()
 
 	logger.DebugfToFile("SearchIndexManager", "Search index built in %v with %d entries",
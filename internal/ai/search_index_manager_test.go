@@ -52,6 +52,37 @@ func TestSearchIndexManager_BuildIndex(t *testing.T) {
 	}
 }
 
+func TestSearchIndexManager_RebuildsOnSchemaEvent(t *testing.T) {
+	cache := &db.SchemaCache{
+		Tables: map[string][]db.CachedTableInfo{
+			"app": {
+				{TableInfo: db.TableInfo{TableName: "users"}},
+				{TableInfo: db.TableInfo{TableName: "orders"}},
+			},
+		},
+		Keyspaces:   []string{"app"},
+		LastRefresh: time.Now(),
+	}
+
+	sim := NewSearchIndexManager(cache)
+	if err := sim.BuildIndexIfNeeded(); err != nil {
+		t.Fatalf("Failed to build index: %v", err)
+	}
+
+	// Another client drops a table; the index must follow before its TTL expires
+	cache.ApplySchemaEvent(db.SchemaEvent{Change: db.SchemaDropped, Target: db.SchemaTargetTable, Keyspace: "app", Name: "orders"})
+	if err := sim.BuildIndexIfNeeded(); err != nil {
+		t.Fatalf("Failed to rebuild index: %v", err)
+	}
+
+	if got := sim.GetStats()["total_entries"].(int); got != 1 {
+		t.Errorf("Expected 1 entry after the drop, got %d", got)
+	}
+	if matches := sim.FindTables("orders", 5); len(matches) != 0 && matches[0].Table == "orders" {
+		t.Errorf("Dropped table still found: %+v", matches)
+	}
+}
+
 func TestSearchIndexManager_FindTables(t *testing.T) {
 	// Create mock schema cache
 	cache := &db.SchemaCache{
@@ -3,6 +3,7 @@ package batch
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -31,14 +32,15 @@ const (
 
 // Options contains batch execution options
 type Options struct {
-	Execute      string       // CQL to execute directly (-e flag)
-	File         string       // CQL file to execute (-f flag)
-	Format       OutputFormat // Output format
-	NoHeader     bool         // Skip headers in output
-	FieldSep     string       // Field separator for CSV
-	NoPager      bool         // Disable paging (print all results)
-	PageSize     int          // Number of rows per batch for streaming
-	ConnOptions  ui.ConnectionOptions
+	Execute     string       // CQL to execute directly (-e flag)
+	File        string       // CQL file to execute (-f flag)
+	Format      OutputFormat // Output format
+	NoHeader    bool         // Skip headers in output
+	FieldSep    string       // Field separator for CSV
+	NoPager     bool         // Disable paging (print all results)
+	PageSize    int          // Number of rows per batch for streaming
+	PageState   string       // Token the first SELECT resumes from (--page-state)
+	ConnOptions ui.ConnectionOptions
 }
 
 // Executor handles batch mode execution
@@ -47,10 +49,17 @@ type Executor struct {
 	sessionManager *session.Manager
 	options        *Options
 	writer         io.Writer
+	jsonWarnings   []string // Warnings of the current statement not yet written to the JSON result
 }
 
 // NewExecutor creates a new batch executor
 func NewExecutor(options *Options, writer io.Writer) (*Executor, error) {
+	if options.PageState != "" {
+		if _, err := db.ParsePageToken(options.PageState); err != nil {
+			return nil, fmt.Errorf("--page-state: %w", err)
+		}
+	}
+
 	// Create database session
 	cfg, err := config.LoadConfig(options.ConnOptions.ConfigFile)
 	if err != nil {
@@ -65,9 +74,20 @@ func NewExecutor(options *Options, writer io.Writer) (*Executor, error) {
 		logger.SetDebugEnabled(true)
 	}
 
+	// Apply the connection profile before command-line overrides
+	if options.ConnOptions.Profile != "" {
+		if err := cfg.ApplyProfile(options.ConnOptions.Profile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Override with connection options
 	if options.ConnOptions.Host != "" {
 		cfg.Host = options.ConnOptions.Host
+		cfg.Hosts = nil
+	}
+	if options.ConnOptions.LocalDC != "" {
+		cfg.LocalDC = options.ConnOptions.LocalDC
 	}
 	if options.ConnOptions.Port != 0 {
 		cfg.Port = options.ConnOptions.Port
@@ -85,6 +105,9 @@ func NewExecutor(options *Options, writer io.Writer) (*Executor, error) {
 	if options.ConnOptions.Consistency != "" {
 		cfg.Consistency = options.ConnOptions.Consistency
 	}
+	if options.ConnOptions.SerialConsistency != "" {
+		cfg.SerialConsistency = options.ConnOptions.SerialConsistency
+	}
 	// Enable SSL from CLI flag (--ssl)
 	if options.ConnOptions.SSL {
 		if cfg.SSL == nil {
@@ -115,17 +138,21 @@ func NewExecutor(options *Options, writer io.Writer) (*Executor, error) {
 	}
 
 	dbSession, err := db.NewSessionWithOptions(db.SessionOptions{
-		Host:           cfg.Host,
-		Port:           cfg.Port,
-		Keyspace:       cfg.Keyspace,
-		Username:       cfg.Username,
-		Password:       cfg.Password,
-		Consistency:    cfg.Consistency,
-		SSL:            cfg.SSL,
-		BatchMode:      true, // Disable schema caching in batch mode
-		ConnectTimeout: options.ConnOptions.ConnectTimeout,
-		RequestTimeout: options.ConnOptions.RequestTimeout,
-		ConfigFile:     options.ConnOptions.ConfigFile,
+		Hosts:             cfg.ContactPoints(),
+		Port:              cfg.Port,
+		LocalDC:           cfg.LocalDC,
+		Keyspace:          cfg.Keyspace,
+		Username:          cfg.Username,
+		Password:          cfg.Password,
+		PasswordCommand:   cfg.PasswordCommand,
+		Consistency:       cfg.Consistency,
+		SerialConsistency: cfg.SerialConsistency,
+		SSL:               cfg.SSL,
+		BatchMode:         true, // Disable schema caching in batch mode
+		ConnectTimeout:    options.ConnOptions.ConnectTimeout,
+		RequestTimeout:    options.ConnOptions.RequestTimeout,
+		ProtocolVersion:   options.ConnOptions.ProtocolVersion,
+		ConfigFile:        options.ConnOptions.ConfigFile,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Cassandra: %w", err)
@@ -137,9 +164,15 @@ func NewExecutor(options *Options, writer io.Writer) (*Executor, error) {
 		// SetKeyspace validates the keyspace name, but config values should already be valid
 		_ = sessionMgr.SetKeyspace(cfg.Keyspace)
 	}
+	for name, value := range options.ConnOptions.Variables {
+		if err := sessionMgr.SetVariable(name, value); err != nil {
+			dbSession.Close()
+			return nil, err
+		}
+	}
 
 	// Initialize router with session manager
-	router.InitRouter(sessionMgr)
+	router.InitRouter(sessionMgr, SplitForNode)
 
 	return &Executor{
 		session:        dbSession,
@@ -189,7 +222,19 @@ func (e *Executor) Execute(cql string) error {
 	}()
 
 	// Process the CQL command
-	result := router.ProcessCommand(cql, e.session, e.sessionManager)
+	result := router.ProcessCommand(ctx, e.applyPageState(cql), e.session, e.sessionManager)
+
+	// Warnings go to stderr so they don't mix with the results; JSON output carries
+	// them in a warnings field instead
+	if e.options.Format == OutputFormatJSON {
+		e.jsonWarnings = e.session.TakeWarnings()
+		defer e.writeJSONWarnings()
+	} else {
+		printWarnings(e.session.TakeWarnings())
+	}
+	for _, line := range e.session.TakeCustomPayload() {
+		fmt.Fprintf(os.Stderr, "Custom payload: %s\n", line)
+	}
 
 	// Handle the result based on type
 	var err error
@@ -208,6 +253,8 @@ func (e *Executor) Execute(cql string) error {
 			e.printTraceData()
 		}
 		return err
+	case db.LWTResult:
+		return e.handleLWTResult(v)
 	case [][]string:
 		err = e.outputTable(v)
 		// Check for tracing data after table output
@@ -216,6 +263,10 @@ func (e *Executor) Execute(cql string) error {
 		}
 		return err
 	case string:
+		// Meta commands report failures as "Error: ..." text
+		if message, failed := strings.CutPrefix(v, "Error: "); failed {
+			return errors.New(message)
+		}
 		// Check if this is a USE command result and update the keyspace
 		if strings.HasPrefix(v, "Now using keyspace ") {
 			// Extract the keyspace name
@@ -234,6 +285,17 @@ func (e *Executor) Execute(cql string) error {
 		}
 		fmt.Fprintln(e.writer, v)
 		return nil
+	case *router.ConnectCommand, *router.DisconnectCommand:
+		return fmt.Errorf("CONNECT and DISCONNECT are only available in interactive mode; use --profile to select a connection profile")
+	case *router.LoginCommand:
+		if v.Password == "" {
+			return fmt.Errorf("LOGIN needs a password in batch mode: LOGIN %s <password>", v.Username)
+		}
+		if err := e.session.Login(v.Username, v.Password); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.writer, "Logged in as %s\n", v.Username)
+		return nil
 	case error:
 		return v
 	default:
@@ -245,6 +307,35 @@ func (e *Executor) Execute(cql string) error {
 	}
 }
 
+// applyPageState makes the first SELECT resume from the --page-state token
+func (e *Executor) applyPageState(cql string) string {
+	if e.options.PageState == "" || !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(cql)), "SELECT") {
+		return cql
+	}
+	token := e.options.PageState
+	e.options.PageState = ""
+	return strings.TrimSuffix(strings.TrimSpace(cql), ";") + " RESUME '" + token + "'"
+}
+
+// printPageState writes to stderr the token that resumes an interrupted result
+func (e *Executor) printPageState(result db.StreamingQueryResult) {
+	if result.Cursor == nil {
+		return
+	}
+	position, err := result.Cursor.Position()
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Page state: %s (continue with --page-state)\n", position.Token())
+}
+
+// printWarnings writes the warnings of a statement to stderr
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
 // ExecuteFile executes CQL from a file
 func (e *Executor) ExecuteFile(filename string) error {
 	// Clean the filename to prevent path traversal
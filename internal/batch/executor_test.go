@@ -0,0 +1,72 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestApplyPageState(t *testing.T) {
+	e := &Executor{options: &Options{PageState: "AQU"}}
+
+	if got := e.applyPageState("USE app;"); got != "USE app;" {
+		t.Errorf("applyPageState changed a USE statement: %q", got)
+	}
+	if got := e.applyPageState("SELECT * FROM users;"); got != "SELECT * FROM users RESUME 'AQU'" {
+		t.Errorf("applyPageState() = %q", got)
+	}
+	// Only the first SELECT resumes
+	if got := e.applyPageState("SELECT * FROM events"); got != "SELECT * FROM events" {
+		t.Errorf("applyPageState() = %q", got)
+	}
+}
+
+func TestJSONResultWarnings(t *testing.T) {
+	var out bytes.Buffer
+	e := &Executor{options: &Options{Format: OutputFormatJSON}, writer: &out}
+
+	var result struct {
+		Rows     []map[string]string `json:"rows"`
+		Warnings []string            `json:"warnings"`
+	}
+
+	// A result without warnings has the same shape, with an empty list
+	if err := e.outputJSON([][]string{{"id"}, {"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"warnings": []`) {
+		t.Errorf("result without warnings = %s", out.String())
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil || len(result.Rows) != 1 {
+		t.Errorf("result = %s: %v", out.String(), err)
+	}
+
+	out.Reset()
+	e.jsonWarnings = []string{"Read 1000 live rows and 5000 tombstone cells"}
+	if err := e.outputJSON([][]string{{"id"}, {"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("result = %s: %v", out.String(), err)
+	}
+	if len(result.Rows) != 1 || len(result.Warnings) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Warnings of a statement without rows are written on their own
+	out.Reset()
+	e.jsonWarnings = []string{"Batch is too large"}
+	e.writeJSONWarnings()
+	result.Rows = nil
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil || len(result.Warnings) != 1 || result.Rows == nil {
+		t.Errorf("warnings = %s", out.String())
+	}
+
+	// Statements without rows or warnings write nothing
+	out.Reset()
+	e.writeJSONWarnings()
+	if out.Len() != 0 {
+		t.Errorf("no warnings = %s", out.String())
+	}
+}
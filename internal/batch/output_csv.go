@@ -33,6 +33,9 @@ func (e *Executor) outputCSV(data [][]string) error {
 
 // outputStreamingCSV outputs streaming data in CSV format
 func (e *Executor) outputStreamingCSV(ctx context.Context, result db.StreamingQueryResult) error {
+	// A resumed query replaces the iterator with the one of each page
+	defer func() { _ = result.Close() }()
+
 	csvWriter := csv.NewWriter(e.writer)
 	if e.options.FieldSep != "" && len(e.options.FieldSep) == 1 {
 		csvWriter.Comma = rune(e.options.FieldSep[0])
@@ -97,11 +100,16 @@ func (e *Executor) outputStreamingCSV(ctx context.Context, result db.StreamingQu
 		select {
 		case <-ctx.Done():
 			csvWriter.Flush()
+			e.printPageState(result)
 			return nil
 		default:
-			if !result.Iterator.Scan(scanDest...) {
+			ok, err := result.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.Scan(scanDest...) })
+			if !ok {
 				csvWriter.Flush()
-				return result.Iterator.Close()
+				if err == nil && ctx.Err() != nil {
+					e.printPageState(result)
+				}
+				return err
 			}
 
 			// Convert row to string array
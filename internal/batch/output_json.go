@@ -10,15 +10,46 @@ import (
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
 )
 
+// jsonResult is the JSON output of every statement, so scripts can rely on one shape
+type jsonResult struct {
+	Rows     interface{} `json:"rows"`
+	Warnings []string    `json:"warnings"`
+}
+
+// encodeJSONResult writes the rows of a result as a {"rows": [...], "warnings": [...]} object
+func (e *Executor) encodeJSONResult(rows interface{}) error {
+	encoder := json.NewEncoder(e.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonResult{Rows: rows, Warnings: e.takeJSONWarnings()})
+}
+
+// takeJSONWarnings returns the warnings not yet written to the JSON output and clears
+// them, as an empty list when there are none
+func (e *Executor) takeJSONWarnings() []string {
+	warnings := e.jsonWarnings
+	e.jsonWarnings = nil
+	if warnings == nil {
+		return []string{}
+	}
+	return warnings
+}
+
+// writeJSONWarnings writes the warnings of a statement whose result had no rows, as an
+// object with no rows
+func (e *Executor) writeJSONWarnings() {
+	if len(e.jsonWarnings) > 0 {
+		_ = e.encodeJSONResult([]map[string]interface{}{})
+	}
+}
+
 // outputJSON outputs data in JSON format
 func (e *Executor) outputJSON(data [][]string) error {
 	if len(data) == 0 {
-		fmt.Fprintln(e.writer, "[]")
-		return nil
+		return e.encodeJSONResult([]map[string]string{})
 	}
 
 	headers := data[0]
-	var results []map[string]string
+	results := []map[string]string{}
 
 	for i := 1; i < len(data); i++ {
 		row := make(map[string]string)
@@ -30,28 +61,33 @@ func (e *Executor) outputJSON(data [][]string) error {
 		results = append(results, row)
 	}
 
-	encoder := json.NewEncoder(e.writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(results)
+	return e.encodeJSONResult(results)
 }
 
 // outputJSONWithRawData outputs JSON using the raw data map for better type preservation
 func (e *Executor) outputJSONWithRawData(result db.QueryResult) error {
 	if len(result.RawData) == 0 {
-		fmt.Fprintln(e.writer, "[]")
-		return nil
+		return e.encodeJSONResult([]map[string]interface{}{})
 	}
 
 	// Use the raw data directly for JSON output
-	encoder := json.NewEncoder(e.writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(result.RawData)
+	return e.encodeJSONResult(result.RawData)
 }
 
 // outputStreamingJSON outputs streaming data in JSON format
 func (e *Executor) outputStreamingJSON(ctx context.Context, result db.StreamingQueryResult) error {
-	fmt.Fprint(e.writer, "[")
+	// A resumed query replaces the iterator with the one of each page
+	defer func() { _ = result.Close() }()
+
+	// Warnings of later pages are only known at the end, so they follow the rows
+	warnings := e.takeJSONWarnings()
+	fmt.Fprint(e.writer, `{"rows": [`)
 	first := true
+	closeResult := func() {
+		warnings = append(warnings, e.session.TakeWarnings()...)
+		jsonBytes, _ := json.Marshal(warnings)
+		fmt.Fprintf(e.writer, "\n], \"warnings\": %s}\n", jsonBytes)
+	}
 
 	// Get column information from the iterator
 	cols := result.Iterator.Columns()
@@ -105,12 +141,17 @@ func (e *Executor) outputStreamingJSON(ctx context.Context, result db.StreamingQ
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Fprintln(e.writer, "\n]")
+			closeResult()
+			e.printPageState(result)
 			return nil
 		default:
-			if !result.Iterator.Scan(scanDest...) {
-				fmt.Fprintln(e.writer, "\n]")
-				return result.Iterator.Close()
+			ok, err := result.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.Scan(scanDest...) })
+			if !ok {
+				closeResult()
+				if err == nil && ctx.Err() != nil {
+					e.printPageState(result)
+				}
+				return err
 			}
 
 			// Build row map
@@ -36,6 +36,7 @@ func (e *Executor) printTraceData() {
 	fmt.Fprintln(e.writer, "\nTracing Information:")
 	fmt.Fprintf(e.writer, "Duration: %v\n", traceInfo.Duration)
 	fmt.Fprintf(e.writer, "Coordinator: %s\n", traceInfo.Coordinator)
+	fmt.Fprintf(e.writer, "Retries: %d\n", traceInfo.Retries)
 
 	// Add headers to the beginning of data
 	allData := [][]string{headers}
@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"strings"
 
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"github.com/axonops/cqlai/internal/db"
 	"github.com/axonops/cqlai/internal/ui"
 )
 
 // handleStreamingResult handles streaming query results with automatic pagination
 func (e *Executor) handleStreamingResult(ctx context.Context, result db.StreamingQueryResult) error {
-	defer result.Iterator.Close()
+	defer func() { _ = result.Close() }()
 
 	// For CSV and JSON, we need to handle differently
 	switch e.options.Format {
@@ -27,52 +28,66 @@ func (e *Executor) handleStreamingResult(ctx context.Context, result db.Streamin
 	isFirstBatch := true
 	var columnWidths []int // Store column widths from first batch
 
+	writeRemaining := func() {
+		// Output the rows not written yet, closing the table
+		switch {
+		case len(rows) > 0 && isFirstBatch:
+			// If this is the only batch, include headers
+			allData := append([][]string{result.Headers}, rows...)
+			output := ui.FormatASCIITable(allData)
+			// Remove the row count from FormatASCIITable output as we'll add our own
+			output = strings.TrimSuffix(output, fmt.Sprintf("\n(%d rows)\n", len(rows)))
+			output = strings.TrimSuffix(output, fmt.Sprintf("\n(%d row)\n", len(rows)))
+			fmt.Fprint(e.writer, output)
+		case len(rows) > 0:
+			// For final batch of multi-batch output, just output rows with stored widths
+			allData := append([][]string{result.Headers}, rows...)
+			output := ui.FormatASCIITableRowsOnlyWithWidths(allData, columnWidths)
+			fmt.Fprint(e.writer, output)
+			// Add bottom border using stored widths
+			bottomBorder := ui.FormatASCIITableBottomWithWidths(allData, columnWidths)
+			fmt.Fprint(e.writer, bottomBorder)
+		case isFirstBatch:
+			// No rows at all, just print headers
+			headerData := [][]string{result.Headers}
+			output := ui.FormatASCIITable(headerData)
+			// Remove the row count as we'll add our own
+			output = strings.TrimSuffix(output, "\n(0 rows)\n")
+			fmt.Fprint(e.writer, output)
+		default:
+			// No rows in final batch but had previous batches - just add bottom border with stored widths
+			bottomBorder := ui.FormatASCIITableBottomWithWidths([][]string{result.Headers}, columnWidths)
+			fmt.Fprint(e.writer, bottomBorder)
+		}
+	}
+	// Rows read before an interruption are written, so the page state resumes after them
+	interrupted := func() error {
+		writeRemaining()
+		fmt.Fprintln(e.writer, "\n\nQuery interrupted by user")
+		e.printPageState(result)
+		return nil
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Fprintln(e.writer, "\n\nQuery interrupted by user")
-			return nil
+			return interrupted()
 		default:
 			// Use MapScan to handle NULLs properly - gocql can panic on NULL values with Scan()
 			rowMap := make(map[string]interface{})
-			if !result.Iterator.MapScan(rowMap) {
-				// Check for errors
-				if err := result.Iterator.Close(); err != nil {
-					return fmt.Errorf("iterator error: %w", err)
+			ok, err := result.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.MapScan(rowMap) })
+			if err != nil {
+				if ctx.Err() != nil {
+					return interrupted()
 				}
-
-				// Output final batch if we have rows
-				switch {
-				case len(rows) > 0 && isFirstBatch:
-					// If this is the only batch, include headers
-					allData := append([][]string{result.Headers}, rows...)
-					output := ui.FormatASCIITable(allData)
-					// Remove the row count from FormatASCIITable output as we'll add our own
-					output = strings.TrimSuffix(output, fmt.Sprintf("\n(%d rows)\n", len(rows)))
-					output = strings.TrimSuffix(output, fmt.Sprintf("\n(%d row)\n", len(rows)))
-					fmt.Fprint(e.writer, output)
-				case len(rows) > 0:
-					// For final batch of multi-batch output, just output rows with stored widths
-					allData := append([][]string{result.Headers}, rows...)
-					output := ui.FormatASCIITableRowsOnlyWithWidths(allData, columnWidths)
-					fmt.Fprint(e.writer, output)
-					// Add bottom border using stored widths
-					bottomBorder := ui.FormatASCIITableBottomWithWidths(allData, columnWidths)
-					fmt.Fprint(e.writer, bottomBorder)
-				case isFirstBatch:
-					// No rows at all, just print headers
-					headerData := [][]string{result.Headers}
-					output := ui.FormatASCIITable(headerData)
-					// Remove the row count as we'll add our own
-					output = strings.TrimSuffix(output, "\n(0 rows)\n")
-					fmt.Fprint(e.writer, output)
-				default:
-					// No rows in final batch but had previous batches - just add bottom border with stored widths
-					bottomBorder := ui.FormatASCIITableBottomWithWidths([][]string{result.Headers}, columnWidths)
-					fmt.Fprint(e.writer, bottomBorder)
+				return fmt.Errorf("iterator error: %w", err)
+			}
+			if !ok {
+				if ctx.Err() != nil {
+					return interrupted()
 				}
 
+				writeRemaining()
 				fmt.Fprintf(e.writer, "\n(%d rows)\n", rowCount)
 				return nil
 			}
@@ -158,4 +173,17 @@ func (e *Executor) handleQueryResult(result db.QueryResult) error {
 		// Note: outputTable already includes row count
 		return e.outputTable(result.Data)
 	}
-}
\ No newline at end of file
+}
+
+// handleLWTResult handles the outcome of a conditional statement
+func (e *Executor) handleLWTResult(result db.LWTResult) error {
+	switch e.options.Format {
+	case OutputFormatJSON:
+		return e.outputJSONWithRawData(db.QueryResult{RawData: result.RawData})
+	case OutputFormatCSV:
+		return e.outputCSV(append([][]string{result.Headers}, result.Rows...))
+	default:
+		fmt.Fprintln(e.writer, result.String())
+		return nil
+	}
+}
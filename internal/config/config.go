@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	
@@ -14,33 +15,71 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	Host                string          `json:"host"`
-	Port                int             `json:"port"`
-	Keyspace            string          `json:"keyspace"`
-	Username            string          `json:"username"`
-	Password            string          `json:"password"`
-	RequireConfirmation bool            `json:"requireConfirmation,omitempty"`
-	Consistency         string          `json:"consistency,omitempty"`         // Default consistency level (e.g., "LOCAL_ONE", "QUORUM")
-	PageSize            int             `json:"pageSize,omitempty"`
-	MaxMemoryMB         int             `json:"maxMemoryMB,omitempty"`         // Max memory for results in MB (default: 10)
-	ConnectTimeout      int             `json:"connectTimeout,omitempty"`      // Connection timeout in seconds
-	RequestTimeout      int             `json:"requestTimeout,omitempty"`      // Request timeout in seconds
-	Debug               bool            `json:"debug,omitempty"`               // Enable debug logging
-	HistoryFile         string          `json:"historyFile,omitempty"`         // Path to CQL command history file
-	AIHistoryFile       string          `json:"aiHistoryFile,omitempty"`       // Path to AI command history file
-	OutputFormat        string          `json:"outputFormat,omitempty"`        // Default output format (TABLE, ASCII, EXPAND, JSON)
-	SSL                 *SSLConfig      `json:"ssl,omitempty"`
-	AI                  *AIConfig       `json:"ai,omitempty"`
-	AuthProvider        *AuthProvider   `json:"authProvider,omitempty"`
+	Host                string                        `json:"host"`
+	Hosts               []string                      `json:"hosts,omitempty"` // Contact points; takes precedence over host
+	Port                int                           `json:"port"`
+	LocalDC             string                        `json:"localDC,omitempty"` // Local datacenter for DC-aware routing
+	Keyspace            string                        `json:"keyspace"`
+	Username            string                        `json:"username"`
+	Password            string                        `json:"password"`
+	PasswordCommand     string                        `json:"passwordCommand,omitempty"` // Command that prints the password, used when no password is set
+	RequireConfirmation bool                          `json:"requireConfirmation,omitempty"`
+	Consistency         string                        `json:"consistency,omitempty"`       // Default consistency level (e.g., "LOCAL_ONE", "QUORUM")
+	SerialConsistency   string                        `json:"serialConsistency,omitempty"` // Serial consistency for LWTs (SERIAL or LOCAL_SERIAL)
+	PageSize            int                           `json:"pageSize,omitempty"`
+	MaxMemoryMB         int                           `json:"maxMemoryMB,omitempty"`         // Max memory for results in MB (default: 10)
+	ConnectTimeout      int                           `json:"connectTimeout,omitempty"`      // Connection timeout in seconds
+	RequestTimeout      int                           `json:"requestTimeout,omitempty"`      // Request timeout in seconds
+	HealthCheckInterval int                           `json:"healthCheckInterval,omitempty"` // Seconds between connection checks (default: 5, -1 disables)
+	ReplayIdempotent    bool                          `json:"replayIdempotent,omitempty"`    // Re-run idempotent statements that failed when the connection was lost
+	Retry               *RetryConfig                  `json:"retry,omitempty"`               // Retry and speculative execution policies
+	ProtocolVersion     int                           `json:"protocolVersion,omitempty"`     // Native protocol version (3, 4 or 5; default: negotiate)
+	Compression         string                        `json:"compression,omitempty"`         // Frame compression: lz4, snappy or none (default: lz4 for COPY only)
+	Lint                string                        `json:"lint,omitempty"`                // Query linting: warn, confirm or off (default: warn)
+	Debug               bool                          `json:"debug,omitempty"`               // Enable debug logging
+	HistoryFile         string                        `json:"historyFile,omitempty"`         // Path to CQL command history file
+	AIHistoryFile       string                        `json:"aiHistoryFile,omitempty"`       // Path to AI command history file
+	OutputFormat        string                        `json:"outputFormat,omitempty"`        // Default output format (TABLE, ASCII, EXPAND, JSON)
+	SSL                 *SSLConfig                    `json:"ssl,omitempty"`
+	AI                  *AIConfig                     `json:"ai,omitempty"`
+	AuthProvider        *AuthProvider                 `json:"authProvider,omitempty"`
+	Profiles            map[string]*ConnectionProfile `json:"profiles,omitempty"` // Named connection profiles
 	LoadWarnings        []string        `json:"-"` // Warnings from loading config files (not serialized)
 }
 
+// ConnectionProfile holds the connection settings of a named profile.
+// Fields left empty fall back to the top-level configuration.
+type ConnectionProfile struct {
+	Host              string     `json:"host,omitempty"`
+	Hosts             []string   `json:"hosts,omitempty"`
+	Port              int        `json:"port,omitempty"`
+	LocalDC           string     `json:"localDC,omitempty"`
+	Keyspace          string     `json:"keyspace,omitempty"`
+	Username          string     `json:"username,omitempty"`
+	Password          string     `json:"password,omitempty"`
+	PasswordCommand   string     `json:"passwordCommand,omitempty"`
+	Consistency       string     `json:"consistency,omitempty"`
+	SerialConsistency string     `json:"serialConsistency,omitempty"`
+	SSL               *SSLConfig `json:"ssl,omitempty"`
+}
+
 // AuthProvider holds authentication provider configuration
 type AuthProvider struct {
 	Module    string `json:"module,omitempty"`    // e.g., "cassandra.auth"
 	ClassName string `json:"className,omitempty"` // e.g., "PlainTextAuthProvider"
 }
 
+// RetryConfig holds the retry and speculative execution settings. Only idempotent
+// statements are retried.
+type RetryConfig struct {
+	Policy              string `json:"policy,omitempty"`              // none (default), simple, exponential or downgrading
+	NumRetries          int    `json:"numRetries,omitempty"`          // Retries after the first attempt (default: 3)
+	MinBackoffMs        int    `json:"minBackoffMs,omitempty"`        // Exponential backoff: first wait (default: 100)
+	MaxBackoffMs        int    `json:"maxBackoffMs,omitempty"`        // Exponential backoff: longest wait (default: 10000)
+	SpeculativeAttempts int    `json:"speculativeAttempts,omitempty"` // Extra executions started while a statement is slow (default: 0, off)
+	SpeculativeDelayMs  int    `json:"speculativeDelayMs,omitempty"`  // Wait before each speculative execution
+}
+
 // SSLConfig holds SSL/TLS configuration options
 type SSLConfig struct {
 	Enabled            bool   `json:"enabled"`
@@ -199,9 +238,17 @@ func OverrideWithEnvVars(config *Config) {
 	// Connection settings
 	if host := os.Getenv("CASSANDRA_HOST"); host != "" {
 		config.Host = host
+		config.Hosts = nil
 	}
 	if host := os.Getenv("CQLAI_HOST"); host != "" {
 		config.Host = host
+		config.Hosts = nil
+	}
+	if localDC := os.Getenv("CQLAI_LOCAL_DC"); localDC != "" {
+		config.LocalDC = localDC
+	}
+	if serial := os.Getenv("CQLAI_SERIAL_CONSISTENCY"); serial != "" {
+		config.SerialConsistency = serial
 	}
 
 	if port := os.Getenv("CASSANDRA_PORT"); port != "" {
@@ -397,6 +444,87 @@ func OverrideWithEnvVars(config *Config) {
 	}
 }
 
+// ContactPoints returns the hosts to connect to. The hosts list takes
+// precedence over host, which may also hold a comma-separated list.
+func (c *Config) ContactPoints() []string {
+	if len(c.Hosts) > 0 {
+		return SplitHosts(strings.Join(c.Hosts, ","))
+	}
+	return SplitHosts(c.Host)
+}
+
+// ProfileNames returns the names of the configured connection profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile overlays the settings of the named connection profile onto the configuration
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok || profile == nil {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	if len(profile.Hosts) > 0 || profile.Host != "" {
+		c.Host = profile.Host
+		c.Hosts = profile.Hosts
+	}
+	if profile.Port != 0 {
+		c.Port = profile.Port
+	}
+	if profile.LocalDC != "" {
+		c.LocalDC = profile.LocalDC
+	}
+	if profile.Keyspace != "" {
+		c.Keyspace = profile.Keyspace
+	}
+	if profile.Username != "" {
+		c.Username = profile.Username
+	}
+	if profile.Password != "" {
+		c.Password = profile.Password
+	}
+	if profile.PasswordCommand != "" {
+		// The profile's helper replaces any password set at the top level
+		c.PasswordCommand = profile.PasswordCommand
+		if profile.Password == "" {
+			c.Password = ""
+		}
+	}
+	if profile.Consistency != "" {
+		c.Consistency = profile.Consistency
+	}
+	if profile.SerialConsistency != "" {
+		c.SerialConsistency = profile.SerialConsistency
+	}
+	if profile.SSL != nil {
+		c.SSL = profile.SSL
+	}
+
+	logger.DebugfToFile("Config", "Applied profile %s: hosts=%v, username=%s, keyspace=%s",
+		name, c.ContactPoints(), c.Username, c.Keyspace)
+	return nil
+}
+
+// SplitHosts splits a comma-separated host list, dropping empty entries
+func SplitHosts(hosts string) []string {
+	var result []string
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			result = append(result, host)
+		}
+	}
+	return result
+}
+
 // ParseOutputFormat converts a string to OutputFormat
 func ParseOutputFormat(format string) (OutputFormat, error) {
 	switch strings.ToUpper(format) {
@@ -175,4 +175,87 @@ password = credpass123
 	if config.Password != "credpass123" {
 		t.Errorf("Expected password to be 'credpass123', got '%s'", config.Password)
 	}
-}
\ No newline at end of file
+}
+
+func TestContactPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected []string
+	}{
+		{"single host", Config{Host: "127.0.0.1"}, []string{"127.0.0.1"}},
+		{"comma-separated host", Config{Host: "10.0.0.1, 10.0.0.2,,10.0.0.3:9043"}, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3:9043"}},
+		{"hosts list takes precedence", Config{Host: "localhost", Hosts: []string{"10.0.0.1", " 10.0.0.2 "}}, []string{"10.0.0.1", "10.0.0.2"}},
+		{"empty", Config{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.ContactPoints()
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			Host:     "localhost",
+			Port:     9042,
+			Username: "cassandra",
+			Password: "cassandra",
+			Keyspace: "app",
+			Profiles: map[string]*ConnectionProfile{
+				"prod": {
+					Hosts:    []string{"10.0.0.1", "10.0.0.2"},
+					LocalDC:  "dc1",
+					Username: "admin",
+					SSL:      &SSLConfig{Enabled: true},
+				},
+				"staging": {Host: "staging.example.com", Port: 9043},
+			},
+		}
+	}
+
+	t.Run("profile overrides connection settings", func(t *testing.T) {
+		cfg := newConfig()
+		if err := cfg.ApplyProfile("prod"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.ContactPoints(); len(got) != 2 || got[0] != "10.0.0.1" {
+			t.Errorf("expected prod contact points, got %v", got)
+		}
+		if cfg.LocalDC != "dc1" || cfg.Username != "admin" || cfg.SSL == nil || !cfg.SSL.Enabled {
+			t.Errorf("profile settings not applied: %+v", cfg)
+		}
+		// Unset profile fields fall back to the top-level configuration
+		if cfg.Password != "cassandra" || cfg.Port != 9042 || cfg.Keyspace != "app" {
+			t.Errorf("top-level settings should be kept: %+v", cfg)
+		}
+	})
+
+	t.Run("profile host replaces top-level hosts", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Hosts = []string{"10.1.0.1", "10.1.0.2"}
+		if err := cfg.ApplyProfile("staging"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.ContactPoints(); len(got) != 1 || got[0] != "staging.example.com" || cfg.Port != 9043 {
+			t.Errorf("expected staging host, got %v:%d", got, cfg.Port)
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		err := newConfig().ApplyProfile("dev")
+		if err == nil || err.Error() != `unknown profile "dev" (available: prod, staging)` {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// passwordCommandTimeout bounds how long a password helper may run
+const passwordCommandTimeout = 30 * time.Second
+
+// ReadPasswordFile reads a password from a file such as a mounted secret. A trailing
+// newline is not part of the password.
+func ReadPasswordFile(path string) (string, error) {
+	// Only the user's own home directory is expanded; ~other/ is left alone
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - Path comes from the user's environment
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// PasswordFromEnv returns the password set in CQLAI_PASSWORD or, failing that, read
+// from the file named by CQLAI_PASSWORD_FILE. It is "" when neither is set.
+func PasswordFromEnv() (string, error) {
+	if password := os.Getenv("CQLAI_PASSWORD"); password != "" {
+		return password, nil
+	}
+	path := os.Getenv("CQLAI_PASSWORD_FILE")
+	if path == "" {
+		return "", nil
+	}
+	password, err := ReadPasswordFile(path)
+	if err != nil {
+		return "", fmt.Errorf("CQLAI_PASSWORD_FILE: %w", err)
+	}
+	return password, nil
+}
+
+// runPasswordCommand runs a password helper through the shell and returns what it
+// prints on stdout, without the trailing newline
+func runPasswordCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command) // #nosec G204 - The command is configured by the user
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 - The command is configured by the user
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// No stdin: the terminal may belong to the shell's UI (e.g. CONNECT), so a helper
+	// that needs to ask for something has to use its own dialog
+	cmd.Stdin = nil
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("password command failed: %v: %s", err, msg)
+		}
+		return "", fmt.Errorf("password command failed: %w", err)
+	}
+
+	password := strings.TrimRight(stdout.String(), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password command printed no password")
+	}
+	return password, nil
+}
+
+// ResolvePassword runs the password command if a username is set and no password was
+// given any other way
+func (c *Config) ResolvePassword() error {
+	if c.Password != "" || c.Username == "" || c.PasswordCommand == "" {
+		return nil
+	}
+	logger.DebugfToFile("Config", "Running password command for user %s", c.Username)
+	password, err := runPasswordCommand(c.PasswordCommand)
+	if err != nil {
+		return err
+	}
+	c.Password = password
+	return nil
+}
+
+// NeedsPasswordPrompt reports whether a username is set but there is no password and
+// no password command to get one
+func (c *Config) NeedsPasswordPrompt() bool {
+	return c.Username != "" && c.Password == "" && c.PasswordCommand == ""
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadPasswordFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("s3cret pass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	password, err := ReadPasswordFile(path)
+	if err != nil || password != "s3cret pass" {
+		t.Errorf("ReadPasswordFile() = %q, %v; want %q", password, err, "s3cret pass")
+	}
+
+	if _, err := ReadPasswordFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPasswordFileEnvVar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CQLAI_PASSWORD_FILE", path)
+	t.Setenv("CQLAI_PASSWORD", "")
+	t.Setenv("CASSANDRA_PASSWORD", "")
+
+	if password, err := PasswordFromEnv(); err != nil || password != "from-file" {
+		t.Errorf("expected the password from the file, got %q, %v", password, err)
+	}
+
+	// An explicit password takes precedence over the file
+	t.Setenv("CQLAI_PASSWORD", "from-env")
+	if password, err := PasswordFromEnv(); err != nil || password != "from-env" {
+		t.Errorf("expected the password from CQLAI_PASSWORD, got %q, %v", password, err)
+	}
+
+	t.Setenv("CQLAI_PASSWORD", "")
+	t.Setenv("CQLAI_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := PasswordFromEnv(); err == nil {
+		t.Error("expected an error for a missing password file")
+	}
+}
+
+func TestReadPasswordFileHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "password"), []byte("at-home"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if password, err := ReadPasswordFile("~/password"); err != nil || password != "at-home" {
+		t.Errorf("ReadPasswordFile(~/password) = %q, %v", password, err)
+	}
+
+	// ~user/ names another user's home, which is not expanded to ours
+	if _, err := ReadPasswordFile("~other/password"); err == nil {
+		t.Error("expected ~other/password not to be read from $HOME")
+	}
+}
+
+func TestResolvePassword(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("password command tests use sh")
+	}
+
+	cfg := &Config{Username: "app", PasswordCommand: "echo helper-secret"}
+	if err := cfg.ResolvePassword(); err != nil || cfg.Password != "helper-secret" {
+		t.Errorf("ResolvePassword() = %v, password %q", err, cfg.Password)
+	}
+
+	// A password that is already set is not replaced
+	cfg = &Config{Username: "app", Password: "given", PasswordCommand: "echo helper-secret"}
+	if err := cfg.ResolvePassword(); err != nil || cfg.Password != "given" {
+		t.Errorf("expected the given password to be kept, got %q, %v", cfg.Password, err)
+	}
+
+	cfg = &Config{Username: "app", PasswordCommand: "echo locked >&2; exit 3"}
+	err := cfg.ResolvePassword()
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("expected the helper's error output, got %v", err)
+	}
+
+	cfg = &Config{Username: "app", PasswordCommand: "true"}
+	if err := cfg.ResolvePassword(); err == nil {
+		t.Error("expected an error when the helper prints nothing")
+	}
+}
+
+func TestNeedsPasswordPrompt(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected bool
+	}{
+		{Config{}, false},
+		{Config{Username: "app"}, true},
+		{Config{Username: "app", Password: "secret"}, false},
+		{Config{Username: "app", PasswordCommand: "pass show cassandra"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.NeedsPasswordPrompt(); got != tt.expected {
+			t.Errorf("NeedsPasswordPrompt(%+v) = %v, want %v", tt.cfg, got, tt.expected)
+		}
+	}
+}
+
+func TestApplyProfilePasswordCommand(t *testing.T) {
+	cfg := &Config{
+		Username: "admin",
+		Password: "top-level",
+		Profiles: map[string]*ConnectionProfile{
+			"prod": {Username: "app", PasswordCommand: "pass show prod/cassandra"},
+		},
+	}
+	if err := cfg.ApplyProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "" || cfg.PasswordCommand != "pass show prod/cassandra" {
+		t.Errorf("expected the profile's password command to replace the password, got %q / %q",
+			cfg.Password, cfg.PasswordCommand)
+	}
+}
@@ -0,0 +1,367 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// cqlNumberPattern matches integer and floating point CQL literals
+var cqlNumberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// ExtractBindMarkers finds the bind markers of a statement. Named markers (:name) are
+// rewritten to ? so the statement can be prepared; each positional marker (?) is named
+// after its 1-based position among the positional markers. String literals, quoted
+// identifiers and comments are left untouched.
+func ExtractBindMarkers(query string) (string, []string) {
+	var sb strings.Builder
+	var markers []string
+	positional := 0
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			end, _ := quotedEnd(query, i, ch)
+			sb.WriteString(query[i:end])
+			i = end - 1
+		case strings.HasPrefix(query[i:], "$$"):
+			end := strings.Index(query[i+2:], "$$")
+			if end < 0 {
+				sb.WriteString(query[i:])
+				return sb.String(), markers
+			}
+			sb.WriteString(query[i : i+end+4])
+			i += end + 3
+		case strings.HasPrefix(query[i:], "--") || strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				sb.WriteString(query[i:])
+				return sb.String(), markers
+			}
+			sb.WriteString(query[i : i+end])
+			i += end - 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				sb.WriteString(query[i:])
+				return sb.String(), markers
+			}
+			sb.WriteString(query[i : i+end+4])
+			i += end + 3
+		case ch == '?':
+			positional++
+			markers = append(markers, strconv.Itoa(positional))
+			sb.WriteByte(ch)
+		case ch == ':' && isNamedMarkerStart(query, i):
+			end := i + 1
+			for end < len(query) && isIdentifierChar(query[end]) {
+				end++
+			}
+			markers = append(markers, query[i+1:end])
+			sb.WriteByte('?')
+			i = end - 1
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String(), markers
+}
+
+// quotedEnd returns the index just past the literal starting at query[start], where a
+// doubled quote character is an escaped quote. It returns false if the literal is not closed.
+func quotedEnd(query string, start int, quote byte) (int, bool) {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, true
+	}
+	return len(query), false
+}
+
+// isNamedMarkerStart reports whether the colon at query[i] starts a :name marker rather
+// than being part of a map or UDT literal such as {'a':1} or {field:value}
+func isNamedMarkerStart(query string, i int) bool {
+	if i+1 >= len(query) {
+		return false
+	}
+	next := query[i+1]
+	if !(next == '_' || (next >= 'a' && next <= 'z') || (next >= 'A' && next <= 'Z')) {
+		return false
+	}
+	if i > 0 {
+		prev := query[i-1]
+		if isIdentifierChar(prev) || prev == ':' || prev == '\'' || prev == '"' || prev == ')' {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentifierChar(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// ParseCQLLiteral parses a CQL literal into the JSON-like values CoerceJSONValue accepts:
+// quoted strings become strings, numbers json.Number, lists, sets and tuples []interface{},
+// maps and UDTs map[string]interface{}. Unquoted values such as UUIDs, blobs and
+// durations are returned as strings.
+func ParseCQLLiteral(literal string) (interface{}, error) {
+	p := &literalParser{input: literal}
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	return val, nil
+}
+
+type literalParser struct {
+	input string
+	pos   int
+}
+
+func (p *literalParser) skipWhitespace() {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *literalParser) parseValue() (interface{}, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("expected a value at position %d", p.pos)
+	}
+
+	switch ch := p.input[p.pos]; {
+	case ch == '\'':
+		end, ok := quotedEnd(p.input, p.pos, '\'')
+		if !ok {
+			return nil, fmt.Errorf("unterminated string starting at position %d", p.pos)
+		}
+		s := strings.ReplaceAll(p.input[p.pos+1:end-1], "''", "'")
+		p.pos = end
+		return s, nil
+	case strings.HasPrefix(p.input[p.pos:], "$$"):
+		end := strings.Index(p.input[p.pos+2:], "$$")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string starting at position %d", p.pos)
+		}
+		s := p.input[p.pos+2 : p.pos+2+end]
+		p.pos += end + 4
+		return s, nil
+	case ch == '[':
+		return p.parseSequence(']')
+	case ch == '(':
+		return p.parseSequence(')')
+	case ch == '{':
+		return p.parseBraces()
+	}
+
+	token := p.parseToken()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:p.pos+1], p.pos)
+	}
+	switch strings.ToLower(token) {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if cqlNumberPattern.MatchString(token) {
+		return json.Number(token), nil
+	}
+	return token, nil
+}
+
+// parseToken reads an unquoted value up to the next delimiter
+func (p *literalParser) parseToken() string {
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(" \t\r\n,:[](){}'", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// parseSequence parses a list or tuple whose opening bracket is at the current position
+func (p *literalParser) parseSequence(closing byte) (interface{}, error) {
+	p.pos++
+	items := []interface{}{}
+	p.skipWhitespace()
+	if p.pos < len(p.input) && p.input[p.pos] == closing {
+		p.pos++
+		return items, nil
+	}
+	for {
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipWhitespace()
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("expected '%c' at end of input", closing)
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case closing:
+			p.pos++
+			return items, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or '%c' at position %d", closing, p.pos)
+		}
+	}
+}
+
+// parseBraces parses a set ({a, b}) or a map or UDT ({k: v}); an empty {} is an empty map
+func (p *literalParser) parseBraces() (interface{}, error) {
+	p.pos++
+	p.skipWhitespace()
+	if p.pos < len(p.input) && p.input[p.pos] == '}' {
+		p.pos++
+		return map[string]interface{}{}, nil
+	}
+
+	var set []interface{}
+	var obj map[string]interface{}
+	for {
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		p.skipWhitespace()
+		if p.pos < len(p.input) && p.input[p.pos] == ':' && set == nil {
+			if obj == nil {
+				obj = make(map[string]interface{})
+			}
+			p.pos++
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			obj[jsonScalarString(item)] = value
+			p.skipWhitespace()
+		} else if obj == nil {
+			set = append(set, item)
+		} else {
+			return nil, fmt.Errorf("expected ':' at position %d", p.pos)
+		}
+
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("expected '}' at end of input")
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			if obj != nil {
+				return obj, nil
+			}
+			return set, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or '}' at position %d", p.pos)
+		}
+	}
+}
+
+// CoerceLiteral parses a CQL literal and converts it to the Go value gocql binds for typeInfo
+func CoerceLiteral(literal string, typeInfo gocql.TypeInfo, keyspace string, registry *UDTRegistry) (interface{}, error) {
+	val, err := ParseCQLLiteral(literal)
+	if err != nil {
+		return nil, err
+	}
+
+	cqlType := cqlTypeInfoFromGocql(typeInfo)
+	// {} is parsed as an empty map but is also the empty set literal
+	if obj, ok := val.(map[string]interface{}); ok && len(obj) == 0 &&
+		(cqlType.BaseType == "set" || cqlType.BaseType == "list") {
+		val = []interface{}{}
+	}
+	return CoerceJSONValue(val, cqlType, keyspace, registry)
+}
+
+// cqlTypeInfoFromGocql converts a bind marker type reported by the driver to CQLTypeInfo
+func cqlTypeInfoFromGocql(typeInfo gocql.TypeInfo) *CQLTypeInfo {
+	typeStr := formatGocqlTypeInfo(typeInfo)
+	if typeStr != "custom" {
+		if parsed, err := ParseCQLType(typeStr); err == nil {
+			return parsed
+		}
+	}
+	// Custom types such as vectors are bound from their JSON-like value as is
+	return &CQLTypeInfo{BaseType: typeStr}
+}
+
+// ExecuteBoundQueryContext prepares a statement with ? bind markers and executes it with
+// the given CQL literals, converted to the types of the markers
+func (s *Session) ExecuteBoundQueryContext(ctx context.Context, query string, literals []string) interface{} {
+	if !s.Connected() {
+		return fmt.Errorf("not connected to database")
+	}
+
+	metadata, err := s.driver().StatementMetadata(ctx, query, "")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %v", err)
+	}
+	if len(metadata.BindColumns) != len(literals) {
+		return fmt.Errorf("statement has %d bind markers but %d values were given", len(metadata.BindColumns), len(literals))
+	}
+
+	values := make([]interface{}, len(literals))
+	for i, col := range metadata.BindColumns {
+		value, err := CoerceLiteral(literals[i], col.TypeInfo, col.Keyspace, s.GetUDTRegistry())
+		if err != nil {
+			return fmt.Errorf("bind marker %d (%s %s): %v", i+1, col.Name, formatGocqlTypeInfo(col.TypeInfo), err)
+		}
+		values[i] = value
+	}
+
+	logger.DebugfToFile("ExecuteBoundQuery", "Executing %s with %d bound values", query, len(values))
+	return s.executeCQLQuery(ctx, query, values)
+}
+
+// SplitCQLLiterals splits a comma-separated list of CQL literals, such as the values
+// of EXECUTE name (v1, v2), into the individual literals. Commas inside strings and
+// collections do not split.
+func SplitCQLLiterals(list string) ([]string, error) {
+	p := &literalParser{input: list}
+	var literals []string
+	p.skipWhitespace()
+	if p.pos >= len(p.input) {
+		return literals, nil
+	}
+	for {
+		p.skipWhitespace()
+		start := p.pos
+		if _, err := p.parseValue(); err != nil {
+			return nil, err
+		}
+		literals = append(literals, p.input[start:p.pos])
+		p.skipWhitespace()
+		if p.pos >= len(p.input) {
+			return literals, nil
+		}
+		if p.input[p.pos] != ',' {
+			return nil, fmt.Errorf("expected ',' at position %d", p.pos)
+		}
+		p.pos++
+	}
+}
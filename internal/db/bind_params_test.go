@@ -0,0 +1,137 @@
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+func TestExtractBindMarkers(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		rewritten string
+		markers   []string
+	}{
+		{
+			name:      "no markers",
+			query:     "SELECT * FROM users",
+			rewritten: "SELECT * FROM users",
+		},
+		{
+			name:      "named markers",
+			query:     "SELECT * FROM users WHERE id = :uid AND org=:org_id",
+			rewritten: "SELECT * FROM users WHERE id = ? AND org=?",
+			markers:   []string{"uid", "org_id"},
+		},
+		{
+			name:      "positional markers",
+			query:     "INSERT INTO users (id, name) VALUES (?, ?)",
+			rewritten: "INSERT INTO users (id, name) VALUES (?, ?)",
+			markers:   []string{"1", "2"},
+		},
+		{
+			name:      "markers inside literals and comments are ignored",
+			query:     "SELECT * FROM t WHERE a = ':x?' AND \"col:y\" = :z -- :c ?\n/* ? */ AND b = $$:d$$",
+			rewritten: "SELECT * FROM t WHERE a = ':x?' AND \"col:y\" = ? -- :c ?\n/* ? */ AND b = $$:d$$",
+			markers:   []string{"z"},
+		},
+		{
+			name:      "map and UDT literals are not markers",
+			query:     "UPDATE t SET m = {'a':1, 'b': 2}, u = {street:'x'} WHERE id = :id",
+			rewritten: "UPDATE t SET m = {'a':1, 'b': 2}, u = {street:'x'} WHERE id = ?",
+			markers:   []string{"id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewritten, markers := ExtractBindMarkers(tt.query)
+			if rewritten != tt.rewritten {
+				t.Errorf("unexpected query\n got: %q\nwant: %q", rewritten, tt.rewritten)
+			}
+			if !reflect.DeepEqual(markers, tt.markers) {
+				t.Errorf("unexpected markers: got %v, want %v", markers, tt.markers)
+			}
+		})
+	}
+}
+
+func TestParseCQLLiteral(t *testing.T) {
+	tests := []struct {
+		literal  string
+		expected interface{}
+	}{
+		{"'it''s'", "it's"},
+		{"$$raw 'text'$$", "raw 'text'"},
+		{"42", json.Number("42")},
+		{"-1.5e3", json.Number("-1.5e3")},
+		{"TRUE", true},
+		{"null", nil},
+		{"550e8400-e29b-41d4-a716-446655440000", "550e8400-e29b-41d4-a716-446655440000"},
+		{"0xcafe", "0xcafe"},
+		{"[1, 2]", []interface{}{json.Number("1"), json.Number("2")}},
+		{"{'a', 'b'}", []interface{}{"a", "b"}},
+		{"{'a': 1, 'b': [true]}", map[string]interface{}{"a": json.Number("1"), "b": []interface{}{true}}},
+		{"{street: 'Main', zip: 1}", map[string]interface{}{"street": "Main", "zip": json.Number("1")}},
+		{"(1, 'x')", []interface{}{json.Number("1"), "x"}},
+		{"{}", map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			got, err := ParseCQLLiteral(tt.literal)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+
+	for _, literal := range []string{"'unterminated", "[1, 2", "{'a': 1, 'b'}", "1 2", ""} {
+		if _, err := ParseCQLLiteral(literal); err == nil {
+			t.Errorf("expected an error for %q", literal)
+		}
+	}
+}
+
+func TestCoerceLiteral(t *testing.T) {
+	intType := gocql.NewNativeType(4, gocql.TypeInt, "")
+	uuidType := gocql.NewNativeType(4, gocql.TypeUUID, "")
+
+	got, err := CoerceLiteral("42", intType, "ks", nil)
+	if err != nil || got != int32(42) {
+		t.Errorf("int: got %#v, %v", got, err)
+	}
+
+	got, err = CoerceLiteral("550e8400-e29b-41d4-a716-446655440000", uuidType, "ks", nil)
+	if err != nil || got.(gocql.UUID).String() != "550e8400-e29b-41d4-a716-446655440000" {
+		t.Errorf("uuid: got %#v, %v", got, err)
+	}
+
+	if _, err := CoerceLiteral("'abc'", intType, "ks", nil); err == nil {
+		t.Error("expected an error binding text to an int marker")
+	}
+}
+
+func TestSplitCQLLiterals(t *testing.T) {
+	got, err := SplitCQLLiterals(" 42, 'a, b', [1, 2], {'k': 'v'} ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"42", "'a, b'", "[1, 2]", "{'k': 'v'}"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+
+	if got, err := SplitCQLLiterals(""); err != nil || len(got) != 0 {
+		t.Errorf("empty list: got %q, %v", got, err)
+	}
+	if _, err := SplitCQLLiterals("1 2"); err == nil {
+		t.Error("expected an error for a missing comma")
+	}
+}
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// CancelledError is returned when a running query is cancelled (for example with Ctrl+C)
+type CancelledError struct {
+	Rows int64 // Rows read before the query was cancelled
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("query cancelled after %d rows", e.Rows)
+}
+
+// Is reports a CancelledError as context.Canceled so callers can use errors.Is
+func (e *CancelledError) Is(target error) bool {
+	return target == context.Canceled
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCancelledError(t *testing.T) {
+	var err error = &CancelledError{Rows: 42}
+
+	if got := err.Error(); got != "query cancelled after 42 rows" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Error("expected CancelledError to match context.Canceled")
+	}
+
+	var cancelled *CancelledError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &cancelled) || cancelled.Rows != 42 {
+		t.Errorf("expected to unwrap CancelledError with 42 rows, got %+v", cancelled)
+	}
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/apache/cassandra-gocql-driver/v2/lz4"
+	"github.com/apache/cassandra-gocql-driver/v2/snappy"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// Frame compression accepted by the compression setting
+const (
+	CompressionNone   = "none"
+	CompressionLZ4    = "lz4"
+	CompressionSnappy = "snappy"
+)
+
+// supportedProtocolVersions are tried in order when no protocol version is pinned
+var supportedProtocolVersions = []int{5, 4, 3}
+
+// ParseCompression validates a compression setting. An empty setting leaves interactive
+// queries uncompressed and compresses COPY.
+func ParseCompression(name string) (string, error) {
+	switch compression := strings.ToLower(strings.TrimSpace(name)); compression {
+	case "", CompressionNone, CompressionLZ4, CompressionSnappy:
+		return compression, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q (use lz4, snappy or none)", name)
+	}
+}
+
+// newCompressor returns the driver compressor for a compression setting, or nil
+func newCompressor(compression string) gocql.Compressor {
+	switch compression {
+	case CompressionLZ4:
+		return lz4.LZ4Compressor{}
+	case CompressionSnappy:
+		return snappy.SnappyCompressor{}
+	default:
+		return nil
+	}
+}
+
+// protocolVersionsToTry returns the protocol versions to negotiate, or only the pinned one
+func protocolVersionsToTry(pinned int) ([]int, error) {
+	if pinned == 0 {
+		return supportedProtocolVersions, nil
+	}
+	for _, version := range supportedProtocolVersions {
+		if version == pinned {
+			return []int{pinned}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported protocol version %d (use 3, 4 or 5)", pinned)
+}
+
+// ProtocolVersion returns the native protocol version negotiated with the cluster
+func (s *Session) ProtocolVersion() int {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	if s.cluster == nil {
+		return 0
+	}
+	return s.cluster.ProtoVersion
+}
+
+// Compression describes the frame compression requested for the session. The server
+// may not support it, in which case the driver falls back to uncompressed frames.
+func (s *Session) Compression() string {
+	if s.compression == "" {
+		return CompressionNone + " (" + CompressionLZ4 + " for COPY)"
+	}
+	return s.compression
+}
+
+// StartBulkTransfer returns the session a COPY runs on and a function that ends the
+// transfer. When no compression was configured that is a copy of s on an LZ4-compressed
+// driver session, opened by the first COPY and kept for the next ones; otherwise, or
+// if the compressed connection can't be opened, the COPY runs on s itself.
+func (s *Session) StartBulkTransfer() (bulk *Session, done func()) {
+	if s == nil || s.compression != "" {
+		return s, func() {}
+	}
+	conn, release := s.acquireBulkDriver()
+	if conn == nil {
+		return s, func() {}
+	}
+
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	bulk = &Session{
+		conn:              conn,
+		cluster:           s.clusterConfig(),
+		consistency:       s.consistency,
+		serialConsistency: s.serialConsistency,
+		pageSize:          s.pageSize,
+		tracing:           s.tracing,
+		autoFetch:         s.autoFetch,
+		username:          s.username,
+		cassandraVersion:  s.cassandraVersion,
+		schemaCache:       s.GetSchemaCache(),
+		contactPoints:     s.contactPoints,
+		localDC:           s.localDC,
+		replayIdempotent:  s.replayIdempotent,
+		retrySettings:     s.retrySettings,
+		retryPolicy:       s.retryPolicy,
+		speculative:       s.speculative,
+		compression:       CompressionLZ4,
+		lintMode:          s.lintMode,
+		configFile:        s.configFile,
+		offline:           s.offline,
+	}
+	bulk.SetUDTRegistry(s.GetUDTRegistry())
+	return bulk, release
+}
+
+// acquireBulkDriver returns the LZ4-compressed driver session COPY runs on, opening it
+// on first use, and keeps it open until release is called. It returns nil when the
+// session can't be opened.
+func (s *Session) acquireBulkDriver() (conn *driverConn, release func()) {
+	s.openMu.Lock()
+	defer s.openMu.Unlock()
+
+	s.connMu.RLock()
+	conn = s.bulkConn
+	if conn != nil {
+		release = conn.acquire()
+	}
+	s.connMu.RUnlock()
+	if conn != nil {
+		return conn, release
+	}
+
+	cluster := s.clusterConfig()
+	if cluster == nil {
+		return nil, func() {}
+	}
+	cluster.Compressor = newCompressor(CompressionLZ4)
+	// The session statements run on already delivers the schema events
+	cluster.Metadata.SchemaListener = gocql.SchemaListenersConfig{}
+	bulkSession, err := createSession(cluster, s.localDC)
+	if err != nil {
+		logger.DebugfToFile("StartBulkTransfer", "Compressed connection failed, continuing without compression: %v", err)
+		return nil, func() {}
+	}
+
+	conn = &driverConn{session: bulkSession}
+	s.connMu.Lock()
+	s.bulkConn = conn
+	release = conn.acquire()
+	s.connMu.Unlock()
+	return conn, release
+}
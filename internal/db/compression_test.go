@@ -0,0 +1,54 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := map[string]string{
+		"":       "",
+		"none":   CompressionNone,
+		"LZ4":    CompressionLZ4,
+		"snappy": CompressionSnappy,
+	}
+	for value, expected := range tests {
+		if got, err := ParseCompression(value); err != nil || got != expected {
+			t.Errorf("ParseCompression(%q) = %q, %v; want %q", value, got, err, expected)
+		}
+	}
+	if _, err := ParseCompression("gzip"); err == nil {
+		t.Error("expected an error for gzip")
+	}
+}
+
+func TestProtocolVersionsToTry(t *testing.T) {
+	versions, err := protocolVersionsToTry(0)
+	if err != nil || !reflect.DeepEqual(versions, []int{5, 4, 3}) {
+		t.Errorf("expected to negotiate 5, 4, 3, got %v, %v", versions, err)
+	}
+	versions, err = protocolVersionsToTry(4)
+	if err != nil || !reflect.DeepEqual(versions, []int{4}) {
+		t.Errorf("expected only version 4, got %v, %v", versions, err)
+	}
+	if _, err := protocolVersionsToTry(2); err == nil {
+		t.Error("expected an error for protocol version 2")
+	}
+}
+
+func TestCompressionDescription(t *testing.T) {
+	if got := (&Session{}).Compression(); got != "none (lz4 for COPY)" {
+		t.Errorf("unexpected default compression %q", got)
+	}
+	if got := (&Session{compression: CompressionSnappy}).Compression(); got != "snappy" {
+		t.Errorf("unexpected compression %q", got)
+	}
+
+	// Without a cluster the COPY runs on the session itself
+	s := &Session{}
+	bulk, done := s.StartBulkTransfer()
+	defer done()
+	if bulk != s {
+		t.Error("expected the session itself without a cluster")
+	}
+}
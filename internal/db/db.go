@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
@@ -19,32 +21,72 @@ import (
 
 // Session is a wrapper around the gocql.Session.
 type Session struct {
-	*gocql.Session
-	cluster          *gocql.ClusterConfig
-	consistency      gocql.Consistency
-	pageSize         int
-	tracing          bool
-	autoFetch        bool   // Auto-fetch all pages without scroll pauses
-	username         string // Current connection username
-	cassandraVersion string
-	schemaCache      *SchemaCache
-	udtRegistry      *UDTRegistry
-	lastTraceID      []byte // Store the last trace ID for retrieval
+	conn              *driverConn          // Current driver session; guarded by connMu, read with driver()
+	cluster           *gocql.ClusterConfig // Configuration new driver sessions are opened from; guarded by connMu
+	bulkConn          *driverConn          // LZ4-compressed driver session for COPY, opened on first use; guarded by connMu
+	openMu            sync.Mutex           // Serializes opening driver sessions
+	settingsMu        sync.RWMutex         // Guards the settings below, which the UI reads while a command changes them
+	consistency       gocql.Consistency
+	serialConsistency gocql.Consistency // Serial consistency for lightweight transactions
+	pageSize          int
+	tracing           bool
+	autoFetch         bool   // Auto-fetch all pages without scroll pauses
+	username          string // Current connection username
+	cassandraVersion  string
+	schemaCache       *SchemaCache
+	udtRegistry       *UDTRegistry
+	udtOnce           sync.Once
+	lastTraceID       []byte // Store the last trace ID for retrieval
+	contactPoints     []string
+	localDC           string
+	lastCoordinator   atomic.Pointer[gocql.HostInfo] // Node that coordinated the last query
+	preparedMu        sync.RWMutex                   // Guards prepared
+	prepared          map[string]*PreparedStatement  // Statements prepared with PREPARE, by name
+	replayIdempotent  bool                           // Re-run idempotent statements that failed because the connection was lost
+	connMu            sync.RWMutex                   // Guards replacing the driver session, cluster configuration and schema cache
+	connState         atomic.Int32                   // ConnectionState reported by the health monitor
+	healthStop        chan struct{}
+	healthDone        chan struct{}
+	schemaEvents      *schemaEventListener // nil in batch mode
+	schemaWatchMu     sync.Mutex           // Guards starting and stopping the schema watcher
+	schemaWatchStop   chan struct{}
+	schemaWatchDone   chan struct{}
+	retrySettings     RetrySettings
+	retryPolicy       gocql.RetryPolicy                // nil leaves failed statements alone
+	speculative       gocql.SpeculativeExecutionPolicy // nil disables speculative execution
+	lastRetries       atomic.Int32                     // Retries of the last statement
+	warningsMu        sync.Mutex
+	warnings          []string          // Warnings to show with the result of the current statement
+	lastWarnings      []string          // Warnings raised by the last statement
+	customPayload     map[string][]byte // Custom payload the server returned with the last statement
+	compression       string            // Configured frame compression; empty compresses COPY only
+	lintMode          string            // warn, confirm or off; empty warns
+	pagingMu          sync.Mutex
+	lastCursor        *PagingCursor // Cursor of the last streaming result, for PAGING STATE
+	configFile        string        // Config file the session was created from, for profile lookups
+	offline           bool          // Works from a schema snapshot without a cluster
 }
 
 // SessionOptions represents options for creating a session with command-line overrides
 type SessionOptions struct {
-	Host           string
-	Port           int
-	Keyspace       string
-	Username       string
-	Password       string
-	Consistency    string // Default consistency level (e.g., "LOCAL_ONE", "QUORUM")
-	SSL            *config.SSLConfig
-	BatchMode      bool   // Skip schema caching for batch mode
-	ConnectTimeout int    // Connection timeout in seconds (0 = use default)
-	RequestTimeout int    // Request timeout in seconds (0 = use default)
-	ConfigFile     string // Path to custom config file
+	Host              string
+	Hosts             []string // Contact points (takes precedence over Host)
+	Port              int
+	LocalDC           string // Local datacenter for DC-aware routing
+	Keyspace          string
+	Username          string
+	Password          string
+	Consistency       string // Default consistency level (e.g., "LOCAL_ONE", "QUORUM")
+	SerialConsistency string // Serial consistency level for LWTs (SERIAL or LOCAL_SERIAL)
+	SSL               *config.SSLConfig
+	BatchMode         bool   // Skip schema caching for batch mode
+	ConnectTimeout    int    // Connection timeout in seconds (0 = use default)
+	RequestTimeout    int    // Request timeout in seconds (0 = use default)
+	ConfigFile        string // Path to custom config file
+	ReplayIdempotent  bool   // Re-run idempotent statements after reconnecting
+	ProtocolVersion   int    // Native protocol version to use (0 = negotiate)
+	PasswordCommand   string // Command that prints the password, used when no password is set
+	SchemaFile        string // Schema snapshot to load instead of reading the schema at startup
 }
 
 // NewSession creates a new Cassandra session.
@@ -52,6 +94,37 @@ func NewSession() (*Session, error) {
 	return NewSessionWithOptions(SessionOptions{})
 }
 
+// NewProfileSession connects to the named connection profile of the config file
+// without caching its schema, e.g. to read the schema of another cluster
+func NewProfileSession(configFile, profile string) (*Session, error) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
+		return nil, err
+	}
+	return NewSessionWithOptions(SessionOptions{
+		Hosts:             cfg.ContactPoints(),
+		Port:              cfg.Port,
+		LocalDC:           cfg.LocalDC,
+		Keyspace:          cfg.Keyspace,
+		Username:          cfg.Username,
+		Password:          cfg.Password,
+		PasswordCommand:   cfg.PasswordCommand,
+		Consistency:       cfg.Consistency,
+		SerialConsistency: cfg.SerialConsistency,
+		SSL:               cfg.SSL,
+		BatchMode:         true,
+		ConfigFile:        configFile,
+	})
+}
+
+// ConfigFile returns the config file the session was created from ("" for the default locations)
+func (s *Session) ConfigFile() string {
+	return s.configFile
+}
+
 // customLogger suppresses gocql error messages to prevent terminal corruption
 type customLogger struct{}
 
@@ -89,10 +162,19 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 	}
 
 	// Override config with command-line options if provided
-	if options.Host != "" {
+	if len(options.Hosts) > 0 {
+		cfg.Host = options.Hosts[0]
+		cfg.Hosts = options.Hosts
+		logger.DebugfToFile("Session", "Overriding hosts with command-line option: %v", options.Hosts)
+	} else if options.Host != "" {
 		cfg.Host = options.Host
+		cfg.Hosts = nil
 		logger.DebugfToFile("Session", "Overriding host with command-line option: %s", options.Host)
 	}
+	if options.LocalDC != "" {
+		cfg.LocalDC = options.LocalDC
+		logger.DebugfToFile("Session", "Overriding local datacenter with command-line option: %s", options.LocalDC)
+	}
 	if options.Port != 0 {
 		cfg.Port = options.Port
 		logger.DebugfToFile("Session", "Overriding port with command-line option: %d", options.Port)
@@ -109,21 +191,41 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 		cfg.Password = options.Password
 		logger.DebugfToFile("Session", "Overriding password with command-line option")
 	}
+	if options.PasswordCommand != "" {
+		cfg.PasswordCommand = options.PasswordCommand
+	}
+	if err := cfg.ResolvePassword(); err != nil {
+		return nil, err
+	}
 	// Override SSL config if provided
 	if options.SSL != nil {
 		cfg.SSL = options.SSL
 		logger.DebugfToFile("Session", "Overriding SSL config with command-line option")
 	}
 
+	contactPoints := contactPointAddresses(cfg.ContactPoints(), cfg.Port)
+	if len(contactPoints) == 0 {
+		return nil, fmt.Errorf("no contact points configured")
+	}
+
 	// Log final configuration being used
-	logger.DebugfToFile("Session", "Final config for connection: host=%s:%d, username=%s, keyspace=%s, hasPassword=%v",
-		cfg.Host, cfg.Port, cfg.Username, cfg.Keyspace, cfg.Password != "")
+	logger.DebugfToFile("Session", "Final config for connection: hosts=%v, localDC=%s, username=%s, keyspace=%s, hasPassword=%v",
+		contactPoints, cfg.LocalDC, cfg.Username, cfg.Keyspace, cfg.Password != "")
 
 	// Create cluster configuration
-	cluster := gocql.NewCluster(fmt.Sprintf("%s:%d", cfg.Host, cfg.Port))
+	cluster := gocql.NewCluster(contactPoints...)
 	// Suppress gocql's default logging to prevent terminal corruption
 	cluster.Logger = &customLogger{}
 	cluster.Consistency = gocql.LocalOne
+	cluster.MaxWaitSchemaAgreement = DefaultSchemaAgreementTimeout
+
+	// Schema change events keep the schema cache current (not needed in batch mode)
+	var schemaEvents *schemaEventListener
+	conn := &driverConn{}
+	if !options.BatchMode {
+		schemaEvents = newSchemaEventListener()
+		cluster.Metadata.SchemaListener = schemaEvents.config(conn)
+	}
 
 	// Set timeouts based on options, config, or use defaults
 	switch {
@@ -144,7 +246,13 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 		cluster.ConnectTimeout = 10 * time.Second
 	}
 
-	cluster.DisableInitialHostLookup = true
+	// A single contact point without a local DC keeps talking to that node only,
+	// which also works when the peers' addresses aren't reachable (e.g. Docker).
+	// Otherwise discover the ring so queries are routed token- and DC-aware.
+	// createSession sets the routing policy for each session it opens.
+	if len(contactPoints) == 1 && cfg.LocalDC == "" {
+		cluster.DisableInitialHostLookup = true
+	}
 
 	if cfg.Keyspace != "" {
 		cluster.Keyspace = cfg.Keyspace
@@ -159,7 +267,12 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 
 	// Configure SSL if enabled
 	if cfg.SSL != nil && cfg.SSL.Enabled {
-		tlsConfig, err := createTLSConfig(cfg.SSL, cfg.Host)
+		// With several contact points the driver fills in each node's server name
+		tlsHost := ""
+		if len(contactPoints) == 1 {
+			tlsHost = contactPoints[0]
+		}
+		tlsConfig, err := createTLSConfig(cfg.SSL, tlsHost)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create TLS configuration: %v", err)
 		}
@@ -173,11 +286,39 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 	// Protocol v4: Cassandra 3.0+
 	// Protocol v3: Cassandra 2.1+
 	var session *gocql.Session
-	protocolVersions := []int{5, 4, 3}
+	retrySettings, err := retrySettingsFromConfig(cfg.Retry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry configuration: %w", err)
+	}
+
+	compression, err := ParseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	cluster.Compressor = newCompressor(compression)
+
+	lintMode, err := ParseLintMode(cfg.Lint)
+	if err != nil {
+		return nil, err
+	}
+	// Batch output is read by scripts, so only lint there when asked to
+	if options.BatchMode && strings.TrimSpace(cfg.Lint) == "" {
+		lintMode = LintOff
+	}
+
+	// Pin the protocol version if asked to (options override config)
+	pinnedVersion := cfg.ProtocolVersion
+	if options.ProtocolVersion != 0 {
+		pinnedVersion = options.ProtocolVersion
+	}
+	protocolVersions, err := protocolVersionsToTry(pinnedVersion)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, protoVer := range protocolVersions {
 		cluster.ProtoVersion = protoVer
-		session, err = cluster.CreateSession()
+		session, err = createSession(cluster, cfg.LocalDC)
 		if err == nil {
 			// Successfully connected
 			logger.DebugfToFile("Session", "Connected with protocol version %d", protoVer)
@@ -187,6 +328,9 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 		logger.DebugfToFile("Session", "Failed to connect with protocol version %d: %v", protoVer, err)
 	}
 
+	if session == nil && pinnedVersion != 0 {
+		return nil, fmt.Errorf("failed to connect to Cassandra with protocol version %d: %v", pinnedVersion, err)
+	}
 	if session == nil {
 		return nil, fmt.Errorf("failed to connect to Cassandra with any supported protocol version: %v", err)
 	}
@@ -249,24 +393,60 @@ func NewSessionWithOptions(options SessionOptions) (*Session, error) {
 		}
 	}
 
+	// Determine initial serial consistency level (options override config)
+	initialSerialConsistency := gocql.Serial
+	serialLevel := options.SerialConsistency
+	if serialLevel == "" {
+		serialLevel = cfg.SerialConsistency
+	}
+	if serialLevel != "" {
+		if serial, err := parseSerialConsistency(serialLevel); err == nil {
+			initialSerialConsistency = serial
+		} else {
+			logger.DebugfToFile("Session", "Invalid serial consistency level '%s', defaulting to SERIAL", serialLevel)
+		}
+	}
+
 	// Use page size from config, default to 100 if not set
 	pageSize := cfg.PageSize
 	if pageSize <= 0 {
 		pageSize = 100
 	}
 
+	conn.session = session
 	s := &Session{
-		Session:          session,
-		cluster:          cluster,
-		consistency:      initialConsistency,
-		pageSize:         pageSize,
-		tracing:          false,
-		username:         cfg.Username,
-		cassandraVersion: releaseVersion,
+		conn:              conn,
+		cluster:           cluster,
+		consistency:       initialConsistency,
+		serialConsistency: initialSerialConsistency,
+		pageSize:          pageSize,
+		tracing:           false,
+		username:          cfg.Username,
+		cassandraVersion:  releaseVersion,
+		contactPoints:     contactPoints,
+		localDC:           cfg.LocalDC,
+		replayIdempotent:  options.ReplayIdempotent || cfg.ReplayIdempotent,
+		compression:       compression,
+		lintMode:          lintMode,
+		configFile:        options.ConfigFile,
+		schemaEvents:      schemaEvents,
+	}
+	if err := s.SetRetrySettings(retrySettings); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("invalid retry configuration: %w", err)
 	}
 
 	// Initialize schema cache for AI features (skip in batch mode)
-	if !options.BatchMode {
+	if !options.BatchMode && options.SchemaFile != "" {
+		snapshot, err := LoadSchemaSnapshot(options.SchemaFile)
+		if err != nil {
+			session.Close()
+			return nil, fmt.Errorf("failed to load schema file: %w", err)
+		}
+		s.schemaCache = NewSchemaCache(s)
+		s.schemaCache.LoadSnapshot(snapshot)
+		logger.DebugfToFile("Session", "Schema cache loaded from %s with %d keyspaces", options.SchemaFile, len(snapshot.Keyspaces))
+	} else if !options.BatchMode {
 		s.schemaCache = NewSchemaCache(s)
 		if err := s.schemaCache.Refresh(); err != nil {
 			// Log error but don't fail connection - AI features will work without cache
@@ -301,6 +481,8 @@ func loadConfig(customConfigPath string) (*config.Config, error) {
 
 // Consistency returns the current consistency level
 func (s *Session) Consistency() string {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
 	switch s.consistency {
 	case gocql.Any:
 		return "ANY"
@@ -350,53 +532,113 @@ func (s *Session) SetConsistency(level string) error {
 	default:
 		return fmt.Errorf("invalid consistency level: %s", level)
 	}
+	s.settingsMu.Lock()
 	s.consistency = consistency
+	s.settingsMu.Unlock()
 	return nil
 }
 
+// SerialConsistency returns the serial consistency level used for lightweight transactions
+func (s *Session) SerialConsistency() string {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	if s.serialConsistency == gocql.LocalSerial {
+		return "LOCAL_SERIAL"
+	}
+	return "SERIAL"
+}
+
+// SetSerialConsistency sets the serial consistency level used for lightweight transactions
+func (s *Session) SetSerialConsistency(level string) error {
+	serial, err := parseSerialConsistency(level)
+	if err != nil {
+		return err
+	}
+	s.settingsMu.Lock()
+	s.serialConsistency = serial
+	s.settingsMu.Unlock()
+	return nil
+}
+
+// parseSerialConsistency converts SERIAL or LOCAL_SERIAL to its gocql level
+func parseSerialConsistency(level string) (gocql.Consistency, error) {
+	switch strings.ToUpper(level) {
+	case "SERIAL":
+		return gocql.Serial, nil
+	case "LOCAL_SERIAL":
+		return gocql.LocalSerial, nil
+	default:
+		return 0, fmt.Errorf("invalid serial consistency level: %s (valid: SERIAL, LOCAL_SERIAL)", level)
+	}
+}
+
 // PageSize returns the current page size
 func (s *Session) PageSize() int {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
 	return s.pageSize
 }
 
 // SetPageSize sets the page size
 func (s *Session) SetPageSize(size int) {
+	s.settingsMu.Lock()
 	s.pageSize = size
+	s.settingsMu.Unlock()
 }
 
 // Tracing returns whether tracing is enabled
 func (s *Session) Tracing() bool {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
 	return s.tracing
 }
 
 // SetTracing enables or disables tracing
 func (s *Session) SetTracing(enabled bool) {
+	s.settingsMu.Lock()
 	s.tracing = enabled
+	s.settingsMu.Unlock()
 }
 
 // AutoFetch returns whether auto-fetch is enabled
 func (s *Session) AutoFetch() bool {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
 	return s.autoFetch
 }
 
 // SetAutoFetch enables or disables auto-fetching all pages
 func (s *Session) SetAutoFetch(enabled bool) {
+	s.settingsMu.Lock()
 	s.autoFetch = enabled
+	s.settingsMu.Unlock()
 }
 
 // Username returns the current connection username
 func (s *Session) Username() string {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
 	return s.username
 }
 
 // Query creates a new query with session defaults applied
 func (s *Session) Query(stmt string, values ...interface{}) *gocql.Query {
-	query := s.Session.Query(stmt, values...)
-	query.Consistency(s.consistency)
+	return s.queryOn(s.driver(), stmt, values...)
+}
+
+// queryOn creates a query with session defaults applied on the given driver session
+func (s *Session) queryOn(conn *gocql.Session, stmt string, values ...interface{}) *gocql.Query {
+	s.settingsMu.RLock()
+	consistency, serial, pageSize := s.consistency, s.serialConsistency, s.pageSize
+	s.settingsMu.RUnlock()
+
+	query := conn.Query(stmt, values...)
+	query.Consistency(consistency)
+	query.SerialConsistency(serial)
 	// Only set page size if it's greater than 0
 	// PageSize 0 means use server default (no client-side paging control)
-	if s.pageSize > 0 {
-		query.PageSize(s.pageSize)
+	if pageSize > 0 {
+		query.PageSize(pageSize)
 	}
 	// Tracing will be handled in ExecuteSelectQuery when needed
 	return query
@@ -446,6 +688,8 @@ func (s *Session) IsVersion3OrHigher() bool {
 
 // GetSchemaCache returns the schema cache
 func (s *Session) GetSchemaCache() *SchemaCache {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
 	return s.schemaCache
 }
 
@@ -453,6 +697,7 @@ func (s *Session) GetSchemaCache() *SchemaCache {
 type TraceInfo struct {
 	Coordinator string
 	Duration    int
+	Retries     int // Attempts after the first, by the retry policy or speculative execution
 }
 
 // GetTraceData retrieves trace data for the last executed query
@@ -470,7 +715,7 @@ func (s *Session) GetTraceData() ([][]string, []string, *TraceInfo, error) {
 	          ORDER BY event_id`
 
 	// Use LOCAL_ONE consistency for trace queries regardless of session consistency
-	iter := s.Session.Query(query, s.lastTraceID).Consistency(gocql.LocalOne).Iter()
+	iter := s.driver().Query(query, s.lastTraceID).Consistency(gocql.LocalOne).Iter()
 	defer iter.Close()
 
 	// Define headers
@@ -502,13 +747,14 @@ func (s *Session) GetTraceData() ([][]string, []string, *TraceInfo, error) {
 	var traceInfo *TraceInfo
 	var coordinator string
 	var duration int
-	sessionIter := s.Session.Query(`SELECT coordinator, duration
+	sessionIter := s.driver().Query(`SELECT coordinator, duration
 	                                FROM system_traces.sessions
 	                                WHERE session_id = ?`, s.lastTraceID).Consistency(gocql.LocalOne).Iter()
 	if sessionIter.Scan(&coordinator, &duration) {
 		traceInfo = &TraceInfo{
 			Coordinator: coordinator,
 			Duration:    duration,
+			Retries:     s.LastRetries(),
 		}
 	}
 	_ = sessionIter.Close()
@@ -518,19 +764,28 @@ func (s *Session) GetTraceData() ([][]string, []string, *TraceInfo, error) {
 
 // Keyspace returns the current keyspace
 func (s *Session) Keyspace() string {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
 	if s.cluster != nil {
 		return s.cluster.Keyspace
 	}
 	return ""
 }
 
-// GetUDTRegistry returns the UDT registry
+// GetUDTRegistry returns the UDT registry, which reads UDT definitions through the
+// current driver session
 func (s *Session) GetUDTRegistry() *UDTRegistry {
+	s.udtOnce.Do(func() {
+		if s.udtRegistry == nil {
+			s.udtRegistry = &UDTRegistry{driver: s.driver}
+		}
+	})
 	return s.udtRegistry
 }
 
 // SetUDTRegistry sets the UDT registry
 func (s *Session) SetUDTRegistry(registry *UDTRegistry) {
+	s.udtOnce.Do(func() {})
 	s.udtRegistry = registry
 }
 
@@ -542,26 +797,54 @@ func (s *Session) GetColumnTypeFromSystemTable(keyspace, table, column string) s
 
 // SetKeyspace changes the current keyspace by recreating the session
 func (s *Session) SetKeyspace(keyspace string) error {
-	// Close the current session
-	s.Close()
-
-	// Update cluster config with new keyspace
-	s.cluster.Keyspace = keyspace
+	if s.offline {
+		if s.GetSchemaCache().Snapshot().Keyspace(keyspace) == nil {
+			return fmt.Errorf("keyspace %s not found in the schema snapshot", keyspace)
+		}
+		s.connMu.Lock()
+		s.cluster.Keyspace = keyspace
+		s.connMu.Unlock()
+		return nil
+	}
 
-	// Create new session with the new keyspace
-	newSession, err := s.cluster.CreateSession()
+	// Switch to a new session with the new keyspace
+	err := s.reopen(func(cluster *gocql.ClusterConfig) { cluster.Keyspace = keyspace })
 	if err != nil {
 		return fmt.Errorf("failed to create session with keyspace %s: %w", keyspace, err)
 	}
 
-	// Update the session
-	s.Session = newSession
-
-	// Reinitialize schema cache for the new keyspace
-	if s.schemaCache != nil {
+	// Reinitialize schema cache for the new keyspace; a loaded snapshot covers every keyspace
+	s.connMu.Lock()
+	if s.schemaCache != nil && s.schemaCache.Snapshot() == nil {
 		s.schemaCache = NewSchemaCache(s)
 	}
+	s.connMu.Unlock()
+
+	return nil
+}
+
+// Login re-authenticates as another role by recreating the session with new
+// credentials. The keyspace and all session settings are kept; if the login fails
+// the current connection stays in use.
+func (s *Session) Login(username, password string) error {
+	if username == "" {
+		return fmt.Errorf("a username is required")
+	}
+
+	err := s.reopen(func(cluster *gocql.ClusterConfig) {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: username,
+			Password: password,
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log in as %s: %w", username, err)
+	}
 
+	s.settingsMu.Lock()
+	s.username = username
+	s.settingsMu.Unlock()
+	logger.DebugfToFile("Login", "Logged in as %s", username)
 	return nil
 }
 
@@ -9,13 +9,13 @@ import (
 
 // AggregateInfo holds aggregate information for manual describe
 type AggregateInfo struct {
-	Name          string
-	ArgumentTypes []string
-	StateFunc     string
-	StateType     string
-	FinalFunc     string
-	InitCond      string
-	ReturnType    string
+	Name          string   `json:"name"`
+	ArgumentTypes []string `json:"argumentTypes"`
+	StateFunc     string   `json:"stateFunc"`
+	StateType     string   `json:"stateType"`
+	FinalFunc     string   `json:"finalFunc,omitempty"`
+	InitCond      string   `json:"initCond,omitempty"`
+	ReturnType    string   `json:"returnType"`
 }
 
 // AggregateListInfo holds aggregate list information for manual describe
@@ -37,13 +37,13 @@ func (s *Session) DescribeFunctionsQuery(currentKeyspace string) ([][]string, er
 
 // FunctionDetails holds the details of a function for describe operations
 type FunctionDetails struct {
-	Name          string
-	ArgumentTypes []string
-	ArgumentNames []string
-	ReturnType    string
-	Language      string
-	Body          string
-	CalledOnNull  bool
+	Name          string   `json:"name"`
+	ArgumentTypes []string `json:"argumentTypes"`
+	ArgumentNames []string `json:"argumentNames"`
+	ReturnType    string   `json:"returnType"`
+	Language      string   `json:"language"`
+	Body          string   `json:"body"`
+	CalledOnNull  bool     `json:"calledOnNullInput"`
 }
 
 // DescribeFunctionQuery executes the query to get detailed information about a specific function
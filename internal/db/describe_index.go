@@ -9,10 +9,10 @@ import (
 
 // IndexInfo holds index information for manual describe
 type IndexInfo struct {
-	TableName string
-	IndexName string
-	Kind      string
-	Options   map[string]string
+	TableName string            `json:"table"`
+	IndexName string            `json:"name"`
+	Kind      string            `json:"kind"`
+	Options   map[string]string `json:"options,omitempty"`
 }
 
 // DescribeIndexQuery executes the query to get index information (for pre-4.0)
@@ -20,10 +20,10 @@ type TableInfo struct {
 
 // ColumnInfo holds column information
 type ColumnInfo struct {
-	Name     string
-	DataType string
-	Kind     string
-	Position int
+	Name     string `json:"name"`
+	DataType string `json:"type"`
+	Kind     string `json:"kind"`
+	Position int    `json:"position"`
 }
 
 // TableListInfo holds table list information for manual describe
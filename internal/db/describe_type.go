@@ -9,9 +9,9 @@ import (
 
 // TypeInfo holds user-defined type information for manual describe
 type TypeInfo struct {
-	Name       string
-	FieldNames []string
-	FieldTypes []string
+	Name       string   `json:"name"`
+	FieldNames []string `json:"fieldNames"`
+	FieldTypes []string `json:"fieldTypes"`
 }
 
 // TypeListInfo holds type list information for manual describe
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
 )
@@ -19,13 +20,24 @@ func (s *Session) AddToBatch(batch *gocql.Batch, query string) {
 
 // CreateBatch creates a new batch with the specified type
 func (s *Session) CreateBatch(batchType gocql.BatchType) *gocql.Batch {
-	return s.Batch(batchType)
+	s.settingsMu.RLock()
+	serial := s.serialConsistency
+	s.settingsMu.RUnlock()
+
+	batch := s.driver().Batch(batchType)
+	batch.SerialConsistency(serial)
+	return batch
 }
 
 // ExecuteBatch executes a batch of statements
 func (s *Session) ExecuteBatch(batch *gocql.Batch) error {
+	return s.ExecuteBatchContext(context.Background(), batch)
+}
+
+// ExecuteBatchContext executes a batch of statements, giving up when ctx is cancelled
+func (s *Session) ExecuteBatchContext(ctx context.Context, batch *gocql.Batch) error {
 	if batch == nil {
 		return fmt.Errorf("no batch to execute")
 	}
-	return batch.Exec()
-}
\ No newline at end of file
+	return batch.ExecContext(ctx)
+}
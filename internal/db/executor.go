@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -214,7 +216,7 @@ func extractTableName(query string) (keyspace, table string) {
 // getColumnTypeFromSystemTable gets the full type definition for a column from system tables
 // This method is kept for backward compatibility but getColumnTypeUsingMetadata is preferred
 func (s *Session) getColumnTypeFromSystemTable(keyspace, table, column string) string {
-	if s.Session == nil {
+	if !s.Connected() {
 		return ""
 	}
 
@@ -233,7 +235,7 @@ func (s *Session) getColumnTypeFromSystemTable(keyspace, table, column string) s
 
 // getColumnTypeUsingMetadata gets the full type definition for a column using gocql metadata API
 func (s *Session) getColumnTypeUsingMetadata(keyspace, table, column string) string {
-	if s.Session == nil {
+	if !s.Connected() {
 		return ""
 	}
 
@@ -287,9 +289,43 @@ func (t *captureTracer) Trace(traceID []byte) {
 
 // ExecuteCQLQuery executes a regular CQL query
 func (s *Session) ExecuteCQLQuery(query string) interface{} {
+	return s.ExecuteCQLQueryContext(context.Background(), query)
+}
+
+// ExecuteCQLQueryContext executes a regular CQL query, stopping when ctx is cancelled
+func (s *Session) ExecuteCQLQueryContext(ctx context.Context, query string) interface{} {
+	return s.executeCQLQuery(ctx, query, nil)
+}
+
+// executeCQLQuery executes a CQL query with the given bind values. If the connection was
+// lost and replaying is enabled, an idempotent statement is run again after reconnecting.
+func (s *Session) executeCQLQuery(ctx context.Context, query string, values []interface{}) interface{} {
 	logger.DebugfToFile("ExecuteCQLQuery", "Called with query: %s", query)
 
-	if s == nil || s.Session == nil {
+	if !s.Connected() {
+		return fmt.Errorf("not connected to database")
+	}
+
+	s.resetQueryStats()
+	s.lintStatement(query)
+	result := s.runCQLQuery(ctx, query, values)
+	if err, ok := result.(error); !ok || !errors.Is(err, ErrConnectionLost) || !s.replayIdempotent || !isIdempotentStatement(query) {
+		return result
+	}
+
+	logger.DebugfToFile("ExecuteCQLQuery", "Connection lost, reconnecting to replay: %s", query)
+	if err := s.Reconnect(); err != nil {
+		logger.DebugfToFile("ExecuteCQLQuery", "Replay skipped: %v", err)
+		return result
+	}
+	return s.runCQLQuery(ctx, query, values)
+}
+
+// runCQLQuery executes a CQL query once. The driver session is not closed while it runs.
+func (s *Session) runCQLQuery(ctx context.Context, query string, values []interface{}) interface{} {
+	conn, release := s.acquireDriver()
+	defer release()
+	if conn == nil {
 		return fmt.Errorf("not connected to database")
 	}
 
@@ -298,7 +334,7 @@ func (s *Session) ExecuteCQLQuery(query string) interface{} {
 	switch {
 	case strings.HasPrefix(upperQuery, "SELECT") || strings.HasPrefix(upperQuery, "DESCRIBE") || strings.HasPrefix(upperQuery, "LIST"):
 		logger.DebugToFile("ExecuteCQLQuery", "Routing to ExecuteSelectQuery for query that returns results")
-		return s.ExecuteSelectQuery(query)
+		return s.executeSelectQuery(ctx, conn, query, values)
 	case strings.HasPrefix(upperQuery, "USE "):
 		// Handle USE statement - gocql doesn't support USE directly
 		// Return the keyspace name for the UI/router layer to handle
@@ -313,10 +349,10 @@ func (s *Session) ExecuteCQLQuery(query string) interface{} {
 			
 			if s.IsVersion3OrHigher() {
 				// Cassandra 3.0+ uses system_schema.keyspaces
-				iter = s.Query("SELECT keyspace_name FROM system_schema.keyspaces WHERE keyspace_name = ?", keyspace).Iter()
+				iter = s.queryOn(conn.session, "SELECT keyspace_name FROM system_schema.keyspaces WHERE keyspace_name = ?", keyspace).Iter()
 			} else {
 				// Cassandra 2.x uses system.schema_keyspaces
-				iter = s.Query("SELECT keyspace_name FROM system.schema_keyspaces WHERE keyspace_name = ?", keyspace).Iter()
+				iter = s.queryOn(conn.session, "SELECT keyspace_name FROM system.schema_keyspaces WHERE keyspace_name = ?", keyspace).Iter()
 			}
 			
 			if !iter.Scan(&exists) {
@@ -331,15 +367,21 @@ func (s *Session) ExecuteCQLQuery(query string) interface{} {
 		return "Invalid USE statement"
 	default:
 		// Execute non-SELECT query
-		if err := s.Query(query).Exec(); err != nil {
-			// Check if it's a connection error
-			errStr := err.Error()
-			if strings.Contains(errStr, "connection refused") ||
-				strings.Contains(errStr, "no connections") ||
-				strings.Contains(errStr, "unable to connect") {
-				return fmt.Errorf("connection lost to Cassandra - please check if the server is running")
+		q := s.queryOn(conn.session, query, values...)
+		downgrade := s.applyRetryPolicy(q, query)
+		iter := q.IterContext(ctx)
+		s.recordCoordinator(iter)
+		s.recordAttempts(iter, downgrade)
+		s.recordServerWarnings(iter)
+		lwtResult, isLWT := readLWTResult(iter)
+		if err := iter.Close(); err != nil {
+			if ctx.Err() != nil {
+				return &CancelledError{}
 			}
-			return fmt.Errorf("query failed: %v", err)
+			return queryError(err)
+		}
+		if isLWT {
+			return lwtResult
 		}
 		return "Query executed successfully"
 	}
@@ -347,31 +389,51 @@ func (s *Session) ExecuteCQLQuery(query string) interface{} {
 
 // ExecuteSelectQuery executes a SELECT query and returns formatted results
 func (s *Session) ExecuteSelectQuery(query string) interface{} {
+	return s.ExecuteSelectQueryContext(context.Background(), query)
+}
+
+// ExecuteSelectQueryContext executes a SELECT query, stopping when ctx is cancelled
+func (s *Session) ExecuteSelectQueryContext(ctx context.Context, query string) interface{} {
+	conn, release := s.acquireDriver()
+	defer release()
+	if conn == nil {
+		return fmt.Errorf("not connected to database")
+	}
+	return s.executeSelectQuery(ctx, conn, query, nil)
+}
+
+// executeSelectQuery executes a SELECT query with the given bind values on conn, which
+// the caller holds
+func (s *Session) executeSelectQuery(ctx context.Context, conn *driverConn, query string, values []interface{}) interface{} {
 	// Add debug logging
 	logger.DebugToFile("executeSelectQuery", "Starting executeSelectQuery")
 
 	// Initialize UDT registry if needed (will be cached)
-	if s.udtRegistry == nil {
-		s.udtRegistry = NewUDTRegistry(s.Session)
+
+	// A resumed query continues from a paging state, so it is always streamed
+	query, resume, err := ExtractResumeClause(query)
+	if err != nil {
+		return err
 	}
 
 	// Check if we should use streaming for large results
 	// This is a simple heuristic - could be made configurable
-	useStreaming := s.shouldUseStreaming(query)
+	useStreaming := resume != nil || s.shouldUseStreaming(query)
 
 	if useStreaming {
-		return s.ExecuteStreamingQuery(query)
+		return s.executeStreamingQuery(ctx, conn, query, values, resume)
 	}
 
 	// Track query execution time
 	startTime := time.Now()
 
 	// Create the query
-	q := s.Query(query)
+	q := s.queryOn(conn.session, query, values...)
+	downgrade := s.applyRetryPolicy(q, query)
 	
 	// Enable tracing if needed and capture trace ID
 	var tracer *captureTracer
-	if s.tracing {
+	if s.Tracing() {
 		tracer = &captureTracer{}
 		q = q.Trace(tracer)
 		defer func() {
@@ -382,7 +444,10 @@ func (s *Session) ExecuteSelectQuery(query string) interface{} {
 		}()
 	}
 
-	iter := q.Iter()
+	iter := q.IterContext(ctx)
+	s.recordCoordinator(iter)
+	s.recordAttempts(iter, downgrade)
+	s.recordServerWarnings(iter)
 
 	// Get column info
 	columns := iter.Columns()
@@ -427,7 +492,7 @@ func (s *Session) ExecuteSelectQuery(query string) interface{} {
 	if len(filteredColumns) == 0 {
 		if err := iter.Close(); err != nil {
 			logger.DebugfToFile("executeSelectQuery", "Error closing empty iterator: %v", err)
-			return fmt.Errorf("query failed: %v", err)
+			return queryError(err)
 		}
 		return "No results"
 	}
@@ -504,7 +569,7 @@ func (s *Session) ExecuteSelectQuery(query string) interface{} {
 	// MapScan handles NULLs gracefully by omitting them from the map
 	if true {  // Always use MapScan for safety
 		virtualResults := make([][]string, 0)
-		for {
+		for ctx.Err() == nil {
 			rowMap := make(map[string]interface{})
 			if !iter.MapScan(rowMap) {
 				break
@@ -619,14 +684,14 @@ func (s *Session) ExecuteSelectQuery(query string) interface{} {
 						logger.DebugfToFile("ExecuteSelectQuery", "UDT %s came as bytes: %d bytes", col.Name, len(bytes))
 
 						// Use our binary decoder to decode the UDT
-						decoder := NewBinaryDecoder(s.udtRegistry)
+						decoder := NewBinaryDecoder(s.GetUDTRegistry())
 
 						// Determine the keyspace - prefer query keyspace, then current
 						keyspace := currentKeyspace
 						if keyspace == "" {
 							keyspace = s.Keyspace()
-							if keyspace == "" && s.cluster != nil {
-								keyspace = s.cluster.Keyspace
+							if keyspace == "" {
+								keyspace = s.Keyspace()
 							}
 						}
 
@@ -691,9 +756,14 @@ func (s *Session) ExecuteSelectQuery(query string) interface{} {
 	}
 	logger.DebugfToFile("executeSelectQuery", "Scan completed. Total rows: %d", rowNum)
 
+	if ctx.Err() != nil {
+		_ = iter.Close()
+		logger.DebugfToFile("executeSelectQuery", "Query cancelled after %d rows", len(rawData))
+		return &CancelledError{Rows: int64(len(rawData))}
+	}
 	if err := iter.Close(); err != nil {
 		logger.DebugfToFile("executeSelectQuery", "Iterator close error: %v", err)
-		return fmt.Errorf("query failed: %v", err)
+		return queryError(err)
 	}
 
 	// Calculate query duration
@@ -742,20 +812,44 @@ func (s *Session) shouldUseStreaming(query string) bool {
 
 // ExecuteStreamingQuery executes a query and returns a streaming result
 func (s *Session) ExecuteStreamingQuery(query string) interface{} {
+	return s.ExecuteStreamingQueryContext(context.Background(), query)
+}
+
+// ExecuteStreamingQueryContext executes a query and returns a streaming result. The
+// iterator keeps using ctx while the remaining pages are fetched.
+func (s *Session) ExecuteStreamingQueryContext(ctx context.Context, query string) interface{} {
+	conn, release := s.acquireDriver()
+	defer release()
+	if conn == nil {
+		return fmt.Errorf("not connected to database")
+	}
+	return s.executeStreamingQuery(ctx, conn, query, nil, nil)
+}
+
+// executeStreamingQuery executes a query with the given bind values on conn, which the
+// caller holds, and returns a streaming result, starting at resume if it is not nil
+func (s *Session) executeStreamingQuery(ctx context.Context, conn *driverConn, query string, values []interface{}, resume *PagePosition) interface{} {
 	logger.DebugToFile("ExecuteStreamingQuery", "Starting streaming query execution")
 
 	startTime := time.Now()
+	// The driver session stays open until the result is closed, even if it is replaced;
+	// later pages are fetched from it too
+	release := conn.acquire()
 	// Use the session's page size for pagination
-	q := s.Query(query)
-	// Only set page size if it's greater than 0
-	// Setting to 0 or not setting at all disables client-side paging
-	if s.pageSize > 0 {
-		q.PageSize(s.pageSize)
+	var resumeState []byte
+	if resume != nil {
+		resumeState = resume.State
+		if resumeState == nil {
+			// Resuming on the first page still pages by hand, like any resumed query
+			resumeState = []byte{}
+		}
 	}
+	q := s.pagedQuery(conn, query, values, resumeState)
+	downgrade := s.applyRetryPolicy(q, query)
 	
 	// Enable tracing if needed and capture trace ID
 	var tracer *captureTracer
-	if s.tracing {
+	if s.Tracing() {
 		tracer = &captureTracer{}
 		q = q.Trace(tracer)
 		defer func() {
@@ -766,14 +860,27 @@ func (s *Session) ExecuteStreamingQuery(query string) interface{} {
 		}()
 	}
 	
-	iter := q.Iter()
+	iter := q.IterContext(ctx)
+	s.recordCoordinator(iter)
+	s.recordAttempts(iter, downgrade)
+	s.recordServerWarnings(iter)
+
+	cursor := newPagingCursor(s, conn, query, values, iter, resume)
+	if resume != nil && resume.Skip > 0 {
+		iter = cursor.skipRows(ctx, iter, resume.Skip)
+	}
+	s.setLastCursor(cursor)
 
 	// Get column info
 	columns := iter.Columns()
 	logger.DebugfToFile("ExecuteStreamingQuery", "Got %d columns from iterator", len(columns))
 	if len(columns) == 0 {
+		release()
 		if err := iter.Close(); err != nil {
-			return fmt.Errorf("query failed: %v", err)
+			if ctx.Err() != nil {
+				return &CancelledError{}
+			}
+			return queryError(err)
 		}
 		return "No results"
 	}
@@ -861,6 +968,8 @@ func (s *Session) ExecuteStreamingQuery(query string) interface{} {
 		Iterator:        iter,
 		StartTime:       startTime,
 		Keyspace:        currentKeyspace,
+		Cursor:          cursor,
+		release:         release,
 	}
 }
 
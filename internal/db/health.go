@@ -0,0 +1,362 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// ErrConnectionLost is returned when a statement fails because the cluster can't be reached
+var ErrConnectionLost = errors.New("connection lost to Cassandra - please check if the server is running")
+
+const (
+	// DefaultHealthCheckInterval is how often the health monitor checks the connection
+	DefaultHealthCheckInterval = 5 * time.Second
+	// healthCheckTimeout bounds a single connection check
+	healthCheckTimeout = 5 * time.Second
+	// minReconnectBackoff and maxReconnectBackoff bound the wait between reconnect attempts
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// ConnectionState is the state of the connection reported by the health monitor
+type ConnectionState int32
+
+const (
+	ConnectionUp           ConnectionState = iota // Connected and answering queries
+	ConnectionReconnecting                        // Connection lost, reconnecting with backoff
+)
+
+// String returns the state as shown in the status bar
+func (c ConnectionState) String() string {
+	if c == ConnectionReconnecting {
+		return "reconnecting"
+	}
+	return "connected"
+}
+
+// isConnectionError reports whether err means the cluster could not be reached, as
+// opposed to the statement itself failing
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrConnectionLost) ||
+		errors.Is(err, gocql.ErrNoConnections) ||
+		errors.Is(err, gocql.ErrConnectionClosed) ||
+		errors.Is(err, gocql.ErrSessionClosed) ||
+		errors.Is(err, gocql.ErrTimeoutNoResponse) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "connection refused") ||
+		strings.Contains(errStr, "no connections") ||
+		strings.Contains(errStr, "unable to connect")
+}
+
+// isTimeout reports whether err means a request took too long, which a busy cluster
+// causes as well as a lost one
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, gocql.ErrTimeoutNoResponse) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// queryError converts an error returned by the driver into the error shown to the user
+func queryError(err error) error {
+	if isConnectionError(err) {
+		return ErrConnectionLost
+	}
+	return fmt.Errorf("query failed: %w", err)
+}
+
+// lwtConditionPattern matches the IF clause of a lightweight transaction
+var lwtConditionPattern = regexp.MustCompile(`\bIF\b`)
+
+// Counter updates and list appends or prepends apply twice if replayed:
+// SET c = c + 1, SET l = l + [1], SET l = [1] + l
+var (
+	selfUpdatePattern  = regexp.MustCompile(`(\w+)\s*=\s*(\w+)\s*[+-]`)
+	listPrependPattern = regexp.MustCompile(`=\s*\[[^\]]*\]\s*\+`)
+)
+
+// isIncrementalUpdate reports whether a statement updates a column relative to its current value
+func isIncrementalUpdate(upper string) bool {
+	if listPrependPattern.MatchString(upper) {
+		return true
+	}
+	for _, match := range selfUpdatePattern.FindAllStringSubmatch(upper, -1) {
+		if match[1] == match[2] {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotentStatement reports whether running a statement twice has the same effect
+// as running it once, so it can be replayed after a reconnect
+func isIdempotentStatement(query string) bool {
+	upper := strings.ToUpper(maskStringLiterals(strings.TrimSpace(query)))
+	switch {
+	case strings.HasPrefix(upper, "SELECT"):
+		return true
+	case strings.HasPrefix(upper, "INSERT"), strings.HasPrefix(upper, "DELETE"), strings.HasPrefix(upper, "UPDATE"):
+		// Lightweight transactions may have been applied before the connection was lost
+		if lwtConditionPattern.MatchString(upper) {
+			return false
+		}
+		return !isIncrementalUpdate(upper)
+	default:
+		return false
+	}
+}
+
+// SetReplayIdempotent sets whether a statement that failed because the connection was lost
+// is run again after reconnecting, if it is idempotent
+func (s *Session) SetReplayIdempotent(enabled bool) {
+	s.replayIdempotent = enabled
+}
+
+// ConnectionState returns the state last reported by the health monitor
+func (s *Session) ConnectionState() ConnectionState {
+	return ConnectionState(s.connState.Load())
+}
+
+// driverConn is an open driver session and the results still reading from it
+type driverConn struct {
+	session  *gocql.Session
+	users    sync.WaitGroup
+	replaced atomic.Bool // Another driver session took over; schema events come from that one
+}
+
+// closeWhenUnused closes the driver session once no result reads from it any more
+func (c *driverConn) closeWhenUnused() {
+	c.users.Wait()
+	c.session.Close()
+}
+
+// driver returns the current driver session, or nil when not connected. The health
+// monitor may replace it at any time, so callers keep using the session returned.
+func (s *Session) driver() *gocql.Session {
+	if s == nil {
+		return nil
+	}
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.session
+}
+
+// acquire keeps the driver session open until release is called. Callers hold c
+// already, from acquireDriver, so it can't be closing yet.
+func (c *driverConn) acquire() (release func()) {
+	c.users.Add(1)
+	var once sync.Once
+	return func() { once.Do(c.users.Done) }
+}
+
+// acquireDriver returns the current driver session, or nil when not connected, and
+// keeps it open until release is called, even when it is replaced meanwhile. Iterators
+// that outlive a call hold one.
+func (s *Session) acquireDriver() (conn *driverConn, release func()) {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	if s.conn == nil {
+		return nil, func() {}
+	}
+	return s.conn, s.conn.acquire()
+}
+
+// Connected reports whether the session has a driver session to run statements on
+func (s *Session) Connected() bool {
+	return s.driver() != nil
+}
+
+// clusterConfig returns a copy of the configuration driver sessions are opened from, or
+// nil for a session without one
+func (s *Session) clusterConfig() *gocql.ClusterConfig {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	if s.cluster == nil {
+		return nil
+	}
+	cluster := *s.cluster
+	return &cluster
+}
+
+// reopen opens a driver session from the cluster configuration as changed by configure
+// and switches to it. The change is only kept when the new session opens. The previous
+// session is closed once the results still reading from it are done.
+func (s *Session) reopen(configure func(*gocql.ClusterConfig)) error {
+	s.openMu.Lock()
+	defer s.openMu.Unlock()
+
+	cluster := s.clusterConfig()
+	if cluster == nil {
+		return fmt.Errorf("not connected to database")
+	}
+	if configure != nil {
+		configure(cluster)
+	}
+	conn := &driverConn{}
+	if s.schemaEvents != nil {
+		cluster.Metadata.SchemaListener = s.schemaEvents.config(conn)
+	}
+	newSession, err := createSession(cluster, s.localDC)
+	if err != nil {
+		return err
+	}
+	conn.session = newSession
+
+	// The COPY session was opened from the previous configuration, so it goes too
+	s.connMu.Lock()
+	previous, previousBulk := s.conn, s.bulkConn
+	s.conn = conn
+	s.bulkConn = nil
+	s.cluster = cluster
+	s.connMu.Unlock()
+
+	if previous != nil {
+		previous.replaced.Store(true)
+		go previous.closeWhenUnused()
+	}
+	if previousBulk != nil {
+		go previousBulk.closeWhenUnused()
+	}
+	return nil
+}
+
+// Ping runs a lightweight query to check that the cluster answers
+func (s *Session) Ping(ctx context.Context) error {
+	conn := s.driver()
+	if conn == nil {
+		return gocql.ErrSessionClosed
+	}
+
+	var version string
+	iter := conn.Query("SELECT release_version FROM system.local").IterContext(ctx)
+	iter.Scan(&version)
+	return iter.Close()
+}
+
+// Reconnect replaces the driver session with a new one. The keyspace is kept in the
+// cluster configuration and consistency, paging and tracing are applied to every query
+// from Session, so all of them carry over to the new connection.
+func (s *Session) Reconnect() error {
+	if err := s.reopen(nil); err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+	logger.DebugToFile("Reconnect", "Reconnected to the cluster")
+	return nil
+}
+
+// StartHealthMonitor checks the connection every interval in the background. When the
+// cluster stops answering it reconnects, waiting longer after each failed attempt.
+// onChange is called from the monitor goroutine whenever the state changes.
+func (s *Session) StartHealthMonitor(interval time.Duration, onChange func(ConnectionState)) {
+	s.StopHealthMonitor()
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	s.healthStop, s.healthDone = stop, done
+
+	go func() {
+		defer close(done)
+		wait := interval
+		backoff := minReconnectBackoff
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(wait):
+			}
+
+			state := s.checkConnection()
+			if state == ConnectionUp {
+				wait, backoff = interval, minReconnectBackoff
+			} else {
+				wait, backoff = backoff, min(backoff*2, maxReconnectBackoff)
+			}
+
+			if previous := ConnectionState(s.connState.Swap(int32(state))); previous != state {
+				logger.DebugfToFile("HealthMonitor", "Connection state changed to %s", state)
+				if onChange != nil {
+					onChange(state)
+				}
+			}
+		}
+	}()
+}
+
+// checkConnection pings the cluster and tries to reconnect if it does not answer
+func (s *Session) checkConnection() ConnectionState {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	err := s.Ping(ctx)
+	if err == nil {
+		return ConnectionUp
+	}
+	if isTimeout(err) {
+		// A busy cluster answers slowly; reconnecting would only abort its work
+		logger.DebugfToFile("HealthMonitor", "Health check timed out: %v", err)
+		return s.ConnectionState()
+	}
+	if !isConnectionError(err) {
+		// The cluster answered, even if with an error
+		logger.DebugfToFile("HealthMonitor", "Health check failed: %v", err)
+		return ConnectionUp
+	}
+
+	logger.DebugfToFile("HealthMonitor", "Connection lost: %v", err)
+	if err := s.Reconnect(); err != nil {
+		logger.DebugfToFile("HealthMonitor", "%v", err)
+		return ConnectionReconnecting
+	}
+	return ConnectionUp
+}
+
+// StopHealthMonitor stops the health monitor and waits for it to exit
+func (s *Session) StopHealthMonitor() {
+	if s.healthStop == nil {
+		return
+	}
+	close(s.healthStop)
+	<-s.healthDone
+	s.healthStop, s.healthDone = nil, nil
+}
+
+// Close stops the health monitor and schema watcher and closes the connection to the cluster
+func (s *Session) Close() {
+	s.StopHealthMonitor()
+	s.StopSchemaWatcher()
+	if conn := s.driver(); conn != nil {
+		conn.Close()
+	}
+	s.connMu.RLock()
+	bulk := s.bulkConn
+	s.connMu.RUnlock()
+	if bulk != nil {
+		bulk.session.Close()
+	}
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+func TestIsIdempotentStatement(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT * FROM users WHERE id = 1":                                              true,
+		"INSERT INTO users (id, name) VALUES (1, 'alice')":                              true,
+		"UPDATE users SET name = 'bob' WHERE id = 5b6962dd-3f90-4c93-8f61-eabfa4a803e2": true,
+		"DELETE FROM users WHERE id = 1":                                                true,
+		"UPDATE users SET name = 'a = a + 1' WHERE id = 1":                              true,
+		"INSERT INTO users (id, name) VALUES (1, 'alice') IF NOT EXISTS":                false,
+		"UPDATE users SET name = 'bob' WHERE id = 1 IF name = 'alice'":                  false,
+		"UPDATE stats SET views = views + 1 WHERE id = 1":                               false,
+		"update stats set views=views-1 where id = 1":                                   false,
+		"UPDATE users SET tags = tags + ['new'] WHERE id = 1":                           false,
+		"UPDATE users SET tags = ['new'] + tags WHERE id = 1":                           false,
+		"BEGIN BATCH INSERT INTO users (id) VALUES (1); APPLY BATCH":                    false,
+		"TRUNCATE users": false,
+	}
+	for query, expected := range tests {
+		if got := isIdempotentStatement(query); got != expected {
+			t.Errorf("isIdempotentStatement(%q) = %v, want %v", query, got, expected)
+		}
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	for _, err := range []error{
+		gocql.ErrNoConnections,
+		fmt.Errorf("wrapped: %w", gocql.ErrConnectionClosed),
+		errors.New("dial tcp 127.0.0.1:9042: connect: connection refused"),
+	} {
+		if got := queryError(err); !errors.Is(got, ErrConnectionLost) {
+			t.Errorf("queryError(%v) = %v, want ErrConnectionLost", err, got)
+		}
+	}
+
+	syntaxErr := errors.New("line 1:0 no viable alternative at input 'SELEC'")
+	got := queryError(syntaxErr)
+	if errors.Is(got, ErrConnectionLost) || got.Error() != "query failed: "+syntaxErr.Error() {
+		t.Errorf("queryError(%v) = %v", syntaxErr, got)
+	}
+}
+
+func TestConnectionStateString(t *testing.T) {
+	if ConnectionUp.String() != "connected" || ConnectionReconnecting.String() != "reconnecting" {
+		t.Errorf("unexpected state names: %s, %s", ConnectionUp, ConnectionReconnecting)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTimeout(t *testing.T) {
+	for _, err := range []error{
+		context.DeadlineExceeded,
+		gocql.ErrTimeoutNoResponse,
+		fmt.Errorf("ping: %w", timeoutError{}),
+	} {
+		if !isTimeout(err) {
+			t.Errorf("isTimeout(%v) = false, want true", err)
+		}
+	}
+	for _, err := range []error{gocql.ErrNoConnections, gocql.ErrConnectionClosed} {
+		if isTimeout(err) {
+			t.Errorf("isTimeout(%v) = true, want false", err)
+		}
+	}
+}
+
+func TestReplacedDriverClosesAfterRelease(t *testing.T) {
+	previous := &driverConn{session: &gocql.Session{}}
+	s := &Session{conn: previous}
+	conn, release := s.acquireDriver()
+	if conn != previous {
+		t.Fatal("acquireDriver did not return the current driver session")
+	}
+
+	s.conn = &driverConn{session: &gocql.Session{}}
+	closed := make(chan struct{})
+	go func() {
+		previous.closeWhenUnused()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("the replaced session was closed while a result still used it")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	release()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replaced session was not closed after its last user released it")
+	}
+	if !conn.session.Closed() {
+		t.Error("the replaced session is still open")
+	}
+}
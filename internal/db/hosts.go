@@ -0,0 +1,64 @@
+package db
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// contactPointAddresses returns host:port addresses for the given contact points.
+// Entries that already carry a port keep it; the default port is added to the rest.
+func contactPointAddresses(hosts []string, defaultPort int) []string {
+	addresses := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if _, _, err := net.SplitHostPort(host); err == nil {
+			addresses = append(addresses, host)
+			continue
+		}
+		addresses = append(addresses, net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(defaultPort)))
+	}
+	return addresses
+}
+
+// hostSelectionPolicy returns the token-aware routing policy for the cluster,
+// preferring nodes in localDC when one is configured
+func hostSelectionPolicy(localDC string) gocql.HostSelectionPolicy {
+	if localDC != "" {
+		return gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy(localDC))
+	}
+	return gocql.TokenAwareHostPolicy(gocql.RoundRobinHostPolicy())
+}
+
+// createSession opens a driver session from cluster. A token-aware policy can serve
+// only one session, so every session gets a fresh routing policy.
+func createSession(cluster *gocql.ClusterConfig, localDC string) (*gocql.Session, error) {
+	if !cluster.DisableInitialHostLookup {
+		cluster.PoolConfig.HostSelectionPolicy = hostSelectionPolicy(localDC)
+	}
+	return cluster.CreateSession()
+}
+
+// ContactPoints returns the addresses the session was configured to connect to
+func (s *Session) ContactPoints() []string {
+	return s.contactPoints
+}
+
+// LocalDC returns the datacenter used for DC-aware routing, or "" if not set
+func (s *Session) LocalDC() string {
+	return s.localDC
+}
+
+// LastCoordinator returns the node that coordinated the most recent query,
+// or nil if no query has been executed yet
+func (s *Session) LastCoordinator() *gocql.HostInfo {
+	return s.lastCoordinator.Load()
+}
+
+// recordCoordinator remembers which node coordinated the query behind iter
+func (s *Session) recordCoordinator(iter *gocql.Iter) {
+	if host := iter.Host(); host != nil {
+		s.lastCoordinator.Store(host)
+	}
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+func TestContactPointAddresses(t *testing.T) {
+	got := contactPointAddresses([]string{"10.0.0.1", "10.0.0.2:9043", "node3.example.com", "::1", "[::2]"}, 9042)
+	expected := []string{"10.0.0.1:9042", "10.0.0.2:9043", "node3.example.com:9042", "[::1]:9042", "[::2]:9042"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCreateSessionTwiceFromOneConfig(t *testing.T) {
+	// Nothing listens on port 1, so both attempts fail, but neither may panic over
+	// a token-aware policy shared between sessions
+	cluster := gocql.NewCluster("127.0.0.1:1", "127.0.0.2:1")
+	cluster.ConnectTimeout = 100 * time.Millisecond
+	cluster.Logger = &customLogger{}
+
+	for i := 0; i < 2; i++ {
+		session, err := createSession(cluster, "dc1")
+		if err == nil {
+			session.Close()
+		}
+	}
+}
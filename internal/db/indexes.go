@@ -0,0 +1,216 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// indexTargetPattern matches an index target such as tags or values(tags)
+var indexTargetPattern = regexp.MustCompile(`^(?:(\w+)\()?("(?:[^"]|"")+"|[^()]+)\)?$`)
+
+// sasiTextTypes are the column types SASI indexes as text
+var sasiTextTypes = map[string]bool{"text": true, "varchar": true, "ascii": true}
+
+// saiEqualityTypes are the column types SAI only supports equality on
+var saiEqualityTypes = map[string]bool{
+	"text": true, "varchar": true, "ascii": true, "uuid": true, "timeuuid": true,
+	"boolean": true, "inet": true, "blob": true,
+}
+
+// TableIndex describes a secondary index as stored in system_schema.indexes
+type TableIndex struct {
+	Keyspace   string
+	Table      string
+	Name       string
+	Kind       string            // KEYS, COMPOSITES or CUSTOM
+	Options    map[string]string // Index options, including target and class_name
+	Column     string            // Indexed column
+	TargetKind string            // values, keys, entries or full for collections, "" otherwise
+	ColumnType string            // CQL type of the indexed column, if known
+}
+
+// Type returns 2i, SASI, SAI or the class name of a custom index
+func (i TableIndex) Type() string {
+	if i.Kind != "CUSTOM" {
+		return "2i"
+	}
+	class := i.Options["class_name"]
+	switch {
+	case strings.HasSuffix(class, "SASIIndex"):
+		return "SASI"
+	case strings.HasSuffix(class, "StorageAttachedIndex"), strings.EqualFold(class, "sai"):
+		return "SAI"
+	case class == "":
+		return "custom"
+	}
+	return class[strings.LastIndex(class, ".")+1:]
+}
+
+// Target returns the indexed column as written in CREATE INDEX, e.g. keys(prefs)
+func (i TableIndex) Target() string {
+	if i.TargetKind != "" {
+		return fmt.Sprintf("%s(%s)", i.TargetKind, QuoteIdentifier(i.Column))
+	}
+	return QuoteIdentifier(i.Column)
+}
+
+// IndexOptions returns the options other than target and class_name, such as analyzer
+// settings, as name=value pairs
+func (i TableIndex) IndexOptions() string {
+	var pairs []string
+	for name, value := range i.Options {
+		if name != "target" && name != "class_name" {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// Serves describes the WHERE restrictions the index can serve
+func (i TableIndex) Serves() string {
+	column := QuoteIdentifier(i.Column)
+	switch i.TargetKind {
+	case "keys":
+		return column + " CONTAINS KEY"
+	case "values":
+		if i.ColumnType == "" || isCollectionType(i.ColumnType) {
+			return column + " CONTAINS"
+		}
+	case "entries":
+		return column + "[key] ="
+	case "full":
+		return column + " = (whole collection)"
+	}
+
+	switch i.Type() {
+	case "2i":
+		return column + " ="
+	case "SASI":
+		if i.ColumnType != "" && !sasiTextTypes[i.ColumnType] || strings.EqualFold(i.Options["mode"], "SPARSE") {
+			return column + " =, <, <=, >, >="
+		}
+		if strings.EqualFold(i.Options["mode"], "CONTAINS") {
+			return column + " =, LIKE 'x%', LIKE '%x', LIKE '%x%'"
+		}
+		return column + " =, LIKE 'x%'"
+	case "SAI":
+		switch {
+		case strings.HasPrefix(i.ColumnType, "vector"):
+			return "ORDER BY " + column + " ANN OF [...]"
+		case isCollectionType(i.ColumnType):
+			return column + " CONTAINS"
+		case i.analyzed():
+			return column + " = (matches analyzed terms)"
+		case i.ColumnType == "" || saiEqualityTypes[i.ColumnType]:
+			return column + " ="
+		}
+		return column + " =, <, <=, >, >="
+	}
+	return "see the index class"
+}
+
+// analyzed reports whether an SAI index transforms the values it indexes
+func (i TableIndex) analyzed() bool {
+	for name, value := range i.Options {
+		switch strings.ToLower(name) {
+		case "index_analyzer", "query_analyzer":
+			return true
+		case "case_sensitive":
+			if strings.EqualFold(value, "false") {
+				return true
+			}
+		case "normalize", "ascii":
+			if strings.EqualFold(value, "true") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCollectionType reports whether a CQL type is a list, set or map, frozen or not
+func isCollectionType(cqlType string) bool {
+	cqlType = strings.TrimPrefix(cqlType, "frozen<")
+	return strings.HasPrefix(cqlType, "list<") || strings.HasPrefix(cqlType, "set<") || strings.HasPrefix(cqlType, "map<")
+}
+
+// parseIndexTarget splits an index target such as values(tags) into the column and
+// the kind of target
+func parseIndexTarget(target string) (string, string) {
+	match := indexTargetPattern.FindStringSubmatch(strings.TrimSpace(target))
+	if match == nil {
+		return "", ""
+	}
+	column := match[2]
+	if !strings.HasPrefix(column, `"`) {
+		// Targets are stored with their case, quoted only when needed
+		column = strings.ToLower(column)
+	}
+	return normalizeIdentifier(column), strings.ToLower(match[1])
+}
+
+// TableIndexes returns the secondary indexes of a table, or of every table of the
+// keyspace if table is empty
+func (s *Session) TableIndexes(keyspace, table string) ([]TableIndex, error) {
+	if s != nil && !s.Connected() && s.loadedSnapshot() != nil {
+		return s.snapshotIndexes(keyspace, table)
+	}
+	if !s.Connected() {
+		return nil, fmt.Errorf("not connected to database")
+	}
+
+	query := "SELECT table_name, index_name, kind, options FROM system_schema.indexes WHERE keyspace_name = ?"
+	values := []interface{}{keyspace}
+	if table != "" {
+		query += " AND table_name = ?"
+		values = append(values, table)
+	}
+	iter := s.Query(query, values...).Iter()
+
+	var indexes []TableIndex
+	var tableName, name, kind string
+	var options map[string]string
+	for iter.Scan(&tableName, &name, &kind, &options) {
+		index := TableIndex{
+			Keyspace: keyspace,
+			Table:    tableName,
+			Name:     name,
+			Kind:     kind,
+			Options:  options,
+		}
+		index.Column, index.TargetKind = parseIndexTarget(options["target"])
+		indexes = append(indexes, index)
+		options = nil
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	// Column types tell which restrictions an index serves
+	for i := range indexes {
+		if meta, err := s.GetTableMetadata(keyspace, indexes[i].Table); err == nil {
+			if col, ok := meta.Columns[indexes[i].Column]; ok {
+				indexes[i].ColumnType = formatTypeInfo(col.Type)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// IndexedColumns returns the columns of a table that have a secondary index
+func (s *Session) IndexedColumns(keyspace, table string) (map[string]bool, error) {
+	indexes, err := s.TableIndexes(keyspace, table)
+	if err != nil {
+		return nil, err
+	}
+	indexed := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		if index.Column != "" {
+			indexed[index.Column] = true
+		}
+	}
+	return indexed, nil
+}
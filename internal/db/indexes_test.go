@@ -0,0 +1,77 @@
+package db
+
+import "testing"
+
+func TestParseIndexTarget(t *testing.T) {
+	tests := []struct {
+		target, column, kind string
+	}{
+		{"email", "email", ""},
+		{"values(tags)", "tags", "values"},
+		{"keys(prefs)", "prefs", "keys"},
+		{"entries(prefs)", "prefs", "entries"},
+		{"full(frozen_list)", "frozen_list", "full"},
+		{`"MyCol"`, "MyCol", ""},
+		{`values("My""Tags")`, `My"Tags`, "values"},
+	}
+	for _, tt := range tests {
+		column, kind := parseIndexTarget(tt.target)
+		if column != tt.column || kind != tt.kind {
+			t.Errorf("parseIndexTarget(%q) = %q, %q", tt.target, column, kind)
+		}
+	}
+}
+
+func TestTableIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		index     TableIndex
+		wantType  string
+		wantServe string
+	}{
+		{"legacy 2i", TableIndex{Kind: "COMPOSITES", Column: "email", ColumnType: "text"}, "2i", "email ="},
+		{"2i on map keys", TableIndex{Kind: "COMPOSITES", Column: "prefs", TargetKind: "keys", ColumnType: "map<text, text>"}, "2i", "prefs CONTAINS KEY"},
+		{"2i on set values", TableIndex{Kind: "COMPOSITES", Column: "tags", TargetKind: "values", ColumnType: "set<text>"}, "2i", "tags CONTAINS"},
+		{"2i on map entries", TableIndex{Kind: "COMPOSITES", Column: "prefs", TargetKind: "entries"}, "2i", "prefs[key] ="},
+		{"SASI prefix", TableIndex{Kind: "CUSTOM", Column: "name", ColumnType: "text",
+			Options: map[string]string{"class_name": "org.apache.cassandra.index.sasi.SASIIndex"}}, "SASI", "name =, LIKE 'x%'"},
+		{"SASI contains", TableIndex{Kind: "CUSTOM", Column: "name", ColumnType: "text",
+			Options: map[string]string{"class_name": "org.apache.cassandra.index.sasi.SASIIndex", "mode": "CONTAINS"}}, "SASI", "name =, LIKE 'x%', LIKE '%x', LIKE '%x%'"},
+		{"SASI numeric", TableIndex{Kind: "CUSTOM", Column: "age", ColumnType: "int",
+			Options: map[string]string{"class_name": "org.apache.cassandra.index.sasi.SASIIndex"}}, "SASI", "age =, <, <=, >, >="},
+		{"SAI text", TableIndex{Kind: "CUSTOM", Column: "name", ColumnType: "text",
+			Options: map[string]string{"class_name": "org.apache.cassandra.index.sai.StorageAttachedIndex"}}, "SAI", "name ="},
+		{"SAI analyzed", TableIndex{Kind: "CUSTOM", Column: "name", ColumnType: "text",
+			Options: map[string]string{"class_name": "sai", "case_sensitive": "false"}}, "SAI", "name = (matches analyzed terms)"},
+		{"SAI numeric", TableIndex{Kind: "CUSTOM", Column: "age", ColumnType: "int",
+			Options: map[string]string{"class_name": "StorageAttachedIndex"}}, "SAI", "age =, <, <=, >, >="},
+		{"SAI vector", TableIndex{Kind: "CUSTOM", Column: "embedding", ColumnType: "vector<float, 3>",
+			Options: map[string]string{"class_name": "StorageAttachedIndex"}}, "SAI", "ORDER BY embedding ANN OF [...]"},
+		{"other custom", TableIndex{Kind: "CUSTOM", Column: "doc",
+			Options: map[string]string{"class_name": "com.example.LuceneIndex"}}, "LuceneIndex", "see the index class"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.index.Type(); got != tt.wantType {
+				t.Errorf("Type() = %q, want %q", got, tt.wantType)
+			}
+			if got := tt.index.Serves(); got != tt.wantServe {
+				t.Errorf("Serves() = %q, want %q", got, tt.wantServe)
+			}
+		})
+	}
+}
+
+func TestTableIndexTargetAndOptions(t *testing.T) {
+	index := TableIndex{
+		Column:     "Tags",
+		TargetKind: "values",
+		Options:    map[string]string{"target": `values("Tags")`, "class_name": "sai", "normalize": "true", "ascii": "true"},
+	}
+	if got := index.Target(); got != `values("Tags")` {
+		t.Errorf("Target() = %q", got)
+	}
+	if got := index.IndexOptions(); got != "ascii=true, normalize=true" {
+		t.Errorf("IndexOptions() = %q", got)
+	}
+}
@@ -0,0 +1,536 @@
+package db
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"gopkg.in/inf.v0"
+)
+
+// ToJSONValue converts a value scanned from Cassandra into one that encoding/json renders as
+// natural JSON. Collections, tuples and UDTs become nested arrays and objects; types without
+// a JSON equivalent (UUIDs, timestamps, blobs, durations) become strings in CQL literal form.
+func ToJSONValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return jsonFloat(float64(v), 32)
+	case float64:
+		return jsonFloat(v, 64)
+	case gocql.UUID:
+		return v.String()
+	case []byte:
+		return fmt.Sprintf("0x%x", v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		// Only CQL time columns scan into time.Duration
+		return formatTimeOfDay(v)
+	case gocql.Duration:
+		return fmt.Sprintf("%dmo%dd%dns", v.Months, v.Days, v.Nanoseconds)
+	case net.IP:
+		return v.String()
+	case *big.Int:
+		if v == nil {
+			return nil
+		}
+		return json.Number(v.String())
+	case *inf.Dec:
+		if v == nil {
+			return nil
+		}
+		return json.Number(v.String())
+	case inf.Dec:
+		return json.Number(v.String())
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			obj[key] = ToJSONValue(item)
+		}
+		return obj
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return ToJSONValue(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = ToJSONValue(rv.Index(i).Interface())
+		}
+		return items
+	case reflect.Map:
+		// JSON object keys must be strings, so non-text map keys use their literal form
+		obj := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := ToJSONValue(iter.Key().Interface())
+			keyStr, ok := key.(string)
+			if !ok {
+				keyStr = fmt.Sprintf("%v", key)
+			}
+			obj[keyStr] = ToJSONValue(iter.Value().Interface())
+		}
+		return obj
+	}
+
+	return fmt.Sprintf("%v", val)
+}
+
+// jsonFloat keeps NaN and infinities, which JSON can't represent as numbers, as strings
+// Formatting with the column's own precision keeps a float like 1.1 from becoming 1.100000023841858.
+func jsonFloat(f float64, bits int) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bits))
+}
+
+// formatTimeOfDay renders nanoseconds since midnight as a CQL time literal
+func formatTimeOfDay(d time.Duration) string {
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+	return fmt.Sprintf("%02d:%02d:%02d.%09d", hours, minutes, seconds, d)
+}
+
+// MarshalJSONRow renders a row as a single-line JSON object with keys in column order
+func MarshalJSONRow(columns []string, row map[string]interface{}) ([]byte, error) {
+	var buf strings.Builder
+	buf.WriteByte('{')
+	for i, col := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(col)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(ToJSONValue(row[col]))
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return []byte(buf.String()), nil
+}
+
+// CoerceJSONValue converts a value decoded from JSON (with json.Decoder.UseNumber) into the Go
+// type gocql binds for a column of the given CQL type. UDT field types are looked up in registry.
+func CoerceJSONValue(val interface{}, typeInfo *CQLTypeInfo, keyspace string, registry *UDTRegistry) (interface{}, error) {
+	if val == nil || typeInfo == nil {
+		return val, nil
+	}
+
+	switch typeInfo.BaseType {
+	case "ascii", "text", "varchar":
+		if s, ok := val.(string); ok {
+			return s, nil
+		}
+		return jsonScalarString(val), nil
+	case "tinyint":
+		n, err := coerceInt(val, 8)
+		return int8(n), err
+	case "smallint":
+		n, err := coerceInt(val, 16)
+		return int16(n), err
+	case "int":
+		n, err := coerceInt(val, 32)
+		return int32(n), err
+	case "bigint", "counter":
+		return coerceInt(val, 64)
+	case "varint":
+		n, ok := new(big.Int).SetString(jsonScalarString(val), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid varint %v", val)
+		}
+		return n, nil
+	case "float":
+		f, err := coerceFloat(val, 32)
+		return float32(f), err
+	case "double":
+		return coerceFloat(val, 64)
+	case "decimal":
+		d, ok := new(inf.Dec).SetString(jsonScalarString(val))
+		if !ok {
+			return nil, fmt.Errorf("invalid decimal %v", val)
+		}
+		return *d, nil
+	case "boolean":
+		if b, ok := val.(bool); ok {
+			return b, nil
+		}
+		return strconv.ParseBool(jsonScalarString(val))
+	case "uuid", "timeuuid":
+		return gocql.ParseUUID(jsonScalarString(val))
+	case "timestamp":
+		return parseJSONTimestamp(val)
+	case "date":
+		return parseJSONDate(val)
+	case "time":
+		return parseJSONTimeOfDay(val)
+	case "duration":
+		return ParseCQLDuration(jsonScalarString(val))
+	case "blob":
+		s := jsonScalarString(val)
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid blob %q: expected 0x-prefixed hex", s)
+		}
+		return b, nil
+	case "inet":
+		ip := net.ParseIP(jsonScalarString(val))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid inet %v", val)
+		}
+		return ip, nil
+	case "list", "set":
+		items, ok := val.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected JSON array for %s, got %T", typeInfo.String(), val)
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := CoerceJSONValue(item, typeInfo.Parameters[0], keyspace, registry)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	case "map":
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected JSON object for %s, got %T", typeInfo.String(), val)
+		}
+		result := make(map[interface{}]interface{}, len(obj))
+		for key, item := range obj {
+			coercedKey, err := coerceJSONMapKey(key, typeInfo.Parameters[0], keyspace, registry)
+			if err != nil {
+				return nil, err
+			}
+			coercedValue, err := CoerceJSONValue(item, typeInfo.Parameters[1], keyspace, registry)
+			if err != nil {
+				return nil, err
+			}
+			result[coercedKey] = coercedValue
+		}
+		return result, nil
+	case "tuple":
+		items, ok := val.([]interface{})
+		if !ok || len(items) != len(typeInfo.Parameters) {
+			return nil, fmt.Errorf("expected JSON array of %d elements for %s", len(typeInfo.Parameters), typeInfo.String())
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := CoerceJSONValue(item, typeInfo.Parameters[i], keyspace, registry)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	case "udt":
+		return coerceJSONUDT(val, typeInfo, keyspace, registry)
+	}
+
+	// Custom types such as vectors: arrays of numbers become float vectors
+	if items, ok := val.([]interface{}); ok {
+		floats := make([]float32, len(items))
+		for i, item := range items {
+			f, err := coerceFloat(item, 32)
+			if err != nil {
+				return val, nil
+			}
+			floats[i] = float32(f)
+		}
+		return floats, nil
+	}
+	return val, nil
+}
+
+// coerceJSONUDT converts a JSON object into a UDT value using the UDT's field types
+func coerceJSONUDT(val interface{}, typeInfo *CQLTypeInfo, keyspace string, registry *UDTRegistry) (interface{}, error) {
+	obj, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected JSON object for UDT %s, got %T", typeInfo.UDTName, val)
+	}
+
+	udtKeyspace := keyspace
+	if typeInfo.Keyspace != "" {
+		udtKeyspace = typeInfo.Keyspace
+	}
+
+	var definition *UDTDefinition
+	if registry != nil {
+		definition, _ = registry.GetUDTDefinition(udtKeyspace, typeInfo.UDTName)
+	}
+
+	result := make(map[string]interface{}, len(obj))
+	for name, fieldValue := range obj {
+		var fieldType *CQLTypeInfo
+		if definition != nil {
+			for _, field := range definition.Fields {
+				if field.Name == name {
+					fieldType = field.TypeInfo
+					break
+				}
+			}
+		}
+		if fieldType == nil {
+			result[name] = plainJSONValue(fieldValue)
+			continue
+		}
+		coerced, err := CoerceJSONValue(fieldValue, fieldType, udtKeyspace, registry)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		result[name] = coerced
+	}
+	return result, nil
+}
+
+// coerceJSONMapKey converts a JSON object key, which is always a string, to the map key type.
+// Numeric keys are parsed as JSON numbers; other keys, such as timestamps, dates and UUIDs,
+// are parsed from their literal form as written on export.
+func coerceJSONMapKey(key string, keyType *CQLTypeInfo, keyspace string, registry *UDTRegistry) (interface{}, error) {
+	switch keyType.BaseType {
+	case "ascii", "text", "varchar":
+		return key, nil
+	case "list", "set", "map", "tuple", "udt":
+		return nil, fmt.Errorf("map keys of type %s are not supported in JSON", keyType.String())
+	case "tinyint", "smallint", "int", "bigint", "counter", "varint", "float", "double", "decimal":
+		return CoerceJSONValue(json.Number(key), keyType, keyspace, registry)
+	case "inet", "blob":
+		// net.IP and []byte can't be Go map keys; the driver binds their string form
+		coerced, err := CoerceJSONValue(key, keyType, keyspace, registry)
+		if ip, ok := coerced.(net.IP); ok {
+			return ip.String(), err
+		}
+		if b, ok := coerced.([]byte); ok {
+			return string(b), err
+		}
+		return coerced, err
+	default:
+		return CoerceJSONValue(key, keyType, keyspace, registry)
+	}
+}
+
+// plainJSONValue converts json.Number values to int64 or float64 when no CQL type is known
+func plainJSONValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = plainJSONValue(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = plainJSONValue(v[key])
+		}
+	}
+	return val
+}
+
+// jsonScalarString returns the textual form of a decoded JSON scalar
+func jsonScalarString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// coerceInt parses an integer that must fit in the given number of bits
+func coerceInt(val interface{}, bits int) (int64, error) {
+	s := jsonScalarString(val)
+	n, err := strconv.ParseInt(s, 10, bits)
+	if err != nil {
+		// Accept whole numbers written with a fraction or exponent, e.g. 1.0 or 1e3
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || f != math.Trunc(f) {
+			return 0, fmt.Errorf("invalid %d-bit integer %q", bits, s)
+		}
+		n, err = strconv.ParseInt(strconv.FormatFloat(f, 'f', 0, 64), 10, bits)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %d-bit integer %q", bits, s)
+		}
+	}
+	return n, nil
+}
+
+// coerceFloat parses a float, accepting the NaN and Infinity strings written by ToJSONValue
+func coerceFloat(val interface{}, bits int) (float64, error) {
+	s := jsonScalarString(val)
+	f, err := strconv.ParseFloat(s, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float %q", s)
+	}
+	return f, nil
+}
+
+// jsonTimestampLayouts are the timestamp formats accepted on import, most specific first
+var jsonTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseJSONTimestamp accepts a timestamp string or milliseconds since the epoch
+func parseJSONTimestamp(val interface{}) (time.Time, error) {
+	if n, ok := val.(json.Number); ok {
+		ms, err := n.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %v", val)
+		}
+		return time.UnixMilli(ms).UTC(), nil
+	}
+
+	s := jsonScalarString(val)
+	for _, layout := range jsonTimestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// parseJSONDate accepts a date string, or a full timestamp as written for date columns on export
+func parseJSONDate(val interface{}) (time.Time, error) {
+	t, err := parseJSONTimestamp(val)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %v", val)
+	}
+	return t, nil
+}
+
+// parseJSONTimeOfDay accepts hh:mm:ss[.fffffffff] or nanoseconds since midnight
+func parseJSONTimeOfDay(val interface{}) (time.Duration, error) {
+	if n, ok := val.(json.Number); ok {
+		ns, err := n.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %v", val)
+		}
+		return time.Duration(ns), nil
+	}
+
+	s := jsonScalarString(val)
+	clock, frac, _ := strings.Cut(s, ".")
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q: expected hh:mm:ss", s)
+	}
+	var fields [3]int64
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		fields[i] = n
+	}
+	d := time.Duration(fields[0])*time.Hour + time.Duration(fields[1])*time.Minute + time.Duration(fields[2])*time.Second
+	if frac != "" {
+		if len(frac) > 9 {
+			return 0, fmt.Errorf("invalid time %q: more than nanosecond precision", s)
+		}
+		ns, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		d += time.Duration(ns)
+	}
+	return d, nil
+}
+
+// cqlDurationPattern matches one quantity/unit pair of a CQL duration literal such as 1y2mo3d4h
+var cqlDurationPattern = regexp.MustCompile(`(?i)(\d+)(y|mo|w|d|h|ms|m|s|us|µs|ns)`)
+
+// ParseCQLDuration parses a CQL duration literal (e.g. 1mo2d3ns or -3h) into a gocql.Duration
+func ParseCQLDuration(s string) (gocql.Duration, error) {
+	var d gocql.Duration
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	matches := cqlDurationPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return d, fmt.Errorf("invalid duration %q", s)
+	}
+
+	consumed := 0
+	for _, m := range matches {
+		if m[0] != consumed {
+			return d, fmt.Errorf("invalid duration %q", s)
+		}
+		consumed = m[1]
+
+		n, err := strconv.ParseInt(text[m[2]:m[3]], 10, 64)
+		if err != nil {
+			return d, fmt.Errorf("invalid duration %q", s)
+		}
+		switch strings.ToLower(text[m[4]:m[5]]) {
+		case "y":
+			d.Months += int32(n * 12)
+		case "mo":
+			d.Months += int32(n)
+		case "w":
+			d.Days += int32(n * 7)
+		case "d":
+			d.Days += int32(n)
+		case "h":
+			d.Nanoseconds += n * int64(time.Hour)
+		case "m":
+			d.Nanoseconds += n * int64(time.Minute)
+		case "s":
+			d.Nanoseconds += n * int64(time.Second)
+		case "ms":
+			d.Nanoseconds += n * int64(time.Millisecond)
+		case "us", "µs":
+			d.Nanoseconds += n * int64(time.Microsecond)
+		case "ns":
+			d.Nanoseconds += n
+		}
+	}
+	if consumed != len(text) {
+		return d, fmt.Errorf("invalid duration %q", s)
+	}
+
+	if negative {
+		d.Months, d.Days, d.Nanoseconds = -d.Months, -d.Days, -d.Nanoseconds
+	}
+	return d, nil
+}
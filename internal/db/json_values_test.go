@@ -0,0 +1,237 @@
+package db
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"gopkg.in/inf.v0"
+)
+
+func TestMarshalJSONRow(t *testing.T) {
+	id, _ := gocql.ParseUUID("123e4567-e89b-12d3-a456-426614174000")
+	row := map[string]interface{}{
+		"id":       id,
+		"name":     "alice",
+		"score":    float32(1.1),
+		"tags":     []string{"a", "b"},
+		"counts":   map[string]int{"x": 1},
+		"address":  map[string]interface{}{"city": "Paris", "zip": int32(75001)},
+		"created":  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"data":     []byte{0xca, 0xfe},
+		"balance":  inf.NewDec(12345, 2),
+		"big":      big.NewInt(42),
+		"missing":  nil,
+		"at":       5*time.Hour + 30*time.Minute,
+		"ttl":      gocql.Duration{Months: 1, Days: 2, Nanoseconds: 3},
+		"ip":       net.ParseIP("10.0.0.1"),
+		"nan":      math.NaN(),
+		"location": []interface{}{int32(1), "x"},
+	}
+	columns := []string{"id", "name", "score", "tags", "counts", "address", "created", "data",
+		"balance", "big", "missing", "at", "ttl", "ip", "nan", "location"}
+
+	line, err := MarshalJSONRow(columns, row)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"id":"123e4567-e89b-12d3-a456-426614174000","name":"alice","score":1.1,"tags":["a","b"],` +
+		`"counts":{"x":1},"address":{"city":"Paris","zip":75001},"created":"2024-01-02T03:04:05Z",` +
+		`"data":"0xcafe","balance":123.45,"big":42,"missing":null,"at":"05:30:00.000000000",` +
+		`"ttl":"1mo2d3ns","ip":"10.0.0.1","nan":"NaN","location":[1,"x"]}`
+	if string(line) != expected {
+		t.Errorf("unexpected JSON\n got: %s\nwant: %s", line, expected)
+	}
+}
+
+func mustParseType(t *testing.T, typeStr string) *CQLTypeInfo {
+	t.Helper()
+	typeInfo, err := ParseCQLType(typeStr)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", typeStr, err)
+	}
+	return typeInfo
+}
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatalf("invalid test JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestCoerceJSONValue(t *testing.T) {
+	uuid, _ := gocql.ParseUUID("123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name     string
+		typeStr  string
+		json     string
+		expected interface{}
+	}{
+		{"int", "int", `42`, int32(42)},
+		{"int from string", "int", `"42"`, int32(42)},
+		{"bigint", "bigint", `9007199254740993`, int64(9007199254740993)},
+		{"smallint", "smallint", `-3`, int16(-3)},
+		{"float", "float", `1.5`, float32(1.5)},
+		{"double NaN", "double", `"NaN"`, math.NaN()},
+		{"boolean", "boolean", `true`, true},
+		{"text from number", "text", `7`, "7"},
+		{"uuid", "uuid", `"123e4567-e89b-12d3-a456-426614174000"`, uuid},
+		{"timestamp", "timestamp", `"2024-01-02T03:04:05Z"`, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"timestamp millis", "timestamp", `1704164645000`, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"time", "time", `"05:30:00.5"`, 5*time.Hour + 30*time.Minute + 500*time.Millisecond},
+		{"duration", "duration", `"1mo2d3ns"`, gocql.Duration{Months: 1, Days: 2, Nanoseconds: 3}},
+		{"blob", "blob", `"0xcafe"`, []byte{0xca, 0xfe}},
+		{"inet", "inet", `"10.0.0.1"`, net.ParseIP("10.0.0.1")},
+		{"varint", "varint", `123456789012345678901234567890`, func() *big.Int {
+			n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+			return n
+		}()},
+		{"decimal", "decimal", `123.45`, *inf.NewDec(12345, 2)},
+		{"list", "list<int>", `[1, 2]`, []interface{}{int32(1), int32(2)}},
+		{"set of text", "set<text>", `["a"]`, []interface{}{"a"}},
+		{"map with int keys", "map<int, text>", `{"1": "one"}`, map[interface{}]interface{}{int32(1): "one"}},
+		{"map with timestamp keys", "map<timestamp, int>", `{"2024-01-02T03:04:05Z": 1}`,
+			map[interface{}]interface{}{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC): int32(1)}},
+		{"map with inet keys", "map<inet, int>", `{"10.0.0.1": 1}`,
+			map[interface{}]interface{}{"10.0.0.1": int32(1)}},
+		{"tuple", "tuple<int, text>", `[1, "x"]`, []interface{}{int32(1), "x"}},
+		{"null", "int", `null`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceJSONValue(decodeJSON(t, tt.json), mustParseType(t, tt.typeStr), "ks", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if f, ok := tt.expected.(float64); ok && math.IsNaN(f) {
+				if g, ok := got.(float64); !ok || !math.IsNaN(g) {
+					t.Errorf("expected NaN, got %v", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v (%T), got %#v (%T)", tt.expected, tt.expected, got, got)
+			}
+		})
+	}
+}
+
+func TestCoerceJSONValueErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		typeStr string
+		json    string
+	}{
+		{"int overflow", "int", `3000000000`},
+		{"tinyint overflow", "tinyint", `300`},
+		{"fractional int", "int", `1.5`},
+		{"bad uuid", "uuid", `"not-a-uuid"`},
+		{"bad blob", "blob", `"zz"`},
+		{"list from object", "list<int>", `{"a": 1}`},
+		{"tuple arity", "tuple<int, int>", `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CoerceJSONValue(decodeJSON(t, tt.json), mustParseType(t, tt.typeStr), "ks", nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseCQLDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected gocql.Duration
+		wantErr  bool
+	}{
+		{"1y2mo", gocql.Duration{Months: 14}, false},
+		{"3w1d", gocql.Duration{Days: 22}, false},
+		{"1h30m", gocql.Duration{Nanoseconds: int64(90 * time.Minute)}, false},
+		{"2ms5us7ns", gocql.Duration{Nanoseconds: 2_005_007}, false},
+		{"-1d", gocql.Duration{Days: -1}, false},
+		{"1mo2d3ns", gocql.Duration{Months: 1, Days: 2, Nanoseconds: 3}, false},
+		{"", gocql.Duration{}, true},
+		{"5 days", gocql.Duration{}, true},
+		{"1h garbage", gocql.Duration{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCQLDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJSONMapKeysRoundTrip(t *testing.T) {
+	uuid, _ := gocql.ParseUUID("123e4567-e89b-12d3-a456-426614174000")
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tod := 5*time.Hour + 30*time.Minute
+
+	row := map[string]interface{}{
+		"by_timestamp": map[time.Time]int32{ts: 1},
+		"by_date":      map[time.Time]string{date: "d"},
+		"by_time":      map[time.Duration]string{tod: "t"},
+		"by_uuid":      map[gocql.UUID]string{uuid: "u"},
+		"by_boolean":   map[bool]string{true: "b"},
+		"by_double":    map[float64]string{1.5: "f"},
+	}
+	types := map[string]string{
+		"by_timestamp": "map<timestamp, int>",
+		"by_date":      "map<date, text>",
+		"by_time":      "map<time, text>",
+		"by_uuid":      "map<uuid, text>",
+		"by_boolean":   "map<boolean, text>",
+		"by_double":    "map<double, text>",
+	}
+	expected := map[string]interface{}{
+		"by_timestamp": map[interface{}]interface{}{ts: int32(1)},
+		"by_date":      map[interface{}]interface{}{date: "d"},
+		"by_time":      map[interface{}]interface{}{tod: "t"},
+		"by_uuid":      map[interface{}]interface{}{uuid: "u"},
+		"by_boolean":   map[interface{}]interface{}{true: "b"},
+		"by_double":    map[interface{}]interface{}{1.5: "f"},
+	}
+
+	columns := []string{"by_timestamp", "by_date", "by_time", "by_uuid", "by_boolean", "by_double"}
+	data, err := MarshalJSONRow(columns, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, ok := decodeJSON(t, string(data)).(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a JSON object, got %s", data)
+	}
+	for _, column := range columns {
+		got, err := CoerceJSONValue(record[column], mustParseType(t, types[column]), "ks", nil)
+		if err != nil {
+			t.Errorf("%s: %v", column, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, expected[column]) {
+			t.Errorf("%s: expected %#v, got %#v", column, expected[column], got)
+		}
+	}
+}
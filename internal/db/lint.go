@@ -0,0 +1,600 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// Lint modes accepted by the lint setting
+const (
+	LintWarn    = "warn"    // Warn about anti-patterns with the result (default)
+	LintConfirm = "confirm" // Also ask before running a statement with findings
+	LintOff     = "off"
+)
+
+// maxPartitionKeyInValues is the number of IN values on a partition key above which
+// a query is reported as hitting too many partitions through one coordinator
+const maxPartitionKeyInValues = 10
+
+// ParseLintMode validates a lint setting. An empty setting warns.
+func ParseLintMode(mode string) (string, error) {
+	switch lower := strings.ToLower(strings.TrimSpace(mode)); lower {
+	case "":
+		return LintWarn, nil
+	case LintWarn, LintConfirm, LintOff:
+		return lower, nil
+	default:
+		return "", fmt.Errorf("unknown lint mode %q (use warn, confirm or off)", mode)
+	}
+}
+
+// LintFinding is an anti-pattern found in a statement
+type LintFinding struct {
+	Rule    string // Short name of the rule, e.g. allow-filtering
+	Message string
+}
+
+// QueryAnalysis is the result of linting a statement before it is sent
+type QueryAnalysis struct {
+	Keyspace string
+	Table    string
+	Schema   *LintTable // Schema of the table, nil if it is unknown
+	Findings []LintFinding
+}
+
+// LintTable is the schema the linter needs about a table
+type LintTable struct {
+	PartitionKeys  []string
+	ClusteringKeys []string
+	Indexed        map[string]bool // Columns with a secondary index; nil if unknown
+
+	// loadIndexes reads Indexed when a statement filters on a regular column, to
+	// save a round trip for statements that only use the primary key
+	loadIndexes func() map[string]bool
+}
+
+// TableLookup returns the schema of a table, or nil if it is unknown
+type TableLookup func(keyspace, table string) *LintTable
+
+// restriction is one condition of a WHERE clause
+type restriction struct {
+	columns  []string // Several for a multi-column restriction such as (a, b) > (1, 2)
+	operator string   // Upper case: =, <, IN, CONTAINS, ...
+	inValues int      // Number of IN values, -1 if they come from a bind marker
+	token    bool     // token(...) restriction on the partition key
+}
+
+var (
+	lintFromPattern      = regexp.MustCompile(`\bFROM\s+`)
+	lintUpdatePattern    = regexp.MustCompile(`^UPDATE\s+`)
+	lintWherePattern     = regexp.MustCompile(`\bWHERE\b`)
+	lintAndPattern       = regexp.MustCompile(`\s+AND\s+`)
+	lintAllowFiltering   = regexp.MustCompile(`\bALLOW\s+FILTERING\b`)
+	lintUnloggedBatch    = regexp.MustCompile(`^BEGIN\s+UNLOGGED\s+BATCH\b`)
+	lintApplyBatch       = regexp.MustCompile(`\bAPPLY\s+BATCH\b`)
+	lintBatchBody        = regexp.MustCompile(`^BEGIN\s+(?:UNLOGGED\s+|LOGGED\s+|COUNTER\s+)?BATCH(?:\s+USING\s+TIMESTAMP\s+\S+)?`)
+	lintInsertPattern    = regexp.MustCompile(`^INSERT\s+INTO\s+`)
+	lintRestriction      = regexp.MustCompile(`^(.*?)\s*(<=|>=|!=|=|<|>|\bIN\b|\bCONTAINS\s+KEY\b|\bCONTAINS\b|\bLIKE\b)\s*(.*)$`)
+	lintWhereTerminators = regexp.MustCompile(`\b(ORDER\s+BY|GROUP\s+BY|PER\s+PARTITION\s+LIMIT|LIMIT|ALLOW\s+FILTERING|IF)\b`)
+)
+
+// AnalyzeStatement looks for anti-patterns in a SELECT, UPDATE, DELETE or unlogged
+// batch: scans without a partition key, ALLOW FILTERING, large IN clauses on the
+// partition key, batches spanning partitions and filtering on non-indexed columns.
+// keyspace is used for tables that are not qualified with one.
+func AnalyzeStatement(stmt, keyspace string, lookup TableLookup) QueryAnalysis {
+	original := strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+	upper := strings.ToUpper(maskStringLiterals(original))
+	analysis := QueryAnalysis{Keyspace: keyspace}
+
+	if lintUnloggedBatch.MatchString(upper) {
+		analysis.Findings = analyzeBatch(original, upper, keyspace, lookup)
+		return analysis
+	}
+
+	var kind string
+	switch {
+	case strings.HasPrefix(upper, "SELECT"):
+		kind = "SELECT"
+	case strings.HasPrefix(upper, "UPDATE"):
+		kind = "UPDATE"
+	case strings.HasPrefix(upper, "DELETE"):
+		kind = "DELETE"
+	default:
+		return analysis
+	}
+
+	ks, table, ok := statementTable(original, upper, keyspace)
+	if !ok || strings.HasPrefix(ks, "system") {
+		return analysis
+	}
+	analysis.Keyspace, analysis.Table = ks, table
+	if lookup != nil {
+		analysis.Schema = lookup(ks, table)
+	}
+	schema := analysis.Schema
+	if schema == nil {
+		return analysis
+	}
+
+	where, hasWhere := whereClause(original, upper)
+	restrictions := parseRestrictions(where)
+	allowFiltering := lintAllowFiltering.MatchString(upper)
+
+	missing := unrestrictedPartitionKeys(schema, restrictions)
+	qualified := ks + "." + table
+	switch {
+	case !hasWhere && kind == "SELECT":
+		analysis.add("full-scan", fmt.Sprintf("No WHERE clause: the query reads every partition of %s", qualified))
+	case len(missing) > 0:
+		analysis.add("missing-partition-key", fmt.Sprintf("The WHERE clause does not restrict the partition key (%s): the query has to scan all partitions of %s",
+			strings.Join(missing, ", "), qualified))
+	}
+
+	if allowFiltering {
+		if len(missing) > 0 || !hasWhere {
+			analysis.add("allow-filtering", "ALLOW FILTERING without a partition key reads and discards rows across the whole table")
+		} else {
+			analysis.add("allow-filtering", "ALLOW FILTERING reads and discards the rows of the partition that don't match")
+		}
+	}
+
+	for _, r := range restrictions {
+		if r.operator != "IN" || r.inValues <= maxPartitionKeyInValues || len(r.columns) != 1 || !contains(schema.PartitionKeys, r.columns[0]) {
+			continue
+		}
+		analysis.add("partition-key-in", fmt.Sprintf("IN on partition key %s with %d values queries %d partitions through one coordinator; run separate queries instead",
+			r.columns[0], r.inValues, r.inValues))
+	}
+
+	var regular []string
+	for _, r := range restrictions {
+		for _, column := range r.columns {
+			if !r.token && !contains(schema.PartitionKeys, column) && !contains(schema.ClusteringKeys, column) {
+				regular = append(regular, column)
+			}
+		}
+	}
+	indexed := schema.Indexed
+	if indexed == nil && len(regular) > 0 && schema.loadIndexes != nil {
+		indexed = schema.loadIndexes()
+	}
+	if indexed != nil {
+		for _, column := range regular {
+			if !indexed[column] {
+				analysis.add("non-indexed-column", fmt.Sprintf("Column %s is neither part of the primary key nor indexed: filtering on it reads every row the query scans", column))
+			}
+		}
+	}
+
+	return analysis
+}
+
+// add records a finding
+func (a *QueryAnalysis) add(rule, message string) {
+	a.Findings = append(a.Findings, LintFinding{Rule: rule, Message: message})
+}
+
+// String describes the analysis for EXPLAIN
+func (a QueryAnalysis) String() string {
+	var b strings.Builder
+	if a.Table != "" {
+		fmt.Fprintf(&b, "Table: %s.%s", a.Keyspace, a.Table)
+		if a.Schema != nil {
+			fmt.Fprintf(&b, " (partition key: %s", strings.Join(a.Schema.PartitionKeys, ", "))
+			if len(a.Schema.ClusteringKeys) > 0 {
+				fmt.Fprintf(&b, "; clustering: %s", strings.Join(a.Schema.ClusteringKeys, ", "))
+			}
+			b.WriteString(")")
+		} else {
+			b.WriteString(" (not found in the schema)")
+		}
+		b.WriteString("\n")
+	}
+	if len(a.Findings) == 0 {
+		b.WriteString("No anti-patterns found")
+		return b.String()
+	}
+	for _, finding := range a.Findings {
+		fmt.Fprintf(&b, "- [%s] %s\n", finding.Rule, finding.Message)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// statementTable returns the keyspace and table a SELECT, UPDATE or DELETE reads or
+// writes. upper is the statement in upper case with string literals masked.
+func statementTable(original, upper, keyspace string) (string, string, bool) {
+	var loc []int
+	if strings.HasPrefix(upper, "UPDATE") {
+		loc = lintUpdatePattern.FindStringIndex(upper)
+	} else {
+		loc = lintFromPattern.FindStringIndex(upper)
+	}
+	if loc == nil {
+		return "", "", false
+	}
+	return SplitTableName(identifierAt(original, loc[1]), keyspace)
+}
+
+// identifierAt returns the (possibly keyspace-qualified and quoted) name starting at pos
+func identifierAt(s string, pos int) string {
+	end := pos
+	inQuotes := false
+	for end < len(s) {
+		c := s[end]
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if !inQuotes && (c == ' ' || c == '\t' || c == '\n' || c == '(' || c == ';') {
+			break
+		}
+		end++
+	}
+	return s[pos:end]
+}
+
+// SplitTableName splits ks.table, defaulting to keyspace
+func SplitTableName(name, keyspace string) (string, string, bool) {
+	if name == "" {
+		return "", "", false
+	}
+	ks, table := keyspace, name
+	inQuotes := false
+	for i, c := range name {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == '.' && !inQuotes {
+			ks, table = name[:i], name[i+1:]
+			break
+		}
+	}
+	ks, table = normalizeIdentifier(ks), normalizeIdentifier(table)
+	if ks == "" || table == "" {
+		return "", "", false
+	}
+	return ks, table, true
+}
+
+// normalizeIdentifier lower-cases an unquoted CQL identifier and unquotes a quoted one
+func normalizeIdentifier(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return strings.ToLower(name)
+}
+
+// whereClause returns the conditions of the WHERE clause, without ORDER BY, LIMIT,
+// ALLOW FILTERING or IF conditions
+func whereClause(original, upper string) (string, bool) {
+	loc := lintWherePattern.FindStringIndex(upper)
+	if loc == nil {
+		return "", false
+	}
+	start, end := loc[1], len(upper)
+	if term := lintWhereTerminators.FindStringIndex(upper[start:]); term != nil {
+		end = start + term[0]
+	}
+	return original[start:end], true
+}
+
+// parseRestrictions splits a WHERE clause into its conditions
+func parseRestrictions(where string) []restriction {
+	if strings.TrimSpace(where) == "" {
+		return nil
+	}
+	masked := strings.ToUpper(maskStringLiterals(where))
+
+	var restrictions []restriction
+	start := 0
+	for _, loc := range append(lintAndPattern.FindAllStringIndex(masked, -1), []int{len(masked), len(masked)}) {
+		if r, ok := parseRestriction(where[start:loc[0]], masked[start:loc[0]]); ok {
+			restrictions = append(restrictions, r)
+		}
+		start = loc[1]
+	}
+	return restrictions
+}
+
+// parseRestriction parses one condition such as id = 1, (a, b) > (1, 2) or
+// token(id) > 100
+func parseRestriction(original, masked string) (restriction, bool) {
+	match := lintRestriction.FindStringSubmatchIndex(strings.TrimSpace(masked))
+	if match == nil {
+		return restriction{}, false
+	}
+	trimmedOriginal := strings.TrimSpace(original)
+	trimmedMasked := strings.TrimSpace(masked)
+	lhs := trimmedOriginal[match[2]:match[3]]
+	r := restriction{operator: strings.Join(strings.Fields(trimmedMasked[match[4]:match[5]]), " ")}
+
+	upperLHS := strings.ToUpper(strings.TrimSpace(lhs))
+	if strings.HasPrefix(upperLHS, "TOKEN") {
+		r.token = true
+		lhs = strings.TrimSpace(lhs)[len("TOKEN"):]
+	}
+	lhs = strings.Trim(strings.TrimSpace(lhs), "()")
+	for _, column := range strings.Split(lhs, ",") {
+		if column = normalizeIdentifier(column); column != "" {
+			r.columns = append(r.columns, column)
+		}
+	}
+
+	if r.operator == "IN" {
+		values := strings.TrimSpace(trimmedMasked[match[6]:match[7]])
+		if strings.HasPrefix(values, "(") {
+			r.inValues = countTopLevelValues(values)
+		} else {
+			r.inValues = -1
+		}
+	}
+	return r, len(r.columns) > 0
+}
+
+// countTopLevelValues counts the values of a parenthesized list such as (1, (2, 3), 4)
+func countTopLevelValues(list string) int {
+	depth, count, empty := 0, 1, true
+	for _, c := range list {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 1 {
+				count++
+			}
+		case ' ', '\t', '\n':
+		default:
+			empty = false
+		}
+	}
+	if empty {
+		return 0
+	}
+	return count
+}
+
+// unrestrictedPartitionKeys returns the partition key columns the WHERE clause does not
+// restrict to single partitions. A token() restriction is an intentional range scan.
+func unrestrictedPartitionKeys(schema *LintTable, restrictions []restriction) []string {
+	restricted := make(map[string]bool)
+	for _, r := range restrictions {
+		if r.token {
+			return nil
+		}
+		if r.operator == "=" || r.operator == "IN" {
+			for _, column := range r.columns {
+				restricted[column] = true
+			}
+		}
+	}
+	var missing []string
+	for _, pk := range schema.PartitionKeys {
+		if !restricted[pk] {
+			missing = append(missing, pk)
+		}
+	}
+	return missing
+}
+
+// analyzeBatch reports an unlogged batch whose statements write to more than one
+// partition, which makes the coordinator fan out to several replicas
+func analyzeBatch(original, upper, keyspace string, lookup TableLookup) []LintFinding {
+	start := 0
+	if loc := lintBatchBody.FindStringIndex(upper); loc != nil {
+		start = loc[1]
+	}
+	end := len(upper)
+	if loc := lintApplyBatch.FindStringIndex(upper); loc != nil {
+		end = loc[0]
+	}
+
+	partitions := make(map[string]bool)
+	offset := start
+	for _, part := range strings.Split(upper[start:end], ";") {
+		partOriginal := original[offset : offset+len(part)]
+		offset += len(part) + 1
+		key, ok := batchPartition(strings.TrimSpace(partOriginal), keyspace, lookup)
+		if !ok {
+			continue
+		}
+		partitions[key] = true
+	}
+	if len(partitions) <= 1 {
+		return nil
+	}
+	return []LintFinding{{
+		Rule: "multi-partition-batch",
+		Message: fmt.Sprintf("Unlogged batch writes to %d partitions: it is slower than separate statements and is not atomic; batch only rows of one partition",
+			len(partitions)),
+	}}
+}
+
+// batchPartition returns a key identifying the partition a statement of a batch writes to
+func batchPartition(stmt, keyspace string, lookup TableLookup) (string, bool) {
+	if stmt == "" {
+		return "", false
+	}
+	upper := strings.ToUpper(maskStringLiterals(stmt))
+
+	if loc := lintInsertPattern.FindStringIndex(upper); loc != nil {
+		name := identifierAt(stmt, loc[1])
+		ks, table, ok := SplitTableName(strings.SplitN(name, "(", 2)[0], keyspace)
+		if !ok {
+			return "", false
+		}
+		return insertPartition(stmt, upper, ks, table, lookup)
+	}
+
+	ks, table, ok := statementTable(stmt, upper, keyspace)
+	if !ok {
+		return "", false
+	}
+	var schema *LintTable
+	if lookup != nil {
+		schema = lookup(ks, table)
+	}
+	if schema == nil {
+		return "", false
+	}
+	where, _ := whereClause(stmt, upper)
+	values := make(map[string]string)
+	maskedWhere := strings.ToUpper(maskStringLiterals(where))
+	for _, r := range parseRestrictions(where) {
+		if r.operator == "=" && len(r.columns) == 1 {
+			values[r.columns[0]] = restrictionValue(where, maskedWhere, r.columns[0])
+		}
+	}
+	return partitionKey(ks, table, schema, values)
+}
+
+// restrictionValue returns the text after "column =" in a WHERE clause
+func restrictionValue(where, maskedWhere, column string) string {
+	pattern := regexp.MustCompile(`(?i)"?` + regexp.QuoteMeta(column) + `"?\s*=\s*`)
+	loc := pattern.FindStringIndex(where)
+	if loc == nil {
+		return ""
+	}
+	end := len(where)
+	if and := lintAndPattern.FindStringIndex(maskedWhere[loc[1]:]); and != nil {
+		end = loc[1] + and[0]
+	}
+	return strings.TrimSpace(where[loc[1]:end])
+}
+
+// insertPartition returns the partition key values of an INSERT INTO t (cols) VALUES (...)
+func insertPartition(stmt, upper, ks, table string, lookup TableLookup) (string, bool) {
+	var schema *LintTable
+	if lookup != nil {
+		schema = lookup(ks, table)
+	}
+	if schema == nil {
+		return "", false
+	}
+	valuesIdx := strings.Index(upper, "VALUES")
+	open := strings.Index(upper, "(")
+	if valuesIdx < 0 || open < 0 || open > valuesIdx {
+		return "", false
+	}
+	columns := splitTopLevel(stmt[open+1:strings.LastIndex(upper[:valuesIdx], ")")], upper[open+1:strings.LastIndex(upper[:valuesIdx], ")")])
+	valuesOpen := valuesIdx + strings.Index(upper[valuesIdx:], "(")
+	valuesClose := strings.LastIndex(upper, ")")
+	if valuesOpen < valuesIdx || valuesClose <= valuesOpen {
+		return "", false
+	}
+	values := splitTopLevel(stmt[valuesOpen+1:valuesClose], upper[valuesOpen+1:valuesClose])
+
+	byColumn := make(map[string]string)
+	for i, column := range columns {
+		if i < len(values) {
+			byColumn[normalizeIdentifier(column)] = strings.TrimSpace(values[i])
+		}
+	}
+	return partitionKey(ks, table, schema, byColumn)
+}
+
+// splitTopLevel splits original on the commas that are not nested in masked
+func splitTopLevel(original, masked string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range masked {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, original[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, original[start:])
+}
+
+// partitionKey builds a key from the partition key values of a statement. Bind markers
+// can't be compared, so statements using them are not counted.
+func partitionKey(ks, table string, schema *LintTable, values map[string]string) (string, bool) {
+	key := ks + "." + table
+	for _, pk := range schema.PartitionKeys {
+		value, ok := values[pk]
+		if !ok || value == "" || value == "?" || strings.HasPrefix(value, ":") {
+			return "", false
+		}
+		key += "|" + value
+	}
+	return key, true
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeQuery lints a statement against the schema of the current keyspace
+func (s *Session) AnalyzeQuery(stmt string) QueryAnalysis {
+	return AnalyzeStatement(stmt, s.Keyspace(), s.lintTable)
+}
+
+// lintTable looks up the keys and secondary indexes of a table for the linter
+func (s *Session) lintTable(keyspace, table string) *LintTable {
+	cache := s.GetSchemaCache()
+	if cache == nil {
+		return nil
+	}
+	info, ok := cache.loadedTable(keyspace, table)
+	if !ok {
+		logger.DebugfToFile("Lint", "No cached schema for %s.%s", keyspace, table)
+		return nil
+	}
+
+	return &LintTable{
+		PartitionKeys:  info.PartitionKeys,
+		ClusteringKeys: info.ClusteringKeys,
+		loadIndexes: func() map[string]bool {
+			indexed, err := s.IndexedColumns(keyspace, table)
+			if err != nil {
+				logger.DebugfToFile("Lint", "Could not read the indexes of %s.%s: %v", keyspace, table, err)
+				return nil
+			}
+			return indexed
+		},
+	}
+}
+
+// SetLintMode changes whether statements are linted before they are sent
+func (s *Session) SetLintMode(mode string) error {
+	mode, err := ParseLintMode(mode)
+	if err != nil {
+		return err
+	}
+	s.lintMode = mode
+	return nil
+}
+
+// LintMode returns warn, confirm or off
+func (s *Session) LintMode() string {
+	if s == nil || s.lintMode == "" {
+		return LintWarn
+	}
+	return s.lintMode
+}
+
+// lintStatement warns about the anti-patterns in a statement about to be sent
+func (s *Session) lintStatement(stmt string) {
+	if s.LintMode() == LintOff {
+		return
+	}
+	for _, finding := range s.AnalyzeQuery(stmt).Findings {
+		s.addWarning("Lint: " + finding.Message)
+	}
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func testLookup(keyspace, table string) *LintTable {
+	if keyspace != "app" {
+		return nil
+	}
+	switch table {
+	case "users":
+		return &LintTable{
+			PartitionKeys: []string{"id"},
+			Indexed:       map[string]bool{"email": true},
+		}
+	case "events":
+		return &LintTable{
+			PartitionKeys:  []string{"tenant", "day"},
+			ClusteringKeys: []string{"ts"},
+			Indexed:        map[string]bool{},
+		}
+	}
+	return nil
+}
+
+func findingRules(analysis QueryAnalysis) []string {
+	var rules []string
+	for _, finding := range analysis.Findings {
+		rules = append(rules, finding.Rule)
+	}
+	return rules
+}
+
+func TestAnalyzeStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want []string
+	}{
+		{"partition key lookup", "SELECT * FROM users WHERE id = 1", nil},
+		{"qualified table", "SELECT * FROM app.users WHERE id = 1;", nil},
+		{"full scan", "SELECT * FROM users", []string{"full-scan"}},
+		{"limit without where", "SELECT * FROM users LIMIT 10", []string{"full-scan"}},
+		{"allow filtering scan", "SELECT * FROM users WHERE name = 'x' ALLOW FILTERING",
+			[]string{"missing-partition-key", "allow-filtering", "non-indexed-column"}},
+		{"indexed column", "SELECT * FROM users WHERE email = 'a@b.c'", []string{"missing-partition-key"}},
+		{"composite partition key", "SELECT * FROM events WHERE tenant = 'a' AND day = '2024-01-01' AND ts > 5", nil},
+		{"partial partition key", "SELECT * FROM events WHERE tenant = 'a'", []string{"missing-partition-key"}},
+		{"token range", "SELECT * FROM events WHERE token(tenant, day) > 100", nil},
+		{"small IN", "SELECT * FROM users WHERE id IN (1, 2, 3)", nil},
+		{"large IN", "SELECT * FROM users WHERE id IN (1,2,3,4,5,6,7,8,9,10,11)", []string{"partition-key-in"}},
+		{"IN with bind marker", "SELECT * FROM users WHERE id IN ?", nil},
+		{"literal containing keywords", "SELECT * FROM users WHERE id = 'x WHERE y AND z'", nil},
+		{"update without partition key", "UPDATE users SET name = 'x' WHERE email = 'y'", []string{"missing-partition-key"}},
+		{"update with condition", "UPDATE users SET name = 'x' WHERE id = 1 IF name = 'y'", nil},
+		{"delete", "DELETE FROM users WHERE id = 1", nil},
+		{"unknown table", "SELECT * FROM other", nil},
+		{"system keyspace", "SELECT * FROM system.local", nil},
+		{"insert", "INSERT INTO users (id) VALUES (1)", nil},
+		{"logged batch", "BEGIN BATCH INSERT INTO users (id) VALUES (1); INSERT INTO users (id) VALUES (2); APPLY BATCH", nil},
+		{"unlogged batch one partition", "BEGIN UNLOGGED BATCH INSERT INTO users (id, name) VALUES (1, 'a'); UPDATE users SET name = 'b' WHERE id = 1; APPLY BATCH", nil},
+		{"unlogged batch many partitions", "BEGIN UNLOGGED BATCH INSERT INTO users (id, name) VALUES (1, 'a'); UPDATE users SET name = 'b' WHERE id = 2; APPLY BATCH",
+			[]string{"multi-partition-batch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingRules(AnalyzeStatement(tt.stmt, "app", testLookup))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("AnalyzeStatement(%q) = %v, want %v", tt.stmt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeStatementIdentifiers(t *testing.T) {
+	var gotKeyspace, gotTable string
+	lookup := func(keyspace, table string) *LintTable {
+		gotKeyspace, gotTable = keyspace, table
+		return nil
+	}
+	AnalyzeStatement(`SELECT * FROM "MyKs"."My.Table" WHERE id = 1`, "app", lookup)
+	if gotKeyspace != "MyKs" || gotTable != "My.Table" {
+		t.Errorf("quoted name looked up as %s.%s", gotKeyspace, gotTable)
+	}
+	AnalyzeStatement("SELECT * FROM Users", "App", lookup)
+	if gotKeyspace != "app" || gotTable != "users" {
+		t.Errorf("unquoted name looked up as %s.%s", gotKeyspace, gotTable)
+	}
+}
+
+func TestAnalyzeStatementLoadsIndexesOnlyWhenNeeded(t *testing.T) {
+	loads := 0
+	lookup := func(keyspace, table string) *LintTable {
+		return &LintTable{
+			PartitionKeys: []string{"id"},
+			loadIndexes: func() map[string]bool {
+				loads++
+				return map[string]bool{}
+			},
+		}
+	}
+	AnalyzeStatement("SELECT * FROM t WHERE id = 1", "app", lookup)
+	if loads != 0 {
+		t.Errorf("indexes loaded for a primary key query")
+	}
+	analysis := AnalyzeStatement("SELECT * FROM t WHERE id = 1 AND name = 'x'", "app", lookup)
+	if loads != 1 || strings.Join(findingRules(analysis), ",") != "non-indexed-column" {
+		t.Errorf("loads = %d, findings = %v", loads, findingRules(analysis))
+	}
+}
+
+func TestQueryAnalysisString(t *testing.T) {
+	analysis := AnalyzeStatement("SELECT * FROM events", "app", testLookup)
+	text := analysis.String()
+	for _, want := range []string{"Table: app.events", "partition key: tenant, day", "clustering: ts", "[full-scan]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("String() = %q, missing %q", text, want)
+		}
+	}
+
+	clean := AnalyzeStatement("SELECT * FROM users WHERE id = 1", "app", testLookup)
+	if !strings.Contains(clean.String(), "No anti-patterns found") {
+		t.Errorf("String() = %q", clean.String())
+	}
+}
+
+func TestParseLintMode(t *testing.T) {
+	for input, want := range map[string]string{"": LintWarn, "WARN": LintWarn, "confirm": LintConfirm, " off ": LintOff} {
+		got, err := ParseLintMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLintMode(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseLintMode("strict"); err == nil {
+		t.Error("ParseLintMode(strict) should fail")
+	}
+}
+
+func TestLintModeDefault(t *testing.T) {
+	var s *Session
+	if s.LintMode() != LintWarn {
+		t.Errorf("nil session lint mode = %q", s.LintMode())
+	}
+}
+
+func TestLintTableUsesLoadedSchema(t *testing.T) {
+	cache := newEventTestCache()
+	cache.Tables["app"][0].PartitionKeys = []string{"id"}
+	s := &Session{schemaCache: cache}
+
+	table := s.lintTable("app", "users")
+	if table == nil || len(table.PartitionKeys) != 1 || table.PartitionKeys[0] != "id" {
+		t.Fatalf("lintTable(app.users) = %+v", table)
+	}
+	if s.lintTable("app", "missing") != nil {
+		t.Error("lintTable should not find a table that isn't loaded")
+	}
+	if (&Session{}).lintTable("app", "users") != nil {
+		t.Error("lintTable without a schema cache should return nil")
+	}
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// lwtAppliedColumn is the first result column of a conditional statement
+const lwtAppliedColumn = "[applied]"
+
+// LWTResult is the outcome of a conditional statement (INSERT ... IF NOT EXISTS,
+// UPDATE/DELETE ... IF ..., or a batch containing them). When the statement is
+// not applied, Cassandra also returns the current values of the checked columns.
+type LWTResult struct {
+	Applied bool
+	Headers []string                 // Result columns, starting with [applied]
+	Rows    [][]string               // Formatted result rows
+	RawData []map[string]interface{} // Unformatted result rows for JSON output
+}
+
+// String renders the outcome as text, listing the existing values if the
+// statement was not applied
+func (r LWTResult) String() string {
+	var sb strings.Builder
+	if r.Applied {
+		sb.WriteString("Applied")
+	} else {
+		sb.WriteString("Not applied")
+	}
+
+	header := false
+	for i, row := range r.Rows {
+		if len(row) <= 1 {
+			continue
+		}
+		if !header {
+			sb.WriteString(". Current values:")
+			header = true
+		}
+		if len(r.Rows) > 1 {
+			fmt.Fprintf(&sb, "\n  Row %d:", i+1)
+		}
+		for j := 1; j < len(row) && j < len(r.Headers); j++ {
+			fmt.Fprintf(&sb, "\n    %s = %s", r.Headers[j], row[j])
+		}
+	}
+	return sb.String()
+}
+
+// readLWTResult reads the rows of a conditional statement. It returns false if
+// the result does not start with an [applied] column.
+func readLWTResult(iter *gocql.Iter) (LWTResult, bool) {
+	columns := iter.Columns()
+	if len(columns) == 0 || columns[0].Name != lwtAppliedColumn {
+		return LWTResult{}, false
+	}
+
+	result := LWTResult{Headers: make([]string, len(columns))}
+	for i, col := range columns {
+		result.Headers[i] = col.Name
+	}
+
+	for {
+		rowMap := make(map[string]interface{})
+		if !iter.MapScan(rowMap) {
+			break
+		}
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = FormatValue(rowMap[col.Name])
+		}
+		if applied, ok := rowMap[lwtAppliedColumn].(bool); ok && len(result.Rows) == 0 {
+			result.Applied = applied
+		}
+		result.Rows = append(result.Rows, row)
+		result.RawData = append(result.RawData, rowMap)
+	}
+	return result, true
+}
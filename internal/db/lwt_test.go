@@ -0,0 +1,65 @@
+package db
+
+import "testing"
+
+func TestLWTResultString(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   LWTResult
+		expected string
+	}{
+		{
+			name:     "applied",
+			result:   LWTResult{Applied: true, Headers: []string{"[applied]"}, Rows: [][]string{{"true"}}},
+			expected: "Applied",
+		},
+		{
+			name: "not applied with current values",
+			result: LWTResult{
+				Headers: []string{"[applied]", "id", "name"},
+				Rows:    [][]string{{"false", "1", "alice"}},
+			},
+			expected: "Not applied. Current values:\n    id = 1\n    name = alice",
+		},
+		{
+			name: "batch not applied",
+			result: LWTResult{
+				Headers: []string{"[applied]", "id"},
+				Rows:    [][]string{{"false", "1"}, {"false", "2"}},
+			},
+			expected: "Not applied. Current values:\n  Row 1:\n    id = 1\n  Row 2:\n    id = 2",
+		},
+		{
+			name: "batch whose first row has no values",
+			result: LWTResult{
+				Headers: []string{"[applied]", "id"},
+				Rows:    [][]string{{"false"}, {"false", "2"}},
+			},
+			expected: "Not applied. Current values:\n  Row 2:\n    id = 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.String(); got != tt.expected {
+				t.Errorf("unexpected output\n got: %q\nwant: %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetSerialConsistency(t *testing.T) {
+	s := &Session{}
+	if err := s.SetSerialConsistency("local_serial"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.SerialConsistency(); got != "LOCAL_SERIAL" {
+		t.Errorf("expected LOCAL_SERIAL, got %s", got)
+	}
+	if err := s.SetSerialConsistency("QUORUM"); err == nil {
+		t.Error("expected an error for a non-serial level")
+	}
+	if got := s.SerialConsistency(); got != "LOCAL_SERIAL" {
+		t.Errorf("invalid level should not change the setting, got %s", got)
+	}
+}
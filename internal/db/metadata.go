@@ -8,6 +8,15 @@ import (
 	"github.com/axonops/cqlai/internal/session"
 )
 
+// KeyspaceMetadata returns the driver's cached metadata of a keyspace
+func (s *Session) KeyspaceMetadata(keyspace string) (*gocql.KeyspaceMetadata, error) {
+	conn := s.driver()
+	if conn == nil {
+		return nil, fmt.Errorf("not connected to database")
+	}
+	return conn.KeyspaceMetadata(keyspace)
+}
+
 // GetKeyspaceMetadata retrieves keyspace metadata using gocql's built-in API
 func (s *Session) GetKeyspaceMetadata(keyspace string) (*gocql.KeyspaceMetadata, error) {
 	metadata, err := s.KeyspaceMetadata(keyspace)
@@ -64,11 +73,8 @@ func (s *Session) GetMaterializedViewMetadata(keyspace, viewName string) (*gocql
 
 // LoadKeyspaceUDTsUsingMetadata delegates to the registry's simplified method
 func (s *Session) LoadKeyspaceUDTsUsingMetadata(keyspace string) error {
-	if s.udtRegistry == nil {
-		return fmt.Errorf("UDT registry not initialized")
-	}
 	// The new simplified registry uses gocql's cache directly
-	return s.udtRegistry.LoadKeyspaceUDTsUsingMetadata(keyspace)
+	return s.GetUDTRegistry().LoadKeyspaceUDTsUsingMetadata(keyspace)
 }
 
 // formatTypeInfo converts gocql.TypeInfo to a string representation
@@ -115,6 +121,11 @@ func formatTypeInfo(typeInfo gocql.TypeInfo) string {
 			// but we can still return the UDT name which is what we need
 			return udtName
 		}
+	case gocql.TypeCustom:
+		// Vectors are the only custom type the driver describes
+		if vectorType, ok := typeInfo.(gocql.VectorType); ok {
+			return fmt.Sprintf("vector<%s, %d>", formatTypeInfo(vectorType.SubType), vectorType.Dimensions)
+		}
 	default:
 		// Handle native types
 		return typeNameFromType(baseType)
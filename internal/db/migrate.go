@@ -0,0 +1,311 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// MigrationsTable is the table, in the keyspace of the session, that records the
+// applied migrations
+const MigrationsTable = "cqlai_migrations"
+
+// Migration states reported by MigrationStatuses
+const (
+	MigrationApplied  = "applied"
+	MigrationPending  = "pending"
+	MigrationModified = "modified" // Applied, but the file changed since
+	MigrationMissing  = "missing"  // Applied, but the file is gone
+)
+
+// migrationFilePattern matches <version>_<name>.cql, <version>_<name>.up.cql and
+// <version>_<name>.down.cql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)[_-](.+?)(\.up|\.down)?\.cql$`)
+
+// Migration is a numbered .cql file of a migrations directory. A .down.cql file with
+// the same version reverts it.
+type Migration struct {
+	Version  int64
+	Name     string
+	UpFile   string
+	DownFile string // Empty if the migration cannot be reverted
+	Checksum string // SHA-256 of the up file
+}
+
+// AppliedMigration is a row of the migrations table
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// MigrationStatus is the state of one migration version
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt time.Time // Zero for pending migrations
+}
+
+// LoadMigrations reads the migration files of dir, ordered by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	downs := make(map[int64]string)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid version: %w", entry.Name(), err)
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		if match[3] == ".down" {
+			if downs[version] != "" {
+				return nil, fmt.Errorf("%s and %s have the same version", filepath.Base(downs[version]), entry.Name())
+			}
+			downs[version] = path
+			continue
+		}
+		if m := byVersion[version]; m != nil {
+			return nil, fmt.Errorf("%s and %s have the same version", filepath.Base(m.UpFile), entry.Name())
+		}
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return nil, err
+		}
+		byVersion[version] = &Migration{Version: version, Name: match[2], UpFile: path, Checksum: checksum}
+	}
+
+	for version, path := range downs {
+		m := byVersion[version]
+		if m == nil {
+			return nil, fmt.Errorf("%s has no migration to revert", filepath.Base(path))
+		}
+		m.DownFile = path
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// fileChecksum returns the hex SHA-256 of a file
+func fileChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - Migration file of the user's directory
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// MigrationStatuses merges the migration files with the applied migrations, ordered
+// by version
+func MigrationStatuses(migrations []Migration, applied map[int64]AppliedMigration) []MigrationStatus {
+	var statuses []MigrationStatus
+	seen := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		seen[m.Version] = true
+		status := MigrationStatus{Version: m.Version, Name: m.Name, State: MigrationPending}
+		if a, ok := applied[m.Version]; ok {
+			status.State = MigrationApplied
+			status.AppliedAt = a.AppliedAt
+			if a.Checksum != m.Checksum {
+				status.State = MigrationModified
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for version, a := range applied {
+		if !seen[version] {
+			statuses = append(statuses, MigrationStatus{Version: version, Name: a.Name, State: MigrationMissing, AppliedAt: a.AppliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// CheckMigrationChecksums returns an error naming the applied migrations whose file
+// changed since they were applied
+func CheckMigrationChecksums(statuses []MigrationStatus) error {
+	var modified []string
+	for _, status := range statuses {
+		if status.State == MigrationModified {
+			modified = append(modified, fmt.Sprintf("%d (%s)", status.Version, status.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migration %s changed since it was applied; restore the file or add a new migration instead",
+			strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// PendingMigrations returns the migrations UP applies: those not applied yet, up to
+// and including target when it is not negative
+func PendingMigrations(migrations []Migration, applied map[int64]AppliedMigration, target int64) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok || (target >= 0 && m.Version > target) {
+			continue
+		}
+		pending = append(pending, m)
+	}
+	return pending
+}
+
+// RevertibleMigrations returns the migrations DOWN reverts, newest first: those applied
+// after target, or only the newest one when target is negative. It fails if one of
+// them has no file or no down file.
+func RevertibleMigrations(migrations []Migration, applied map[int64]AppliedMigration, target int64) ([]Migration, error) {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		if target < 0 || version > target {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	if target < 0 && len(versions) > 1 {
+		versions = versions[:1]
+	}
+
+	files := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		files[m.Version] = m
+	}
+	var revert []Migration
+	for _, version := range versions {
+		m, ok := files[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %d (%s) has no file to revert it", version, applied[version].Name)
+		}
+		if m.DownFile == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no .down.cql file", version, m.Name)
+		}
+		revert = append(revert, m)
+	}
+	return revert, nil
+}
+
+// EnsureMigrationsTable creates the migrations table in keyspace if it does not exist
+func (s *Session) EnsureMigrationsTable(ctx context.Context, keyspace string) error {
+	stmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+    version bigint PRIMARY KEY,
+    name text,
+    checksum text,
+    applied_at timestamp
+)`, QuoteIdentifier(keyspace), MigrationsTable)
+	if err := s.Query(stmt).WithContext(ctx).Exec(); err != nil {
+		return fmt.Errorf("failed to create %s.%s: %w", keyspace, MigrationsTable, err)
+	}
+	_, err := s.CheckSchemaAgreement(ctx)
+	return err
+}
+
+// MigrationsTableExists reports whether keyspace has a migrations table, without
+// creating one
+func (s *Session) MigrationsTableExists(ctx context.Context, keyspace string) (bool, error) {
+	var name string
+	err := s.Query("SELECT table_name FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?",
+		keyspace, MigrationsTable).WithContext(ctx).Scan(&name)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look for %s.%s: %w", keyspace, MigrationsTable, err)
+	}
+	return true, nil
+}
+
+// AppliedMigrations reads the migrations table of keyspace
+func (s *Session) AppliedMigrations(ctx context.Context, keyspace string) (map[int64]AppliedMigration, error) {
+	iter := s.Query(fmt.Sprintf("SELECT version, name, checksum, applied_at FROM %s.%s",
+		QuoteIdentifier(keyspace), MigrationsTable)).WithContext(ctx).Iter()
+
+	applied := make(map[int64]AppliedMigration)
+	var m AppliedMigration
+	for iter.Scan(&m.Version, &m.Name, &m.Checksum, &m.AppliedAt) {
+		applied[m.Version] = m
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read %s.%s: %w", keyspace, MigrationsTable, err)
+	}
+	return applied, nil
+}
+
+// ApplyMigration runs the statements of a migration in order, then records the
+// migration in the migrations table, or removes it from there when down is set. After
+// each schema change it checks that the nodes agree on the schema. Statements that ran
+// before a failure stay applied.
+// A migration that contains USE is rejected before any statement runs: the driver
+// can't switch the keyspace of its connections, so tables must be qualified instead.
+func (s *Session) ApplyMigration(ctx context.Context, keyspace string, m Migration, statements []string, down bool) error {
+	for i, stmt := range statements {
+		if isUseStatement(stmt) {
+			return fmt.Errorf("migration %d (%s): statement %d is USE, which migrations can't contain; qualify table names with their keyspace instead",
+				m.Version, m.Name, i+1)
+		}
+	}
+
+	for i, stmt := range statements {
+		if err := s.Query(stmt).WithContext(ctx).Exec(); err != nil {
+			return fmt.Errorf("migration %d (%s) failed at statement %d of %d: %w", m.Version, m.Name, i+1, len(statements), err)
+		}
+		if IsSchemaChange(stmt) {
+			if _, err := s.CheckSchemaAgreement(ctx); err != nil {
+				return fmt.Errorf("migration %d (%s): no schema agreement after statement %d: %w", m.Version, m.Name, i+1, err)
+			}
+		}
+	}
+
+	table := QuoteIdentifier(keyspace) + "." + MigrationsTable
+	var err error
+	if down {
+		err = s.Query("DELETE FROM "+table+" WHERE version = ?", m.Version).WithContext(ctx).Exec()
+	} else {
+		err = s.Query("INSERT INTO "+table+" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+			m.Version, m.Name, m.Checksum, time.Now()).WithContext(ctx).Exec()
+	}
+	if err != nil {
+		return fmt.Errorf("migration %d (%s) ran but could not be recorded: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// isUseStatement reports whether a statement is USE <keyspace>
+func isUseStatement(stmt string) bool {
+	fields := strings.Fields(stmt)
+	return len(fields) > 0 && strings.EqualFold(strings.TrimSuffix(fields[0], ";"), "USE")
+}
+
+// schemaChangePattern matches statements that change the schema
+var schemaChangePattern = regexp.MustCompile(`(?i)^\s*(CREATE|ALTER|DROP)\s+(OR\s+REPLACE\s+)?(CUSTOM\s+)?` +
+	`(KEYSPACE|SCHEMA|TABLE|COLUMNFAMILY|TYPE|INDEX|MATERIALIZED\s+VIEW|FUNCTION|AGGREGATE)\b`)
+
+// IsSchemaChange reports whether a statement creates, alters or drops a schema object
+func IsSchemaChange(stmt string) bool {
+	return schemaChangePattern.MatchString(stmt)
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"0002_add_email.up.cql":   "ALTER TABLE users ADD email text;",
+		"0002_add_email.down.cql": "ALTER TABLE users DROP email;",
+		"0001_create_users.cql":   "CREATE TABLE users (id int PRIMARY KEY);",
+		"10-seed.cql":             "INSERT INTO users (id) VALUES (1);",
+		"README.md":               "not a migration",
+	})
+
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range migrations {
+		got = append(got, m.Name)
+	}
+	if strings.Join(got, ",") != "create_users,add_email,seed" {
+		t.Fatalf("migrations = %v", got)
+	}
+	if migrations[0].DownFile != "" || filepath.Base(migrations[1].DownFile) != "0002_add_email.down.cql" {
+		t.Errorf("down files = %q, %q", migrations[0].DownFile, migrations[1].DownFile)
+	}
+	if migrations[2].Version != 10 || len(migrations[0].Checksum) != 64 {
+		t.Errorf("version = %d, checksum = %q", migrations[2].Version, migrations[0].Checksum)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := map[string]map[string]string{
+		"same version": {"0001_a.cql": "", "1_b.cql": ""},
+		"orphan down":  {"0001_a.cql": "", "0002_b.down.cql": ""},
+	}
+	for name, files := range tests {
+		if _, err := LoadMigrations(writeMigrations(t, files)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := LoadMigrations(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestMigrationStatuses(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "one", Checksum: "a"},
+		{Version: 2, Name: "two", Checksum: "b"},
+		{Version: 3, Name: "three", Checksum: "c"},
+	}
+	appliedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	applied := map[int64]AppliedMigration{
+		1: {Version: 1, Name: "one", Checksum: "a", AppliedAt: appliedAt},
+		2: {Version: 2, Name: "two", Checksum: "changed"},
+		7: {Version: 7, Name: "gone"},
+	}
+
+	var got []string
+	statuses := MigrationStatuses(migrations, applied)
+	for _, status := range statuses {
+		got = append(got, status.Name+"="+status.State)
+	}
+	if strings.Join(got, ",") != "one=applied,two=modified,three=pending,gone=missing" {
+		t.Errorf("statuses = %v", got)
+	}
+	if !statuses[0].AppliedAt.Equal(appliedAt) || !statuses[2].AppliedAt.IsZero() {
+		t.Errorf("applied at = %v, %v", statuses[0].AppliedAt, statuses[2].AppliedAt)
+	}
+
+	err := CheckMigrationChecksums(statuses)
+	if err == nil || !strings.Contains(err.Error(), "2 (two)") {
+		t.Errorf("CheckMigrationChecksums = %v", err)
+	}
+	if err := CheckMigrationChecksums(statuses[:1]); err != nil {
+		t.Errorf("CheckMigrationChecksums(applied) = %v", err)
+	}
+}
+
+func TestPendingAndRevertibleMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "one", DownFile: "1.down.cql"},
+		{Version: 2, Name: "two", DownFile: "2.down.cql"},
+		{Version: 3, Name: "three"},
+		{Version: 4, Name: "four", DownFile: "4.down.cql"},
+	}
+	applied := map[int64]AppliedMigration{1: {Version: 1}, 2: {Version: 2}}
+
+	versions := func(ms []Migration) []int64 {
+		var v []int64
+		for _, m := range ms {
+			v = append(v, m.Version)
+		}
+		return v
+	}
+
+	if got := versions(PendingMigrations(migrations, applied, -1)); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("PendingMigrations = %v", got)
+	}
+	if got := versions(PendingMigrations(migrations, applied, 3)); len(got) != 1 || got[0] != 3 {
+		t.Errorf("PendingMigrations(TO 3) = %v", got)
+	}
+
+	revert, err := RevertibleMigrations(migrations, applied, -1)
+	if got := versions(revert); err != nil || len(got) != 1 || got[0] != 2 {
+		t.Errorf("RevertibleMigrations = %v, %v", got, err)
+	}
+	revert, err = RevertibleMigrations(migrations, applied, 0)
+	if got := versions(revert); err != nil || len(got) != 2 || got[0] != 2 || got[1] != 1 {
+		t.Errorf("RevertibleMigrations(TO 0) = %v, %v", got, err)
+	}
+
+	applied[3] = AppliedMigration{Version: 3}
+	if _, err := RevertibleMigrations(migrations, applied, -1); err == nil {
+		t.Error("expected an error for a migration without a down file")
+	}
+	applied[9] = AppliedMigration{Version: 9, Name: "gone"}
+	if _, err := RevertibleMigrations(migrations, applied, -1); err == nil {
+		t.Error("expected an error for a migration without a file")
+	}
+}
+
+func TestIsSchemaChange(t *testing.T) {
+	tests := map[string]bool{
+		"CREATE TABLE users (id int PRIMARY KEY)":                 true,
+		"  alter table users add email text":                      true,
+		"DROP MATERIALIZED VIEW users_by_email":                   true,
+		"CREATE OR REPLACE FUNCTION f(x int) RETURNS NULL ON ...": true,
+		"CREATE CUSTOM INDEX ON users (v) USING 'SAI'":            true,
+		"INSERT INTO users (id) VALUES (1)":                       false,
+		"CREATE ROLE app":                                         false,
+		"TRUNCATE users":                                          false,
+	}
+	for stmt, want := range tests {
+		if got := IsSchemaChange(stmt); got != want {
+			t.Errorf("IsSchemaChange(%q) = %v, want %v", stmt, got, want)
+		}
+	}
+}
+
+func TestApplyMigrationRejectsUse(t *testing.T) {
+	s := &Session{}
+	m := Migration{Version: 3, Name: "switch"}
+	err := s.ApplyMigration(context.Background(), "app", m, []string{"CREATE TABLE a (id int PRIMARY KEY)", " use other"}, false)
+	if err == nil || !strings.Contains(err.Error(), "statement 2 is USE") {
+		t.Errorf("ApplyMigration = %v", err)
+	}
+}
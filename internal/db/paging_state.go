@@ -0,0 +1,214 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// pageTokenVersion is the first byte of a paging state token, so the format can change
+const pageTokenVersion = 1
+
+// SELECT ... RESUME '<token>' -> SELECT ...
+var resumePattern = regexp.MustCompile(`(?i)\s+RESUME\s+('[^']*')\s*(;\s*)?$`)
+
+// PagePosition is where a result was left: the driver paging state that fetched the
+// page holding the next row, and how many rows of that page were already read
+type PagePosition struct {
+	State []byte // nil for the first page
+	Skip  int
+}
+
+// Token encodes the position as an opaque string to hand to RESUME or --page-state
+func (p PagePosition) Token() string {
+	buf := []byte{pageTokenVersion}
+	buf = binary.AppendUvarint(buf, uint64(p.Skip)) // #nosec G115 - Skip is never negative
+	buf = append(buf, p.State...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// ParsePageToken decodes a token printed by PAGING STATE
+func ParsePageToken(token string) (*PagePosition, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil || len(data) < 2 || data[0] != pageTokenVersion {
+		return nil, fmt.Errorf("invalid paging state token")
+	}
+	skip, n := binary.Uvarint(data[1:])
+	if n <= 0 || skip > 1<<31 {
+		return nil, fmt.Errorf("invalid paging state token")
+	}
+	position := &PagePosition{Skip: int(skip)}
+	if state := data[1+n:]; len(state) > 0 {
+		position.State = state
+	}
+	return position, nil
+}
+
+// ExtractResumeClause removes a trailing RESUME '<token>' clause from a SELECT and
+// returns the statement without it and the position to resume from, or nil if the
+// statement has no such clause
+func ExtractResumeClause(query string) (string, *PagePosition, error) {
+	loc := resumePattern.FindStringSubmatchIndex(maskStringLiterals(query))
+	if loc == nil {
+		return query, nil, nil
+	}
+	position, err := ParsePageToken(strings.Trim(query[loc[2]:loc[3]], "'"))
+	if err != nil {
+		return "", nil, err
+	}
+	return query[:loc[0]], position, nil
+}
+
+// PagingCursor follows how far a streaming result has been read, so PAGING STATE can
+// print a token that resumes right after the last row read
+type PagingCursor struct {
+	mu        sync.Mutex
+	session   *Session
+	conn      *driverConn // Driver session the result reads from; later pages use it too
+	query     string
+	values    []interface{}
+	iter      *gocql.Iter
+	resumed   bool   // Resumed queries are paged by hand: the driver only fetches one page
+	pageStart []byte // Paging state that fetched the current page
+	nextState []byte // Paging state of the next page when the current page was reached
+	inPage    int    // Rows of the current page read so far
+	done      bool   // The result was read to the end
+}
+
+// newPagingCursor starts following iter, which was started at position (nil for the
+// first page)
+func newPagingCursor(s *Session, conn *driverConn, query string, values []interface{}, iter *gocql.Iter, position *PagePosition) *PagingCursor {
+	cursor := &PagingCursor{
+		session:   s,
+		conn:      conn,
+		query:     query,
+		values:    values,
+		iter:      iter,
+		nextState: append([]byte(nil), iter.PageState()...),
+	}
+	if position != nil {
+		cursor.resumed = true
+		cursor.pageStart = position.State
+	}
+	return cursor
+}
+
+// RowRead records that a row was read from the result
+func (c *PagingCursor) RowRead() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The driver moved on to the next page
+	if state := c.iter.PageState(); !bytes.Equal(state, c.nextState) {
+		c.pageStart = c.nextState
+		c.nextState = append([]byte(nil), state...)
+		c.inPage = 0
+	}
+	c.inPage++
+}
+
+// NextPage is called when iter has no more rows. It closes iter and, for a resumed
+// query, fetches the next page, since the driver doesn't page those automatically.
+// It returns nil when the result has been read to the end.
+func (c *PagingCursor) NextPage(ctx context.Context, iter *gocql.Iter) (*gocql.Iter, error) {
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := append([]byte(nil), iter.PageState()...)
+	if !c.resumed || len(state) == 0 || ctx.Err() != nil {
+		c.done = ctx.Err() == nil
+		return nil, nil
+	}
+
+	logger.DebugfToFile("PagingCursor", "Fetching the next page of a resumed query")
+	q := c.session.pagedQuery(c.conn, c.query, c.values, state)
+	c.session.applyRetryPolicy(q, c.query)
+	next := q.IterContext(ctx)
+	c.iter = next
+	c.pageStart = state
+	c.nextState = append([]byte(nil), next.PageState()...)
+	c.inPage = 0
+	return next, nil
+}
+
+// Position returns where reading the result would continue
+func (c *PagingCursor) Position() (PagePosition, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return PagePosition{}, fmt.Errorf("the last result was read to the end")
+	}
+	return PagePosition{State: c.pageStart, Skip: c.inPage}, nil
+}
+
+// pagedQuery creates a query on conn for a streaming result, starting at state if it is
+// not nil
+func (s *Session) pagedQuery(conn *driverConn, query string, values []interface{}, state []byte) *gocql.Query {
+	q := s.queryOn(conn.session, query, values...)
+	// Only set page size if it's greater than 0
+	// Setting to 0 or not setting at all disables client-side paging
+	if pageSize := s.PageSize(); pageSize > 0 {
+		q.PageSize(pageSize)
+	}
+	if state != nil {
+		q.PageState(state)
+	}
+	return q
+}
+
+// skipRows reads and drops the rows of a resumed page that were read before
+func (c *PagingCursor) skipRows(ctx context.Context, iter *gocql.Iter, count int) *gocql.Iter {
+	for skipped := 0; skipped < count; {
+		if iter.MapScan(make(map[string]interface{})) {
+			c.RowRead()
+			skipped++
+			continue
+		}
+		// The closed iterator reports the error, or no rows, to the caller
+		next, err := c.NextPage(ctx, iter)
+		if err != nil || next == nil {
+			return iter
+		}
+		iter = next
+	}
+	return iter
+}
+
+// PagingStateToken returns a token that resumes the last streaming result after the
+// last row read, for SELECT ... RESUME '<token>', and the statement it belongs to
+func (s *Session) PagingStateToken() (string, string, error) {
+	s.pagingMu.Lock()
+	cursor := s.lastCursor
+	s.pagingMu.Unlock()
+	if cursor == nil {
+		return "", "", fmt.Errorf("there is no paged result; run a SELECT first")
+	}
+	position, err := cursor.Position()
+	if err != nil {
+		return "", "", err
+	}
+	return position.Token(), strings.TrimSuffix(strings.TrimSpace(cursor.query), ";"), nil
+}
+
+// setLastCursor remembers the cursor of the last streaming result for PAGING STATE
+func (s *Session) setLastCursor(cursor *PagingCursor) {
+	s.pagingMu.Lock()
+	defer s.pagingMu.Unlock()
+	s.lastCursor = cursor
+}
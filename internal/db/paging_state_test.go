@@ -0,0 +1,82 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	for _, position := range []PagePosition{
+		{},
+		{Skip: 42},
+		{State: []byte{0x00, 0x10, 0xff, 0x27}, Skip: 300},
+	} {
+		parsed, err := ParsePageToken(position.Token())
+		if err != nil {
+			t.Fatalf("ParsePageToken(%q) failed: %v", position.Token(), err)
+		}
+		if parsed.Skip != position.Skip || !bytes.Equal(parsed.State, position.State) {
+			t.Errorf("round trip of %+v gave %+v", position, parsed)
+		}
+		if position.State == nil && parsed.State != nil {
+			t.Errorf("first page position has state %v", parsed.State)
+		}
+	}
+}
+
+func TestParsePageTokenInvalid(t *testing.T) {
+	for _, token := range []string{"", "not a token", "AA", "Ag"} {
+		if _, err := ParsePageToken(token); err == nil {
+			t.Errorf("ParsePageToken(%q) should fail", token)
+		}
+	}
+}
+
+func TestExtractResumeClause(t *testing.T) {
+	token := PagePosition{State: []byte("state"), Skip: 3}.Token()
+
+	query, position, err := ExtractResumeClause("SELECT * FROM users RESUME '" + token + "';")
+	if err != nil || query != "SELECT * FROM users" || position == nil || position.Skip != 3 || string(position.State) != "state" {
+		t.Errorf("got %q, %+v, %v", query, position, err)
+	}
+
+	query, position, err = ExtractResumeClause("select * from users where id > 5 resume '" + token + "'")
+	if err != nil || query != "select * from users where id > 5" || position == nil {
+		t.Errorf("got %q, %+v, %v", query, position, err)
+	}
+
+	for _, plain := range []string{
+		"SELECT * FROM users",
+		"SELECT * FROM users WHERE name = 'x RESUME ''y'''",
+		"SELECT resume FROM users",
+	} {
+		query, position, err = ExtractResumeClause(plain)
+		if err != nil || query != plain || position != nil {
+			t.Errorf("ExtractResumeClause(%q) = %q, %+v, %v", plain, query, position, err)
+		}
+	}
+
+	if _, _, err := ExtractResumeClause("SELECT * FROM users RESUME 'bogus!'"); err == nil {
+		t.Error("invalid token should fail")
+	}
+}
+
+func TestPagingStateTokenWithoutResult(t *testing.T) {
+	s := &Session{}
+	if _, _, err := s.PagingStateToken(); err == nil {
+		t.Error("PagingStateToken should fail before any SELECT")
+	}
+}
+
+func TestPagingCursorPosition(t *testing.T) {
+	cursor := &PagingCursor{pageStart: []byte("page"), inPage: 7}
+	position, err := cursor.Position()
+	if err != nil || string(position.State) != "page" || position.Skip != 7 {
+		t.Errorf("Position() = %+v, %v", position, err)
+	}
+
+	cursor.done = true
+	if _, err := cursor.Position(); err == nil {
+		t.Error("Position() of a result read to the end should fail")
+	}
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// PreparedColumn describes a bind marker or result column of a prepared statement
+type PreparedColumn struct {
+	Keyspace string
+	Table    string
+	Name     string
+	Type     string
+}
+
+// PreparedStatement is a statement prepared with PREPARE name AS <cql>. The driver keeps
+// the server-side prepared statement in its cache; this records the metadata returned
+// when it was prepared.
+type PreparedStatement struct {
+	Name          string
+	Query         string
+	Keyspace      string // Keyspace of the statement's table
+	Table         string
+	BindColumns   []PreparedColumn
+	ResultColumns []PreparedColumn
+	RoutingKey    []int // Indexes of the bind markers that form the partition key
+}
+
+// RoutingKeyColumns returns the names of the bind markers that form the partition key,
+// or nil if the statement does not bind the whole partition key
+func (p *PreparedStatement) RoutingKeyColumns() []string {
+	names := make([]string, 0, len(p.RoutingKey))
+	for _, idx := range p.RoutingKey {
+		if idx >= 0 && idx < len(p.BindColumns) {
+			names = append(names, p.BindColumns[idx].Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// preparedColumns converts column metadata reported by the driver
+func preparedColumns(columns []gocql.ColumnInfo) []PreparedColumn {
+	result := make([]PreparedColumn, len(columns))
+	for i, col := range columns {
+		result[i] = PreparedColumn{
+			Keyspace: col.Keyspace,
+			Table:    col.Table,
+			Name:     col.Name,
+			Type:     formatGocqlTypeInfo(col.TypeInfo),
+		}
+	}
+	return result
+}
+
+// Prepare prepares a statement on the cluster and stores it under name, replacing any
+// statement already prepared with that name. Names are case-insensitive.
+func (s *Session) Prepare(ctx context.Context, name, query string) (*PreparedStatement, error) {
+	conn := s.driver()
+	if conn == nil {
+		return nil, fmt.Errorf("not connected to database")
+	}
+
+	metadata, err := conn.StatementMetadata(ctx, query, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %v", err)
+	}
+
+	prepared := &PreparedStatement{
+		Name:          strings.ToLower(name),
+		Query:         query,
+		Keyspace:      metadata.Keyspace,
+		Table:         metadata.Table,
+		BindColumns:   preparedColumns(metadata.BindColumns),
+		ResultColumns: preparedColumns(metadata.ResultColumns),
+		RoutingKey:    metadata.PKBindColumnIndexes,
+	}
+
+	s.preparedMu.Lock()
+	defer s.preparedMu.Unlock()
+	if s.prepared == nil {
+		s.prepared = make(map[string]*PreparedStatement)
+	}
+	s.prepared[prepared.Name] = prepared
+	return prepared, nil
+}
+
+// PreparedStatement returns the statement prepared under name
+func (s *Session) PreparedStatement(name string) (*PreparedStatement, bool) {
+	s.preparedMu.RLock()
+	defer s.preparedMu.RUnlock()
+	prepared, ok := s.prepared[strings.ToLower(name)]
+	return prepared, ok
+}
+
+// PreparedStatements returns all named prepared statements sorted by name
+func (s *Session) PreparedStatements() []*PreparedStatement {
+	s.preparedMu.RLock()
+	statements := make([]*PreparedStatement, 0, len(s.prepared))
+	for _, prepared := range s.prepared {
+		statements = append(statements, prepared)
+	}
+	s.preparedMu.RUnlock()
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].Name < statements[j].Name
+	})
+	return statements
+}
+
+// ExecutePreparedContext executes the statement prepared under name with the given CQL
+// literals, converted to the types of its bind markers
+func (s *Session) ExecutePreparedContext(ctx context.Context, name string, literals []string) interface{} {
+	prepared, ok := s.PreparedStatement(name)
+	if !ok {
+		return fmt.Errorf("no prepared statement named %s, use PREPARE %s AS <statement>", name, name)
+	}
+	return s.ExecuteBoundQueryContext(ctx, prepared.Query, literals)
+}
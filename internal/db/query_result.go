@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
@@ -26,6 +27,47 @@ type StreamingQueryResult struct {
 	Iterator        *gocql.Iter      // Iterator for fetching more rows
 	StartTime       time.Time        // Query start time for duration calculation
 	Keyspace        string           // Keyspace extracted from query or session
+	Cursor          *PagingCursor    // Follows the rows read, for PAGING STATE
+	release         func()           // Lets the driver session close once the rows were read
+}
+
+// Close closes the iterator and lets the driver session it reads from be closed if it
+// was replaced meanwhile. Closing a result more than once is harmless.
+func (r StreamingQueryResult) Close() error {
+	if r.release != nil {
+		defer r.release()
+	}
+	if r.Iterator == nil {
+		return nil
+	}
+	return r.Iterator.Close()
+}
+
+// OnClose makes Close also call f, e.g. to end the context the remaining pages are read with
+func (r *StreamingQueryResult) OnClose(f func()) {
+	release := r.release
+	r.release = func() {
+		f()
+		if release != nil {
+			release()
+		}
+	}
+}
+
+// NextRow reads the next row with scan, e.g. iter.MapScan or iter.Scan, and records it
+// for PAGING STATE. When a page is used up it moves a resumed query on to its next page,
+// since the driver doesn't page those. At the end of the result it returns false and
+// the error of the iterator, if any.
+func (r *StreamingQueryResult) NextRow(ctx context.Context, scan func(iter *gocql.Iter) bool) (bool, error) {
+	for !scan(r.Iterator) {
+		next, err := r.Cursor.NextPage(ctx, r.Iterator)
+		if err != nil || next == nil {
+			return false, err
+		}
+		r.Iterator = next
+	}
+	r.Cursor.RowRead()
+	return true, nil
 }
 
 // KeyColumnInfo holds information about key columns
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/config"
+)
+
+// Retry policies accepted by RETRY and the retry.policy setting
+const (
+	RetryPolicyNone        = "none"
+	RetryPolicySimple      = "simple"
+	RetryPolicyExponential = "exponential"
+	RetryPolicyDowngrading = "downgrading"
+)
+
+const (
+	// DefaultNumRetries is used when a retry policy is chosen without a number of retries
+	DefaultNumRetries = 3
+	// Default bounds of the wait between attempts of the exponential backoff policy
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// RetrySettings describes how statements that fail or run slowly are retried. Only
+// idempotent statements are retried or executed speculatively.
+type RetrySettings struct {
+	Policy              string        // none, simple, exponential or downgrading
+	NumRetries          int           // Retries after the first attempt
+	MinBackoff          time.Duration // Exponential backoff bounds
+	MaxBackoff          time.Duration
+	SpeculativeAttempts int           // Extra executions started while a statement is slow; 0 disables them
+	SpeculativeDelay    time.Duration // How long to wait before each speculative execution
+}
+
+// retrySettingsFromConfig converts the retry section of the configuration
+func retrySettingsFromConfig(cfg *config.RetryConfig) (RetrySettings, error) {
+	if cfg == nil {
+		return RetrySettings{Policy: RetryPolicyNone}, nil
+	}
+	return RetrySettings{
+		Policy:              cfg.Policy,
+		NumRetries:          cfg.NumRetries,
+		MinBackoff:          time.Duration(cfg.MinBackoffMs) * time.Millisecond,
+		MaxBackoff:          time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+		SpeculativeAttempts: cfg.SpeculativeAttempts,
+		SpeculativeDelay:    time.Duration(cfg.SpeculativeDelayMs) * time.Millisecond,
+	}.normalize()
+}
+
+// ParseRetryPolicy validates a retry policy name
+func ParseRetryPolicy(name string) (string, error) {
+	switch policy := strings.ToLower(strings.TrimSpace(name)); policy {
+	case "", "off":
+		return RetryPolicyNone, nil
+	case RetryPolicyNone, RetryPolicySimple, RetryPolicyExponential, RetryPolicyDowngrading:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown retry policy %q (use simple, exponential, downgrading or none)", name)
+	}
+}
+
+// normalize validates the settings and fills in defaults
+func (r RetrySettings) normalize() (RetrySettings, error) {
+	policy, err := ParseRetryPolicy(r.Policy)
+	if err != nil {
+		return r, err
+	}
+	r.Policy = policy
+	if r.NumRetries < 0 || r.SpeculativeAttempts < 0 {
+		return r, fmt.Errorf("the number of retries and speculative executions can't be negative")
+	}
+	if r.Policy != RetryPolicyNone && r.NumRetries == 0 {
+		r.NumRetries = DefaultNumRetries
+	}
+	if r.MinBackoff <= 0 {
+		r.MinBackoff = defaultMinBackoff
+	}
+	if r.MaxBackoff < r.MinBackoff {
+		r.MaxBackoff = max(defaultMaxBackoff, r.MinBackoff)
+	}
+	if r.SpeculativeAttempts > 0 && r.SpeculativeDelay <= 0 {
+		return r, fmt.Errorf("speculative execution needs a delay greater than zero")
+	}
+	return r, nil
+}
+
+// PolicyString describes the retry policy for RETRY and SHOW SESSION
+func (r RetrySettings) PolicyString() string {
+	switch r.Policy {
+	case RetryPolicyNone, "":
+		return "none"
+	case RetryPolicyExponential:
+		return fmt.Sprintf("exponential, %d retries, backoff %s to %s", r.NumRetries, r.MinBackoff, r.MaxBackoff)
+	case RetryPolicyDowngrading:
+		return fmt.Sprintf("downgrading consistency, %d retries", r.NumRetries)
+	default:
+		return fmt.Sprintf("%s, %d retries", r.Policy, r.NumRetries)
+	}
+}
+
+// SpeculativeString describes speculative execution for RETRY and SHOW SESSION
+func (r RetrySettings) SpeculativeString() string {
+	if r.SpeculativeAttempts == 0 {
+		return "OFF"
+	}
+	return fmt.Sprintf("%d extra attempts, %s apart", r.SpeculativeAttempts, r.SpeculativeDelay)
+}
+
+// downgradeConsistency returns the next weaker consistency level to retry at
+func downgradeConsistency(c gocql.Consistency) (gocql.Consistency, bool) {
+	switch c {
+	case gocql.All:
+		return gocql.Quorum, true
+	case gocql.Quorum, gocql.Two:
+		return gocql.One, true
+	case gocql.Three:
+		return gocql.Two, true
+	case gocql.EachQuorum:
+		return gocql.LocalQuorum, true
+	case gocql.LocalQuorum:
+		return gocql.LocalOne, true
+	default:
+		return c, false
+	}
+}
+
+// downgradingRetryPolicy retries at a weaker consistency level each time, starting
+// from the level of the statement, and remembers the level it downgraded to. No level
+// is downgraded to ANY, so a zero consistency means no downgrade. Every statement gets
+// its own copy, so concurrent statements don't report each other's downgrade.
+type downgradingRetryPolicy struct {
+	numRetries   int
+	downgradedTo atomic.Uint32
+}
+
+func (d *downgradingRetryPolicy) Attempt(q gocql.RetryableQuery) bool {
+	if q.Attempts() > d.numRetries {
+		return false
+	}
+	next, ok := downgradeConsistency(q.GetConsistency())
+	if !ok {
+		return false
+	}
+	q.SetConsistency(next)
+	d.downgradedTo.Store(uint32(next))
+	return true
+}
+
+func (d *downgradingRetryPolicy) GetRetryType(err error) gocql.RetryType {
+	// The driver's policy decides from the error alone which errors are worth a retry
+	return (&gocql.DowngradingConsistencyRetryPolicy{}).GetRetryType(err)
+}
+
+// RetrySettings returns the current retry and speculative execution settings
+func (s *Session) RetrySettings() RetrySettings {
+	return s.retrySettings
+}
+
+// SetRetrySettings changes how statements are retried. It applies to the next statement.
+func (s *Session) SetRetrySettings(settings RetrySettings) error {
+	settings, err := settings.normalize()
+	if err != nil {
+		return err
+	}
+
+	switch settings.Policy {
+	case RetryPolicySimple:
+		s.retryPolicy = &gocql.SimpleRetryPolicy{NumRetries: settings.NumRetries}
+	case RetryPolicyExponential:
+		s.retryPolicy = &gocql.ExponentialBackoffRetryPolicy{
+			NumRetries: settings.NumRetries,
+			Min:        settings.MinBackoff,
+			Max:        settings.MaxBackoff,
+		}
+	case RetryPolicyDowngrading:
+		s.retryPolicy = &downgradingRetryPolicy{numRetries: settings.NumRetries}
+	default:
+		s.retryPolicy = nil
+	}
+
+	s.speculative = nil
+	if settings.SpeculativeAttempts > 0 {
+		s.speculative = &gocql.SimpleSpeculativeExecution{
+			NumAttempts:  settings.SpeculativeAttempts,
+			TimeoutDelay: settings.SpeculativeDelay,
+		}
+	}
+
+	s.retrySettings = settings
+	return nil
+}
+
+// applyRetryPolicy sets the retry and speculative execution policies of a statement the
+// user ran. Internal queries keep the driver defaults. It returns the statement's own
+// downgrading policy, or nil when the policy does not downgrade.
+func (s *Session) applyRetryPolicy(query *gocql.Query, stmt string) *downgradingRetryPolicy {
+	// The driver only retries statements marked idempotent
+	query.Idempotent(isIdempotentStatement(stmt))
+	policy := s.retryPolicy
+	downgrade, ok := policy.(*downgradingRetryPolicy)
+	if ok {
+		downgrade = &downgradingRetryPolicy{numRetries: downgrade.numRetries}
+		policy = downgrade
+	}
+	if policy != nil {
+		query.RetryPolicy(policy)
+	}
+	if s.speculative != nil {
+		query.SetSpeculativeExecutionPolicy(s.speculative)
+	}
+	return downgrade
+}
+
+// LastRetries returns how many times the last statement was attempted again, by the
+// retry policy or speculatively
+func (s *Session) LastRetries() int {
+	return int(s.lastRetries.Load())
+}
+
+// resetQueryStats forgets the retries and warnings of the previous statement
+func (s *Session) resetQueryStats() {
+	s.lastRetries.Store(0)
+	s.ResetLastWarnings()
+}
+
+// recordAttempts remembers how many attempts the query behind iter took and warns if
+// downgrade retried it at a lower consistency level. It returns the number of retries.
+func (s *Session) recordAttempts(iter *gocql.Iter, downgrade *downgradingRetryPolicy) int {
+	retries := max(iter.Attempts()-1, 0)
+	if retries > 0 {
+		s.lastRetries.Store(int32(retries))
+	}
+	if downgrade == nil {
+		return retries
+	}
+	if consistency := gocql.Consistency(downgrade.downgradedTo.Load()); consistency != 0 {
+		s.addWarning(fmt.Sprintf("Consistency %s could not be met, the statement was retried at %s",
+			s.Consistency(), consistency))
+	}
+	return retries
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/config"
+)
+
+func TestDowngradeConsistency(t *testing.T) {
+	tests := map[gocql.Consistency]gocql.Consistency{
+		gocql.All:         gocql.Quorum,
+		gocql.Quorum:      gocql.One,
+		gocql.Three:       gocql.Two,
+		gocql.EachQuorum:  gocql.LocalQuorum,
+		gocql.LocalQuorum: gocql.LocalOne,
+	}
+	for from, expected := range tests {
+		if got, ok := downgradeConsistency(from); !ok || got != expected {
+			t.Errorf("downgradeConsistency(%s) = %s, %v; want %s", from, got, ok, expected)
+		}
+	}
+	for _, c := range []gocql.Consistency{gocql.One, gocql.LocalOne, gocql.Any} {
+		if _, ok := downgradeConsistency(c); ok {
+			t.Errorf("expected no downgrade from %s", c)
+		}
+	}
+}
+
+func TestRetrySettingsFromConfig(t *testing.T) {
+	settings, err := retrySettingsFromConfig(nil)
+	if err != nil || settings.Policy != RetryPolicyNone {
+		t.Fatalf("expected no retries by default, got %+v, %v", settings, err)
+	}
+
+	settings, err = retrySettingsFromConfig(&config.RetryConfig{
+		Policy:              "Exponential",
+		MinBackoffMs:        200,
+		SpeculativeAttempts: 1,
+		SpeculativeDelayMs:  50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := RetrySettings{
+		Policy:              RetryPolicyExponential,
+		NumRetries:          DefaultNumRetries,
+		MinBackoff:          200 * time.Millisecond,
+		MaxBackoff:          defaultMaxBackoff,
+		SpeculativeAttempts: 1,
+		SpeculativeDelay:    50 * time.Millisecond,
+	}
+	if settings != expected {
+		t.Errorf("got %+v, want %+v", settings, expected)
+	}
+
+	for _, cfg := range []*config.RetryConfig{
+		{Policy: "sometimes"},
+		{Policy: "simple", NumRetries: -1},
+		{SpeculativeAttempts: 2},
+	} {
+		if _, err := retrySettingsFromConfig(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestSetRetrySettings(t *testing.T) {
+	s := &Session{}
+	if err := s.SetRetrySettings(RetrySettings{Policy: RetryPolicyDowngrading}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.retryPolicy.(*downgradingRetryPolicy); !ok {
+		t.Errorf("expected the downgrading policy, got %T", s.retryPolicy)
+	}
+	if s.speculative != nil {
+		t.Errorf("expected speculative execution to be off")
+	}
+
+	if err := s.SetRetrySettings(RetrySettings{SpeculativeAttempts: 2, SpeculativeDelay: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if s.retryPolicy != nil || s.speculative == nil || s.speculative.Attempts() != 2 {
+		t.Errorf("unexpected policies %T, %+v", s.retryPolicy, s.speculative)
+	}
+
+	s.addWarning("first")
+	if warnings := s.TakeWarnings(); len(warnings) != 1 || warnings[0] != "first" {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	if warnings := s.TakeWarnings(); len(warnings) != 0 {
+		t.Errorf("expected warnings to be cleared, got %v", warnings)
+	}
+}
+
+type retryableQuery struct {
+	attempts    int
+	consistency gocql.Consistency
+}
+
+func (q *retryableQuery) Attempts() int                      { return q.attempts }
+func (q *retryableQuery) SetConsistency(c gocql.Consistency) { q.consistency = c }
+func (q *retryableQuery) GetConsistency() gocql.Consistency  { return q.consistency }
+func (q *retryableQuery) Context() context.Context           { return context.Background() }
+
+func TestApplyRetryPolicy(t *testing.T) {
+	s := &Session{conn: &driverConn{session: &gocql.Session{}}, serialConsistency: gocql.Serial}
+	if err := s.SetRetrySettings(RetrySettings{Policy: RetryPolicyDowngrading, NumRetries: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Internal queries keep the driver defaults
+	if s.Query("SELECT * FROM system_schema.tables").IsIdempotent() {
+		t.Error("an internal query was marked idempotent")
+	}
+
+	// Each user statement reports its own downgrade
+	first, second := s.Query("SELECT * FROM users"), s.Query("SELECT * FROM orders")
+	firstDowngrade := s.applyRetryPolicy(first, first.Statement())
+	secondDowngrade := s.applyRetryPolicy(second, second.Statement())
+	if !first.IsIdempotent() || firstDowngrade == nil || firstDowngrade == secondDowngrade || firstDowngrade == s.retryPolicy {
+		t.Fatalf("statements share downgrading policies %p and %p", firstDowngrade, secondDowngrade)
+	}
+	if !firstDowngrade.Attempt(&retryableQuery{attempts: 1, consistency: gocql.Quorum}) {
+		t.Fatal("expected a retry at a lower consistency")
+	}
+	if got := gocql.Consistency(firstDowngrade.downgradedTo.Load()); got != gocql.One {
+		t.Errorf("first statement downgraded to %s, want ONE", got)
+	}
+	if got := secondDowngrade.downgradedTo.Load(); got != 0 {
+		t.Errorf("second statement downgraded to %s", gocql.Consistency(got))
+	}
+
+	if err := s.SetRetrySettings(RetrySettings{Policy: RetryPolicySimple, NumRetries: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if downgrade := s.applyRetryPolicy(first, first.Statement()); downgrade != nil {
+		t.Errorf("expected no downgrading policy, got %p", downgrade)
+	}
+}
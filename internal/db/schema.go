@@ -37,6 +37,10 @@ type SchemaCatalog struct {
 
 // GetSchemaCatalog retrieves the complete schema catalog from Cassandra
 func (s *Session) GetSchemaCatalog() (*SchemaCatalog, error) {
+	if !s.Connected() && s.loadedSnapshot() != nil {
+		return s.snapshotCatalog(s.loadedSnapshot()), nil
+	}
+
 	catalog := &SchemaCatalog{
 		Keyspaces: make(map[string]*KeyspaceSchema),
 	}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// DefaultSchemaAgreementTimeout bounds the wait for schema agreement after a DDL
+// statement. The driver waits up to this long after each schema change before returning.
+const DefaultSchemaAgreementTimeout = 10 * time.Second
+
+// NodeSchemaVersion is the schema version a node reports in system.local or system.peers
+type NodeSchemaVersion struct {
+	Address       string
+	DataCenter    string
+	Rack          string
+	HostID        string
+	SchemaVersion string // Empty if the node did not report one
+	Local         bool   // The node that answered, read from system.local
+}
+
+// SchemaVersions reads the schema version of every node from system.local and
+// system.peers of one coordinator, ordered by schema version and address
+func (s *Session) SchemaVersions(ctx context.Context) ([]NodeSchemaVersion, error) {
+	conn, release := s.acquireDriver()
+	defer release()
+	if conn == nil {
+		return nil, fmt.Errorf("not connected to database")
+	}
+
+	// Both tables must come from the same node, or a node is listed twice
+	hostID := ""
+	for _, host := range conn.session.GetHosts() {
+		if host.IsUp() && host.HostID() != "" {
+			hostID = host.HostID()
+			break
+		}
+	}
+	if hostID == "" {
+		return nil, fmt.Errorf("no node is up to read the schema versions from")
+	}
+
+	var nodes []NodeSchemaVersion
+	read := func(stmt string, local bool) error {
+		iter := conn.session.Query(stmt).SetHostID(hostID).WithContext(ctx).Iter()
+		var address net.IP
+		var dc, rack string
+		var id, version gocql.UUID
+		for iter.Scan(&address, &dc, &rack, &id, &version) {
+			node := NodeSchemaVersion{Address: address.String(), DataCenter: dc, Rack: rack, Local: local}
+			if id != (gocql.UUID{}) {
+				node.HostID = id.String()
+			}
+			if version != (gocql.UUID{}) {
+				node.SchemaVersion = version.String()
+			}
+			nodes = append(nodes, node)
+			address, dc, rack, id, version = nil, "", "", gocql.UUID{}, gocql.UUID{}
+		}
+		return iter.Close()
+	}
+	if err := read("SELECT broadcast_address, data_center, rack, host_id, schema_version FROM system.local", true); err != nil {
+		return nil, fmt.Errorf("failed to read system.local: %w", err)
+	}
+	if err := read("SELECT peer, data_center, rack, host_id, schema_version FROM system.peers", false); err != nil {
+		return nil, fmt.Errorf("failed to read system.peers: %w", err)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].SchemaVersion != nodes[j].SchemaVersion {
+			return nodes[i].SchemaVersion < nodes[j].SchemaVersion
+		}
+		return nodes[i].Address < nodes[j].Address
+	})
+	return nodes, nil
+}
+
+// SchemaDisagreement returns an error listing the schema versions and their nodes when
+// the nodes report more than one version. Nodes without a version are ignored.
+func SchemaDisagreement(nodes []NodeSchemaVersion) error {
+	var versions []string
+	addresses := make(map[string][]string)
+	for _, node := range nodes {
+		if node.SchemaVersion == "" {
+			continue
+		}
+		if addresses[node.SchemaVersion] == nil {
+			versions = append(versions, node.SchemaVersion)
+		}
+		addresses[node.SchemaVersion] = append(addresses[node.SchemaVersion], node.Address)
+	}
+	if len(versions) <= 1 {
+		return nil
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return len(addresses[versions[i]]) > len(addresses[versions[j]]) })
+	details := make([]string, len(versions))
+	for i, version := range versions {
+		details[i] = fmt.Sprintf("%s on %s", version, strings.Join(addresses[version], ", "))
+	}
+	return fmt.Errorf("nodes report %d schema versions: %s", len(versions), strings.Join(details, "; "))
+}
+
+// CheckSchemaAgreement reads the schema versions once and returns them. It is meant
+// for after a schema change, which the driver has already waited on, so it does not
+// wait itself. On disagreement it queues a warning for the current statement and
+// returns the SchemaDisagreement error.
+func (s *Session) CheckSchemaAgreement(ctx context.Context) ([]NodeSchemaVersion, error) {
+	nodes, err := s.SchemaVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if disagreement := SchemaDisagreement(nodes); disagreement != nil {
+		logger.DebugfToFile("SchemaAgreement", "No agreement after the driver's wait: %v", disagreement)
+		s.addWarning("Schema disagreement: " + disagreement.Error())
+		return nodes, disagreement
+	}
+	return nodes, nil
+}
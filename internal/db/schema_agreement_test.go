@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSchemaDisagreement(t *testing.T) {
+	nodes := []NodeSchemaVersion{
+		{Address: "10.0.0.1", SchemaVersion: "aaaa"},
+		{Address: "10.0.0.2", SchemaVersion: "aaaa"},
+		{Address: "10.0.0.3"},
+	}
+	if err := SchemaDisagreement(nodes); err != nil {
+		t.Errorf("SchemaDisagreement(agreed) = %v", err)
+	}
+	if err := SchemaDisagreement(nil); err != nil {
+		t.Errorf("SchemaDisagreement(nil) = %v", err)
+	}
+
+	nodes = append(nodes, NodeSchemaVersion{Address: "10.0.0.4", SchemaVersion: "bbbb"})
+	err := SchemaDisagreement(nodes)
+	want := "nodes report 2 schema versions: aaaa on 10.0.0.1, 10.0.0.2; bbbb on 10.0.0.4"
+	if err == nil || err.Error() != want {
+		t.Errorf("SchemaDisagreement = %v, want %s", err, want)
+	}
+}
+
+func TestCheckSchemaAgreementNotConnected(t *testing.T) {
+	s := &Session{}
+	nodes, err := s.CheckSchemaAgreement(context.Background())
+	if nodes != nil || err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("CheckSchemaAgreement = %v, %v", nodes, err)
+	}
+	if warnings := s.TakeWarnings(); len(warnings) != 0 {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
@@ -2,8 +2,10 @@ package db
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/axonops/cqlai/internal/logger"
@@ -19,6 +21,8 @@ type SchemaCache struct {
 	LastRefresh time.Time
 	Mu          sync.RWMutex
 	session     *Session
+	snapshot    atomic.Pointer[SchemaSnapshot] // Schema snapshot the cache was loaded from, if any
+	generation  atomic.Uint64                  // Incremented whenever the cached schema changes
 }
 
 // CachedTableInfo extends TableInfo with cache-specific fields
@@ -50,7 +54,7 @@ func NewSchemaCache(session *Session) *SchemaCache {
 // Note: gocql doesn't provide a direct method to list all keyspaces,
 // so we need to query system tables for this specific case
 func (sc *SchemaCache) GetAllKeyspaces() ([]string, error) {
-	if sc.session == nil || sc.session.Session == nil {
+	if sc.session == nil || !sc.session.Connected() {
 		return nil, fmt.Errorf("no session available")
 	}
 
@@ -75,7 +79,21 @@ func (sc *SchemaCache) GetAllKeyspaces() ([]string, error) {
 
 // GetKeyspaceTables returns all tables for a specific keyspace using gocql metadata
 func (sc *SchemaCache) GetKeyspaceTables(keyspace string) ([]CachedTableInfo, error) {
-	if sc.session == nil || sc.session.Session == nil {
+	if snapshot := sc.Snapshot(); snapshot != nil {
+		ks := snapshot.Keyspace(keyspace)
+		if ks == nil {
+			return nil, fmt.Errorf("keyspace %s not found in the schema snapshot", keyspace)
+		}
+		var tables []CachedTableInfo
+		for i := range ks.Tables {
+			tables = append(tables, CachedTableInfo{
+				TableInfo:   *ks.Tables[i].TableInfo(keyspace),
+				LastUpdated: snapshot.CreatedAt,
+			})
+		}
+		return tables, nil
+	}
+	if sc.session == nil || !sc.session.Connected() {
 		return nil, fmt.Errorf("no session available")
 	}
 
@@ -112,7 +130,14 @@ func (sc *SchemaCache) GetKeyspaceTables(keyspace string) ([]CachedTableInfo, er
 
 // GetTableColumns returns columns for a specific table using gocql metadata
 func (sc *SchemaCache) GetTableColumns(keyspace, table string) ([]ColumnInfo, error) {
-	if sc.session == nil || sc.session.Session == nil {
+	if sc.Snapshot() != nil {
+		t, err := sc.snapshotTable(keyspace, table)
+		if err != nil {
+			return nil, err
+		}
+		return t.Columns, nil
+	}
+	if sc.session == nil || !sc.session.Connected() {
 		return nil, fmt.Errorf("no session available")
 	}
 
@@ -188,11 +213,18 @@ func (sc *SchemaCache) Refresh() error {
 
 	logger.DebugToFile("SchemaCache", "Starting schema refresh using metadata API")
 
+	// A snapshot stands in for the cluster until there is one to refresh from
+	if sc.Snapshot() != nil && (sc.session == nil || !sc.session.Connected()) {
+		sc.LastRefresh = time.Now()
+		return nil
+	}
+
 	// Get all keyspaces
 	keyspaces, err := sc.GetAllKeyspaces()
 	if err != nil {
 		return fmt.Errorf("failed to get keyspaces: %w", err)
 	}
+	sc.snapshot.Store(nil)
 
 	sc.Keyspaces = keyspaces
 
@@ -235,6 +267,7 @@ func (sc *SchemaCache) Refresh() error {
 	}
 
 	sc.LastRefresh = time.Now()
+	sc.generation.Add(1)
 	logger.DebugfToFile("SchemaCache", "Schema refresh completed. Found %d keyspaces", len(keyspaces))
 
 	return nil
@@ -242,6 +275,11 @@ func (sc *SchemaCache) Refresh() error {
 
 // RefreshIfNeeded refreshes the cache if it's older than the specified duration
 func (sc *SchemaCache) RefreshIfNeeded(maxAge time.Duration) error {
+	// A loaded snapshot does not age; DDL refreshes the cache explicitly
+	if sc.Snapshot() != nil {
+		return nil
+	}
+
 	sc.Mu.RLock()
 	needsRefresh := time.Since(sc.LastRefresh) > maxAge
 	sc.Mu.RUnlock()
@@ -252,9 +290,178 @@ func (sc *SchemaCache) RefreshIfNeeded(maxAge time.Duration) error {
 	return nil
 }
 
+// Generation changes whenever the cached schema does, so users of the cache can tell
+// when indexes built from it are stale
+func (sc *SchemaCache) Generation() uint64 {
+	return sc.generation.Load()
+}
+
+// ApplySchemaEvent updates the cache for a schema change the cluster pushed. Only the
+// keyspace or table that changed is reloaded; other changes just move the generation.
+// A loaded snapshot is kept until the schema is refreshed explicitly.
+func (sc *SchemaCache) ApplySchemaEvent(event SchemaEvent) {
+	if sc.Snapshot() != nil {
+		logger.DebugfToFile("SchemaCache", "Keeping the loaded schema snapshot, ignoring %s", event)
+		return
+	}
+
+	// Read the changed schema before taking Mu so readers don't wait on the driver
+	var tables []*TableInfo
+	var err error
+	switch event.Target {
+	case SchemaTargetKeyspace:
+		if event.Change == SchemaCreated {
+			tables, err = sc.fetchKeyspace(event.Keyspace)
+		}
+	case SchemaTargetTable:
+		if event.Change != SchemaDropped {
+			var info *TableInfo
+			if info, err = sc.GetTableInfo(event.Keyspace, event.Name); err == nil {
+				tables = []*TableInfo{info}
+			}
+		}
+	}
+	if (event.Target == SchemaTargetType || event.Target == SchemaTargetKeyspace) && sc.session != nil {
+		sc.session.GetUDTRegistry().ClearKeyspace(event.Keyspace)
+	}
+
+	sc.Mu.Lock()
+	defer sc.Mu.Unlock()
+	defer sc.generation.Add(1)
+
+	if err != nil {
+		// Let the next RefreshIfNeeded reload everything instead
+		logger.DebugfToFile("SchemaCache", "Failed to apply %s: %v", event, err)
+		sc.LastRefresh = time.Time{}
+		return
+	}
+
+	switch event.Target {
+	case SchemaTargetKeyspace:
+		switch event.Change {
+		case SchemaCreated:
+			sc.removeKeyspace(event.Keyspace)
+			sc.Keyspaces = append(sc.Keyspaces, event.Keyspace)
+			for _, info := range tables {
+				sc.storeTable(info)
+			}
+		case SchemaDropped:
+			sc.removeKeyspace(event.Keyspace)
+		}
+	case SchemaTargetTable:
+		if event.Change == SchemaDropped {
+			sc.removeTable(event.Keyspace, event.Name)
+		} else {
+			sc.storeTable(tables[0])
+		}
+	}
+}
+
+// fetchKeyspace reads the tables of a keyspace with their keys and columns
+func (sc *SchemaCache) fetchKeyspace(keyspace string) ([]*TableInfo, error) {
+	cached, err := sc.GetKeyspaceTables(keyspace)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*TableInfo, 0, len(cached))
+	for _, table := range cached {
+		info, err := sc.GetTableInfo(keyspace, table.TableName)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, info)
+	}
+	return tables, nil
+}
+
+// storeTable replaces one table's keys, columns and search tokens. Callers hold Mu.
+func (sc *SchemaCache) storeTable(info *TableInfo) {
+	keyspace, table := info.KeyspaceName, info.TableName
+	sc.removeTable(keyspace, table)
+
+	if !slices.Contains(sc.Keyspaces, keyspace) {
+		sc.Keyspaces = append(sc.Keyspaces, keyspace)
+	}
+	if sc.Tables == nil {
+		sc.Tables = make(map[string][]CachedTableInfo)
+	}
+	sc.Tables[keyspace] = append(sc.Tables[keyspace], CachedTableInfo{
+		TableInfo: TableInfo{
+			KeyspaceName:   keyspace,
+			TableName:      table,
+			PartitionKeys:  info.PartitionKeys,
+			ClusteringKeys: info.ClusteringKeys,
+		},
+		LastUpdated: time.Now(),
+	})
+	if sc.Columns == nil {
+		sc.Columns = make(map[string]map[string][]ColumnInfo)
+	}
+	if sc.Columns[keyspace] == nil {
+		sc.Columns[keyspace] = make(map[string][]ColumnInfo)
+	}
+	sc.Columns[keyspace][table] = info.Columns
+	if sc.SearchIndex == nil {
+		sc.SearchIndex = &SearchIndex{TableTokens: make(map[string][]string)}
+	}
+	sc.SearchIndex.TableTokens[fmt.Sprintf("%s.%s", keyspace, table)] = buildSearchTokens(table)
+}
+
+// removeKeyspace drops a keyspace and its tables from the cache. Callers hold Mu.
+func (sc *SchemaCache) removeKeyspace(keyspace string) {
+	if sc.SearchIndex != nil {
+		for _, table := range sc.Tables[keyspace] {
+			delete(sc.SearchIndex.TableTokens, fmt.Sprintf("%s.%s", keyspace, table.TableName))
+		}
+	}
+	delete(sc.Tables, keyspace)
+	delete(sc.Columns, keyspace)
+	for i, ks := range sc.Keyspaces {
+		if ks == keyspace {
+			sc.Keyspaces = append(sc.Keyspaces[:i:i], sc.Keyspaces[i+1:]...)
+			break
+		}
+	}
+}
+
+// removeTable drops a table from the cache. Callers hold Mu.
+func (sc *SchemaCache) removeTable(keyspace, table string) {
+	tables := sc.Tables[keyspace]
+	for i, t := range tables {
+		if t.TableName == table {
+			sc.Tables[keyspace] = append(tables[:i:i], tables[i+1:]...)
+			break
+		}
+	}
+	delete(sc.Columns[keyspace], table)
+	if sc.SearchIndex != nil {
+		delete(sc.SearchIndex.TableTokens, fmt.Sprintf("%s.%s", keyspace, table))
+	}
+}
+
+// loadedTable returns a table as already loaded in the cache, without asking the cluster
+func (sc *SchemaCache) loadedTable(keyspace, table string) (TableInfo, bool) {
+	sc.Mu.RLock()
+	defer sc.Mu.RUnlock()
+
+	for _, t := range sc.Tables[keyspace] {
+		if t.TableName == table {
+			return t.TableInfo, true
+		}
+	}
+	return TableInfo{}, false
+}
+
 // GetTableInfo retrieves information about a specific table
 func (sc *SchemaCache) GetTableInfo(keyspace, table string) (*TableInfo, error) {
-	if sc.session == nil || sc.session.Session == nil {
+	if sc.Snapshot() != nil {
+		t, err := sc.snapshotTable(keyspace, table)
+		if err != nil {
+			return nil, err
+		}
+		return t.TableInfo(keyspace), nil
+	}
+	if sc.session == nil || !sc.session.Connected() {
 		return nil, fmt.Errorf("no session available")
 	}
 
@@ -0,0 +1,507 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Phases order the statements of a schema diff so that objects are dropped before
+// what they depend on and created after it
+const (
+	phaseDropView = iota
+	phaseDropIndex
+	phaseDropTable
+	phaseDropType
+	phaseKeyspace
+	phaseType
+	phaseTable
+	phaseCreateIndex
+	phaseView
+	phaseDropKeyspace
+)
+
+// SchemaChange is one difference between two schemas. Statements apply it to the
+// source schema; changes CQL cannot make in place get a comment instead.
+type SchemaChange struct {
+	Object        string // KEYSPACE, TYPE, TABLE, COLUMN, OPTION, INDEX or VIEW
+	Name          string // Qualified name, e.g. keyspace.table.column
+	Change        string // added, removed or changed
+	Detail        string
+	Statements    []string
+	phase         int
+	recreate      []string // Statements that recreate a dropped object, run in recreatePhase
+	recreatePhase int
+}
+
+// SchemaDiff lists the changes that turn a source schema into a target schema
+type SchemaDiff struct {
+	Changes []SchemaChange
+}
+
+// Statements returns the CQL that converges the source schema to the target, in an
+// order that respects dependencies between objects
+func (d SchemaDiff) Statements() []string {
+	type phased struct {
+		phase      int
+		statements []string
+	}
+	var steps []phased
+	for _, change := range d.Changes {
+		steps = append(steps, phased{change.phase, change.Statements})
+		if len(change.recreate) > 0 {
+			steps = append(steps, phased{change.recreatePhase, change.recreate})
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].phase < steps[j].phase })
+
+	var statements []string
+	for _, step := range steps {
+		statements = append(statements, step.statements...)
+	}
+	return statements
+}
+
+// DiffSchemas compares two schema snapshots. When both hold a single keyspace they
+// are compared whatever their names; otherwise keyspaces are matched by name.
+// Statements use the name of the source keyspace.
+func DiffSchemas(source, target *SchemaSnapshot) SchemaDiff {
+	var diff SchemaDiff
+	if len(source.Keyspaces) == 1 && len(target.Keyspaces) == 1 {
+		diff.diffKeyspace(&source.Keyspaces[0], &target.Keyspaces[0])
+		return diff
+	}
+
+	for _, name := range unionNames(source.KeyspaceNames(), target.KeyspaceNames()) {
+		diff.diffKeyspace(source.Keyspace(name), target.Keyspace(name))
+	}
+	return diff
+}
+
+// add records a change
+func (d *SchemaDiff) add(phase int, object, name, change, detail string, statements ...string) {
+	d.Changes = append(d.Changes, SchemaChange{
+		Object:     object,
+		Name:       name,
+		Change:     change,
+		Detail:     detail,
+		Statements: statements,
+		phase:      phase,
+	})
+}
+
+// recreate adds the statement that recreates the object the last change dropped
+func (d *SchemaDiff) recreate(phase int, statement string) {
+	last := &d.Changes[len(d.Changes)-1]
+	last.recreate = append(last.recreate, statement)
+	last.recreatePhase = phase
+}
+
+// diffKeyspace compares a keyspace of both schemas; either may be nil
+func (d *SchemaDiff) diffKeyspace(source, target *KeyspaceSnapshot) {
+	switch {
+	case source == nil:
+		d.add(phaseKeyspace, "KEYSPACE", target.Name, "added", "", createKeyspaceStatements(target.Name, target)...)
+		return
+	case target == nil:
+		d.add(phaseDropKeyspace, "KEYSPACE", source.Name, "removed", "",
+			fmt.Sprintf("DROP KEYSPACE %s;", source.Name))
+		return
+	}
+
+	ks := source.Name
+	if !reflect.DeepEqual(source.Replication, target.Replication) || source.DurableWrites != target.DurableWrites {
+		var details []string
+		if !reflect.DeepEqual(source.Replication, target.Replication) {
+			details = append(details, fmt.Sprintf("replication %s → %s",
+				FormatMapForCQL(source.Replication), FormatMapForCQL(target.Replication)))
+		}
+		if source.DurableWrites != target.DurableWrites {
+			details = append(details, fmt.Sprintf("durable_writes %v → %v", source.DurableWrites, target.DurableWrites))
+		}
+		d.add(phaseKeyspace, "KEYSPACE", ks, "changed", strings.Join(details, "; "),
+			fmt.Sprintf("ALTER KEYSPACE %s WITH replication = %s AND durable_writes = %v;",
+				ks, FormatMapForCQL(target.Replication), target.DurableWrites))
+	}
+
+	d.diffTypes(ks, source.Types, target.Types)
+	d.diffTables(ks, source.Tables, target.Tables)
+	d.diffIndexes(ks, source.Indexes, target.Indexes)
+	d.diffViews(ks, source.Views, target.Views)
+}
+
+// diffTypes compares the user-defined types of a keyspace
+func (d *SchemaDiff) diffTypes(ks string, source, target []TypeInfo) {
+	sourceTypes := make(map[string]*TypeInfo)
+	for i := range source {
+		sourceTypes[source[i].Name] = &source[i]
+	}
+	targetTypes := make(map[string]*TypeInfo)
+	for i := range target {
+		targetTypes[target[i].Name] = &target[i]
+	}
+
+	// Types are created in dependency order, so nested types come first
+	for _, t := range orderTypes(target) {
+		if _, ok := sourceTypes[t.Name]; !ok {
+			d.add(phaseType, "TYPE", ks+"."+t.Name, "added", "", formatTypeCreateStatement(ks, &t))
+		}
+	}
+
+	for _, name := range unionNames(typeNames(source), typeNames(target)) {
+		src, tgt := sourceTypes[name], targetTypes[name]
+		qualified := ks + "." + name
+		switch {
+		case tgt == nil:
+			d.add(phaseDropType, "TYPE", qualified, "removed", "", fmt.Sprintf("DROP TYPE %s;", qualified))
+		case src != nil:
+			srcFields := typeFields(src)
+			tgtFields := typeFields(tgt)
+			for i, field := range tgt.FieldNames {
+				srcType, ok := srcFields[field]
+				switch {
+				case !ok:
+					d.add(phaseType, "FIELD", qualified+"."+field, "added", tgt.FieldTypes[i],
+						fmt.Sprintf("ALTER TYPE %s ADD %s %s;", qualified, field, tgt.FieldTypes[i]))
+				case srcType != tgt.FieldTypes[i]:
+					d.add(phaseType, "FIELD", qualified+"."+field, "changed", srcType+" → "+tgt.FieldTypes[i],
+						fmt.Sprintf("-- %s.%s: the type of a field cannot be changed", qualified, field))
+				}
+			}
+			for _, field := range src.FieldNames {
+				if _, ok := tgtFields[field]; !ok {
+					d.add(phaseType, "FIELD", qualified+"."+field, "removed", srcFields[field],
+						fmt.Sprintf("-- %s.%s: fields cannot be dropped from a type", qualified, field))
+				}
+			}
+		}
+	}
+}
+
+// diffTables compares the tables of a keyspace: columns, primary key and options
+func (d *SchemaDiff) diffTables(ks string, source, target []TableSnapshot) {
+	sourceTables := make(map[string]*TableSnapshot)
+	var sourceNames []string
+	for i := range source {
+		sourceTables[source[i].Name] = &source[i]
+		sourceNames = append(sourceNames, source[i].Name)
+	}
+	targetTables := make(map[string]*TableSnapshot)
+	var targetNames []string
+	for i := range target {
+		targetTables[target[i].Name] = &target[i]
+		targetNames = append(targetNames, target[i].Name)
+	}
+
+	for _, name := range unionNames(sourceNames, targetNames) {
+		src, tgt := sourceTables[name], targetTables[name]
+		qualified := ks + "." + name
+		switch {
+		case src == nil:
+			d.add(phaseTable, "TABLE", qualified, "added", "", FormatTableCreateStatement(tgt.TableInfo(ks), false))
+		case tgt == nil:
+			d.add(phaseDropTable, "TABLE", qualified, "removed", "", fmt.Sprintf("DROP TABLE %s;", qualified))
+		default:
+			d.diffTable(qualified, src, tgt)
+		}
+	}
+}
+
+// diffTable compares a table present in both schemas
+func (d *SchemaDiff) diffTable(qualified string, source, target *TableSnapshot) {
+	sourceKey := primaryKeyText(source.PartitionKeys, source.ClusteringKeys)
+	targetKey := primaryKeyText(target.PartitionKeys, target.ClusteringKeys)
+	if sourceKey != targetKey {
+		d.add(phaseTable, "TABLE", qualified, "changed", "primary key "+sourceKey+" → "+targetKey,
+			fmt.Sprintf("-- %s: the primary key cannot be changed; recreate the table and reload its data", qualified))
+	} else {
+		sourceColumns := columnsByName(source.Columns)
+		targetColumns := columnsByName(target.Columns)
+		for _, col := range target.Columns {
+			src, ok := sourceColumns[col.Name]
+			switch {
+			case !ok:
+				static := ""
+				if col.Kind == "static" {
+					static = " static"
+				}
+				d.add(phaseTable, "COLUMN", qualified+"."+col.Name, "added", col.DataType+static,
+					fmt.Sprintf("ALTER TABLE %s ADD %s %s%s;", qualified, col.Name, col.DataType, static))
+			case src.DataType != col.DataType || src.Kind != col.Kind:
+				d.add(phaseTable, "COLUMN", qualified+"."+col.Name, "changed",
+					columnText(src)+" → "+columnText(col),
+					fmt.Sprintf("-- %s.%s: the type of a column cannot be changed", qualified, col.Name))
+			}
+		}
+		for _, col := range source.Columns {
+			if _, ok := targetColumns[col.Name]; !ok {
+				d.add(phaseTable, "COLUMN", qualified+"."+col.Name, "removed", col.DataType,
+					fmt.Sprintf("ALTER TABLE %s DROP %s;", qualified, col.Name))
+			}
+		}
+	}
+
+	d.diffOptions(phaseTable, "ALTER TABLE", qualified, source.Options, target.Options)
+}
+
+// diffOptions compares the WITH options of a table or view
+func (d *SchemaDiff) diffOptions(phase int, alter, qualified string, source, target map[string]string) {
+	for _, name := range unionNames(mapKeys(source), mapKeys(target)) {
+		src, inSource := source[name]
+		tgt, inTarget := target[name]
+		switch {
+		case !inTarget:
+			// Usually an option of a newer Cassandra version; it cannot be unset
+			d.add(phase, "OPTION", qualified+"."+name, "removed", src)
+		case !inSource:
+			d.add(phase, "OPTION", qualified+"."+name, "added", tgt,
+				fmt.Sprintf("%s %s WITH %s = %s;", alter, qualified, name, tgt))
+		case src != tgt:
+			d.add(phase, "OPTION", qualified+"."+name, "changed", src+" → "+tgt,
+				fmt.Sprintf("%s %s WITH %s = %s;", alter, qualified, name, tgt))
+		}
+	}
+}
+
+// diffIndexes compares the secondary indexes of a keyspace
+func (d *SchemaDiff) diffIndexes(ks string, source, target []IndexInfo) {
+	sourceIndexes := make(map[string]*IndexInfo)
+	var sourceNames []string
+	for i := range source {
+		sourceIndexes[source[i].IndexName] = &source[i]
+		sourceNames = append(sourceNames, source[i].IndexName)
+	}
+	targetIndexes := make(map[string]*IndexInfo)
+	var targetNames []string
+	for i := range target {
+		targetIndexes[target[i].IndexName] = &target[i]
+		targetNames = append(targetNames, target[i].IndexName)
+	}
+
+	for _, name := range unionNames(sourceNames, targetNames) {
+		src, tgt := sourceIndexes[name], targetIndexes[name]
+		qualified := ks + "." + name
+		drop := fmt.Sprintf("DROP INDEX %s;", qualified)
+		switch {
+		case src == nil:
+			d.add(phaseCreateIndex, "INDEX", qualified, "added", "on "+tgt.TableName, formatIndexCreateStatement(ks, tgt))
+		case tgt == nil:
+			d.add(phaseDropIndex, "INDEX", qualified, "removed", "on "+src.TableName, drop)
+		case src.TableName != tgt.TableName || src.Kind != tgt.Kind || !reflect.DeepEqual(src.Options, tgt.Options):
+			d.add(phaseDropIndex, "INDEX", qualified, "changed", indexText(src)+" → "+indexText(tgt), drop)
+			d.recreate(phaseCreateIndex, formatIndexCreateStatement(ks, tgt))
+		}
+	}
+}
+
+// diffViews compares the materialized views of a keyspace
+func (d *SchemaDiff) diffViews(ks string, source, target []ViewSnapshot) {
+	sourceViews := make(map[string]*ViewSnapshot)
+	var sourceNames []string
+	for i := range source {
+		sourceViews[source[i].Name] = &source[i]
+		sourceNames = append(sourceNames, source[i].Name)
+	}
+	targetViews := make(map[string]*ViewSnapshot)
+	var targetNames []string
+	for i := range target {
+		targetViews[target[i].Name] = &target[i]
+		targetNames = append(targetNames, target[i].Name)
+	}
+
+	for _, name := range unionNames(sourceNames, targetNames) {
+		src, tgt := sourceViews[name], targetViews[name]
+		qualified := ks + "." + name
+		drop := fmt.Sprintf("DROP MATERIALIZED VIEW %s;", qualified)
+		switch {
+		case src == nil:
+			d.add(phaseView, "VIEW", qualified, "added", "on "+tgt.BaseTable, formatViewCreateStatement(ks, tgt))
+		case tgt == nil:
+			d.add(phaseDropView, "VIEW", qualified, "removed", "on "+src.BaseTable, drop)
+		case viewDefinition(src) != viewDefinition(tgt):
+			d.add(phaseDropView, "VIEW", qualified, "changed", "definition changed; the view is recreated", drop)
+			d.recreate(phaseView, formatViewCreateStatement(ks, tgt))
+		default:
+			d.diffOptions(phaseView, "ALTER MATERIALIZED VIEW", qualified, src.Options, tgt.Options)
+		}
+	}
+}
+
+// createKeyspaceStatements returns the statements that create a keyspace and its objects
+func createKeyspaceStatements(ks string, keyspace *KeyspaceSnapshot) []string {
+	statements := []string{fmt.Sprintf("CREATE KEYSPACE %s WITH replication = %s AND durable_writes = %v;",
+		ks, FormatMapForCQL(keyspace.Replication), keyspace.DurableWrites)}
+	for _, t := range orderTypes(keyspace.Types) {
+		statements = append(statements, formatTypeCreateStatement(ks, &t))
+	}
+	for i := range keyspace.Functions {
+		statements = append(statements, formatFunctionCreateStatement(ks, &keyspace.Functions[i]))
+	}
+	for i := range keyspace.Aggregates {
+		statements = append(statements, formatAggregateCreateStatement(ks, &keyspace.Aggregates[i]))
+	}
+	for i := range keyspace.Tables {
+		statements = append(statements, FormatTableCreateStatement(keyspace.Tables[i].TableInfo(ks), false))
+	}
+	for i := range keyspace.Indexes {
+		statements = append(statements, formatIndexCreateStatement(ks, &keyspace.Indexes[i]))
+	}
+	for i := range keyspace.Views {
+		statements = append(statements, formatViewCreateStatement(ks, &keyspace.Views[i]))
+	}
+	return statements
+}
+
+// formatViewCreateStatement formats the CREATE MATERIALIZED VIEW statement of a view
+func formatViewCreateStatement(ks string, view *ViewSnapshot) string {
+	var result strings.Builder
+
+	selectors := "*"
+	if !view.AllColumns {
+		names := make([]string, len(view.Columns))
+		for i, col := range view.Columns {
+			names[i] = col.Name
+		}
+		selectors = strings.Join(names, ", ")
+	}
+
+	fmt.Fprintf(&result, "CREATE MATERIALIZED VIEW %s.%s AS\n", ks, view.Name)
+	fmt.Fprintf(&result, "    SELECT %s FROM %s.%s\n", selectors, ks, view.BaseTable)
+	fmt.Fprintf(&result, "    WHERE %s\n", view.WhereClause)
+	fmt.Fprintf(&result, "    PRIMARY KEY %s", primaryKeyText(view.PartitionKeys, view.ClusteringKeys))
+
+	var options []string
+	for _, name := range mapKeys(view.Options) {
+		options = append(options, name+" = "+view.Options[name])
+	}
+	if len(options) > 0 {
+		result.WriteString(" WITH ")
+		result.WriteString(strings.Join(options, "\n    AND "))
+	}
+	result.WriteString(";")
+	return result.String()
+}
+
+// typeReferencePattern finds the identifiers in a CQL type, e.g. frozen<address>
+var typeReferencePattern = regexp.MustCompile(`\w+`)
+
+// orderTypes sorts types so that a type comes after the types its fields use
+func orderTypes(types []TypeInfo) []TypeInfo {
+	byName := make(map[string]TypeInfo, len(types))
+	for _, t := range types {
+		byName[t.Name] = t
+	}
+
+	var ordered []TypeInfo
+	visited := make(map[string]bool)
+	var visit func(t TypeInfo)
+	visit = func(t TypeInfo) {
+		if visited[t.Name] {
+			return
+		}
+		visited[t.Name] = true
+		for _, fieldType := range t.FieldTypes {
+			for _, ref := range typeReferencePattern.FindAllString(fieldType, -1) {
+				if dep, ok := byName[ref]; ok {
+					visit(dep)
+				}
+			}
+		}
+		ordered = append(ordered, t)
+	}
+	for _, name := range typeNames(types) {
+		visit(byName[name])
+	}
+	return ordered
+}
+
+// primaryKeyText formats a primary key as in CREATE TABLE, e.g. ((a, b), c)
+func primaryKeyText(partitionKeys, clusteringKeys []string) string {
+	partition := strings.Join(partitionKeys, ", ")
+	if len(partitionKeys) > 1 {
+		partition = "(" + partition + ")"
+	}
+	if len(clusteringKeys) == 0 {
+		return "(" + partition + ")"
+	}
+	return "(" + partition + ", " + strings.Join(clusteringKeys, ", ") + ")"
+}
+
+// viewDefinition renders what cannot be changed without recreating a view
+func viewDefinition(view *ViewSnapshot) string {
+	columns := make([]string, len(view.Columns))
+	for i, col := range view.Columns {
+		columns[i] = col.Name
+	}
+	return fmt.Sprintf("%s|%s|%v|%s|%s", view.BaseTable, view.WhereClause, view.AllColumns,
+		strings.Join(columns, ","), primaryKeyText(view.PartitionKeys, view.ClusteringKeys))
+}
+
+// columnText describes a column for a diff, e.g. "int static"
+func columnText(col ColumnInfo) string {
+	if col.Kind == "regular" || col.Kind == "" {
+		return col.DataType
+	}
+	return col.DataType + " " + col.Kind
+}
+
+// indexText describes an index for a diff
+func indexText(index *IndexInfo) string {
+	return fmt.Sprintf("%s %s %s", index.TableName, index.Kind, FormatMapForCQL(index.Options))
+}
+
+// columnsByName indexes columns by name
+func columnsByName(columns []ColumnInfo) map[string]ColumnInfo {
+	byName := make(map[string]ColumnInfo, len(columns))
+	for _, col := range columns {
+		byName[col.Name] = col
+	}
+	return byName
+}
+
+// typeFields maps the fields of a type to their types
+func typeFields(t *TypeInfo) map[string]string {
+	fields := make(map[string]string, len(t.FieldNames))
+	for i, name := range t.FieldNames {
+		if i < len(t.FieldTypes) {
+			fields[name] = t.FieldTypes[i]
+		}
+	}
+	return fields
+}
+
+// typeNames returns the sorted names of types
+func typeNames(types []TypeInfo) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mapKeys returns the sorted keys of a map
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unionNames returns the sorted names present in either list
+func unionNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var names []string
+	for _, name := range append(append([]string(nil), a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
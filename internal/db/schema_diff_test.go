@@ -0,0 +1,189 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func testKeyspace(name string) KeyspaceSnapshot {
+	return KeyspaceSnapshot{
+		Name:          name,
+		Replication:   map[string]string{"class": "org.apache.cassandra.locator.SimpleStrategy", "replication_factor": "1"},
+		DurableWrites: true,
+		Types: []TypeInfo{
+			{Name: "address", FieldNames: []string{"street", "city"}, FieldTypes: []string{"text", "text"}},
+		},
+		Tables: []TableSnapshot{{
+			Name: "users",
+			Columns: []ColumnInfo{
+				{Name: "id", DataType: "uuid", Kind: "partition_key"},
+				{Name: "email", DataType: "text", Kind: "regular", Position: -1},
+				{Name: "home", DataType: "frozen<address>", Kind: "regular", Position: -1},
+			},
+			PartitionKeys: []string{"id"},
+			Options:       map[string]string{"comment": "''", "gc_grace_seconds": "864000"},
+		}},
+		Indexes: []IndexInfo{
+			{TableName: "users", IndexName: "users_email_idx", Kind: "COMPOSITES", Options: map[string]string{"target": "email"}},
+		},
+	}
+}
+
+func TestDiffSchemasIdentical(t *testing.T) {
+	source := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app")}}
+	target := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app_staging")}}
+
+	if diff := DiffSchemas(source, target); len(diff.Changes) != 0 {
+		t.Errorf("expected no changes, got %+v", diff.Changes)
+	}
+}
+
+func TestDiffSchemasChanges(t *testing.T) {
+	source := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app")}}
+	targetKs := testKeyspace("app")
+	targetKs.Replication = map[string]string{"class": "org.apache.cassandra.locator.NetworkTopologyStrategy", "dc1": "3"}
+	targetKs.Types = append(targetKs.Types, TypeInfo{Name: "geo", FieldNames: []string{"lat", "lon"}, FieldTypes: []string{"double", "double"}})
+	targetKs.Types[0].FieldNames = append(targetKs.Types[0].FieldNames, "location")
+	targetKs.Types[0].FieldTypes = append(targetKs.Types[0].FieldTypes, "frozen<geo>")
+	users := &targetKs.Tables[0]
+	users.Columns = append(users.Columns[:1], ColumnInfo{Name: "name", DataType: "text", Kind: "regular", Position: -1}, users.Columns[2])
+	users.Options = map[string]string{"comment": "'accounts'", "gc_grace_seconds": "864000"}
+	targetKs.Indexes[0].Options = map[string]string{"target": "name"}
+	targetKs.Tables = append(targetKs.Tables, TableSnapshot{
+		Name:          "events",
+		Columns:       []ColumnInfo{{Name: "id", DataType: "uuid", Kind: "partition_key"}},
+		PartitionKeys: []string{"id"},
+	})
+	target := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{targetKs}}
+
+	diff := DiffSchemas(source, target)
+
+	var got []string
+	for _, change := range diff.Changes {
+		got = append(got, change.Object+" "+change.Name+" "+change.Change)
+	}
+	want := []string{
+		"KEYSPACE app changed",
+		"TYPE app.geo added",
+		"FIELD app.address.location added",
+		"TABLE app.events added",
+		"COLUMN app.users.name added",
+		"COLUMN app.users.email removed",
+		"OPTION app.users.comment changed",
+		"INDEX app.users_email_idx changed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	statements := strings.Join(diff.Statements(), "\n")
+	for _, s := range []string{
+		"DROP INDEX app.users_email_idx;",
+		"ALTER KEYSPACE app WITH replication = {'class': 'org.apache.cassandra.locator.NetworkTopologyStrategy', 'dc1': '3'} AND durable_writes = true;",
+		"CREATE TYPE app.geo (",
+		"ALTER TYPE app.address ADD location frozen<geo>;",
+		"CREATE TABLE app.events (",
+		"ALTER TABLE app.users ADD name text;",
+		"ALTER TABLE app.users DROP email;",
+		"ALTER TABLE app.users WITH comment = 'accounts';",
+		"CREATE INDEX users_email_idx ON app.users (name);",
+	} {
+		if !strings.Contains(statements, s) {
+			t.Errorf("statements missing %q:\n%s", s, statements)
+		}
+	}
+
+	// Drops come first, the new type before the field that uses it, indexes last
+	order := []string{"DROP INDEX", "ALTER KEYSPACE", "CREATE TYPE app.geo", "ALTER TYPE", "CREATE TABLE", "CREATE INDEX"}
+	last := -1
+	for _, s := range order {
+		idx := strings.Index(statements, s)
+		if idx < last {
+			t.Errorf("%q is out of order:\n%s", s, statements)
+		}
+		last = idx
+	}
+}
+
+func TestDiffSchemasPrimaryKey(t *testing.T) {
+	source := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app")}}
+	targetKs := testKeyspace("app")
+	targetKs.Tables[0].Columns[1].Kind = "clustering"
+	targetKs.Tables[0].ClusteringKeys = []string{"email"}
+	target := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{targetKs}}
+
+	diff := DiffSchemas(source, target)
+	if len(diff.Changes) != 1 || diff.Changes[0].Detail != "primary key (id) → (id, email)" {
+		t.Fatalf("unexpected changes: %+v", diff.Changes)
+	}
+	if statements := diff.Statements(); len(statements) != 1 || !strings.HasPrefix(statements[0], "-- app.users:") {
+		t.Errorf("expected a comment, got %v", statements)
+	}
+}
+
+func TestDiffSchemasKeyspaces(t *testing.T) {
+	source := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app"), testKeyspace("old")}}
+	target := &SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app"), testKeyspace("new")}}
+
+	diff := DiffSchemas(source, target)
+	if len(diff.Changes) != 2 {
+		t.Fatalf("unexpected changes: %+v", diff.Changes)
+	}
+	statements := diff.Statements()
+	if statements[0] != "CREATE KEYSPACE new WITH replication = {'class': 'org.apache.cassandra.locator.SimpleStrategy', 'replication_factor': '1'} AND durable_writes = true;" {
+		t.Errorf("first statement = %q", statements[0])
+	}
+	if statements[len(statements)-1] != "DROP KEYSPACE old;" {
+		t.Errorf("last statement = %q", statements[len(statements)-1])
+	}
+}
+
+func TestTableSnapshotTableInfo(t *testing.T) {
+	table := testKeyspace("app").Tables[0]
+	got := FormatTableCreateStatement(table.TableInfo("app"), false)
+	want := "CREATE TABLE app.users (\n    id uuid PRIMARY KEY,\n    email text,\n    home frozen<address>\n)" +
+		" WITH comment = ''\n    AND gc_grace_seconds = 864000;"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOrderTypes(t *testing.T) {
+	types := []TypeInfo{
+		{Name: "a_person", FieldTypes: []string{"frozen<address>"}},
+		{Name: "address", FieldTypes: []string{"frozen<geo>", "text"}},
+		{Name: "geo", FieldTypes: []string{"double"}},
+	}
+	var names []string
+	for _, typ := range orderTypes(types) {
+		names = append(names, typ.Name)
+	}
+	if strings.Join(names, ",") != "geo,address,a_person" {
+		t.Errorf("orderTypes = %v", names)
+	}
+}
+
+func TestSnapshotOptions(t *testing.T) {
+	options := snapshotOptions(map[string]interface{}{
+		"keyspace_name":     "app",
+		"table_name":        "users",
+		"comment":           "accounts",
+		"gc_grace_seconds":  864000,
+		"compaction":        map[string]string{"class": "SizeTieredCompactionStrategy"},
+		"speculative_retry": "99p",
+	})
+	want := map[string]string{
+		"comment":           "'accounts'",
+		"gc_grace_seconds":  "864000",
+		"compaction":        "{'class': 'SizeTieredCompactionStrategy'}",
+		"speculative_retry": "'99p'",
+	}
+	if len(options) != len(want) {
+		t.Fatalf("options = %v", options)
+	}
+	for name, value := range want {
+		if options[name] != value {
+			t.Errorf("%s = %q, want %q", name, options[name], value)
+		}
+	}
+}
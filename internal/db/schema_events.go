@@ -0,0 +1,218 @@
+package db
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// Schema changes and targets reported in a SchemaEvent
+const (
+	SchemaCreated = "CREATED"
+	SchemaUpdated = "UPDATED"
+	SchemaDropped = "DROPPED"
+
+	SchemaTargetKeyspace  = "KEYSPACE"
+	SchemaTargetTable     = "TABLE"
+	SchemaTargetType      = "TYPE"
+	SchemaTargetFunction  = "FUNCTION"
+	SchemaTargetAggregate = "AGGREGATE"
+)
+
+// schemaEventBuffer is how many schema events can wait for the watcher. When it is
+// full the cache is reloaded as a whole instead.
+const schemaEventBuffer = 256
+
+// SchemaEvent is a schema change the cluster pushed, made by this or any other client
+type SchemaEvent struct {
+	Change   string // SchemaCreated, SchemaUpdated or SchemaDropped
+	Target   string // SchemaTargetKeyspace, SchemaTargetTable, ...
+	Keyspace string
+	Name     string // Empty for keyspaces
+}
+
+// String returns the event as shown in logs, e.g. "TABLE app.users CREATED"
+func (e SchemaEvent) String() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s %s %s", e.Target, e.Keyspace, e.Change)
+	}
+	return fmt.Sprintf("%s %s.%s %s", e.Target, e.Keyspace, e.Name, e.Change)
+}
+
+// schemaEventListener receives the schema change events of the native protocol, after
+// the driver has refreshed its metadata, and queues them for the schema watcher. The
+// driver calls it from its event goroutine, so it never blocks.
+type schemaEventListener struct {
+	events   chan SchemaEvent
+	overflow *atomic.Bool // Events were dropped; the whole schema must be reloaded
+	conn     *driverConn  // Driver session the events come from; nil takes them from any
+}
+
+func newSchemaEventListener() *schemaEventListener {
+	return &schemaEventListener{events: make(chan SchemaEvent, schemaEventBuffer), overflow: new(atomic.Bool)}
+}
+
+// config returns the listener as the schema listener configuration of the driver session
+// conn is opened with. A driver session that was replaced stays open until its results
+// are read, and its events are dropped meanwhile as its successor delivers them too.
+func (l *schemaEventListener) config(conn *driverConn) gocql.SchemaListenersConfig {
+	view := &schemaEventListener{events: l.events, overflow: l.overflow, conn: conn}
+	return gocql.SchemaListenersConfig{
+		KeyspaceChangeListener:  view,
+		TableChangeListener:     view,
+		UserTypeChangeListener:  view,
+		FunctionChangeListener:  view,
+		AggregateChangeListener: view,
+	}
+}
+
+func (l *schemaEventListener) push(event SchemaEvent) {
+	if l.conn != nil && l.conn.replaced.Load() {
+		return
+	}
+	select {
+	case l.events <- event:
+	default:
+		l.overflow.Store(true)
+		logger.DebugfToFile("SchemaEvents", "Event queue full, dropped %s", event)
+	}
+}
+
+func (l *schemaEventListener) OnKeyspaceCreated(e gocql.OnKeyspaceCreatedEvent) {
+	l.push(SchemaEvent{Change: SchemaCreated, Target: SchemaTargetKeyspace, Keyspace: e.Keyspace.Name})
+}
+
+func (l *schemaEventListener) OnKeyspaceUpdated(e gocql.OnKeyspaceUpdatedEvent) {
+	l.push(SchemaEvent{Change: SchemaUpdated, Target: SchemaTargetKeyspace, Keyspace: e.New.Name})
+}
+
+func (l *schemaEventListener) OnKeyspaceDropped(e gocql.OnKeyspaceDroppedEvent) {
+	l.push(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetKeyspace, Keyspace: e.Keyspace.Name})
+}
+
+func (l *schemaEventListener) OnTableCreated(e gocql.OnTableCreatedEvent) {
+	l.push(SchemaEvent{Change: SchemaCreated, Target: SchemaTargetTable, Keyspace: e.Table.Keyspace, Name: e.Table.Name})
+}
+
+func (l *schemaEventListener) OnTableUpdated(e gocql.OnTableUpdatedEvent) {
+	l.push(SchemaEvent{Change: SchemaUpdated, Target: SchemaTargetTable, Keyspace: e.New.Keyspace, Name: e.New.Name})
+}
+
+func (l *schemaEventListener) OnTableDropped(e gocql.OnTableDroppedEvent) {
+	l.push(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetTable, Keyspace: e.Table.Keyspace, Name: e.Table.Name})
+}
+
+func (l *schemaEventListener) OnUserTypeCreated(e gocql.OnUserTypeCreatedEvent) {
+	l.push(SchemaEvent{Change: SchemaCreated, Target: SchemaTargetType, Keyspace: e.UserType.Keyspace, Name: e.UserType.Name})
+}
+
+func (l *schemaEventListener) OnUserTypeUpdated(e gocql.OnUserTypeUpdatedEvent) {
+	l.push(SchemaEvent{Change: SchemaUpdated, Target: SchemaTargetType, Keyspace: e.New.Keyspace, Name: e.New.Name})
+}
+
+func (l *schemaEventListener) OnUserTypeDropped(e gocql.OnUserTypeDroppedEvent) {
+	l.push(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetType, Keyspace: e.UserType.Keyspace, Name: e.UserType.Name})
+}
+
+func (l *schemaEventListener) OnFunctionCreated(e gocql.OnFunctionCreatedEvent) {
+	l.push(SchemaEvent{Change: SchemaCreated, Target: SchemaTargetFunction, Keyspace: e.Function.Keyspace, Name: e.Function.Name})
+}
+
+func (l *schemaEventListener) OnFunctionUpdated(e gocql.OnFunctionUpdatedEvent) {
+	l.push(SchemaEvent{Change: SchemaUpdated, Target: SchemaTargetFunction, Keyspace: e.New.Keyspace, Name: e.New.Name})
+}
+
+func (l *schemaEventListener) OnFunctionDropped(e gocql.OnFunctionDroppedEvent) {
+	l.push(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetFunction, Keyspace: e.Function.Keyspace, Name: e.Function.Name})
+}
+
+func (l *schemaEventListener) OnAggregateCreated(e gocql.OnAggregateCreatedEvent) {
+	l.push(SchemaEvent{Change: SchemaCreated, Target: SchemaTargetAggregate, Keyspace: e.Aggregate.Keyspace, Name: e.Aggregate.Name})
+}
+
+func (l *schemaEventListener) OnAggregateUpdated(e gocql.OnAggregateUpdatedEvent) {
+	l.push(SchemaEvent{Change: SchemaUpdated, Target: SchemaTargetAggregate, Keyspace: e.New.Keyspace, Name: e.New.Name})
+}
+
+func (l *schemaEventListener) OnAggregateDropped(e gocql.OnAggregateDroppedEvent) {
+	l.push(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetAggregate, Keyspace: e.Aggregate.Keyspace, Name: e.Aggregate.Name})
+}
+
+// StartSchemaWatcher applies the schema events the cluster pushes to the schema cache
+// in the background, reloading only the keyspace or table that changed. onChange is
+// called from the watcher goroutine after each event was applied; an event without a
+// Target means the whole schema was reloaded.
+func (s *Session) StartSchemaWatcher(onChange func(SchemaEvent)) {
+	s.schemaWatchMu.Lock()
+	defer s.schemaWatchMu.Unlock()
+	s.stopSchemaWatcher()
+	if s.schemaEvents == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	s.schemaWatchStop, s.schemaWatchDone = stop, done
+	events := s.schemaEvents
+
+	go func() {
+		defer close(done)
+		for {
+			var event SchemaEvent
+			select {
+			case <-stop:
+				return
+			case event = <-events.events:
+			}
+
+			cache := s.GetSchemaCache()
+			if events.overflow.Swap(false) {
+				// Some changes were lost; the queued ones are covered by the reload
+				for len(events.events) > 0 {
+					<-events.events
+				}
+				event = SchemaEvent{}
+				if cache != nil {
+					if err := cache.Refresh(); err != nil {
+						logger.DebugfToFile("SchemaEvents", "Failed to reload the schema: %v", err)
+					}
+				}
+			} else if cache != nil {
+				cache.ApplySchemaEvent(event)
+			}
+
+			logger.DebugfToFile("SchemaEvents", "Applied schema event %s", event)
+			if onChange != nil {
+				onChange(event)
+			}
+		}
+	}()
+}
+
+// StopSchemaWatcher stops the schema watcher and waits for it to exit
+func (s *Session) StopSchemaWatcher() {
+	s.schemaWatchMu.Lock()
+	defer s.schemaWatchMu.Unlock()
+	s.stopSchemaWatcher()
+}
+
+func (s *Session) stopSchemaWatcher() {
+	if s.schemaWatchStop == nil {
+		return
+	}
+	close(s.schemaWatchStop)
+	<-s.schemaWatchDone
+	s.schemaWatchStop, s.schemaWatchDone = nil, nil
+}
+
+// WatchingSchema reports whether the schema watcher keeps the schema cache current
+func (s *Session) WatchingSchema() bool {
+	if s == nil {
+		return false
+	}
+	s.schemaWatchMu.Lock()
+	defer s.schemaWatchMu.Unlock()
+	return s.schemaWatchStop != nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+func newEventTestCache() *SchemaCache {
+	return &SchemaCache{
+		Keyspaces: []string{"app", "logs"},
+		Tables: map[string][]CachedTableInfo{
+			"app":  {{TableInfo: TableInfo{TableName: "users"}}, {TableInfo: TableInfo{TableName: "orders"}}},
+			"logs": {{TableInfo: TableInfo{TableName: "events"}}},
+		},
+		Columns: map[string]map[string][]ColumnInfo{
+			"app":  {"users": {{Name: "id"}}, "orders": {{Name: "id"}}},
+			"logs": {"events": {{Name: "ts"}}},
+		},
+		SearchIndex: &SearchIndex{TableTokens: map[string][]string{
+			"app.users": {"users"}, "app.orders": {"orders"}, "logs.events": {"events"},
+		}},
+		LastRefresh: time.Now(),
+	}
+}
+
+func TestSchemaEventListener(t *testing.T) {
+	l := newSchemaEventListener()
+	l.OnTableCreated(gocql.OnTableCreatedEvent{Table: &gocql.TableMetadata{Keyspace: "app", Name: "users"}})
+	l.OnKeyspaceDropped(gocql.OnKeyspaceDroppedEvent{Keyspace: &gocql.KeyspaceMetadata{Name: "logs"}})
+	l.OnUserTypeUpdated(gocql.OnUserTypeUpdatedEvent{New: &gocql.UserTypeMetadata{Keyspace: "app", Name: "address"}})
+
+	var got []string
+	for len(l.events) > 0 {
+		got = append(got, (<-l.events).String())
+	}
+	want := "TABLE app.users CREATED,KEYSPACE logs DROPPED,TYPE app.address UPDATED"
+	if strings.Join(got, ",") != want {
+		t.Errorf("events = %v, want %s", got, want)
+	}
+
+	// A full queue never blocks the driver; the watcher reloads everything instead
+	for i := 0; i <= schemaEventBuffer; i++ {
+		l.OnTableDropped(gocql.OnTableDroppedEvent{Table: &gocql.TableMetadata{Keyspace: "app", Name: "users"}})
+	}
+	if !l.overflow.Load() || len(l.events) != schemaEventBuffer {
+		t.Errorf("overflow = %v with %d queued events", l.overflow.Load(), len(l.events))
+	}
+}
+
+func TestSchemaEventListenerReplacedConn(t *testing.T) {
+	l := newSchemaEventListener()
+	previous, current := &driverConn{}, &driverConn{}
+	fromPrevious := l.config(previous).TableChangeListener
+	fromCurrent := l.config(current).TableChangeListener
+
+	// Both driver sessions report a change while the previous one is still open
+	previous.replaced.Store(true)
+	event := gocql.OnTableCreatedEvent{Table: &gocql.TableMetadata{Keyspace: "app", Name: "users"}}
+	fromPrevious.OnTableCreated(event)
+	fromCurrent.OnTableCreated(event)
+
+	if len(l.events) != 1 {
+		t.Errorf("queued %d events, want 1", len(l.events))
+	}
+}
+
+func TestApplySchemaEventDrops(t *testing.T) {
+	sc := newEventTestCache()
+	generation := sc.Generation()
+
+	sc.ApplySchemaEvent(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetTable, Keyspace: "app", Name: "orders"})
+	if len(sc.Tables["app"]) != 1 || sc.Tables["app"][0].TableName != "users" {
+		t.Errorf("app tables = %+v", sc.Tables["app"])
+	}
+	if _, ok := sc.Columns["app"]["orders"]; ok {
+		t.Error("columns of the dropped table are still cached")
+	}
+	if _, ok := sc.SearchIndex.TableTokens["app.orders"]; ok {
+		t.Error("search tokens of the dropped table are still cached")
+	}
+
+	sc.ApplySchemaEvent(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetKeyspace, Keyspace: "logs"})
+	if strings.Join(sc.Keyspaces, ",") != "app" || sc.Tables["logs"] != nil || sc.Columns["logs"] != nil {
+		t.Errorf("keyspaces = %v, logs tables = %v", sc.Keyspaces, sc.Tables["logs"])
+	}
+	if _, ok := sc.SearchIndex.TableTokens["logs.events"]; ok {
+		t.Error("search tokens of the dropped keyspace are still cached")
+	}
+
+	// Changes the cache does not hold still tell its users that the schema moved
+	sc.ApplySchemaEvent(SchemaEvent{Change: SchemaCreated, Target: SchemaTargetType, Keyspace: "app", Name: "address"})
+	if got := sc.Generation(); got != generation+3 {
+		t.Errorf("generation = %d, want %d", got, generation+3)
+	}
+	if sc.LastRefresh.IsZero() {
+		t.Error("a change the cache does not hold must not force a reload")
+	}
+}
+
+func TestApplySchemaEventLoadFailure(t *testing.T) {
+	sc := newEventTestCache()
+	sc.ApplySchemaEvent(SchemaEvent{Change: SchemaUpdated, Target: SchemaTargetTable, Keyspace: "app", Name: "users"})
+
+	// Without a session the table can't be reloaded, so the next RefreshIfNeeded must
+	if !sc.LastRefresh.IsZero() {
+		t.Errorf("LastRefresh = %v, want zero", sc.LastRefresh)
+	}
+	if len(sc.Tables["app"]) != 2 {
+		t.Errorf("app tables = %+v", sc.Tables["app"])
+	}
+}
+
+func TestApplySchemaEventKeepsSnapshot(t *testing.T) {
+	s := NewOfflineSession(&SchemaSnapshot{Keyspaces: []KeyspaceSnapshot{testKeyspace("app")}}, "app")
+	sc := s.GetSchemaCache()
+	tables := sc.GetCachedTableCount("app")
+
+	sc.ApplySchemaEvent(SchemaEvent{Change: SchemaDropped, Target: SchemaTargetKeyspace, Keyspace: "app"})
+	if sc.Snapshot() == nil || sc.GetCachedTableCount("app") != tables {
+		t.Errorf("snapshot = %v with %d app tables, want the loaded snapshot with %d", sc.Snapshot(), sc.GetCachedTableCount("app"), tables)
+	}
+}
+
+func TestSchemaWatcher(t *testing.T) {
+	s := &Session{schemaEvents: newSchemaEventListener(), schemaCache: newEventTestCache()}
+	applied := make(chan SchemaEvent, 1)
+	s.StartSchemaWatcher(func(event SchemaEvent) { applied <- event })
+	defer s.StopSchemaWatcher()
+	if !s.WatchingSchema() {
+		t.Fatal("WatchingSchema = false after StartSchemaWatcher")
+	}
+
+	s.schemaEvents.OnTableDropped(gocql.OnTableDroppedEvent{Table: &gocql.TableMetadata{Keyspace: "logs", Name: "events"}})
+	select {
+	case event := <-applied:
+		if event.Target != SchemaTargetTable || event.Name != "events" {
+			t.Errorf("applied %v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("schema event was not applied")
+	}
+	if got := s.GetSchemaCache().GetCachedTableCount("logs"); got != 0 {
+		t.Errorf("logs has %d cached tables after the drop", got)
+	}
+
+	s.StopSchemaWatcher()
+	if s.WatchingSchema() {
+		t.Error("WatchingSchema = true after StopSchemaWatcher")
+	}
+}
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// NewOfflineSession returns a session without a cluster connection. It answers schema
+// lookups (completion, DESCRIBE, the linter and AI) from the snapshot but cannot run
+// statements.
+func NewOfflineSession(snapshot *SchemaSnapshot, keyspace string) *Session {
+	cluster := gocql.NewCluster()
+	cluster.Keyspace = keyspace
+
+	s := &Session{
+		cluster:           cluster,
+		consistency:       gocql.LocalOne,
+		serialConsistency: gocql.Serial,
+		pageSize:          100,
+		offline:           true,
+	}
+	s.schemaCache = NewSchemaCache(s)
+	s.schemaCache.LoadSnapshot(snapshot)
+	return s
+}
+
+// loadedSnapshot returns the snapshot the schema cache was loaded from, if any
+func (s *Session) loadedSnapshot() *SchemaSnapshot {
+	cache := s.GetSchemaCache()
+	if cache == nil {
+		return nil
+	}
+	return cache.Snapshot()
+}
+
+// IsOffline reports whether the session works from a schema snapshot without a cluster
+func (s *Session) IsOffline() bool {
+	return s != nil && s.offline
+}
+
+// Snapshot returns the snapshot the cache was loaded from, or nil when the
+// cache reflects the cluster
+func (sc *SchemaCache) Snapshot() *SchemaSnapshot {
+	return sc.snapshot.Load()
+}
+
+// LoadSnapshot fills the cache from a schema snapshot instead of the cluster. Table
+// lookups are answered from the snapshot until the cache is refreshed from a cluster.
+func (sc *SchemaCache) LoadSnapshot(snapshot *SchemaSnapshot) {
+	sc.Mu.Lock()
+	defer sc.Mu.Unlock()
+
+	sc.Keyspaces = snapshot.KeyspaceNames()
+	sc.Tables = make(map[string][]CachedTableInfo)
+	sc.Columns = make(map[string]map[string][]ColumnInfo)
+	sc.SearchIndex = &SearchIndex{
+		TableTokens: make(map[string][]string),
+	}
+
+	for _, ks := range snapshot.Keyspaces {
+		sc.Columns[ks.Name] = make(map[string][]ColumnInfo)
+		for i := range ks.Tables {
+			table := &ks.Tables[i]
+			sc.Tables[ks.Name] = append(sc.Tables[ks.Name], CachedTableInfo{
+				TableInfo:   *table.TableInfo(ks.Name),
+				LastUpdated: snapshot.CreatedAt,
+			})
+			sc.Columns[ks.Name][table.Name] = table.Columns
+			sc.SearchIndex.TableTokens[ks.Name+"."+table.Name] = buildSearchTokens(table.Name)
+		}
+	}
+
+	sc.LastRefresh = time.Now()
+	sc.snapshot.Store(snapshot)
+	sc.generation.Add(1)
+}
+
+// snapshotTable returns a table of the loaded snapshot
+func (sc *SchemaCache) snapshotTable(keyspace, table string) (*TableSnapshot, error) {
+	snapshot := sc.Snapshot()
+	ks := snapshot.Keyspace(keyspace)
+	if ks == nil {
+		return nil, fmt.Errorf("keyspace %s not found in the schema snapshot", keyspace)
+	}
+	t := ks.Table(table)
+	if t == nil {
+		return nil, fmt.Errorf("table %s.%s not found in the schema snapshot", keyspace, table)
+	}
+	return t, nil
+}
+
+// snapshotIndexes returns the indexes of a keyspace, or of one of its tables, from
+// the snapshot of an offline session
+func (s *Session) snapshotIndexes(keyspace, table string) ([]TableIndex, error) {
+	ks := s.GetSchemaCache().Snapshot().Keyspace(keyspace)
+	if ks == nil {
+		return nil, fmt.Errorf("keyspace %s not found in the schema snapshot", keyspace)
+	}
+
+	var indexes []TableIndex
+	for _, info := range ks.Indexes {
+		if table != "" && info.TableName != table {
+			continue
+		}
+		index := TableIndex{
+			Keyspace: keyspace,
+			Table:    info.TableName,
+			Name:     info.IndexName,
+			Kind:     info.Kind,
+			Options:  info.Options,
+		}
+		index.Column, index.TargetKind = parseIndexTarget(info.Options["target"])
+		if t := ks.Table(info.TableName); t != nil {
+			for _, col := range t.Columns {
+				if col.Name == index.Column {
+					index.ColumnType = col.DataType
+				}
+			}
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// snapshotCatalog builds the schema catalog from the loaded snapshot
+func (s *Session) snapshotCatalog(snapshot *SchemaSnapshot) *SchemaCatalog {
+	catalog := &SchemaCatalog{Keyspaces: make(map[string]*KeyspaceSchema)}
+	for _, ks := range snapshot.Keyspaces {
+		keyspace := &KeyspaceSchema{Name: ks.Name, Tables: make(map[string]*TableSchema)}
+		for _, t := range ks.Tables {
+			table := &TableSchema{
+				Keyspace:       ks.Name,
+				TableName:      t.Name,
+				PartitionKeys:  t.PartitionKeys,
+				ClusteringKeys: t.ClusteringKeys,
+			}
+			for _, col := range t.Columns {
+				table.Columns = append(table.Columns, ColumnSchema{
+					Name:     col.Name,
+					Type:     col.DataType,
+					Kind:     col.Kind,
+					Position: col.Position,
+				})
+			}
+			keyspace.Tables[t.Name] = table
+		}
+		catalog.Keyspaces[ks.Name] = keyspace
+	}
+	return catalog
+}
+
+// Save writes the snapshot to a JSON file
+func (s *SchemaSnapshot) Save(filename string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0600)
+}
+
+// Type returns the named user-defined type of the keyspace, or nil
+func (k *KeyspaceSnapshot) Type(name string) *TypeInfo {
+	for i := range k.Types {
+		if k.Types[i].Name == name {
+			return &k.Types[i]
+		}
+	}
+	return nil
+}
+
+// CountTables returns the number of tables in the snapshot
+func (s *SchemaSnapshot) CountTables() int {
+	count := 0
+	for _, ks := range s.Keyspaces {
+		count += len(ks.Tables)
+	}
+	return count
+}
+
+// FormatKeyspaceSchema returns the CREATE statements of a keyspace and its objects,
+// like DESCRIBE KEYSPACE
+func FormatKeyspaceSchema(keyspace *KeyspaceSnapshot) string {
+	return strings.Join(createKeyspaceStatements(keyspace.Name, keyspace), "\n\n")
+}
+
+// ObjectNames returns the sorted names of the tables, types, functions, aggregates,
+// indexes or views (kind TABLE, TYPE, FUNCTION, AGGREGATE, INDEX or VIEW) of the keyspace
+func (k *KeyspaceSnapshot) ObjectNames(kind string) []string {
+	var names []string
+	switch kind {
+	case "TABLE":
+		for _, t := range k.Tables {
+			names = append(names, t.Name)
+		}
+	case "TYPE":
+		names = typeNames(k.Types)
+	case "FUNCTION":
+		for _, fn := range k.Functions {
+			names = append(names, fn.Name)
+		}
+	case "AGGREGATE":
+		for _, agg := range k.Aggregates {
+			names = append(names, agg.Name)
+		}
+	case "INDEX":
+		for _, index := range k.Indexes {
+			names = append(names, index.IndexName)
+		}
+	case "VIEW":
+		for _, view := range k.Views {
+			names = append(names, view.Name)
+		}
+	}
+	return unionNames(names, nil)
+}
+
+// DescribeObject returns the CREATE statement of a table, type, function (every
+// overload), aggregate, index or view of the keyspace, like DESCRIBE
+func (k *KeyspaceSnapshot) DescribeObject(kind, name string) (string, error) {
+	var statements []string
+	switch kind {
+	case "TABLE":
+		if t := k.Table(name); t != nil {
+			statements = append(statements, FormatTableCreateStatement(t.TableInfo(k.Name), false))
+		}
+	case "TYPE":
+		if typ := k.Type(name); typ != nil {
+			statements = append(statements, formatTypeCreateStatement(k.Name, typ))
+		}
+	case "FUNCTION":
+		for i := range k.Functions {
+			if k.Functions[i].Name == name {
+				statements = append(statements, formatFunctionCreateStatement(k.Name, &k.Functions[i]))
+			}
+		}
+	case "AGGREGATE":
+		for i := range k.Aggregates {
+			if k.Aggregates[i].Name == name {
+				statements = append(statements, formatAggregateCreateStatement(k.Name, &k.Aggregates[i]))
+			}
+		}
+	case "INDEX":
+		for i := range k.Indexes {
+			if k.Indexes[i].IndexName == name {
+				statements = append(statements, formatIndexCreateStatement(k.Name, &k.Indexes[i]))
+			}
+		}
+	case "VIEW":
+		for i := range k.Views {
+			if k.Views[i].Name == name {
+				statements = append(statements, formatViewCreateStatement(k.Name, &k.Views[i]))
+			}
+		}
+	}
+	if len(statements) == 0 {
+		return "", fmt.Errorf("%s %s.%s not found in the schema snapshot", strings.ToLower(kind), k.Name, name)
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
+
+// Filter returns a snapshot with only the given keyspaces, or the snapshot itself when
+// none are given
+func (s *SchemaSnapshot) Filter(keyspaces ...string) (*SchemaSnapshot, error) {
+	if len(keyspaces) == 0 {
+		return s, nil
+	}
+	filtered := &SchemaSnapshot{Version: s.Version, Source: s.Source, CreatedAt: s.CreatedAt}
+	for _, name := range keyspaces {
+		ks := s.Keyspace(name)
+		if ks == nil {
+			return nil, fmt.Errorf("keyspace %s not found in the schema snapshot", name)
+		}
+		filtered.Keyspaces = append(filtered.Keyspaces, *ks)
+	}
+	return filtered, nil
+}
+
+// UseSchemaSnapshot replaces the cached schema with a snapshot until the cache is next
+// refreshed from the cluster
+func (s *Session) UseSchemaSnapshot(snapshot *SchemaSnapshot) {
+	s.connMu.Lock()
+	if s.schemaCache == nil {
+		s.schemaCache = NewSchemaCache(s)
+	}
+	cache := s.schemaCache
+	s.connMu.Unlock()
+	cache.LoadSnapshot(snapshot)
+}
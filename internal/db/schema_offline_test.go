@@ -0,0 +1,130 @@
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSnapshot() *SchemaSnapshot {
+	ks := testKeyspace("app")
+	ks.Functions = []FunctionDetails{{
+		Name:          "twice",
+		ArgumentNames: []string{"x"},
+		ArgumentTypes: []string{"int"},
+		ReturnType:    "int",
+		Language:      "java",
+		Body:          "return x * 2;",
+	}}
+	return &SchemaSnapshot{
+		Version:   SchemaSnapshotVersion,
+		Source:    "10.0.0.1",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Keyspaces: []KeyspaceSnapshot{ks, testKeyspace("other")},
+	}
+}
+
+func TestOfflineSessionSchema(t *testing.T) {
+	s := NewOfflineSession(testSnapshot(), "app")
+	if !s.IsOffline() || s.Keyspace() != "app" {
+		t.Fatalf("IsOffline = %v, Keyspace = %q", s.IsOffline(), s.Keyspace())
+	}
+
+	cache := s.GetSchemaCache()
+	if strings.Join(cache.Keyspaces, ",") != "app,other" || len(cache.Tables["app"]) != 1 {
+		t.Errorf("cache keyspaces = %v, tables = %v", cache.Keyspaces, cache.Tables)
+	}
+	if err := cache.Refresh(); err != nil || cache.Snapshot() == nil {
+		t.Errorf("Refresh without a cluster = %v, snapshot kept = %v", err, cache.Snapshot() != nil)
+	}
+
+	info, err := cache.GetTableInfo("app", "users")
+	if err != nil || strings.Join(info.PartitionKeys, ",") != "id" || len(info.Columns) != 3 {
+		t.Fatalf("GetTableInfo = %+v, %v", info, err)
+	}
+	if _, err := cache.GetTableInfo("app", "missing"); err == nil {
+		t.Error("expected an error for a missing table")
+	}
+
+	indexes, err := s.TableIndexes("app", "users")
+	if err != nil || len(indexes) != 1 || indexes[0].Column != "email" || indexes[0].ColumnType != "text" {
+		t.Errorf("TableIndexes = %+v, %v", indexes, err)
+	}
+
+	catalog, err := s.GetSchemaCatalog()
+	if err != nil || catalog.Keyspaces["app"].Tables["users"] == nil {
+		t.Errorf("GetSchemaCatalog = %+v, %v", catalog, err)
+	}
+
+	if analysis := s.AnalyzeQuery("SELECT * FROM users WHERE email = 'a@b.c'"); analysis.Schema == nil {
+		t.Errorf("AnalyzeQuery found no table: %+v", analysis)
+	}
+}
+
+func TestOfflineSessionSetKeyspace(t *testing.T) {
+	s := NewOfflineSession(testSnapshot(), "")
+	if err := s.SetKeyspace("other"); err != nil || s.Keyspace() != "other" {
+		t.Errorf("SetKeyspace = %v, Keyspace = %q", err, s.Keyspace())
+	}
+	if err := s.SetKeyspace("missing"); err == nil || s.Keyspace() != "other" {
+		t.Errorf("SetKeyspace(missing) = %v, Keyspace = %q", err, s.Keyspace())
+	}
+}
+
+func TestSchemaSnapshotSaveLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schema.json")
+	if err := testSnapshot().Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSchemaSnapshot(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Source != "10.0.0.1" || len(loaded.Keyspaces) != 2 || len(loaded.Keyspaces[0].Functions) != 1 {
+		t.Errorf("loaded = %+v", loaded)
+	}
+	if diff := DiffSchemas(testSnapshot(), loaded); len(diff.Changes) != 0 {
+		t.Errorf("round trip changed the schema: %+v", diff.Changes)
+	}
+
+	s := NewOfflineSession(loaded, "app")
+	filtered, err := s.SchemaSnapshot("other")
+	if err != nil || len(filtered.Keyspaces) != 1 || filtered.Keyspaces[0].Name != "other" {
+		t.Errorf("SchemaSnapshot(other) = %+v, %v", filtered, err)
+	}
+	if _, err := s.SchemaSnapshot("missing"); err == nil {
+		t.Error("expected an error for a missing keyspace")
+	}
+}
+
+func TestKeyspaceSnapshotDescribe(t *testing.T) {
+	ks := testSnapshot().Keyspace("app")
+
+	if got := strings.Join(ks.ObjectNames("TABLE"), ","); got != "users" {
+		t.Errorf("ObjectNames(TABLE) = %q", got)
+	}
+	if got := strings.Join(ks.ObjectNames("FUNCTION"), ","); got != "twice" {
+		t.Errorf("ObjectNames(FUNCTION) = %q", got)
+	}
+
+	statement, err := ks.DescribeObject("INDEX", "users_email_idx")
+	if err != nil || statement != "CREATE INDEX users_email_idx ON app.users (email);" {
+		t.Errorf("DescribeObject(INDEX) = %q, %v", statement, err)
+	}
+	if _, err := ks.DescribeObject("TYPE", "missing"); err == nil {
+		t.Error("expected an error for a missing type")
+	}
+
+	schema := FormatKeyspaceSchema(ks)
+	order := []string{"CREATE KEYSPACE app", "CREATE TYPE app.address", "CREATE FUNCTION app.twice(x int)", "CREATE TABLE app.users", "CREATE INDEX"}
+	last := -1
+	for _, s := range order {
+		idx := strings.Index(schema, s)
+		if idx <= last {
+			t.Errorf("%q missing or out of order:\n%s", s, schema)
+		}
+		last = idx
+	}
+}
@@ -0,0 +1,373 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaSnapshotVersion is the format version written to schema snapshot files
+const SchemaSnapshotVersion = 1
+
+// snapshotInternalOptions are the system_schema.tables and system_schema.views columns
+// that are not WITH options of the table or view
+var snapshotInternalOptions = map[string]bool{
+	"keyspace_name":              true,
+	"table_name":                 true,
+	"view_name":                  true,
+	"base_table_id":              true,
+	"base_table_name":            true,
+	"include_all_columns":        true,
+	"where_clause":               true,
+	"id":                         true,
+	"flags":                      true,
+	"dclocal_read_repair_chance": true, // Deprecated property
+	"read_repair_chance":         true, // Deprecated property
+}
+
+// SchemaSnapshot is a copy of the schema of one or more keyspaces that can be saved
+// as JSON and compared with another snapshot
+type SchemaSnapshot struct {
+	Version   int                `json:"version"`
+	Source    string             `json:"source,omitempty"` // Where the snapshot was taken, e.g. a host list
+	CreatedAt time.Time          `json:"createdAt"`
+	Keyspaces []KeyspaceSnapshot `json:"keyspaces"`
+}
+
+// KeyspaceSnapshot is the schema of a keyspace
+type KeyspaceSnapshot struct {
+	Name          string            `json:"name"`
+	Replication   map[string]string `json:"replication"`
+	DurableWrites bool              `json:"durableWrites"`
+	Types         []TypeInfo        `json:"types,omitempty"`
+	Tables        []TableSnapshot   `json:"tables,omitempty"`
+	Indexes       []IndexInfo       `json:"indexes,omitempty"`
+	Views         []ViewSnapshot    `json:"views,omitempty"`
+	Functions     []FunctionDetails `json:"functions,omitempty"`
+	Aggregates    []AggregateInfo   `json:"aggregates,omitempty"`
+}
+
+// TableSnapshot is the definition of a table. Options hold the CQL value of each
+// WITH property, e.g. "compaction": "{'class': '...'}".
+type TableSnapshot struct {
+	Name           string            `json:"name"`
+	Columns        []ColumnInfo      `json:"columns"`
+	PartitionKeys  []string          `json:"partitionKeys"`
+	ClusteringKeys []string          `json:"clusteringKeys,omitempty"`
+	Options        map[string]string `json:"options,omitempty"`
+}
+
+// ViewSnapshot is the definition of a materialized view
+type ViewSnapshot struct {
+	Name           string            `json:"name"`
+	BaseTable      string            `json:"baseTable"`
+	WhereClause    string            `json:"whereClause"`
+	AllColumns     bool              `json:"allColumns,omitempty"`
+	Columns        []ColumnInfo      `json:"columns"`
+	PartitionKeys  []string          `json:"partitionKeys"`
+	ClusteringKeys []string          `json:"clusteringKeys,omitempty"`
+	Options        map[string]string `json:"options,omitempty"`
+}
+
+// cqlLiteral is an option value that is already CQL text, so formatTableProperty
+// writes it as it is
+type cqlLiteral string
+
+// Keyspace returns the snapshot of the named keyspace, or nil
+func (s *SchemaSnapshot) Keyspace(name string) *KeyspaceSnapshot {
+	for i := range s.Keyspaces {
+		if s.Keyspaces[i].Name == name {
+			return &s.Keyspaces[i]
+		}
+	}
+	return nil
+}
+
+// KeyspaceNames returns the names of the keyspaces in the snapshot
+func (s *SchemaSnapshot) KeyspaceNames() []string {
+	names := make([]string, len(s.Keyspaces))
+	for i, ks := range s.Keyspaces {
+		names[i] = ks.Name
+	}
+	return names
+}
+
+// Table returns the named table of the keyspace, or nil
+func (k *KeyspaceSnapshot) Table(name string) *TableSnapshot {
+	for i := range k.Tables {
+		if k.Tables[i].Name == name {
+			return &k.Tables[i]
+		}
+	}
+	return nil
+}
+
+// TableInfo converts the table to the structure used by DESCRIBE
+func (t *TableSnapshot) TableInfo(keyspace string) *TableInfo {
+	props := make(map[string]interface{}, len(t.Options))
+	for name, value := range t.Options {
+		props[name] = cqlLiteral(value)
+	}
+	return &TableInfo{
+		KeyspaceName:   keyspace,
+		TableName:      t.Name,
+		TableProps:     props,
+		Columns:        t.Columns,
+		PartitionKeys:  t.PartitionKeys,
+		ClusteringKeys: t.ClusteringKeys,
+	}
+}
+
+// SchemaSnapshot reads the schema of the given keyspaces, or of every non-system
+// keyspace when none are given
+func (s *Session) SchemaSnapshot(keyspaces ...string) (*SchemaSnapshot, error) {
+	if s != nil && !s.Connected() && s.loadedSnapshot() != nil {
+		return s.loadedSnapshot().Filter(keyspaces...)
+	}
+	if !s.Connected() {
+		return nil, fmt.Errorf("not connected to database")
+	}
+
+	if len(keyspaces) == 0 {
+		names, err := s.schemaObjectNames(`SELECT keyspace_name FROM system_schema.keyspaces`)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !strings.HasPrefix(name, "system") {
+				keyspaces = append(keyspaces, name)
+			}
+		}
+	}
+
+	snapshot := &SchemaSnapshot{
+		Version:   SchemaSnapshotVersion,
+		Source:    strings.Join(s.ContactPoints(), ","),
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, name := range keyspaces {
+		ks, err := s.keyspaceSnapshot(name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Keyspaces = append(snapshot.Keyspaces, *ks)
+	}
+	return snapshot, nil
+}
+
+// keyspaceSnapshot reads the schema of one keyspace with the queries DESCRIBE uses
+// on clusters without server-side DESCRIBE
+func (s *Session) keyspaceSnapshot(keyspace string) (*KeyspaceSnapshot, error) {
+	info, err := s.DescribeKeyspaceQuery(keyspace)
+	if err != nil {
+		return nil, err
+	}
+	ks := &KeyspaceSnapshot{
+		Name:          info.Name,
+		Replication:   info.Replication,
+		DurableWrites: info.DurableWrites,
+	}
+
+	typeNames, err := s.schemaObjectNames(`SELECT type_name FROM system_schema.types WHERE keyspace_name = ?`, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range typeNames {
+		typeInfo, err := s.DescribeTypeQuery(keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		ks.Types = append(ks.Types, *typeInfo)
+	}
+
+	tableNames, err := s.schemaObjectNames(`SELECT table_name FROM system_schema.tables WHERE keyspace_name = ?`, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range tableNames {
+		tableInfo, err := s.DescribeTableQuery(keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		ks.Tables = append(ks.Tables, TableSnapshot{
+			Name:           tableInfo.TableName,
+			Columns:        tableInfo.Columns,
+			PartitionKeys:  tableInfo.PartitionKeys,
+			ClusteringKeys: tableInfo.ClusteringKeys,
+			Options:        snapshotOptions(tableInfo.TableProps),
+		})
+	}
+
+	iter := s.Query(`SELECT table_name, index_name, kind, options FROM system_schema.indexes WHERE keyspace_name = ?`, keyspace).Iter()
+	var index IndexInfo
+	for iter.Scan(&index.TableName, &index.IndexName, &index.Kind, &index.Options) {
+		ks.Indexes = append(ks.Indexes, index)
+		index = IndexInfo{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read the indexes of %s: %w", keyspace, err)
+	}
+	sort.Slice(ks.Indexes, func(i, j int) bool { return ks.Indexes[i].IndexName < ks.Indexes[j].IndexName })
+
+	viewNames, err := s.schemaObjectNames(`SELECT view_name FROM system_schema.views WHERE keyspace_name = ?`, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range viewNames {
+		view, err := s.viewSnapshot(keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		ks.Views = append(ks.Views, *view)
+	}
+
+	functionNames, err := s.schemaObjectNames(`SELECT function_name FROM system_schema.functions WHERE keyspace_name = ?`, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range functionNames {
+		// Every overload of the function
+		functions, err := s.DescribeFunctionQuery(keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		ks.Functions = append(ks.Functions, functions...)
+	}
+
+	aggregateNames, err := s.schemaObjectNames(`SELECT aggregate_name FROM system_schema.aggregates WHERE keyspace_name = ?`, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range aggregateNames {
+		aggregate, err := s.DescribeAggregateQuery(keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		ks.Aggregates = append(ks.Aggregates, *aggregate)
+	}
+	return ks, nil
+}
+
+// viewSnapshot reads the definition of a materialized view
+func (s *Session) viewSnapshot(keyspace, name string) (*ViewSnapshot, error) {
+	props := make(map[string]interface{})
+	iter := s.Query(`SELECT * FROM system_schema.views WHERE keyspace_name = ? AND view_name = ?`, keyspace, name).Iter()
+	if !iter.MapScan(props) {
+		_ = iter.Close()
+		return nil, fmt.Errorf("materialized view '%s' not found in keyspace '%s'", name, keyspace)
+	}
+	_ = iter.Close()
+
+	view := &ViewSnapshot{Name: name, Options: snapshotOptions(props)}
+	view.BaseTable, _ = props["base_table_name"].(string)
+	view.WhereClause, _ = props["where_clause"].(string)
+	view.AllColumns, _ = props["include_all_columns"].(bool)
+
+	// The columns of a view are stored like those of a table
+	columns, err := s.tableColumns(keyspace, name)
+	if err != nil {
+		return nil, err
+	}
+	view.Columns = columns
+	for _, col := range columns {
+		switch col.Kind {
+		case "partition_key":
+			view.PartitionKeys = append(view.PartitionKeys, col.Name)
+		case "clustering":
+			view.ClusteringKeys = append(view.ClusteringKeys, col.Name)
+		}
+	}
+	return view, nil
+}
+
+// tableColumns returns the columns of a table or view in CREATE order:
+// partition key, clustering columns, then the other columns by name
+func (s *Session) tableColumns(keyspace, table string) ([]ColumnInfo, error) {
+	iter := s.Query(`SELECT column_name, type, kind, position FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?`,
+		keyspace, table).Iter()
+	var columns []ColumnInfo
+	var col ColumnInfo
+	for iter.Scan(&col.Name, &col.DataType, &col.Kind, &col.Position) {
+		columns = append(columns, col)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read the columns of %s.%s: %w", keyspace, table, err)
+	}
+	sortColumns(columns)
+	return columns, nil
+}
+
+// sortColumns orders columns as CREATE TABLE lists them
+func sortColumns(columns []ColumnInfo) {
+	kindPriority := map[string]int{"partition_key": 0, "clustering": 1}
+	priority := func(kind string) int {
+		if p, ok := kindPriority[kind]; ok {
+			return p
+		}
+		return 2
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		pi, pj := priority(columns[i].Kind), priority(columns[j].Kind)
+		if pi != pj {
+			return pi < pj
+		}
+		if pi < 2 {
+			return columns[i].Position < columns[j].Position
+		}
+		return columns[i].Name < columns[j].Name
+	})
+}
+
+// schemaObjectNames returns the sorted, distinct names read by a system_schema query
+func (s *Session) schemaObjectNames(query string, values ...interface{}) ([]string, error) {
+	iter := s.Query(query, values...).Iter()
+	var names []string
+	seen := make(map[string]bool)
+	var name string
+	for iter.Scan(&name) {
+		// Overloaded functions and aggregates have a row per signature
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// snapshotOptions converts the row of a table or view to the CQL values of its options
+func snapshotOptions(props map[string]interface{}) map[string]string {
+	options := make(map[string]string)
+	for name, value := range props {
+		if snapshotInternalOptions[name] {
+			continue
+		}
+		if prop := formatTableProperty(name, value); prop != "" {
+			options[name] = strings.TrimPrefix(prop, name+" = ")
+		}
+	}
+	return options
+}
+
+// LoadSchemaSnapshot reads a schema snapshot from a JSON file
+func LoadSchemaSnapshot(filename string) (*SchemaSnapshot, error) {
+	data, err := os.ReadFile(filename) // #nosec G304 - User-provided snapshot filename
+	if err != nil {
+		return nil, err
+	}
+	var snapshot SchemaSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("%s is not a schema snapshot: %w", filename, err)
+	}
+	if snapshot.Version > SchemaSnapshotVersion {
+		return nil, fmt.Errorf("%s has snapshot version %d; this cqlai reads up to version %d",
+			filename, snapshot.Version, SchemaSnapshotVersion)
+	}
+	return &snapshot, nil
+}
@@ -101,6 +101,27 @@ func TestSetPageSize(t *testing.T) {
 	}
 }
 
+func TestSettingsWhileCommandRuns(t *testing.T) {
+	// The UI reads the settings while a command changes them in the background
+	session := &Session{pageSize: 100}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			session.SetPageSize(i + 1)
+			_ = session.SetConsistency("QUORUM")
+			session.SetTracing(i%2 == 0)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_, _, _ = session.PageSize(), session.Consistency(), session.Tracing()
+	}
+	<-done
+	if session.PageSize() != 100 || session.Consistency() != "QUORUM" {
+		t.Errorf("page size %d, consistency %s", session.PageSize(), session.Consistency())
+	}
+}
+
 func TestConvertToJSONQuery(t *testing.T) {
 	tests := []struct {
 		name     string
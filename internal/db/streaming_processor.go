@@ -10,7 +10,7 @@ import (
 
 // StreamingProcessor handles progressive loading and formatting of query results
 type StreamingProcessor struct {
-	iterator        *gocql.Iter
+	result          StreamingQueryResult
 	headers         []string
 	columnNames     []string
 	columnTypes     []string
@@ -36,7 +36,7 @@ func NewStreamingProcessor(result StreamingQueryResult, session *Session) *Strea
 	// TODO: Could parse from query or pass explicitly
 
 	return &StreamingProcessor{
-		iterator:        result.Iterator,
+		result:          result,
 		headers:         result.Headers,
 		columnNames:     result.ColumnNames,
 		columnTypes:     result.ColumnTypes,
@@ -51,7 +51,7 @@ func NewStreamingProcessor(result StreamingQueryResult, session *Session) *Strea
 // LoadResults loads a batch of results from the iterator
 // Returns the formatted rows, whether more results exist, and any error
 func (sp *StreamingProcessor) LoadResults(ctx context.Context, maxRows int) ([][]string, bool, error) {
-	if sp.iterator == nil {
+	if sp.result.Iterator == nil {
 		return nil, false, fmt.Errorf("iterator is nil")
 	}
 
@@ -65,11 +65,11 @@ func (sp *StreamingProcessor) LoadResults(ctx context.Context, maxRows int) ([][
 		default:
 			// Use MapScan to handle NULLs properly
 			rowMap := make(map[string]interface{})
-			if !sp.iterator.MapScan(rowMap) {
-				// No more rows or error occurred
-				if err := sp.iterator.Close(); err != nil {
-					return rows, false, fmt.Errorf("iterator error: %w", err)
-				}
+			ok, err := sp.result.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.MapScan(rowMap) })
+			if err != nil {
+				return rows, false, fmt.Errorf("iterator error: %w", err)
+			}
+			if !ok {
 				return rows, false, nil
 			}
 
@@ -89,7 +89,7 @@ func (sp *StreamingProcessor) formatRow(rowMap map[string]interface{}) []string
 	row := make([]string, len(sp.columnNames))
 
 	// Get column information for type-aware formatting
-	cols := sp.iterator.Columns()
+	cols := sp.result.Iterator.Columns()
 
 	for i, colName := range sp.columnNames {
 		val, exists := rowMap[colName]
@@ -157,12 +157,9 @@ func (sp *StreamingProcessor) isUDT(col *gocql.ColumnInfo) bool {
 	return isUDT
 }
 
-// Close closes the iterator if it's still open
+// Close closes the iterator if it's still open and lets its driver session close
 func (sp *StreamingProcessor) Close() error {
-	if sp.iterator != nil {
-		return sp.iterator.Close()
-	}
-	return nil
+	return sp.result.Close()
 }
 
 // GetHeaders returns the column headers
@@ -195,6 +192,10 @@ func (s *Session) ProcessStreamingQuery(result StreamingQueryResult) *StreamingR
 		HasMore:     true,
 		LoadMore: func(ctx context.Context, count int) ([][]string, bool, error) {
 			rows, hasMore, err := processor.LoadResults(ctx, count)
+			if err != nil || !hasMore {
+				// Nothing more is read, so the driver session may close
+				_ = processor.Close()
+			}
 			if err != nil {
 				return nil, false, err
 			}
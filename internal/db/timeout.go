@@ -0,0 +1,120 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// timeoutValue matches a timeout given in seconds or with an ms, s or m unit
+const timeoutValue = `(\d+(?:\.\d+)?(?:ms|s|m)?)`
+
+var (
+	// USING TIMEOUT 5s AND TTL 60 -> USING TTL 60
+	usingTimeoutAndPattern = regexp.MustCompile(`(?i)\bUSING\s+TIMEOUT\s+` + timeoutValue + `\s+AND\s+`)
+	// USING TTL 60 AND TIMEOUT 5s -> USING TTL 60
+	andTimeoutPattern = regexp.MustCompile(`(?i)\s+AND\s+TIMEOUT\s+` + timeoutValue + `\b`)
+	// SELECT ... USING TIMEOUT 5s -> SELECT ...
+	usingTimeoutPattern = regexp.MustCompile(`(?i)\s*\bUSING\s+TIMEOUT\s+` + timeoutValue + `\b`)
+)
+
+// ParseTimeout parses a timeout given as seconds ("30", "1.5") or with a unit ("500ms", "2m")
+func ParseTimeout(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	var timeout time.Duration
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		timeout = time.Duration(seconds * float64(time.Second))
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timeout %q (use seconds, or a value such as 500ms or 2m)", value)
+		}
+		timeout = parsed
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout must be greater than zero")
+	}
+	return timeout, nil
+}
+
+// ExtractStatementTimeout removes a client-side USING TIMEOUT clause from a statement and
+// returns the statement without it and the timeout, or 0 if the statement has none. The
+// clause can be combined with TTL and TIMESTAMP: USING TTL 60 AND TIMEOUT 5s.
+func ExtractStatementTimeout(query string) (string, time.Duration, error) {
+	// Look for the clause outside string literals only
+	masked := maskStringLiterals(query)
+
+	for _, pattern := range []*regexp.Regexp{usingTimeoutAndPattern, andTimeoutPattern, usingTimeoutPattern} {
+		loc := pattern.FindStringSubmatchIndex(masked)
+		if loc == nil {
+			continue
+		}
+		timeout, err := ParseTimeout(query[loc[2]:loc[3]])
+		if err != nil {
+			return "", 0, err
+		}
+		replacement := ""
+		if pattern == usingTimeoutAndPattern {
+			replacement = query[loc[0]:loc[0]+len("USING")] + " "
+		}
+		return query[:loc[0]] + replacement + query[loc[1]:], timeout, nil
+	}
+	return query, 0, nil
+}
+
+// maskStringLiterals replaces the contents of quoted strings and identifiers with spaces,
+// keeping every other character at the same position
+func maskStringLiterals(query string) string {
+	masked := []byte(query)
+	for i := 0; i < len(query); i++ {
+		if query[i] != '\'' && query[i] != '"' {
+			continue
+		}
+		end, _ := quotedEnd(query, i, query[i])
+		for j := i + 1; j < end-1; j++ {
+			masked[j] = ' '
+		}
+		i = end - 1
+	}
+	return string(masked)
+}
+
+// RequestTimeout returns the timeout of a single request to the cluster, or 0 if
+// requests never time out on the client
+func (s *Session) RequestTimeout() time.Duration {
+	if s == nil {
+		return 0
+	}
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	if s.cluster == nil {
+		return 0
+	}
+	return s.cluster.Timeout
+}
+
+// SetRequestTimeout changes the timeout of a single request to the cluster; 0 disables it.
+// The driver applies the timeout to each connection, so the session is reconnected.
+func (s *Session) SetRequestTimeout(timeout time.Duration) error {
+	if s == nil || s.clusterConfig() == nil {
+		return fmt.Errorf("not connected to database")
+	}
+
+	// The new session opens before the old one closes, so a failure leaves the shell connected
+	if err := s.reopen(func(cluster *gocql.ClusterConfig) { cluster.Timeout = timeout }); err != nil {
+		return fmt.Errorf("failed to reconnect with the new timeout: %w", err)
+	}
+	return nil
+}
+
+// FormatTimeout renders a timeout for SHOW SESSION and TIMEOUT
+func FormatTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "OFF"
+	}
+	return timeout.String()
+}
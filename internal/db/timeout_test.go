@@ -0,0 +1,73 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30":    30 * time.Second,
+		"1.5":   1500 * time.Millisecond,
+		"500ms": 500 * time.Millisecond,
+		"2m":    2 * time.Minute,
+		"10S":   10 * time.Second,
+	}
+	for value, expected := range tests {
+		got, err := ParseTimeout(value)
+		if err != nil || got != expected {
+			t.Errorf("ParseTimeout(%q) = %v, %v; want %v", value, got, err, expected)
+		}
+	}
+
+	for _, value := range []string{"0", "-5", "soon", ""} {
+		if _, err := ParseTimeout(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestExtractStatementTimeout(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+		timeout  time.Duration
+	}{
+		{"SELECT * FROM users", "SELECT * FROM users", 0},
+		{"SELECT * FROM users USING TIMEOUT 30;", "SELECT * FROM users;", 30 * time.Second},
+		{"select * from users using timeout 500ms", "select * from users", 500 * time.Millisecond},
+		{"INSERT INTO t (a) VALUES (1) USING TTL 60 AND TIMEOUT 5s", "INSERT INTO t (a) VALUES (1) USING TTL 60", 5 * time.Second},
+		{"INSERT INTO t (a) VALUES (1) USING TIMEOUT 5s AND TTL 60", "INSERT INTO t (a) VALUES (1) USING TTL 60", 5 * time.Second},
+		{"SELECT * FROM t WHERE a = 'USING TIMEOUT 5'", "SELECT * FROM t WHERE a = 'USING TIMEOUT 5'", 0},
+		{"COPY t TO 'out.csv' USING TIMEOUT 2m", "COPY t TO 'out.csv'", 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		query, timeout, err := ExtractStatementTimeout(tt.query)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.query, err)
+			continue
+		}
+		if query != tt.expected || timeout != tt.timeout {
+			t.Errorf("%q: got (%q, %v), want (%q, %v)", tt.query, query, timeout, tt.expected, tt.timeout)
+		}
+	}
+
+	if _, _, err := ExtractStatementTimeout("SELECT * FROM t USING TIMEOUT 0"); err == nil {
+		t.Error("expected an error for a zero timeout")
+	}
+}
+
+func TestStreamingResultOnClose(t *testing.T) {
+	var calls []string
+	result := StreamingQueryResult{release: func() { calls = append(calls, "release") }}
+	result.OnClose(func() { calls = append(calls, "cancel") })
+
+	if err := result.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "cancel,release" {
+		t.Errorf("Close() called %v", calls)
+	}
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// MinToken and MaxToken bound the Murmur3Partitioner token ring.
+// Murmur3 never assigns MinToken to a key, so (MinToken, MaxToken] covers every row.
+const (
+	MinToken int64 = math.MinInt64
+	MaxToken int64 = math.MaxInt64
+)
+
+// TokenRange is a slice of the token ring covering tokens in (Start, End]
+type TokenRange struct {
+	Start int64
+	End   int64
+}
+
+// String returns the range in the (start, end] notation used in progress messages
+func (r TokenRange) String() string {
+	return fmt.Sprintf("(%d, %d]", r.Start, r.End)
+}
+
+// SplitTokenRange divides (begin, end] into at most n contiguous sub-ranges of roughly equal width
+func SplitTokenRange(begin, end int64, n int) []TokenRange {
+	if begin >= end {
+		return nil
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	// Use big.Int so the width of the full ring (2^64 - 1) doesn't overflow
+	width := new(big.Int).Sub(big.NewInt(end), big.NewInt(begin))
+	if width.Cmp(big.NewInt(int64(n))) < 0 {
+		n = int(width.Int64())
+	}
+	step := new(big.Int).Div(width, big.NewInt(int64(n)))
+
+	ranges := make([]TokenRange, 0, n)
+	start := begin
+	for i := 0; i < n; i++ {
+		rangeEnd := end
+		if i < n-1 {
+			next := new(big.Int).Add(big.NewInt(start), step)
+			rangeEnd = next.Int64()
+		}
+		ranges = append(ranges, TokenRange{Start: start, End: rangeEnd})
+		start = rangeEnd
+	}
+
+	return ranges
+}
+
+// SupportsTokenRangeScan reports whether the cluster partitioner uses the Murmur3 token ring
+func (s *Session) SupportsTokenRangeScan() bool {
+	if !s.Connected() {
+		return false
+	}
+	var partitioner string
+	iter := s.Query("SELECT partitioner FROM system.local").Iter()
+	iter.Scan(&partitioner)
+	if err := iter.Close(); err != nil {
+		logger.DebugfToFile("TokenRange", "Failed to read partitioner: %v", err)
+		return false
+	}
+	return strings.HasSuffix(partitioner, "Murmur3Partitioner")
+}
+
+// PartitionKeyColumns returns the partition key column names of a table in key order
+func (s *Session) PartitionKeyColumns(keyspace, table string) ([]string, error) {
+	if !s.Connected() {
+		return nil, fmt.Errorf("not connected to database")
+	}
+
+	tableMeta, err := s.GetTableMetadata(keyspace, table)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(tableMeta.PartitionKey))
+	for _, pk := range tableMeta.PartitionKey {
+		keys = append(keys, pk.Name)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("table %s.%s has no partition key", keyspace, table)
+	}
+	return keys, nil
+}
+
+// BuildTokenRangeQuery builds a SELECT restricted to one token range.
+// The query binds the range start and end as its two parameters. Columns are
+// given as written in the statement, quoted or not; the other names as stored.
+func BuildTokenRangeQuery(keyspace, table string, columns, partitionKeys []string) string {
+	selectList := "*"
+	if len(columns) > 0 {
+		quotedColumns := make([]string, len(columns))
+		for i, column := range columns {
+			quotedColumns[i] = QuoteIdentifier(normalizeIdentifier(column))
+		}
+		selectList = strings.Join(quotedColumns, ", ")
+	}
+
+	quotedKeys := make([]string, len(partitionKeys))
+	for i, key := range partitionKeys {
+		quotedKeys[i] = QuoteIdentifier(key)
+	}
+	tokenExpr := fmt.Sprintf("token(%s)", strings.Join(quotedKeys, ", "))
+
+	return fmt.Sprintf("SELECT %s FROM %s.%s WHERE %s > ? AND %s <= ?",
+		selectList, QuoteIdentifier(keyspace), QuoteIdentifier(table), tokenExpr, tokenExpr)
+}
+
+// QuoteIdentifier double-quotes identifiers that are not plain lowercase names
+func QuoteIdentifier(name string) string {
+	for i, r := range name {
+		isLower := r >= 'a' && r <= 'z'
+		isDigit := r >= '0' && r <= '9'
+		if !isLower && r != '_' && (!isDigit || i == 0) {
+			return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+		}
+	}
+	return name
+}
+
+// TokenRangePage holds one page of rows fetched from a token range scan
+type TokenRangePage struct {
+	ColumnNames     []string
+	ColumnTypes     []string
+	ColumnTypeInfos []gocql.TypeInfo
+	Rows            []map[string]interface{}
+	PageState       []byte // nil once the range is exhausted
+}
+
+// TokenRangeColumns returns a page without rows that describes the columns a token range
+// query returns, so an export can write its header before any rows arrive
+func (s *Session) TokenRangeColumns(ctx context.Context, query string) (*TokenRangePage, error) {
+	if !s.Connected() {
+		return nil, fmt.Errorf("not connected to database")
+	}
+
+	metadata, err := s.driver().StatementMetadata(ctx, query, "")
+	if err != nil {
+		return nil, err
+	}
+
+	columns := metadata.ResultColumns
+	page := &TokenRangePage{
+		ColumnNames:     make([]string, len(columns)),
+		ColumnTypes:     make([]string, len(columns)),
+		ColumnTypeInfos: make([]gocql.TypeInfo, len(columns)),
+	}
+	for i, col := range columns {
+		page.ColumnNames[i] = col.Name
+		page.ColumnTypes[i] = formatTypeInfo(col.TypeInfo)
+		page.ColumnTypeInfos[i] = col.TypeInfo
+	}
+	return page, nil
+}
+
+// FetchTokenRangePage fetches a single page of a token range query starting at pageState.
+// Passing the returned PageState back in continues the scan, which lets callers retry a
+// failed range from its last completed page instead of from the beginning.
+func (s *Session) FetchTokenRangePage(ctx context.Context, query string, r TokenRange, pageSize int, pageState []byte) (*TokenRangePage, error) {
+	if !s.Connected() {
+		return nil, fmt.Errorf("not connected to database")
+	}
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+
+	q := s.Query(query, r.Start, r.End).PageSize(pageSize).PageState(pageState).Idempotent(true)
+	iter := q.IterContext(ctx)
+
+	columns := iter.Columns()
+	page := &TokenRangePage{
+		ColumnNames:     make([]string, len(columns)),
+		ColumnTypes:     make([]string, len(columns)),
+		ColumnTypeInfos: make([]gocql.TypeInfo, len(columns)),
+		Rows:            make([]map[string]interface{}, 0, iter.NumRows()),
+	}
+	for i, col := range columns {
+		page.ColumnNames[i] = col.Name
+		page.ColumnTypes[i] = formatTypeInfo(col.TypeInfo)
+		page.ColumnTypeInfos[i] = col.TypeInfo
+	}
+
+	for {
+		row := make(map[string]interface{})
+		if !iter.MapScan(row) {
+			break
+		}
+		page.Rows = append(page.Rows, row)
+	}
+
+	nextState := iter.PageState()
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	if len(nextState) > 0 {
+		page.PageState = nextState
+	}
+
+	return page, nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSplitTokenRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		begin         int64
+		end           int64
+		n             int
+		expectedCount int
+	}{
+		{"full ring", MinToken, MaxToken, 96, 96},
+		{"single range", MinToken, MaxToken, 1, 1},
+		{"zero splits treated as one", -100, 100, 0, 1},
+		{"narrow range caps split count", 0, 3, 10, 3},
+		{"empty range", 10, 10, 4, 0},
+		{"inverted range", 10, -10, 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges := SplitTokenRange(tt.begin, tt.end, tt.n)
+			if len(ranges) != tt.expectedCount {
+				t.Fatalf("expected %d ranges, got %d", tt.expectedCount, len(ranges))
+			}
+			if len(ranges) == 0 {
+				return
+			}
+
+			// Ranges must be contiguous and cover (begin, end] exactly
+			if ranges[0].Start != tt.begin {
+				t.Errorf("first range starts at %d, expected %d", ranges[0].Start, tt.begin)
+			}
+			if ranges[len(ranges)-1].End != tt.end {
+				t.Errorf("last range ends at %d, expected %d", ranges[len(ranges)-1].End, tt.end)
+			}
+			for i, r := range ranges {
+				if r.Start >= r.End {
+					t.Errorf("range %d is empty: %s", i, r)
+				}
+				if i > 0 && ranges[i-1].End != r.Start {
+					t.Errorf("gap between range %d %s and range %d %s", i-1, ranges[i-1], i, r)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildTokenRangeQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		table         string
+		columns       []string
+		partitionKeys []string
+		expected      string
+	}{
+		{
+			name:          "all columns",
+			partitionKeys: []string{"id"},
+			expected:      "SELECT * FROM ks.users WHERE token(id) > ? AND token(id) <= ?",
+		},
+		{
+			name:          "selected columns with composite key",
+			columns:       []string{"a", "b", "c"},
+			partitionKeys: []string{"a", "b"},
+			expected:      "SELECT a, b, c FROM ks.users WHERE token(a, b) > ? AND token(a, b) <= ?",
+		},
+		{
+			name:          "case sensitive key is quoted",
+			partitionKeys: []string{"userId"},
+			expected:      `SELECT * FROM ks.users WHERE token("userId") > ? AND token("userId") <= ?`,
+		},
+		{
+			name:          "case sensitive table and columns are quoted",
+			table:         "UserEvents",
+			columns:       []string{`"userId"`, "Name"},
+			partitionKeys: []string{"userId"},
+			expected:      `SELECT "userId", name FROM ks."UserEvents" WHERE token("userId") > ? AND token("userId") <= ?`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := tt.table
+			if table == "" {
+				table = "users"
+			}
+			query := BuildTokenRangeQuery("ks", table, tt.columns, tt.partitionKeys)
+			if query != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, query)
+			}
+		})
+	}
+}
+
+func TestTokenRangeRequiresConnection(t *testing.T) {
+	s := &Session{}
+	if s.SupportsTokenRangeScan() {
+		t.Error("expected token range scan to be unsupported without a connection")
+	}
+	if _, err := s.PartitionKeyColumns("ks", "users"); err == nil {
+		t.Error("expected error without a connection")
+	}
+	if _, err := s.FetchTokenRangePage(context.Background(), "SELECT * FROM ks.users", TokenRange{Start: MinToken, End: MaxToken}, 100, nil); err == nil {
+		t.Error("expected error without a connection")
+	}
+}
@@ -24,24 +24,25 @@ type UDTDefinition struct {
 // UDTRegistry manages UDT definitions using gocql's cached metadata
 // This simplified version relies on gocql's internal caching instead of maintaining our own cache
 type UDTRegistry struct {
-	session *gocql.Session
+	driver func() *gocql.Session // Current driver session, which may be replaced on reconnect
 }
 
 // NewUDTRegistry creates a new UDT registry with the given session
 func NewUDTRegistry(session *gocql.Session) *UDTRegistry {
 	return &UDTRegistry{
-		session: session,
+		driver: func() *gocql.Session { return session },
 	}
 }
 
 // GetUDTDefinition retrieves a UDT definition from gocql's cached metadata
 func (r *UDTRegistry) GetUDTDefinition(keyspace, udtName string) (*UDTDefinition, error) {
-	if r.session == nil {
+	session := r.driver()
+	if session == nil {
 		return nil, fmt.Errorf("no session available")
 	}
 
 	// Get keyspace metadata from gocql (this is cached internally by gocql)
-	ksMetadata, err := r.session.KeyspaceMetadata(keyspace)
+	ksMetadata, err := session.KeyspaceMetadata(keyspace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get keyspace metadata: %w", err)
 	}
@@ -90,11 +91,12 @@ func (r *UDTRegistry) GetUDTDefinitionOrLoad(keyspace, udtName string) (*UDTDefi
 // LoadKeyspaceUDTs is now a no-op since gocql handles loading automatically
 // Kept for backward compatibility
 func (r *UDTRegistry) LoadKeyspaceUDTs(keyspace string) error {
-	if r.session == nil {
+	session := r.driver()
+	if session == nil {
 		return fmt.Errorf("no session available")
 	}
 	// gocql will load and cache metadata when KeyspaceMetadata is called
-	_, err := r.session.KeyspaceMetadata(keyspace)
+	_, err := session.KeyspaceMetadata(keyspace)
 	return err
 }
 
@@ -117,10 +119,11 @@ func (r *UDTRegistry) ClearKeyspace(keyspace string) {
 
 // GetAllUDTs returns all UDT definitions for a keyspace from gocql's cached metadata
 func (r *UDTRegistry) GetAllUDTs(keyspace string) map[string]*UDTDefinition {
-	if r.session == nil {
+	session := r.driver()
+	if session == nil {
 		return nil
 	}
-	ksMetadata, err := r.session.KeyspaceMetadata(keyspace)
+	ksMetadata, err := session.KeyspaceMetadata(keyspace)
 	if err != nil {
 		return nil
 	}
@@ -137,10 +140,11 @@ func (r *UDTRegistry) GetAllUDTs(keyspace string) map[string]*UDTDefinition {
 
 // HasUDT checks if a UDT exists in the keyspace
 func (r *UDTRegistry) HasUDT(keyspace, udtName string) bool {
-	if r.session == nil {
+	session := r.driver()
+	if session == nil {
 		return false
 	}
-	ksMetadata, err := r.session.KeyspaceMetadata(keyspace)
+	ksMetadata, err := session.KeyspaceMetadata(keyspace)
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,317 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// DefaultVectorSearchLimit is the LIMIT of an ANN query when none is given
+const DefaultVectorSearchLimit = 10
+
+// vectorSimilarityFunctions maps the similarity names of VECTOR SEARCH to CQL functions
+var vectorSimilarityFunctions = map[string]string{
+	"COSINE":      "similarity_cosine",
+	"EUCLIDEAN":   "similarity_euclidean",
+	"DOT_PRODUCT": "similarity_dot_product",
+}
+
+// ParseSimilarity returns the CQL function of a similarity name (default: cosine)
+func ParseSimilarity(name string) (string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		name = "COSINE"
+	}
+	if fn, ok := vectorSimilarityFunctions[name]; ok {
+		return fn, nil
+	}
+	return "", fmt.Errorf("unknown similarity %q (use COSINE, EUCLIDEAN or DOT_PRODUCT)", name)
+}
+
+// VectorSearch describes an ANN query: the rows of Table closest to Vector in Column
+type VectorSearch struct {
+	Keyspace   string
+	Table      string
+	Column     string
+	Columns    []string // Selectors returned with the similarity score
+	Vector     []float32
+	Limit      int
+	Similarity string // CQL similarity function, e.g. similarity_cosine
+}
+
+// Query builds the SELECT ... ORDER BY <column> ANN OF [...] LIMIT n statement
+func (v VectorSearch) Query() string {
+	literal := FormatVectorLiteral(v.Vector)
+	column := QuoteIdentifier(v.Column)
+
+	selectors := append([]string(nil), v.Columns...)
+	if v.Similarity != "" {
+		selectors = append(selectors, fmt.Sprintf("%s(%s, %s) AS similarity", v.Similarity, column, literal))
+	}
+	if len(selectors) == 0 {
+		selectors = []string{"*"}
+	}
+
+	table := QuoteIdentifier(v.Table)
+	if v.Keyspace != "" {
+		table = QuoteIdentifier(v.Keyspace) + "." + table
+	}
+
+	limit := v.Limit
+	if limit <= 0 {
+		limit = DefaultVectorSearchLimit
+	}
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s ANN OF %s LIMIT %d",
+		strings.Join(selectors, ", "), table, column, literal, limit)
+}
+
+// FormatVectorLiteral renders a vector as a CQL vector literal
+func FormatVectorLiteral(vector []float32) string {
+	items := make([]string, len(vector))
+	for i, x := range vector {
+		items[i] = strconv.FormatFloat(float64(x), 'g', -1, 32)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// VectorFromValue converts a value decoded from JSON or Parquet to a vector
+func VectorFromValue(value interface{}) ([]float32, error) {
+	switch v := value.(type) {
+	case []float32:
+		return v, nil
+	case []float64:
+		vector := make([]float32, len(v))
+		for i, x := range v {
+			vector[i] = float32(x)
+		}
+		return vector, nil
+	case []interface{}:
+		vector := make([]float32, len(v))
+		for i, item := range v {
+			x, err := vectorElement(item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			vector[i] = x
+		}
+		return vector, nil
+	case string:
+		return ParseVectorText(v, "", 1)
+	case nil:
+		return nil, fmt.Errorf("the value is null")
+	default:
+		return nil, fmt.Errorf("a %T is not a vector", value)
+	}
+}
+
+// vectorElement converts one element of a vector to float32
+func vectorElement(value interface{}) (float32, error) {
+	switch v := value.(type) {
+	case float32:
+		return v, nil
+	case float64:
+		return float32(v), nil
+	case int8:
+		return float32(v), nil
+	case int16:
+		return float32(v), nil
+	case int32:
+		return float32(v), nil
+	case int64:
+		return float32(v), nil
+	case int:
+		return float32(v), nil
+	case json.Number:
+		f, err := v.Float64()
+		return float32(f), err
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
+
+// ParseVectorText reads a vector from text. The text is a JSON array of numbers, a JSON
+// object holding the vector in field, several such values (a JSON array of them or
+// JSON Lines), from which row (1-based) is picked, or plain numbers separated by
+// commas or spaces.
+func ParseVectorText(text, field string, row int) ([]float32, error) {
+	if row < 1 {
+		return nil, fmt.Errorf("ROW must be 1 or more")
+	}
+
+	values, err := decodeJSONValues(text)
+	if err == nil && len(values) > 0 {
+		// Numbers separated by spaces decode as several JSON values
+		if _, ok := values[0].(json.Number); ok {
+			err = fmt.Errorf("not a JSON array or object")
+		}
+	}
+	if err != nil {
+		if field != "" || row != 1 {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return parsePlainVector(text)
+	}
+
+	// A single array of rows rather than a vector
+	if len(values) == 1 {
+		if rows, ok := values[0].([]interface{}); ok && len(rows) > 0 {
+			switch rows[0].(type) {
+			case []interface{}, map[string]interface{}:
+				values = rows
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no vector found")
+	}
+	if row > len(values) {
+		return nil, fmt.Errorf("ROW %d is out of range (%d rows)", row, len(values))
+	}
+
+	value := values[row-1]
+	if object, ok := value.(map[string]interface{}); ok {
+		if value, err = vectorField(object, field); err != nil {
+			return nil, err
+		}
+	} else if field != "" {
+		return nil, fmt.Errorf("FIELD %s given but row %d is not a JSON object", field, row)
+	}
+	return VectorFromValue(value)
+}
+
+// VectorFromRecord picks the vector in field of a record, such as a Parquet row
+func VectorFromRecord(record map[string]interface{}, field string) ([]float32, error) {
+	value, err := vectorField(record, field)
+	if err != nil {
+		return nil, err
+	}
+	return VectorFromValue(value)
+}
+
+// vectorField returns field of a record, or its only array field if field is empty
+func vectorField(record map[string]interface{}, field string) (interface{}, error) {
+	if field != "" {
+		value, ok := record[field]
+		if !ok {
+			return nil, fmt.Errorf("field %s not found", field)
+		}
+		return value, nil
+	}
+
+	var found []string
+	for name, value := range record {
+		switch value.(type) {
+		case []interface{}, []float32, []float64:
+			found = append(found, name)
+		}
+	}
+	if len(found) != 1 {
+		return nil, fmt.Errorf("use FIELD to choose the field holding the vector")
+	}
+	return record[found[0]], nil
+}
+
+// decodeJSONValues decodes the JSON values of text, one or more (JSON Lines)
+func decodeJSONValues(text string) ([]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	var values []interface{}
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// parsePlainVector parses numbers separated by commas or spaces, optionally in brackets
+func parsePlainVector(text string) ([]float32, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "["), "]")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no vector found")
+	}
+
+	vector := make([]float32, len(fields))
+	for i, field := range fields {
+		x, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", field)
+		}
+		vector[i] = float32(x)
+	}
+	return vector, nil
+}
+
+// CompactVector shortens a formatted vector such as [0.1, 0.2, ...] to its dimension
+// and its first and last edge elements. Other values are returned as they are.
+func CompactVector(formatted string, edge int) string {
+	if len(formatted) < 2 || formatted[0] != '[' || formatted[len(formatted)-1] != ']' {
+		return formatted
+	}
+	items := strings.Split(formatted[1:len(formatted)-1], ",")
+	if len(items) <= 2*edge {
+		return formatted
+	}
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return fmt.Sprintf("vector<%d> [%s, …, %s]", len(items),
+		strings.Join(items[:edge], ", "), strings.Join(items[len(items)-edge:], ", "))
+}
+
+// ResolveVectorSearch fills in the keyspace and table of tableName ([keyspace.]table),
+// checks that column is a vector of the dimension of the search vector and, unless
+// columns were chosen, selects every other column of the table
+func (s *Session) ResolveVectorSearch(search *VectorSearch, tableName string) error {
+	if !s.Connected() {
+		return fmt.Errorf("not connected to database")
+	}
+	keyspace, table, ok := SplitTableName(tableName, s.Keyspace())
+	if !ok {
+		return fmt.Errorf("no keyspace selected; use keyspace.table or USE a keyspace")
+	}
+	column := normalizeIdentifier(search.Column)
+
+	meta, err := s.GetTableMetadata(keyspace, table)
+	if err != nil {
+		return err
+	}
+	colMeta, ok := meta.Columns[column]
+	if !ok {
+		return fmt.Errorf("column %s not found in %s.%s", column, keyspace, table)
+	}
+	vectorType, ok := colMeta.Type.(gocql.VectorType)
+	if !ok {
+		return fmt.Errorf("column %s is not a vector", column)
+	}
+	if vectorType.Dimensions != len(search.Vector) {
+		return fmt.Errorf("column %s has %d dimensions but the query vector has %d",
+			column, vectorType.Dimensions, len(search.Vector))
+	}
+
+	if len(search.Columns) == 0 {
+		for _, name := range meta.OrderedColumns {
+			if name != column {
+				search.Columns = append(search.Columns, QuoteIdentifier(name))
+			}
+		}
+	}
+	search.Keyspace, search.Table, search.Column = keyspace, table, column
+	return nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVectorText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		field string
+		row   int
+		want  []float32
+	}{
+		{"json array", "[0.5, 1, -2]", "", 1, []float32{0.5, 1, -2}},
+		{"plain commas", "0.5, 1, -2\n", "", 1, []float32{0.5, 1, -2}},
+		{"plain spaces", "0.5 1 -2", "", 1, []float32{0.5, 1, -2}},
+		{"object field", `{"id": 1, "embedding": [1, 2]}`, "embedding", 1, []float32{1, 2}},
+		{"object single array", `{"id": 1, "embedding": [1, 2]}`, "", 1, []float32{1, 2}},
+		{"array of vectors", "[[1, 2], [3, 4]]", "", 2, []float32{3, 4}},
+		{"array of objects", `[{"v": [1, 2]}, {"v": [3, 4]}]`, "v", 2, []float32{3, 4}},
+		{"json lines", "{\"v\": [1, 2]}\n{\"v\": [5, 6]}\n", "v", 2, []float32{5, 6}},
+		{"vector as string", `{"v": "[7, 8]"}`, "v", 1, []float32{7, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVectorText(tt.text, tt.field, tt.row)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseVectorText(%q) = %v, %v, want %v", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVectorTextErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		field string
+		row   int
+	}{
+		{"empty", "", "", 1},
+		{"not numbers", "a, b", "", 1},
+		{"row out of range", "[[1, 2]]", "", 2},
+		{"missing field", `{"v": [1]}`, "w", 1},
+		{"ambiguous object", `{"a": [1], "b": [2]}`, "", 1},
+		{"field on array", "[1, 2]", "v", 1},
+		{"non-numeric element", `["x", 1]`, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseVectorText(tt.text, tt.field, tt.row); err == nil {
+				t.Errorf("ParseVectorText(%q) = %v, want an error", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestVectorSearchQuery(t *testing.T) {
+	search := VectorSearch{
+		Keyspace:   "app",
+		Table:      "Docs",
+		Column:     "embedding",
+		Columns:    []string{"id", "title"},
+		Vector:     []float32{0.1, 2, -3.5},
+		Similarity: "similarity_cosine",
+	}
+	want := `SELECT id, title, similarity_cosine(embedding, [0.1, 2, -3.5]) AS similarity FROM app."Docs" ORDER BY embedding ANN OF [0.1, 2, -3.5] LIMIT 10`
+	if got := search.Query(); got != want {
+		t.Errorf("Query() = %s", got)
+	}
+
+	search.Columns, search.Similarity, search.Limit = nil, "", 3
+	want = `SELECT * FROM app."Docs" ORDER BY embedding ANN OF [0.1, 2, -3.5] LIMIT 3`
+	if got := search.Query(); got != want {
+		t.Errorf("Query() = %s", got)
+	}
+}
+
+func TestParseSimilarity(t *testing.T) {
+	for input, want := range map[string]string{"": "similarity_cosine", "euclidean": "similarity_euclidean", "DOT_PRODUCT": "similarity_dot_product"} {
+		if got, err := ParseSimilarity(input); err != nil || got != want {
+			t.Errorf("ParseSimilarity(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseSimilarity("manhattan"); err == nil {
+		t.Error("ParseSimilarity(manhattan) should fail")
+	}
+}
+
+func TestCompactVector(t *testing.T) {
+	tests := map[string]string{
+		"[1, 2, 3, 4, 5, 6, 7, 8]": "vector<8> [1, 2, 3, …, 6, 7, 8]",
+		"[1, 2, 3, 4, 5, 6]":       "[1, 2, 3, 4, 5, 6]",
+		"null":                     "null",
+		"":                         "",
+	}
+	for input, want := range tests {
+		if got := CompactVector(input, 3); got != want {
+			t.Errorf("CompactVector(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
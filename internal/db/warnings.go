@@ -0,0 +1,128 @@
+package db
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// recordServerWarnings remembers the client warnings and custom payload the server
+// sent with the response behind iter, such as tombstone or batch size warnings.
+// Both need protocol v4 or later.
+func (s *Session) recordServerWarnings(iter *gocql.Iter) {
+	for _, warning := range iter.Warnings() {
+		logger.DebugfToFile("ServerWarning", "%s", warning)
+		s.addWarning(warning)
+	}
+
+	payload := iter.GetCustomPayload()
+	if len(payload) == 0 {
+		return
+	}
+	// The driver may reuse the buffers of the response, so keep a copy
+	copied := make(map[string][]byte, len(payload))
+	for key, value := range payload {
+		copied[key] = append([]byte(nil), value...)
+	}
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	s.customPayload = copied
+}
+
+// addWarning queues a warning to show with the result of the current statement
+func (s *Session) addWarning(warning string) {
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	s.warnings = append(s.warnings, warning)
+	s.lastWarnings = append(s.lastWarnings, warning)
+}
+
+// ResetLastWarnings forgets the warnings and custom payload of the previous statement,
+// so that commands which run no statement don't report them. Warnings not yet taken
+// are still shown.
+func (s *Session) ResetLastWarnings() {
+	if s == nil {
+		return
+	}
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	s.lastWarnings = nil
+	s.customPayload = nil
+}
+
+// TakeWarnings returns the warnings raised since it was last called and clears them
+func (s *Session) TakeWarnings() []string {
+	if s == nil {
+		return nil
+	}
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	warnings := s.warnings
+	s.warnings = nil
+	return warnings
+}
+
+// LastWarnings returns the warnings raised by the last statement, for JSON output and
+// CAPTURE files. Unlike TakeWarnings it does not clear them.
+func (s *Session) LastWarnings() []string {
+	if s == nil {
+		return nil
+	}
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	return append([]string(nil), s.lastWarnings...)
+}
+
+// TakeCustomPayload returns the custom payload of the last statement as "key: value"
+// lines sorted by key, and clears it so it is only shown once
+func (s *Session) TakeCustomPayload() []string {
+	if s == nil {
+		return nil
+	}
+	s.warningsMu.Lock()
+	payload := s.customPayload
+	s.customPayload = nil
+	s.warningsMu.Unlock()
+	return FormatCustomPayload(payload)
+}
+
+// FormatCustomPayload formats a custom payload as "key: value" lines sorted by key.
+// Values that are not printable text are shown as hex.
+func FormatCustomPayload(payload map[string][]byte) []string {
+	if len(payload) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(payload))
+	for key := range payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = fmt.Sprintf("%s: %s", key, formatPayloadValue(payload[key]))
+	}
+	return lines
+}
+
+// formatPayloadValue returns value as text if it is printable, otherwise as 0x-prefixed hex
+func formatPayloadValue(value []byte) string {
+	if utf8.Valid(value) {
+		printable := true
+		for _, r := range string(value) {
+			if !unicode.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(value)
+		}
+	}
+	return "0x" + hex.EncodeToString(value)
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLastWarnings(t *testing.T) {
+	s := &Session{}
+	s.addWarning("Read 1001 tombstone cells")
+	s.addWarning("Batch is too large")
+
+	if warnings := s.TakeWarnings(); len(warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %v", warnings)
+	}
+	// Taking the warnings to show them keeps them for JSON output and CAPTURE
+	want := []string{"Read 1001 tombstone cells", "Batch is too large"}
+	if warnings := s.LastWarnings(); !reflect.DeepEqual(warnings, want) {
+		t.Errorf("LastWarnings() = %v, want %v", warnings, want)
+	}
+
+	s.ResetLastWarnings()
+	if warnings := s.LastWarnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings after a reset, got %v", warnings)
+	}
+
+	var nilSession *Session
+	nilSession.ResetLastWarnings()
+	if warnings := nilSession.LastWarnings(); warnings != nil {
+		t.Errorf("expected no warnings without a session, got %v", warnings)
+	}
+}
+
+func TestFormatCustomPayload(t *testing.T) {
+	payload := map[string][]byte{
+		"node":   []byte("10.0.0.1"),
+		"binary": {0x00, 0xff},
+	}
+	want := []string{"binary: 0x00ff", "node: 10.0.0.1"}
+	if lines := FormatCustomPayload(payload); !reflect.DeepEqual(lines, want) {
+		t.Errorf("FormatCustomPayload() = %v, want %v", lines, want)
+	}
+	if lines := FormatCustomPayload(nil); lines != nil {
+		t.Errorf("expected no lines for an empty payload, got %v", lines)
+	}
+}
+
+func TestTakeCustomPayload(t *testing.T) {
+	s := &Session{customPayload: map[string][]byte{"trace": []byte("abc")}}
+	if lines := s.TakeCustomPayload(); !reflect.DeepEqual(lines, []string{"trace: abc"}) {
+		t.Errorf("unexpected payload lines %v", lines)
+	}
+	if lines := s.TakeCustomPayload(); lines != nil {
+		t.Errorf("expected the payload to be cleared, got %v", lines)
+	}
+}
@@ -162,12 +162,9 @@ func (r *ParquetReader) ReadBatch(batchSize int) ([]map[string]any, error) {
 				continue // Try next row group
 			}
 
-			// Create a record reader from the table
-			chunkSize := int64(batchSize)
-			if chunkSize <= 0 || chunkSize > table.NumRows() {
-				chunkSize = table.NumRows()
-			}
-			tableReader := array.NewTableReader(table, chunkSize)
+			// Hold the whole row group as the current batch; rows are handed out
+			// batchSize at a time below so none of the group is dropped
+			tableReader := array.NewTableReader(table, table.NumRows())
 
 			if tableReader.Next() {
 				r.currentBatch = tableReader.RecordBatch()
@@ -204,6 +201,57 @@ func (r *ParquetReader) ReadBatch(batchSize int) ([]map[string]any, error) {
 	return rows, nil
 }
 
+// SkipRows advances the reader past n rows. Row groups that are skipped entirely are never
+// decoded, which lets a resumed import jump straight to the row group it stopped in.
+func (r *ParquetReader) SkipRows(n int64) (int64, error) {
+	ctx := context.Background()
+
+	if r.numRowGroups == 0 {
+		r.numRowGroups = r.reader.NumRowGroups()
+	}
+
+	var skipped int64
+
+	// Consume whatever is left of the current batch first
+	if r.currentBatch != nil && n > 0 {
+		remaining := r.currentBatch.NumRows() - int64(r.batchIdx)
+		step := min(remaining, n)
+		r.batchIdx += int(step)
+		skipped += step
+	}
+
+	for skipped < n && r.rowGroupIdx < r.numRowGroups {
+		groupRows := r.reader.MetaData().RowGroup(r.rowGroupIdx).NumRows()
+		if skipped+groupRows <= n {
+			skipped += groupRows
+			r.rowGroupIdx++
+			continue
+		}
+
+		// The target row is inside this row group: load all of it and position within it
+		table, err := r.arrowReader.ReadRowGroups(ctx, r.allColumns, []int{r.rowGroupIdx})
+		if err != nil {
+			return skipped, fmt.Errorf("failed to read row group %d: %w", r.rowGroupIdx, err)
+		}
+		r.rowGroupIdx++
+
+		tableReader := array.NewTableReader(table, table.NumRows())
+		if tableReader.Next() {
+			if r.currentBatch != nil {
+				r.currentBatch.Release()
+			}
+			r.currentBatch = tableReader.RecordBatch()
+			r.currentBatch.Retain()
+			r.batchIdx = int(n - skipped)
+			skipped = n
+		}
+		tableReader.Release()
+		table.Release()
+	}
+
+	return skipped, nil
+}
+
 // ReadAll reads all rows from the file
 func (r *ParquetReader) ReadAll() ([]map[string]any, error) {
 	ctx := context.Background()
@@ -0,0 +1,94 @@
+package parquet
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestRows writes count rows with sequential ids, using small chunks so the file has several row groups
+func writeTestRows(t *testing.T, path string, count int) {
+	t.Helper()
+
+	options := DefaultWriterOptions()
+	options.ChunkSize = 10
+	writer, err := NewParquetCaptureWriter(path, []string{"id"}, []string{"int"}, options)
+	require.NoError(t, err)
+
+	for i := 0; i < count; i++ {
+		require.NoError(t, writer.WriteRow(map[string]interface{}{"id": int32(i)}))
+	}
+	require.NoError(t, writer.Close())
+}
+
+// readAllIDs drains the reader in small batches and returns the ids read
+func readAllIDs(t *testing.T, reader *ParquetReader) []int32 {
+	t.Helper()
+
+	var ids []int32
+	for {
+		batch, err := reader.ReadBatch(5)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		for _, row := range batch {
+			ids = append(ids, row["id"].(int32))
+		}
+	}
+	return ids
+}
+
+func TestSkipRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skip.parquet")
+	writeTestRows(t, path, 35)
+
+	t.Run("skip into the middle of a row group", func(t *testing.T) {
+		reader, err := NewParquetReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		skipped, err := reader.SkipRows(12)
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), skipped)
+
+		ids := readAllIDs(t, reader)
+		require.Len(t, ids, 23)
+		assert.Equal(t, int32(12), ids[0])
+		assert.Equal(t, int32(34), ids[len(ids)-1])
+	})
+
+	t.Run("skip after reading", func(t *testing.T) {
+		reader, err := NewParquetReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		batch, err := reader.ReadBatch(3)
+		require.NoError(t, err)
+		require.Len(t, batch, 3)
+
+		skipped, err := reader.SkipRows(20)
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), skipped)
+
+		ids := readAllIDs(t, reader)
+		require.NotEmpty(t, ids)
+		assert.Equal(t, int32(23), ids[0])
+	})
+
+	t.Run("skip past the end", func(t *testing.T) {
+		reader, err := NewParquetReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		skipped, err := reader.SkipRows(100)
+		require.NoError(t, err)
+		assert.Equal(t, int64(35), skipped)
+
+		_, err = reader.ReadBatch(5)
+		assert.Equal(t, io.EOF, err)
+	})
+}
@@ -289,28 +289,23 @@ func (w *ParquetCaptureWriter) Close() error {
 		return fmt.Errorf("failed to flush final chunk: %w", err)
 	}
 
-	// Only write if we have data
-	if len(w.records) > 0 || (w.builder != nil && w.builder.Field(0).Len() > 0) {
-		// If there's data in the builder but not yet in records, create final record
-		if w.builder != nil && w.builder.Field(0).Len() > 0 {
-			record := w.builder.NewRecordBatch()
-			w.records = append(w.records, record)
-		}
-
-		// Create table from all records
-		if len(w.records) > 0 {
-			table := array.NewTableFromRecords(w.schema, w.records)
-			defer table.Release()
+	// If there's data in the builder but not yet in records, create final record
+	if w.builder != nil && w.builder.Field(0).Len() > 0 {
+		record := w.builder.NewRecordBatch()
+		w.records = append(w.records, record)
+	}
 
-			// Write the entire table to Parquet
-			if err := pqarrow.WriteTable(table, w.writer, w.chunkSize, w.props, w.arrowProps); err != nil {
-				return fmt.Errorf("failed to write Parquet table: %w", err)
-			}
+	// Create table from all records; without any the file still holds the schema
+	table := array.NewTableFromRecords(w.schema, w.records)
+	defer table.Release()
 
-			logger.DebugfToFile("ParquetWriter", "Wrote Parquet file with %d total rows", w.totalRows)
-		}
+	// Write the entire table to Parquet
+	if err := pqarrow.WriteTable(table, w.writer, w.chunkSize, w.props, w.arrowProps); err != nil {
+		return fmt.Errorf("failed to write Parquet table: %w", err)
 	}
 
+	logger.DebugfToFile("ParquetWriter", "Wrote Parquet file with %d total rows", w.totalRows)
+
 	// Release all stored records - they are retained by the table
 	for _, record := range w.records {
 		if record != nil {
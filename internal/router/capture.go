@@ -280,6 +280,9 @@ func (h *MetaCommandHandler) WriteCaptureText(command string, output string) err
 		// For CSV format, write as a single column with the output
 		// Write command as comment
 		_ = h.csvWriter.Write([]string{"# Command: " + command})
+		for _, warning := range h.session.LastWarnings() {
+			_ = h.csvWriter.Write([]string{"# Warning: " + warning})
+		}
 
 		// Split output by lines and write each as a row
 		lines := strings.Split(output, "\n")
@@ -299,15 +302,17 @@ func (h *MetaCommandHandler) WriteCaptureText(command string, output string) err
 	case "json":
 		// For JSON format, create a text result object
 		type TextResult struct {
-			Command string `json:"command"`
-			Output  string `json:"output"`
-			Type    string `json:"type"`
+			Command  string   `json:"command"`
+			Output   string   `json:"output"`
+			Type     string   `json:"type"`
+			Warnings []string `json:"warnings,omitempty"`
 		}
 
 		result := TextResult{
-			Command: command,
-			Output:  output,
-			Type:    "text",
+			Command:  command,
+			Output:   output,
+			Type:     "text",
+			Warnings: h.session.LastWarnings(),
 		}
 
 		jsonBytes, err := json.MarshalIndent(result, "  ", "  ")
@@ -322,6 +327,9 @@ func (h *MetaCommandHandler) WriteCaptureText(command string, output string) err
 	default:
 		// Text format - write the command and output
 		_, _ = fmt.Fprintf(h.captureOutput, "\n> %s\n", command)
+		for _, warning := range h.session.LastWarnings() {
+			_, _ = fmt.Fprintf(h.captureOutput, "Warning: %s\n", warning)
+		}
 		_, _ = h.captureOutput.Write([]byte(strings.Repeat("-", 50) + "\n"))
 		_, _ = h.captureOutput.Write([]byte(output))
 		if !strings.HasSuffix(output, "\n") {
@@ -549,6 +557,9 @@ func (h *MetaCommandHandler) WriteCaptureResultWithRawData(command string, heade
 		// Write headers as first row (with query command as comment)
 		// Write comment with the query
 		_ = h.csvWriter.Write([]string{"# Query: " + command})
+		for _, warning := range h.session.LastWarnings() {
+			_ = h.csvWriter.Write([]string{"# Warning: " + warning})
+		}
 
 		// Write headers
 		if err := h.csvWriter.Write(headers); err != nil {
@@ -572,17 +583,19 @@ func (h *MetaCommandHandler) WriteCaptureResultWithRawData(command string, heade
 	case "json":
 		// Format as JSON
 		type QueryResult struct {
-			Query   string                   `json:"query"`
-			Columns []string                 `json:"columns"`
-			Rows    []map[string]interface{} `json:"rows"`
-			Count   int                      `json:"row_count"`
+			Query    string                   `json:"query"`
+			Columns  []string                 `json:"columns"`
+			Rows     []map[string]interface{} `json:"rows"`
+			Count    int                      `json:"row_count"`
+			Warnings []string                 `json:"warnings,omitempty"`
 		}
 
 		result := QueryResult{
-			Query:   command,
-			Columns: headers,
-			Rows:    make([]map[string]interface{}, 0, len(rows)),
-			Count:   len(rows),
+			Query:    command,
+			Columns:  headers,
+			Rows:     make([]map[string]interface{}, 0, len(rows)),
+			Count:    len(rows),
+			Warnings: h.session.LastWarnings(),
 		}
 
 		// Use raw data if provided, otherwise fall back to string parsing
@@ -657,6 +670,9 @@ func (h *MetaCommandHandler) WriteCaptureResultWithRawData(command string, heade
 	default:
 		// Text format - write the command and a simple table representation
 		_, _ = fmt.Fprintf(h.captureOutput, "\n> %s\n", command)
+		for _, warning := range h.session.LastWarnings() {
+			_, _ = fmt.Fprintf(h.captureOutput, "Warning: %s\n", warning)
+		}
 		_, _ = h.captureOutput.Write([]byte(strings.Repeat("-", 50) + "\n"))
 
 		// Write headers
@@ -100,6 +100,10 @@ func (p *CommandParser) parseDescribe(command string) interface{} {
 		upperParts[i] = strings.ToUpper(strings.TrimSuffix(part, ";"))
 	}
 
+	if p.session.IsOffline() {
+		return p.describeOffline(parts, upperParts)
+	}
+
 	switch upperParts[1] {
 	case "KEYSPACES":
 		return p.describeKeyspaces()
@@ -203,4 +207,48 @@ func (p *CommandParser) parseRevoke(command string) interface{} {
 		return fmt.Sprintf("Error: %v", err)
 	}
 	return "REVOKE successful"
-}
\ No newline at end of file
+}
+
+// tokenizeArgs splits command arguments on whitespace, keeping quoted strings and
+// bracketed lists whole
+func tokenizeArgs(args string) []string {
+	var tokens []string
+	var current strings.Builder
+	depth := 0
+	var quote rune
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range args {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0 && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return tokens
+}
+
+// unquoteArg removes the single quotes around an argument such as a file name
+func unquoteArg(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
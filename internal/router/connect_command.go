@@ -0,0 +1,58 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+// ConnectCommand represents a parsed CONNECT command. The UI builds a new
+// session from the named profile; an empty profile lists the available ones.
+type ConnectCommand struct {
+	Profile string
+}
+
+// DisconnectCommand represents a parsed DISCONNECT command
+type DisconnectCommand struct{}
+
+// ParseConnectCommand parses CONNECT [profile] and DISCONNECT (exported for testing)
+func ParseConnectCommand(input string) interface{} {
+	parts := strings.Fields(strings.TrimSuffix(strings.TrimSpace(input), ";"))
+	if len(parts) == 0 {
+		return "Usage: CONNECT [profile] | DISCONNECT"
+	}
+
+	if strings.EqualFold(parts[0], "DISCONNECT") {
+		if len(parts) != 1 {
+			return "Usage: DISCONNECT"
+		}
+		return &DisconnectCommand{}
+	}
+
+	switch len(parts) {
+	case 1:
+		return &ConnectCommand{}
+	case 2:
+		return &ConnectCommand{Profile: strings.Trim(parts[1], "'\"")}
+	default:
+		return "Usage: CONNECT [profile]"
+	}
+}
+
+// SetSession points the router at a new database session after CONNECT or
+// DISCONNECT. Meta-command state such as an active CAPTURE is kept.
+func SetSession(session *db.Session) {
+	if metaHandler != nil {
+		metaHandler.session = session
+	}
+}
+
+// allowedWhileDisconnected reports whether a command can run without a session
+func allowedWhileDisconnected(upperCommand string) bool {
+	for _, cmd := range []string{"CONNECT", "DISCONNECT", "HELP", "SET", "UNSET", "\\SET", "\\UNSET", "SCHEMA LOAD"} {
+		if upperCommand == cmd || strings.HasPrefix(upperCommand, cmd+" ") {
+			return true
+		}
+	}
+	return false
+}
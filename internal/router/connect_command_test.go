@@ -0,0 +1,26 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConnectCommand(t *testing.T) {
+	assert.Equal(t, &ConnectCommand{Profile: "prod"}, ParseConnectCommand("CONNECT prod"))
+	assert.Equal(t, &ConnectCommand{Profile: "staging"}, ParseConnectCommand("connect 'staging';"))
+	assert.Equal(t, &ConnectCommand{}, ParseConnectCommand("CONNECT"))
+	assert.Equal(t, &DisconnectCommand{}, ParseConnectCommand("DISCONNECT"))
+	assert.Equal(t, "Usage: CONNECT [profile]", ParseConnectCommand("CONNECT prod now"))
+	assert.Equal(t, "Usage: DISCONNECT", ParseConnectCommand("DISCONNECT prod"))
+}
+
+func TestProcessCommandWhileDisconnected(t *testing.T) {
+	defer func(previous *MetaCommandHandler) { metaHandler = previous }(metaHandler)
+
+	assert.Equal(t, &ConnectCommand{Profile: "prod"}, ProcessCommand(context.Background(), "CONNECT prod", nil, nil))
+	assert.Equal(t, &DisconnectCommand{}, ProcessCommand(context.Background(), "DISCONNECT;", nil, nil))
+	assert.Equal(t, "Not connected. Use CONNECT <profile> to connect to a cluster.",
+		ProcessCommand(context.Background(), "SELECT * FROM users", nil, nil))
+}
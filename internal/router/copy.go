@@ -52,25 +52,37 @@ func formatCSVValue(val interface{}) string {
 }
 
 // handleCopy handles COPY TO/FROM commands
-func (h *MetaCommandHandler) handleCopy(command string) interface{} {
+func (h *MetaCommandHandler) handleCopy(ctx context.Context, command string) interface{} {
 	// Parse the COPY command
 	// Format: COPY table [(col1, col2, ...)] TO 'filename' [WITH options]
 
 	upperCommand := strings.ToUpper(command)
 
-	// Check if it's COPY TO or COPY FROM
+	// Check if it's COPY TO or COPY FROM. Bulk transfers run on their own
+	// compressed connection unless compression is configured.
 	switch {
 	case strings.Contains(upperCommand, " TO "):
-		return h.handleCopyTo(command)
+		copyHandler, done := h.bulkTransferHandler()
+		defer done()
+		return copyHandler.handleCopyTo(ctx, command)
 	case strings.Contains(upperCommand, " FROM "):
-		return h.handleCopyFrom(command)
+		copyHandler, done := h.bulkTransferHandler()
+		defer done()
+		return copyHandler.handleCopyFrom(ctx, command)
 	default:
-		return "Invalid COPY syntax. Use: COPY table TO 'file' or COPY table FROM 'file'\nSupported formats: CSV (.csv) and Parquet (.parquet)\nFormat is auto-detected from file extension or can be specified with WITH FORMAT='csv|parquet'"
+		return "Invalid COPY syntax. Use: COPY table TO 'file' or COPY table FROM 'file'\nSupported formats: CSV (.csv), Parquet (.parquet) and JSON Lines (.json, .jsonl, .ndjson)\nFormat is auto-detected from file extension or can be specified with WITH FORMAT='csv|parquet|json'"
 	}
 }
 
+// bulkTransferHandler returns a handler that runs a COPY on the session's bulk
+// transfer connection, and a function that ends the transfer
+func (h *MetaCommandHandler) bulkTransferHandler() (*MetaCommandHandler, func()) {
+	bulk, done := h.session.StartBulkTransfer()
+	return NewMetaCommandHandler(bulk, h.sessionManager), done
+}
+
 // handleCopyTo handles COPY TO command for exporting data to CSV
-func (h *MetaCommandHandler) handleCopyTo(command string) interface{} {
+func (h *MetaCommandHandler) handleCopyTo(ctx context.Context, command string) interface{} {
 	// Parse command using regex
 	// Pattern: COPY table [(columns)] TO 'filename' or STDOUT [WITH options]
 	pattern := `(?i)COPY\s+(\S+)(?:\s*\(([^)]+)\))?\s+TO\s+(?:'([^']+)'|(\S+))(?:\s+WITH\s+(.+))?`
@@ -102,7 +114,7 @@ func (h *MetaCommandHandler) handleCopyTo(command string) interface{} {
 	options := parseCopyOptions(optionsStr)
 
 	// Execute the copy
-	return h.executeCopyTo(table, columns, filename, options)
+	return h.executeCopyTo(ctx, table, columns, filename, options)
 }
 
 // parseCopyOptions parses COPY command options
@@ -153,11 +165,11 @@ func parseCopyOptions(optionsStr string) map[string]string {
 }
 
 // executeCopyTo executes the COPY TO operation
-func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filename string, options map[string]string) interface{} {
+func (h *MetaCommandHandler) executeCopyTo(ctx context.Context, table string, columns []string, filename string, options map[string]string) interface{} {
 	logger.DebugfToFile("CopyTo", "Called with filename: %s, options: %+v", filename, options)
 
 	// Determine format from option or filename extension
-	format := strings.ToLower(options["FORMAT"])
+	format := normalizeCopyFormat(strings.ToLower(options["FORMAT"]))
 	if format == "" {
 		// Check file extension if format not explicitly specified
 		ext := strings.ToLower(filepath.Ext(filename))
@@ -165,7 +177,7 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 		switch ext {
 		case ".parquet":
 			format = "parquet"
-		case ".json":
+		case ".json", ".jsonl", ".ndjson":
 			format = "json"
 		default:
 			format = "csv" // Default to CSV
@@ -173,10 +185,25 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 	}
 	logger.DebugfToFile("CopyTo", "Selected format: %s", format)
 
+	// Full-table exports are split into token ranges and fetched in parallel when possible
+	if format == "csv" || format == "parquet" || format == "json" {
+		if plan, ok := h.planTokenRangeExport(table, options); ok {
+			logger.DebugToFile("CopyTo", "Routing to parallel token range export")
+			return h.executeCopyToTokenRanges(ctx, plan, columns, filename, format, options)
+		}
+	}
+	if isResumeRequested(options) {
+		return "Error: RESUME is only supported for full-table CSV and JSON exports (no LIMIT or PARTITION) on clusters using Murmur3Partitioner"
+	}
+
 	// Route to appropriate handler
 	if format == "parquet" {
 		logger.DebugToFile("CopyTo", "Routing to Parquet handler")
-		return h.executeCopyToParquet(table, columns, filename, options)
+		return h.executeCopyToParquet(ctx, table, columns, filename, options)
+	}
+	if format == "json" {
+		logger.DebugToFile("CopyTo", "Routing to JSON handler")
+		return h.executeCopyToJSON(ctx, table, columns, filename, options)
 	}
 
 	// Default to CSV format
@@ -215,7 +242,7 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 	}
 
 	// Execute query
-	result := h.session.ExecuteCQLQuery(query)
+	result := h.session.ExecuteCQLQueryContext(ctx, query)
 
 	// Handle different result types
 	switch v := result.(type) {
@@ -264,7 +291,7 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 
 	case db.StreamingQueryResult:
 		// For streaming results, we need to iterate through the data
-		defer v.Iterator.Close()
+		defer func() { _ = v.Close() }()
 
 		// Get headers
 		headers := v.Headers
@@ -283,9 +310,9 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 			pageSize = 1000
 		}
 
-		for {
+		for ctx.Err() == nil {
 			rowMap := make(map[string]interface{})
-			if !v.Iterator.MapScan(rowMap) {
+			if ok, _ := v.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.MapScan(rowMap) }); !ok {
 				break
 			}
 
@@ -320,6 +347,9 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 			return fmt.Sprintf("Error flushing CSV: %v", err)
 		}
 
+		if ctx.Err() != nil {
+			return fmt.Sprintf("COPY cancelled after %d rows exported to %s", rowCount, filename)
+		}
 		if isStdout {
 			return nil
 		}
@@ -334,7 +364,7 @@ func (h *MetaCommandHandler) executeCopyTo(table string, columns []string, filen
 }
 
 // handleCopyFrom handles COPY FROM command for importing data from CSV
-func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
+func (h *MetaCommandHandler) handleCopyFrom(ctx context.Context, command string) interface{} {
 	// Parse command using regex
 	// Pattern: COPY table [(columns)] FROM 'filename' or STDIN [WITH options]
 	pattern := `(?i)COPY\s+(\S+)(?:\s*\(([^)]+)\))?\s+FROM\s+(?:'([^']+)'|(\S+))(?:\s+WITH\s+(.+))?`
@@ -366,7 +396,7 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 	options := parseCopyOptions(optionsStr)
 
 	// Check format option and delegate to appropriate handler
-	format := strings.ToLower(options["FORMAT"])
+	format := normalizeCopyFormat(strings.ToLower(options["FORMAT"]))
 
 	// If format not specified, detect from file extension
 	if format == "" && !strings.EqualFold(filename, "STDIN") {
@@ -375,7 +405,7 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 		switch ext {
 		case ".parquet":
 			format = "parquet"
-		case ".json":
+		case ".json", ".jsonl", ".ndjson":
 			format = "json"
 		default:
 			format = "csv" // Default to CSV
@@ -385,7 +415,11 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 
 	if format == "parquet" {
 		logger.DebugToFile("CopyFrom", "Routing to Parquet handler")
-		return h.executeCopyFromParquet(table, columns, filename, options)
+		return h.executeCopyFromParquet(ctx, table, columns, filename, options)
+	}
+	if format == "json" {
+		logger.DebugToFile("CopyFrom", "Routing to JSON handler")
+		return h.executeCopyFromJSON(ctx, table, columns, filename, options)
 	}
 
 	// Default to CSV format
@@ -511,6 +545,27 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 		skippedRows++
 	}
 
+	// Record progress in a checkpoint file so an interrupted import can be resumed
+	var checkpointer *importCheckpointer
+	var resumedRows int64
+	if isStdin && isResumeRequested(options) {
+		return "Error: RESUME requires an input file"
+	}
+	if !isStdin {
+		var resumeOffset int64
+		checkpointer, resumeOffset, err = prepareImportCheckpoint(table, columns, "csv", filename, options)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		for resumedRows < resumeOffset {
+			if _, err := csvReader.Read(); err == io.EOF {
+				break
+			}
+			resumedRows++
+		}
+	}
+	recordOffset := resumedRows
+
 	// Create prepared statement template with placeholders
 	placeholders := make([]string, len(columns))
 	for i := range placeholders {
@@ -520,7 +575,7 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 		table, columnList, strings.Join(placeholders, ", "))
 
 	// Create batch channel and wait group for concurrent execution
-	batchChan := make(chan []batchEntry, maxRequests*2)
+	batchChan := make(chan importBatch, maxRequests*2)
 	var wg sync.WaitGroup
 
 	// Start worker goroutines for concurrent batch execution
@@ -529,27 +584,41 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 		go func() {
 			defer wg.Done()
 			for batch := range batchChan {
-				errors := h.executeBatchWithValues(batch)
+				errors := h.executeBatchWithValues(ctx, batch.entries)
+				if ctx.Err() != nil {
+					continue // Cancelled batches stay out of the checkpoint so RESUME retries them
+				}
 				atomic.AddInt64(&insertErrorCount, int64(errors))
-				atomic.AddInt64(&rowCount, int64(len(batch)-errors))
+				atomic.AddInt64(&rowCount, int64(len(batch.entries)-errors))
+				checkpointer.batchFinished(batch.seq, batch.endOffset, int64(len(batch.entries)-errors), int64(errors))
 			}
 		}()
 	}
 
 	// Prepare batch for inserts
 	batch := make([]batchEntry, 0, maxBatchSize)
+	batchSeq := 0
 	lastProgress := int64(0)
 
 	for {
+		if ctx.Err() != nil {
+			close(batchChan)
+			wg.Wait()
+			checkpointer.save()
+			return checkpointer.cancelledMessage(atomic.LoadInt64(&rowCount), filename)
+		}
+
 		record, err := csvReader.Read()
 		if err == io.EOF {
 			break
 		}
+		recordOffset++
 		if err != nil {
 			parseErrorCount++
 			if maxParseErrors != -1 && parseErrorCount > maxParseErrors {
 				close(batchChan)
 				wg.Wait()
+				checkpointer.save()
 				return fmt.Sprintf("Too many parse errors. Imported %d rows, failed after %d parse errors", atomic.LoadInt64(&rowCount), parseErrorCount)
 			}
 			continue
@@ -567,6 +636,7 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 			if maxParseErrors != -1 && parseErrorCount > maxParseErrors {
 				close(batchChan)
 				wg.Wait()
+				checkpointer.save()
 				return fmt.Sprintf("Too many parse errors. Imported %d rows, failed after %d parse errors", atomic.LoadInt64(&rowCount), parseErrorCount)
 			}
 			continue
@@ -592,12 +662,14 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 			if maxInsertErrors != -1 && atomic.LoadInt64(&insertErrorCount) > int64(maxInsertErrors) {
 				close(batchChan)
 				wg.Wait()
+				checkpointer.save()
 				return fmt.Sprintf("Too many insert errors. Imported %d rows, failed after %d insert errors", atomic.LoadInt64(&rowCount), atomic.LoadInt64(&insertErrorCount))
 			}
 			// Send batch to workers (make a copy since we reuse the slice)
 			batchCopy := make([]batchEntry, len(batch))
 			copy(batchCopy, batch)
-			batchChan <- batchCopy
+			batchChan <- importBatch{seq: batchSeq, endOffset: recordOffset, entries: batchCopy}
+			batchSeq++
 			batch = batch[:0] // Clear batch
 		}
 
@@ -613,12 +685,13 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 	if len(batch) > 0 {
 		batchCopy := make([]batchEntry, len(batch))
 		copy(batchCopy, batch)
-		batchChan <- batchCopy
+		batchChan <- importBatch{seq: batchSeq, endOffset: recordOffset, entries: batchCopy}
 	}
 
 	// Close channel and wait for all workers to finish
 	close(batchChan)
 	wg.Wait()
+	checkpointer.complete()
 
 	finalRowCount := atomic.LoadInt64(&rowCount)
 	finalInsertErrors := atomic.LoadInt64(&insertErrorCount)
@@ -645,5 +718,16 @@ func (h *MetaCommandHandler) handleCopyFrom(command string) interface{} {
 	if skipRows > 0 {
 		details += fmt.Sprintf(" (skipped %d rows)", skippedRows)
 	}
+	if resumedRows > 0 {
+		details += fmt.Sprintf(" (resumed after %d rows)", resumedRows)
+	}
 	return details
 }
+
+// importBatch is a batch of rows for the COPY FROM workers. endOffset is the number of input
+// rows consumed once the batch was built, which becomes the checkpoint offset when it finishes.
+type importBatch struct {
+	seq       int
+	endOffset int64
+	entries   []batchEntry
+}
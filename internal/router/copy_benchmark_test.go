@@ -3,6 +3,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -45,7 +46,7 @@ func BenchmarkCopyToCSV(b *testing.B) {
 				outputPath := filepath.Join(tempDir, fmt.Sprintf("test_%d.csv", i))
 				command := fmt.Sprintf("COPY test_table TO '%s'", outputPath)
 
-				result := handler.handleCopy(command)
+				result := handler.handleCopy(context.Background(), command)
 				require.Contains(b, result, fmt.Sprintf("Exported %d rows", bm.rowCount))
 
 				// Clean up
@@ -85,7 +86,7 @@ func BenchmarkCopyToParquet(b *testing.B) {
 				outputPath := filepath.Join(tempDir, fmt.Sprintf("test_%d.parquet", i))
 				command := fmt.Sprintf("COPY test_table TO '%s' WITH FORMAT='PARQUET'", outputPath)
 
-				result := handler.handleCopy(command)
+				result := handler.handleCopy(context.Background(), command)
 				require.Contains(b, result, fmt.Sprintf("Exported %d rows", bm.rowCount))
 
 				// Clean up
@@ -120,7 +121,7 @@ func BenchmarkCopyToParquetWithCompression(b *testing.B) {
 				command := fmt.Sprintf("COPY test_table TO '%s' WITH FORMAT='PARQUET' AND COMPRESSION='%s'",
 					outputPath, compression)
 
-				result := handler.handleCopy(command)
+				result := handler.handleCopy(context.Background(), command)
 				require.Contains(b, result, fmt.Sprintf("Exported %d rows", rowCount))
 
 				// Clean up
@@ -155,7 +156,7 @@ func TestCopyToPerformanceComparison(t *testing.T) {
 			csvPath := filepath.Join(tempDir, fmt.Sprintf("test_%d.csv", rowCount))
 			csvStart := time.Now()
 			csvCommand := fmt.Sprintf("COPY test_table TO '%s'", csvPath)
-			csvResult := handler.handleCopy(csvCommand)
+			csvResult := handler.handleCopy(context.Background(), csvCommand)
 			csvDuration := time.Since(csvStart)
 			require.Contains(t, csvResult, fmt.Sprintf("Exported %d rows", rowCount))
 
@@ -166,7 +167,7 @@ func TestCopyToPerformanceComparison(t *testing.T) {
 			parquetPath := filepath.Join(tempDir, fmt.Sprintf("test_%d.parquet", rowCount))
 			parquetStart := time.Now()
 			parquetCommand := fmt.Sprintf("COPY test_table TO '%s' WITH FORMAT='PARQUET'", parquetPath)
-			parquetResult := handler.handleCopy(parquetCommand)
+			parquetResult := handler.handleCopy(context.Background(), parquetCommand)
 			parquetDuration := time.Since(parquetStart)
 			require.Contains(t, parquetResult, fmt.Sprintf("Exported %d rows", rowCount))
 
@@ -0,0 +1,307 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// checkpointSaveInterval limits how often a running COPY rewrites its checkpoint file
+const checkpointSaveInterval = time.Second
+
+// copyCheckpoint records the progress of a COPY so an interrupted run can be resumed.
+// Exports track the state of every token range; imports track how far into the input file
+// rows have been inserted.
+type copyCheckpoint struct {
+	Operation string              `json:"operation"` // "export" or "import"
+	Table     string              `json:"table"`
+	Columns   []string            `json:"columns,omitempty"`
+	Format    string              `json:"format"`
+	File      string              `json:"file"`
+	Ranges    []checkpointedRange `json:"ranges,omitempty"`      // Export: every token range of the run
+	Bytes     int64               `json:"bytes,omitempty"`       // Export: output size covered by the checkpoint
+	RowOffset int64               `json:"rowOffset,omitempty"`   // Import: input rows processed after SKIPROWS
+	FileSize  int64               `json:"fileSize,omitempty"`    // Import: input size, used to detect a changed file
+	FileMTime time.Time           `json:"fileModTime,omitempty"` // Import: input modification time
+	Rows      int64               `json:"rows"`                  // Rows exported or imported so far
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+// checkpointedRange is the progress of one token range in an export checkpoint
+type checkpointedRange struct {
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Done      bool   `json:"done"`
+	PageState []byte `json:"pageState,omitempty"` // Resume point for a partially exported range
+}
+
+// checkpointPath returns the checkpoint file for a COPY, honouring the CHECKPOINT option
+func checkpointPath(filename string, options map[string]string) string {
+	if path := options["CHECKPOINT"]; path != "" {
+		return filepath.Clean(path)
+	}
+	return filepath.Clean(filename) + ".checkpoint"
+}
+
+// isResumeRequested reports whether the COPY was run with RESUME=true
+func isResumeRequested(options map[string]string) bool {
+	return strings.ToLower(options["RESUME"]) == "true"
+}
+
+// isCheckpointRequested reports whether an import should keep a checkpoint, because it
+// was run with CHECKPOINT or RESUME
+func isCheckpointRequested(options map[string]string) bool {
+	return options["CHECKPOINT"] != "" || isResumeRequested(options)
+}
+
+// loadCopyCheckpoint reads a checkpoint file. It returns nil without error when none exists.
+func loadCopyCheckpoint(path string) (*copyCheckpoint, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - checkpoint path is derived from the COPY file name
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint %s: %v", path, err)
+	}
+
+	var checkpoint copyCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %v", path, err)
+	}
+	return &checkpoint, nil
+}
+
+// save writes the checkpoint atomically so a crash never leaves a truncated file behind
+func (c *copyCheckpoint) save(path string) error {
+	c.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// removeCopyCheckpoint deletes the checkpoint of a COPY that completed
+func removeCopyCheckpoint(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.DebugfToFile("CopyCheckpoint", "Failed to remove checkpoint %s: %v", path, err)
+	}
+}
+
+// validate checks that a checkpoint was written by the same COPY command
+func (c *copyCheckpoint) validate(operation, table string, columns []string, format string) error {
+	if c.Operation != operation {
+		return fmt.Errorf("checkpoint was written by a COPY %s, not a COPY %s", strings.ToUpper(c.Operation), strings.ToUpper(operation))
+	}
+	if !strings.EqualFold(c.Table, table) {
+		return fmt.Errorf("checkpoint is for table %s, not %s", c.Table, table)
+	}
+	if c.Format != format {
+		return fmt.Errorf("checkpoint is for format %s, not %s", c.Format, format)
+	}
+	if strings.Join(c.Columns, ",") != strings.Join(columns, ",") {
+		return fmt.Errorf("checkpoint column list (%s) differs from this COPY (%s)",
+			strings.Join(c.Columns, ", "), strings.Join(columns, ", "))
+	}
+	return nil
+}
+
+// validateInputFile checks that an import's input file hasn't changed since the checkpoint
+func (c *copyCheckpoint) validateInputFile(info os.FileInfo) error {
+	if c.FileSize != info.Size() || !c.FileMTime.Equal(info.ModTime()) {
+		return fmt.Errorf("%s has changed since the checkpoint was written", c.File)
+	}
+	return nil
+}
+
+// pendingRanges returns the token ranges of an export checkpoint that still need work
+func (c *copyCheckpoint) pendingRanges() []int {
+	var pending []int
+	for i, r := range c.Ranges {
+		if !r.Done {
+			pending = append(pending, i)
+		}
+	}
+	return pending
+}
+
+// newExportCheckpoint creates the checkpoint for a fresh token range export
+func newExportCheckpoint(table string, columns []string, format string, filename string, ranges []db.TokenRange) *copyCheckpoint {
+	checkpoint := &copyCheckpoint{
+		Operation: "export",
+		Table:     table,
+		Columns:   columns,
+		Format:    format,
+		File:      filename,
+		Ranges:    make([]checkpointedRange, len(ranges)),
+	}
+	for i, r := range ranges {
+		checkpoint.Ranges[i] = checkpointedRange{Start: r.Start, End: r.End}
+	}
+	return checkpoint
+}
+
+// importCheckpointer tracks completed import batches and periodically saves the checkpoint.
+// Batches finish out of order across workers, so the saved offset only advances past a batch
+// once every batch before it has also finished. It never advances past a batch with insert
+// errors, so RESUME retries that batch.
+type importCheckpointer struct {
+	mu         sync.Mutex
+	path       string
+	checkpoint *copyCheckpoint
+	nextSeq    int
+	finished   map[int]importBatchProgress
+	stalled    bool // A batch had insert errors; the offset stays before it
+	lastSave   time.Time
+}
+
+// importBatchProgress is the input position and row count reached by one finished batch
+type importBatchProgress struct {
+	endOffset int64
+	rows      int64
+	failed    bool
+}
+
+// newImportCheckpointer starts tracking an import, continuing from checkpoint when resuming
+func newImportCheckpointer(path string, checkpoint *copyCheckpoint) *importCheckpointer {
+	return &importCheckpointer{
+		path:       path,
+		checkpoint: checkpoint,
+		finished:   make(map[int]importBatchProgress),
+		lastSave:   time.Now(),
+	}
+}
+
+// batchFinished records that batch seq has been inserted. endOffset is the number of input rows
+// consumed once the batch was built, rows is how many of them were imported and failed is how
+// many could not be inserted.
+func (c *importCheckpointer) batchFinished(seq int, endOffset int64, rows int64, failed int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stalled {
+		return
+	}
+
+	c.finished[seq] = importBatchProgress{endOffset: endOffset, rows: rows, failed: failed > 0}
+	for {
+		progress, ok := c.finished[c.nextSeq]
+		if !ok {
+			break
+		}
+		if progress.failed {
+			c.stalled = true
+			c.finished = nil
+			break
+		}
+		delete(c.finished, c.nextSeq)
+		c.checkpoint.RowOffset = progress.endOffset
+		c.checkpoint.Rows += progress.rows
+		c.nextSeq++
+	}
+
+	if time.Since(c.lastSave) >= checkpointSaveInterval {
+		c.saveLocked()
+	}
+}
+
+// save writes the current progress to disk
+func (c *importCheckpointer) save() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saveLocked()
+}
+
+func (c *importCheckpointer) saveLocked() {
+	if err := c.checkpoint.save(c.path); err != nil {
+		logger.DebugfToFile("CopyCheckpoint", "Failed to save checkpoint %s: %v", c.path, err)
+	}
+	c.lastSave = time.Now()
+}
+
+// complete removes the checkpoint once the whole input has been imported. If rows failed to
+// insert it is kept instead, so RESUME can retry them.
+func (c *importCheckpointer) complete() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stalled {
+		c.saveLocked()
+		return
+	}
+	removeCopyCheckpoint(c.path)
+}
+
+// cancelledMessage reports a cancelled import and, when progress was checkpointed, how to resume it
+func (c *importCheckpointer) cancelledMessage(rows int64, filename string) string {
+	message := fmt.Sprintf("COPY cancelled after %d rows imported from %s", rows, filename)
+	if c != nil {
+		message += fmt.Sprintf("\nProgress saved to %s, run the same COPY WITH RESUME=true to continue", c.path)
+	}
+	return message
+}
+
+// prepareImportCheckpoint sets up checkpointing for a file import run with CHECKPOINT or
+// RESUME, and returns nil otherwise. When RESUME=true and a matching checkpoint exists it
+// returns the number of input rows to skip.
+func prepareImportCheckpoint(table string, columns []string, format string, filename string, options map[string]string) (*importCheckpointer, int64, error) {
+	if !isCheckpointRequested(options) {
+		return nil, 0, nil
+	}
+	path := checkpointPath(filename, options)
+	info, err := os.Stat(filepath.Clean(filename))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var checkpoint *copyCheckpoint
+	if isResumeRequested(options) {
+		checkpoint, err = loadCopyCheckpoint(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		if checkpoint != nil {
+			if err := checkpoint.validate("import", table, columns, format); err != nil {
+				return nil, 0, fmt.Errorf("cannot resume: %v (delete %s to start over)", err, path)
+			}
+			if err := checkpoint.validateInputFile(info); err != nil {
+				return nil, 0, fmt.Errorf("cannot resume: %v (delete %s to start over)", err, path)
+			}
+		} else {
+			logger.DebugfToFile("CopyCheckpoint", "No checkpoint at %s, starting from the beginning", path)
+		}
+	}
+
+	if checkpoint == nil {
+		checkpoint = &copyCheckpoint{
+			Operation: "import",
+			Table:     table,
+			Columns:   columns,
+			Format:    format,
+			File:      filename,
+			FileSize:  info.Size(),
+			FileMTime: info.ModTime(),
+		}
+	}
+
+	return newImportCheckpointer(path, checkpoint), checkpoint.RowOffset, nil
+}
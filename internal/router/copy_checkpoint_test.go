@@ -0,0 +1,194 @@
+package router
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/parquet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "users.csv.checkpoint"), checkpointPath("out/users.csv", map[string]string{}))
+	assert.Equal(t, "custom.ckpt", checkpointPath("users.csv", map[string]string{"CHECKPOINT": "custom.ckpt"}))
+}
+
+func TestCopyCheckpointSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.checkpoint")
+
+	t.Run("missing checkpoint", func(t *testing.T) {
+		checkpoint, err := loadCopyCheckpoint(path)
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		ranges := db.SplitTokenRange(db.MinToken, db.MaxToken, 4)
+		checkpoint := newExportCheckpoint("ks.users", []string{"id", "name"}, "csv", "users.csv", ranges)
+		checkpoint.Ranges[0].Done = true
+		checkpoint.Ranges[1].PageState = []byte{0x01, 0x02}
+		checkpoint.Bytes = 1234
+		require.NoError(t, checkpoint.save(path))
+
+		loaded, err := loadCopyCheckpoint(path)
+		require.NoError(t, err)
+		require.NotNil(t, loaded)
+		assert.Equal(t, int64(1234), loaded.Bytes)
+		assert.Equal(t, []byte{0x01, 0x02}, loaded.Ranges[1].PageState)
+		assert.Equal(t, []int{1, 2, 3}, loaded.pendingRanges())
+		assert.NoError(t, loaded.validate("export", "KS.users", []string{"id", "name"}, "csv"))
+	})
+
+	t.Run("mismatched command", func(t *testing.T) {
+		loaded, err := loadCopyCheckpoint(path)
+		require.NoError(t, err)
+		assert.Error(t, loaded.validate("import", "ks.users", []string{"id", "name"}, "csv"))
+		assert.Error(t, loaded.validate("export", "ks.other", []string{"id", "name"}, "csv"))
+		assert.Error(t, loaded.validate("export", "ks.users", []string{"id"}, "csv"))
+		assert.Error(t, loaded.validate("export", "ks.users", []string{"id", "name"}, "parquet"))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		removeCopyCheckpoint(path)
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestImportCheckpointerOutOfOrderBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	checkpointer := newImportCheckpointer(path, &copyCheckpoint{Operation: "import"})
+
+	// Batch 1 finishing first must not move the offset past the unfinished batch 0
+	checkpointer.batchFinished(1, 40, 20, 0)
+	assert.Equal(t, int64(0), checkpointer.checkpoint.RowOffset)
+
+	checkpointer.batchFinished(0, 20, 18, 0)
+	assert.Equal(t, int64(40), checkpointer.checkpoint.RowOffset)
+	assert.Equal(t, int64(38), checkpointer.checkpoint.Rows)
+
+	checkpointer.save()
+	loaded, err := loadCopyCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), loaded.RowOffset)
+
+	checkpointer.complete()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestImportCheckpointerStopsAtFailedBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	checkpointer := newImportCheckpointer(path, &copyCheckpoint{Operation: "import"})
+
+	checkpointer.batchFinished(0, 20, 20, 0)
+	checkpointer.batchFinished(1, 40, 19, 1)
+	checkpointer.batchFinished(2, 60, 20, 0)
+	assert.Equal(t, int64(20), checkpointer.checkpoint.RowOffset)
+	assert.Equal(t, int64(20), checkpointer.checkpoint.Rows)
+
+	// The checkpoint is kept so RESUME retries the failed batch
+	checkpointer.complete()
+	loaded, err := loadCopyCheckpoint(path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, int64(20), loaded.RowOffset)
+}
+
+func TestImportCheckpointerNil(t *testing.T) {
+	var checkpointer *importCheckpointer
+	assert.NotPanics(t, func() {
+		checkpointer.batchFinished(0, 10, 10, 0)
+		checkpointer.save()
+		checkpointer.complete()
+	})
+}
+
+func TestPrepareImportCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.csv")
+	require.NoError(t, os.WriteFile(input, []byte("1,alice\n2,bob\n"), 0600))
+	columns := []string{"id", "name"}
+
+	t.Run("no checkpoint unless requested", func(t *testing.T) {
+		checkpointer, offset, err := prepareImportCheckpoint("users", columns, "csv", input, map[string]string{})
+		require.NoError(t, err)
+		assert.Nil(t, checkpointer)
+		assert.Equal(t, int64(0), offset)
+		_, err = os.Stat(input + ".checkpoint")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("fresh import starts at zero", func(t *testing.T) {
+		checkpointer, offset, err := prepareImportCheckpoint("users", columns, "csv", input, map[string]string{"CHECKPOINT": input + ".checkpoint"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), offset)
+
+		checkpointer.batchFinished(0, 1, 1, 0)
+		checkpointer.save()
+	})
+
+	t.Run("resume continues from checkpoint", func(t *testing.T) {
+		_, offset, err := prepareImportCheckpoint("users", columns, "csv", input, map[string]string{"RESUME": "true"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), offset)
+	})
+
+	t.Run("resume rejects a changed input file", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(input, later, later))
+
+		_, _, err := prepareImportCheckpoint("users", columns, "csv", input, map[string]string{"RESUME": "true"})
+		assert.Error(t, err)
+	})
+}
+
+func TestExportSinkResumeTruncatesUncheckpointedRows(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "users.csv")
+	require.NoError(t, os.WriteFile(output, []byte("id,name\n1,alice\n2,bo"), 0600))
+
+	// Only the header and first row are covered by the checkpoint
+	columns := &db.TokenRangePage{ColumnNames: []string{"id", "name"}}
+	sink, _, err := newExportSink(context.Background(), "csv", output, map[string]string{"HEADER": "true"}, int64(len("id,name\n1,alice\n")), columns)
+	require.NoError(t, err)
+
+	page := &db.TokenRangePage{
+		ColumnNames: []string{"id", "name"},
+		Rows:        []map[string]interface{}{{"id": 2, "name": "bob"}},
+	}
+	require.NoError(t, sink.WritePage(page))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n1,alice\n2,bob\n", string(data))
+}
+
+func TestExportSinkEmptyTable(t *testing.T) {
+	dir := t.TempDir()
+	columns := &db.TokenRangePage{ColumnNames: []string{"id", "name"}, ColumnTypes: []string{"int", "text"}}
+
+	// Without any rows the output still exists, with its header or schema
+	csvOutput := filepath.Join(dir, "users.csv")
+	sink, _, err := newExportSink(context.Background(), "csv", csvOutput, map[string]string{"HEADER": "true"}, -1, columns)
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+	data, err := os.ReadFile(csvOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n", string(data))
+
+	parquetOutput := filepath.Join(dir, "users.parquet")
+	sink, _, err = newExportSink(context.Background(), "parquet", parquetOutput, map[string]string{}, -1, columns)
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+	reader, err := parquet.NewParquetReader(parquetOutput)
+	require.NoError(t, err)
+	defer reader.Close()
+	names, _ := reader.GetSchema()
+	assert.Equal(t, []string{"id", "name"}, names)
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -97,7 +98,7 @@ func TestCopyFromCSVErrorHandling(t *testing.T) {
 		}
 
 		command := "COPY test_table (id, name) FROM '/nonexistent/file.csv'"
-		result := handler.handleCopyFrom(command)
+		result := handler.handleCopyFrom(context.Background(), command)
 
 		resultStr := fmt.Sprintf("%v", result)
 		t.Logf("File not found result: %v", resultStr)
@@ -105,6 +106,31 @@ func TestCopyFromCSVErrorHandling(t *testing.T) {
 	})
 }
 
+// TestCopyFromCSVCancelled tests that a cancelled import stops and keeps the checkpoint it was asked for
+func TestCopyFromCSVCancelled(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "users.csv")
+	require.NoError(t, os.WriteFile(csvFile, []byte("1,alice\n2,bob\n"), 0600))
+
+	handler := &MetaCommandHandler{
+		session:        &db.Session{},
+		sessionManager: session.NewManager(&config.Config{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := handler.handleCopyFrom(ctx, fmt.Sprintf("COPY test_table (id, name) FROM '%s'", csvFile))
+	resultStr := fmt.Sprintf("%v", result)
+	assert.Contains(t, resultStr, "COPY cancelled after 0 rows imported")
+	assert.NotContains(t, resultStr, "RESUME=true")
+	assert.NoFileExists(t, csvFile+".checkpoint")
+
+	result = handler.handleCopyFrom(ctx, fmt.Sprintf("COPY test_table (id, name) FROM '%s' WITH CHECKPOINT='%s.checkpoint'", csvFile, csvFile))
+	resultStr = fmt.Sprintf("%v", result)
+	assert.Contains(t, resultStr, "COPY cancelled after 0 rows imported")
+	assert.Contains(t, resultStr, "RESUME=true")
+	assert.FileExists(t, csvFile+".checkpoint")
+}
+
 // TestLargeBatchCreation tests that large files create multiple batches
 // Note: This test verifies file preparation logic but doesn't execute batches
 func TestLargeBatchCreation(t *testing.T) {
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -29,11 +30,12 @@ type copyStats struct {
 	processedRows    int
 	insertErrorCount int
 	skippedRows      int
+	resumedRows      int64
 	errorMessages    []string // Store first few error messages for user display
 }
 
 // executeCopyFromParquet executes COPY FROM operation for Parquet format
-func (h *MetaCommandHandler) executeCopyFromParquet(table string, columns []string, filename string, options map[string]string) interface{} {
+func (h *MetaCommandHandler) executeCopyFromParquet(ctx context.Context, table string, columns []string, filename string, options map[string]string) interface{} {
 	// Validate input
 	if err := h.validateParquetInput(filename); err != nil {
 		return err.Error()
@@ -47,10 +49,10 @@ func (h *MetaCommandHandler) executeCopyFromParquet(table string, columns []stri
 
 	// Handle directory (partitioned dataset) vs single file
 	if fileInfo.IsDir() {
-		return h.handlePartitionedParquet(table, columns, cleanPath, options)
+		return h.handlePartitionedParquet(ctx, table, columns, cleanPath, options)
 	}
 
-	return h.handleSingleParquetFile(table, columns, cleanPath, options)
+	return h.handleSingleParquetFile(ctx, table, columns, cleanPath, options)
 }
 
 // validateParquetInput validates the input parameters for Parquet COPY
@@ -62,17 +64,20 @@ func (h *MetaCommandHandler) validateParquetInput(filename string) error {
 }
 
 // handlePartitionedParquet handles COPY from a partitioned Parquet dataset
-func (h *MetaCommandHandler) handlePartitionedParquet(table string, columns []string, path string, options map[string]string) interface{} {
+func (h *MetaCommandHandler) handlePartitionedParquet(ctx context.Context, table string, columns []string, path string, options map[string]string) interface{} {
+	if isResumeRequested(options) {
+		return "Error: RESUME is not supported for partitioned Parquet datasets"
+	}
 	reader, err := parquet.NewPartitionedParquetReader(path)
 	if err != nil {
 		return fmt.Sprintf("Error opening partitioned Parquet dataset: %v", err)
 	}
 	defer reader.Close()
-	return h.executeCopyFromParquetPartitioned(table, columns, reader, options)
+	return h.executeCopyFromParquetPartitioned(ctx, table, columns, reader, options)
 }
 
 // handleSingleParquetFile handles COPY from a single Parquet file
-func (h *MetaCommandHandler) handleSingleParquetFile(table string, columns []string, path string, options map[string]string) interface{} {
+func (h *MetaCommandHandler) handleSingleParquetFile(ctx context.Context, table string, columns []string, path string, options map[string]string) interface{} {
 	reader, err := parquet.NewParquetReader(path)
 	if err != nil {
 		return fmt.Sprintf("Error opening Parquet file: %v", err)
@@ -88,11 +93,22 @@ func (h *MetaCommandHandler) handleSingleParquetFile(table string, columns []str
 	// Parse options
 	opts := parseOptions(options)
 
+	// Record progress so an interrupted import can be resumed
+	checkpointer, resumeOffset, err := prepareImportCheckpoint(table, processColumns, "parquet", path, options)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
 	// Process the file
 	stats := &copyStats{}
-	if err := h.processParquetFile(table, processColumns, reader, opts, stats); err != nil {
+	if err := h.processParquetFile(ctx, table, processColumns, reader, opts, stats, checkpointer, resumeOffset); err != nil {
+		checkpointer.save()
+		if ctx.Err() != nil {
+			return checkpointer.cancelledMessage(int64(stats.rowCount), path)
+		}
 		return err.Error()
 	}
+	checkpointer.complete()
 
 	// Log debug info
 	h.logParquetDebugInfo(reader)
@@ -140,8 +156,10 @@ func parseOptions(options map[string]string) *copyOptions {
 	return opts
 }
 
-// processParquetFile processes the Parquet file and inserts data
-func (h *MetaCommandHandler) processParquetFile(table string, columns []string, reader *parquet.ParquetReader, opts *copyOptions, stats *copyStats) error {
+// processParquetFile processes the Parquet file and inserts data.
+// resumeOffset rows after SKIPROWS are skipped when continuing from a checkpoint.
+// It returns ctx's error when cancelled.
+func (h *MetaCommandHandler) processParquetFile(ctx context.Context, table string, columns []string, reader *parquet.ParquetReader, opts *copyOptions, stats *copyStats, checkpointer *importCheckpointer, resumeOffset int64) error {
 	// Skip initial rows if specified
 	if opts.skipRows > 0 {
 		if err := h.skipRows(reader, opts, stats); err != nil {
@@ -149,6 +167,16 @@ func (h *MetaCommandHandler) processParquetFile(table string, columns []string,
 		}
 	}
 
+	if resumeOffset > 0 {
+		skipped, err := reader.SkipRows(resumeOffset)
+		if err != nil {
+			return fmt.Errorf("error resuming Parquet import: %v", err)
+		}
+		stats.resumedRows = skipped
+	}
+	consumedRows := stats.resumedRows
+	batchSeq := 0
+
 	// Get schema for type information
 	_, parquetTypes := reader.GetSchema()
 
@@ -169,15 +197,21 @@ func (h *MetaCommandHandler) processParquetFile(table string, columns []string,
 			if opts.maxRows > 0 && stats.processedRows >= opts.maxRows {
 				break
 			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 
 			stats.processedRows++
-
-			if err := h.insertRow(table, columns, row, parquetTypes, opts, stats); err != nil {
-				// Error already handled in insertRow
-				if opts.maxInsertErrors > 0 && stats.insertErrorCount >= opts.maxInsertErrors {
-					return fmt.Errorf("aborted after %d insert errors. Successfully imported %d rows",
-						stats.insertErrorCount, stats.rowCount)
-				}
+			consumedRows++
+
+			rowsBefore, errorsBefore := stats.rowCount, stats.insertErrorCount
+			err := h.insertRow(table, columns, row, parquetTypes, opts, stats)
+			checkpointer.batchFinished(batchSeq, consumedRows, int64(stats.rowCount-rowsBefore), int64(stats.insertErrorCount-errorsBefore))
+			batchSeq++
+			// Error already handled in insertRow
+			if err != nil && opts.maxInsertErrors > 0 && stats.insertErrorCount >= opts.maxInsertErrors {
+				return fmt.Errorf("aborted after %d insert errors. Successfully imported %d rows",
+					stats.insertErrorCount, stats.rowCount)
 			}
 		}
 	}
@@ -571,6 +605,10 @@ func (h *MetaCommandHandler) formatCopyResult(stats *copyStats) string {
 		summary += fmt.Sprintf(" (skipped %d rows)", stats.skippedRows)
 	}
 
+	if stats.resumedRows > 0 {
+		summary += fmt.Sprintf(" (resumed after %d rows)", stats.resumedRows)
+	}
+
 	if stats.insertErrorCount > 0 {
 		summary += fmt.Sprintf(" with %d errors", stats.insertErrorCount)
 
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,7 +12,7 @@ import (
 )
 
 // executeCopyFromParquetPartitioned handles COPY FROM for partitioned Parquet datasets
-func (h *MetaCommandHandler) executeCopyFromParquetPartitioned(table string, columns []string, reader *parquet.PartitionedParquetReader, options map[string]string) interface{} {
+func (h *MetaCommandHandler) executeCopyFromParquetPartitioned(ctx context.Context, table string, columns []string, reader *parquet.PartitionedParquetReader, options map[string]string) interface{} {
 
 	// Get schema from partitioned dataset
 	parquetColumns, _ := reader.GetSchema()
@@ -88,6 +89,10 @@ func (h *MetaCommandHandler) executeCopyFromParquetPartitioned(table string, col
 	batch := make([]string, 0, batchSize)
 
 	for {
+		if ctx.Err() != nil {
+			return fmt.Sprintf("COPY cancelled after %d rows imported from partitioned dataset", processedRows)
+		}
+
 		// Read a batch of rows
 		rows, err := reader.ReadBatch(batchSize)
 		if err != nil {
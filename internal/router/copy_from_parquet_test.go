@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -68,7 +69,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		// Note: Since db.Session is a struct not an interface, we can't override ExecuteCQLQuery
 		// The test will use a nil gocql.Session which will return "not connected to database" errors
 		mockSession := &MockSessionForCopyFrom{
-			Session: db.Session{Session: nil}, // nil gocql.Session will fail all queries
+			Session: db.Session{}, // No driver session, so all queries fail
 		}
 
 		handler := &MetaCommandHandler{
@@ -80,7 +81,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 			"FORMAT": "parquet",
 		}
 
-		result := handler.executeCopyFromParquet("test_table", []string{}, testFile, options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{}, testFile, options)
 
 		// Since we have a nil session, all INSERTs will fail with "not connected to database"
 		// The result should show 0 imported rows with errors
@@ -114,7 +115,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 
 		// Test COPY FROM with selected columns
 		mockSession := &MockSessionForCopyFrom{
-			Session: db.Session{Session: nil},
+			Session: db.Session{},
 		}
 
 		handler := &MetaCommandHandler{
@@ -127,7 +128,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		}
 
 		// Only import id and name columns
-		result := handler.executeCopyFromParquet("test_table", []string{"id", "name"}, testFile, options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{"id", "name"}, testFile, options)
 
 		// Since we have a nil session, all INSERTs will fail
 		assert.Contains(t, result, "Imported 0 rows")
@@ -158,7 +159,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		require.NoError(t, err)
 
 		mockSession := &MockSessionForCopyFrom{
-			Session: db.Session{Session: nil},
+			Session: db.Session{},
 		}
 
 		handler := &MetaCommandHandler{
@@ -171,7 +172,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 			"SKIPROWS": "2",
 		}
 
-		result := handler.executeCopyFromParquet("test_table", []string{}, testFile, options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{}, testFile, options)
 
 		// Should try to import 3 rows (5 total - 2 skipped), but all fail due to nil session
 		t.Logf("Skip rows test result: %v", result)
@@ -202,7 +203,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		require.NoError(t, err)
 
 		mockSession := &MockSessionForCopyFrom{
-			Session: db.Session{Session: nil},
+			Session: db.Session{},
 		}
 
 		handler := &MetaCommandHandler{
@@ -215,7 +216,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 			"MAXROWS": "5",
 		}
 
-		result := handler.executeCopyFromParquet("test_table", []string{}, testFile, options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{}, testFile, options)
 
 		// Should try to import 5 rows (MAXROWS limit), but all fail due to nil session
 		assert.Contains(t, result, "Imported 0 rows")
@@ -243,7 +244,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		require.NoError(t, err)
 
 		mockSession := &MockSessionForCopyFrom{
-			Session:     db.Session{Session: nil},
+			Session:     db.Session{},
 			failInserts: true, // All inserts will fail
 		}
 
@@ -257,7 +258,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 			"MAXINSERTERRORS": "3",
 		}
 
-		result := handler.executeCopyFromParquet("test_table", []string{}, testFile, options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{}, testFile, options)
 
 		// Should abort after 3 errors
 		assert.Contains(t, result, "aborted after 3 insert errors")
@@ -282,7 +283,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		require.NoError(t, err)
 
 		mockSession := &MockSessionForCopyFrom{
-			Session: db.Session{Session: nil},
+			Session: db.Session{},
 		}
 
 		handler := &MetaCommandHandler{
@@ -293,14 +294,14 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 		options := map[string]string{"FORMAT": "parquet"}
 
 		// Try to import non-existent column
-		result := handler.executeCopyFromParquet("test_table", []string{"id", "nonexistent"}, testFile, options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{"id", "nonexistent"}, testFile, options)
 
 		assert.Contains(t, result, "column 'nonexistent' not found in Parquet file")
 	})
 
 	t.Run("COPY FROM rejects STDIN", func(t *testing.T) {
 		mockSession := &MockSessionForCopyFrom{
-			Session: db.Session{Session: nil},
+			Session: db.Session{},
 		}
 
 		handler := &MetaCommandHandler{
@@ -310,7 +311,7 @@ func TestExecuteCopyFromParquet(t *testing.T) {
 
 		options := map[string]string{"FORMAT": "parquet"}
 
-		result := handler.executeCopyFromParquet("test_table", []string{}, "STDIN", options)
+		result := handler.executeCopyFromParquet(context.Background(), "test_table", []string{}, "STDIN", options)
 
 		assert.Contains(t, result, "COPY FROM STDIN is not supported for Parquet format")
 	})
@@ -338,7 +339,7 @@ func TestHandleCopyWithParquetFrom(t *testing.T) {
 	require.NoError(t, err)
 
 	mockSession := &MockSessionForCopyFrom{
-		Session: db.Session{Session: nil}, // nil gocql.Session
+		Session: db.Session{}, // No driver session
 	}
 
 	handler := &MetaCommandHandler{
@@ -349,7 +350,7 @@ func TestHandleCopyWithParquetFrom(t *testing.T) {
 	t.Run("COPY FROM with FORMAT=PARQUET", func(t *testing.T) {
 		command := fmt.Sprintf("COPY test_table FROM '%s' WITH FORMAT='PARQUET'", testFile)
 
-		result := handler.handleCopy(command)
+		result := handler.handleCopy(context.Background(), command)
 
 		// Since we have a nil session, the INSERT will fail
 		assert.Contains(t, result, "Imported 0 rows from Parquet file")
@@ -364,7 +365,7 @@ func TestHandleCopyWithParquetFrom(t *testing.T) {
 
 		command := fmt.Sprintf("COPY test_table FROM '%s'", csvFile)
 
-		result := handler.handleCopy(command)
+		result := handler.handleCopy(context.Background(), command)
 
 		// Should try to use CSV format and likely get an error due to mock session
 		// but not a Parquet-specific error
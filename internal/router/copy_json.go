@@ -0,0 +1,505 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/logger"
+	"github.com/axonops/cqlai/internal/parquet"
+)
+
+// maxJSONLineSize is the longest NDJSON line COPY FROM accepts
+const maxJSONLineSize = 64 * 1024 * 1024
+
+// normalizeCopyFormat maps the JSON Lines aliases onto the json format
+func normalizeCopyFormat(format string) string {
+	switch format {
+	case "ndjson", "jsonl":
+		return "json"
+	default:
+		return format
+	}
+}
+
+// jsonExportSink writes exported pages as JSON Lines, one object per row
+type jsonExportSink struct {
+	file    io.WriteCloser
+	counter *countingWriter
+	writer  *bufio.Writer
+}
+
+// newJSONExportSink wraps an output file in a JSON Lines writer that tracks its byte offset
+func newJSONExportSink(file io.WriteCloser, offset int64) *jsonExportSink {
+	counter := &countingWriter{w: file, n: offset}
+	return &jsonExportSink{
+		file:    file,
+		counter: counter,
+		writer:  bufio.NewWriter(counter),
+	}
+}
+
+// WritePage writes one line per row with keys in column order
+func (s *jsonExportSink) WritePage(page *db.TokenRangePage) error {
+	for _, row := range page.Rows {
+		line, err := db.MarshalJSONRow(page.ColumnNames, row)
+		if err != nil {
+			return err
+		}
+		if _, err := s.writer.Write(line); err != nil {
+			return err
+		}
+		if err := s.writer.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return s.writer.Flush()
+}
+
+// Offset returns the number of bytes flushed to the output so far
+func (s *jsonExportSink) Offset() int64 {
+	return s.counter.n
+}
+
+// Close flushes buffered rows and closes the output file
+func (s *jsonExportSink) Close() error {
+	err := s.writer.Flush()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// executeCopyToJSON exports a query with a single sequential scan as JSON Lines.
+// It is used when the export can't be split into token ranges, e.g. with LIMIT.
+func (h *MetaCommandHandler) executeCopyToJSON(ctx context.Context, table string, columns []string, filename string, options map[string]string) interface{} {
+	var query string
+	if len(columns) > 0 {
+		query = fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), table)
+	} else {
+		query = fmt.Sprintf("SELECT * FROM %s", table)
+	}
+	if limit := options["LIMIT"]; limit != "" {
+		query += " LIMIT " + limit
+	}
+
+	isStdout := strings.ToUpper(filename) == "STDOUT"
+	var writer io.WriteCloser
+	if isStdout {
+		writer = nopCloser{os.Stdout}
+	} else {
+		var err error
+		writer, err = parquet.CreateWriter(context.Background(), filename)
+		if err != nil {
+			return fmt.Sprintf("Error creating file: %v", err)
+		}
+	}
+	sink := newJSONExportSink(writer, 0)
+
+	result := h.session.ExecuteStreamingQueryContext(ctx, query)
+	switch v := result.(type) {
+	case db.StreamingQueryResult:
+		defer func() { _ = v.Close() }()
+
+		rowCount := 0
+		page := &db.TokenRangePage{ColumnNames: v.ColumnNames}
+		for ctx.Err() == nil {
+			row := make(map[string]interface{})
+			if ok, _ := v.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.MapScan(row) }); !ok {
+				break
+			}
+			page.Rows = append(page.Rows, row)
+			if len(page.Rows) >= 1000 {
+				if err := sink.WritePage(page); err != nil {
+					_ = sink.Close()
+					return fmt.Sprintf("Error writing row: %v", err)
+				}
+				rowCount += len(page.Rows)
+				page.Rows = page.Rows[:0]
+			}
+		}
+		if err := sink.WritePage(page); err != nil {
+			_ = sink.Close()
+			return fmt.Sprintf("Error writing row: %v", err)
+		}
+		rowCount += len(page.Rows)
+
+		if ctx.Err() != nil {
+			_ = sink.Close()
+			return fmt.Sprintf("COPY cancelled after %d rows exported to %s", rowCount, filename)
+		}
+		if err := v.Iterator.Close(); err != nil {
+			_ = sink.Close()
+			return fmt.Sprintf("Error during query execution: %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			return fmt.Sprintf("Error closing file: %v", err)
+		}
+
+		if isStdout {
+			return nil
+		}
+		return fmt.Sprintf("Exported %d rows to %s (JSON Lines format)", rowCount, filename)
+	case error:
+		_ = sink.Close()
+		return fmt.Sprintf("Error executing query: %v", v)
+	default:
+		_ = sink.Close()
+		return fmt.Sprintf("Unexpected result type for JSON export: %T", result)
+	}
+}
+
+// jsonColumnTypes loads the CQL types of a table's columns from the schema cache
+func (h *MetaCommandHandler) jsonColumnTypes(keyspace, table string) (map[string]*db.CQLTypeInfo, error) {
+	cache := h.session.GetSchemaCache()
+	if cache == nil {
+		return nil, fmt.Errorf("schema cache is not available")
+	}
+
+	columns, err := cache.GetTableColumns(keyspace, table)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]*db.CQLTypeInfo, len(columns))
+	for _, col := range columns {
+		typeInfo, err := db.ParseCQLType(col.DataType)
+		if err != nil {
+			// Unparseable types (e.g. vectors reported as custom) are bound without coercion
+			logger.DebugfToFile("CopyFromJSON", "Cannot parse type %s of column %s: %v", col.DataType, col.Name, err)
+		}
+		types[col.Name] = typeInfo
+	}
+	return types, nil
+}
+
+// jsonRecordReader yields JSON objects from either a JSON Lines file or a single JSON array
+type jsonRecordReader struct {
+	scanner *bufio.Scanner
+	decoder *json.Decoder
+}
+
+// newJSONRecordReader detects whether the input is a JSON array or JSON Lines
+func newJSONRecordReader(r io.Reader) (*jsonRecordReader, error) {
+	buffered := bufio.NewReader(r)
+	for {
+		b, err := buffered.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if b[0] != ' ' && b[0] != '\t' && b[0] != '\r' && b[0] != '\n' {
+			if b[0] == '[' {
+				decoder := json.NewDecoder(buffered)
+				decoder.UseNumber()
+				if _, err := decoder.Token(); err != nil {
+					return nil, err
+				}
+				return &jsonRecordReader{decoder: decoder}, nil
+			}
+			break
+		}
+		_, _ = buffered.ReadByte()
+	}
+
+	scanner := bufio.NewScanner(buffered)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLineSize)
+	return &jsonRecordReader{scanner: scanner}, nil
+}
+
+// Next returns the next record. Blank lines are skipped; a malformed line returns an error
+// without stopping the reader, so the caller can count it as a parse error and continue.
+func (r *jsonRecordReader) Next() (map[string]interface{}, error) {
+	var raw interface{}
+
+	if r.decoder != nil {
+		if !r.decoder.More() {
+			return nil, io.EOF
+		}
+		if err := r.decoder.Decode(&raw); err != nil {
+			// A syntax error inside an array can't be skipped, so stop reading
+			return nil, fmt.Errorf("%w: %v", io.ErrUnexpectedEOF, err)
+		}
+	} else {
+		for {
+			if !r.scanner.Scan() {
+				if err := r.scanner.Err(); err != nil {
+					return nil, fmt.Errorf("%w: %v", io.ErrUnexpectedEOF, err)
+				}
+				return nil, io.EOF
+			}
+			line := bytes.TrimSpace(r.scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			decoder := json.NewDecoder(bytes.NewReader(line))
+			decoder.UseNumber()
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("invalid JSON: %v", err)
+			}
+			break
+		}
+	}
+
+	record, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object, got %T", raw)
+	}
+	return record, nil
+}
+
+// buildJSONInsert converts a JSON record into an INSERT of the keys present in it. Keys that
+// are absent are left unset rather than written as null, so no tombstones are created.
+func buildJSONInsert(table string, record map[string]interface{}, columns []string, types map[string]*db.CQLTypeInfo, keyspace string, registry *db.UDTRegistry) (batchEntry, error) {
+	var keys []string
+	if len(columns) > 0 {
+		for _, col := range columns {
+			if _, ok := record[col]; ok {
+				keys = append(keys, col)
+			}
+		}
+	} else {
+		for key := range record {
+			if _, ok := types[key]; !ok {
+				return batchEntry{}, fmt.Errorf("unknown column %q", key)
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	if len(keys) == 0 {
+		return batchEntry{}, fmt.Errorf("record has none of the imported columns")
+	}
+
+	quoted := make([]string, len(keys))
+	placeholders := make([]string, len(keys))
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, err := db.CoerceJSONValue(record[key], types[key], keyspace, registry)
+		if err != nil {
+			return batchEntry{}, fmt.Errorf("column %s: %v", key, err)
+		}
+		quoted[i] = db.QuoteIdentifier(key)
+		placeholders[i] = "?"
+		values[i] = value
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+	return batchEntry{query: query, values: values}, nil
+}
+
+// executeCopyFromJSON imports JSON Lines (or a JSON array of objects) into a table.
+// Values are converted to the column types recorded in the schema cache before binding.
+func (h *MetaCommandHandler) executeCopyFromJSON(ctx context.Context, table string, columns []string, filename string, options map[string]string) interface{} {
+	keyspace, tableName := h.resolveTableName(table)
+	if keyspace == "" {
+		return "No keyspace selected. Use a keyspace-qualified table name or USE <keyspace> first."
+	}
+	types, err := h.jsonColumnTypes(keyspace, tableName)
+	if err != nil {
+		return fmt.Sprintf("Error loading schema for %s.%s: %v", keyspace, tableName, err)
+	}
+	for _, col := range columns {
+		if _, ok := types[col]; !ok {
+			return fmt.Sprintf("Column %s does not exist in %s.%s", col, keyspace, tableName)
+		}
+	}
+	registry := h.session.GetUDTRegistry()
+	qualifiedTable := db.QuoteIdentifier(keyspace) + "." + db.QuoteIdentifier(tableName)
+
+	var reader io.Reader
+	isStdin := strings.ToUpper(filename) == "STDIN"
+	if isStdin {
+		reader = os.Stdin
+	} else {
+		file, err := os.Open(filepath.Clean(filename)) // #nosec G304 - file path is user input but cleaned
+		if err != nil {
+			return fmt.Sprintf("Error opening file: %v", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	records, err := newJSONRecordReader(reader)
+	if err != nil {
+		return fmt.Sprintf("Error reading JSON: %v", err)
+	}
+
+	chunkSize, _ := strconv.Atoi(options["CHUNKSIZE"])
+	maxRows, _ := strconv.Atoi(options["MAXROWS"])
+	skipRows, _ := strconv.Atoi(options["SKIPROWS"])
+	maxParseErrors, _ := strconv.Atoi(options["MAXPARSEERRORS"])
+	maxInsertErrors, _ := strconv.Atoi(options["MAXINSERTERRORS"])
+	maxBatchSize, _ := strconv.Atoi(options["MAXBATCHSIZE"])
+	maxRequests, _ := strconv.Atoi(options["MAXREQUESTS"])
+	if maxRequests < 1 {
+		maxRequests = 6
+	}
+	if maxBatchSize < 1 {
+		maxBatchSize = 20
+	}
+
+	skippedRows := 0
+	for skippedRows < skipRows {
+		if _, err := records.Next(); err == io.EOF || isFatalJSONError(err) {
+			break
+		}
+		skippedRows++
+	}
+
+	var checkpointer *importCheckpointer
+	var resumedRows int64
+	if isStdin && isResumeRequested(options) {
+		return "Error: RESUME requires an input file"
+	}
+	if !isStdin {
+		var resumeOffset int64
+		checkpointer, resumeOffset, err = prepareImportCheckpoint(table, columns, "json", filename, options)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		for resumedRows < resumeOffset {
+			if _, err := records.Next(); err == io.EOF || isFatalJSONError(err) {
+				break
+			}
+			resumedRows++
+		}
+	}
+	recordOffset := resumedRows
+
+	var rowCount int64
+	var insertErrorCount int64
+	batchChan := make(chan importBatch, maxRequests*2)
+	var wg sync.WaitGroup
+	for i := 0; i < maxRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchChan {
+				failed := h.executeBatchWithValues(ctx, batch.entries)
+				if ctx.Err() != nil {
+					continue // Cancelled batches stay out of the checkpoint so RESUME retries them
+				}
+				atomic.AddInt64(&insertErrorCount, int64(failed))
+				atomic.AddInt64(&rowCount, int64(len(batch.entries)-failed))
+				checkpointer.batchFinished(batch.seq, batch.endOffset, int64(len(batch.entries)-failed), int64(failed))
+			}
+		}()
+	}
+
+	stopWorkers := func() {
+		close(batchChan)
+		wg.Wait()
+	}
+
+	batch := make([]batchEntry, 0, maxBatchSize)
+	batchSeq := 0
+	processedRows := 0
+	parseErrorCount := 0
+	var parseErrors []string
+	lastProgress := int64(0)
+
+	for {
+		if ctx.Err() != nil {
+			stopWorkers()
+			checkpointer.save()
+			return checkpointer.cancelledMessage(atomic.LoadInt64(&rowCount), filename)
+		}
+		if maxRows != -1 && processedRows >= maxRows {
+			break
+		}
+
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		recordOffset++
+		if err == nil {
+			processedRows++
+			var entry batchEntry
+			entry, err = buildJSONInsert(qualifiedTable, record, columns, types, keyspace, registry)
+			if err == nil {
+				batch = append(batch, entry)
+			}
+		}
+		if err != nil {
+			parseErrorCount++
+			if len(parseErrors) < 5 {
+				parseErrors = append(parseErrors, fmt.Sprintf("Row %d: %v", recordOffset, err))
+			}
+			if isFatalJSONError(err) || (maxParseErrors != -1 && parseErrorCount > maxParseErrors) {
+				stopWorkers()
+				checkpointer.save()
+				return fmt.Sprintf("Too many parse errors. Imported %d rows, failed after %d parse errors\n  %s",
+					atomic.LoadInt64(&rowCount), parseErrorCount, strings.Join(parseErrors, "\n  "))
+			}
+			continue
+		}
+
+		if len(batch) >= maxBatchSize {
+			if maxInsertErrors != -1 && atomic.LoadInt64(&insertErrorCount) > int64(maxInsertErrors) {
+				stopWorkers()
+				checkpointer.save()
+				return fmt.Sprintf("Too many insert errors. Imported %d rows, failed after %d insert errors", atomic.LoadInt64(&rowCount), atomic.LoadInt64(&insertErrorCount))
+			}
+			batchChan <- importBatch{seq: batchSeq, endOffset: recordOffset, entries: batch}
+			batchSeq++
+			batch = make([]batchEntry, 0, maxBatchSize)
+		}
+
+		currentRows := atomic.LoadInt64(&rowCount)
+		if currentRows-lastProgress >= int64(chunkSize) && !isStdin {
+			fmt.Printf("\rImported %d rows...", currentRows)
+			lastProgress = currentRows
+		}
+	}
+
+	if len(batch) > 0 {
+		batchChan <- importBatch{seq: batchSeq, endOffset: recordOffset, entries: batch}
+	}
+	stopWorkers()
+	checkpointer.complete()
+
+	if lastProgress > 0 {
+		fmt.Println() // New line after progress updates
+	}
+
+	details := fmt.Sprintf("Imported %d rows from %s", atomic.LoadInt64(&rowCount), filename)
+	if skipRows > 0 {
+		details += fmt.Sprintf(" (skipped %d rows)", skippedRows)
+	}
+	if resumedRows > 0 {
+		details += fmt.Sprintf(" (resumed after %d rows)", resumedRows)
+	}
+	if parseErrorCount > 0 {
+		details += fmt.Sprintf(" (%d parse errors)", parseErrorCount)
+	}
+	if insertErrors := atomic.LoadInt64(&insertErrorCount); insertErrors > 0 {
+		details += fmt.Sprintf(" (%d insert errors)", insertErrors)
+	}
+	if len(parseErrors) > 0 {
+		details += "\n  " + strings.Join(parseErrors, "\n  ")
+	}
+	return details
+}
+
+// isFatalJSONError reports whether the record reader can't continue after err
+func isFatalJSONError(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCopyFormat(t *testing.T) {
+	assert.Equal(t, "json", normalizeCopyFormat("json"))
+	assert.Equal(t, "json", normalizeCopyFormat("ndjson"))
+	assert.Equal(t, "json", normalizeCopyFormat("jsonl"))
+	assert.Equal(t, "csv", normalizeCopyFormat("csv"))
+	assert.Equal(t, "", normalizeCopyFormat(""))
+}
+
+func readAllJSONRecords(t *testing.T, input string) ([]map[string]interface{}, []error) {
+	t.Helper()
+	reader, err := newJSONRecordReader(strings.NewReader(input))
+	require.NoError(t, err)
+
+	var records []map[string]interface{}
+	var errs []error
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+			if isFatalJSONError(err) {
+				break
+			}
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, errs
+}
+
+func TestJSONRecordReader(t *testing.T) {
+	t.Run("JSON Lines with blank and malformed lines", func(t *testing.T) {
+		records, errs := readAllJSONRecords(t, "{\"id\": 1}\n\n{bad json}\n{\"id\": 2}\n[1, 2]\n")
+		require.Len(t, records, 2)
+		assert.Equal(t, "1", records[0]["id"].(interface{ String() string }).String())
+		require.Len(t, errs, 2)
+		assert.False(t, isFatalJSONError(errs[0]))
+	})
+
+	t.Run("JSON array", func(t *testing.T) {
+		records, errs := readAllJSONRecords(t, "  [\n {\"id\": 1},\n {\"id\": 2}\n]")
+		assert.Len(t, records, 2)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("malformed JSON array stops reading", func(t *testing.T) {
+		records, errs := readAllJSONRecords(t, `[{"id": 1}, {"id": }, {"id": 3}]`)
+		assert.Len(t, records, 1)
+		require.Len(t, errs, 1)
+		assert.True(t, isFatalJSONError(errs[0]))
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		records, errs := readAllJSONRecords(t, "")
+		assert.Empty(t, records)
+		assert.Empty(t, errs)
+	})
+}
+
+func TestBuildJSONInsert(t *testing.T) {
+	types := map[string]*db.CQLTypeInfo{}
+	for name, typeStr := range map[string]string{"id": "int", "name": "text", "userId": "uuid"} {
+		typeInfo, err := db.ParseCQLType(typeStr)
+		require.NoError(t, err)
+		types[name] = typeInfo
+	}
+
+	records, _ := readAllJSONRecords(t, `{"name": "alice", "id": 1}`+"\n"+`{"id": 2, "nickname": "bob"}`)
+	require.Len(t, records, 2)
+
+	t.Run("all columns from record", func(t *testing.T) {
+		entry, err := buildJSONInsert("ks.users", records[0], nil, types, "ks", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO ks.users (id, name) VALUES (?, ?)", entry.query)
+		assert.Equal(t, []interface{}{int32(1), "alice"}, entry.values)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := buildJSONInsert("ks.users", records[1], nil, types, "ks", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("explicit columns ignore other keys and leave missing ones unset", func(t *testing.T) {
+		entry, err := buildJSONInsert("ks.users", records[1], []string{"id", "name"}, types, "ks", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO ks.users (id) VALUES (?)", entry.query)
+	})
+
+	t.Run("case sensitive column is quoted", func(t *testing.T) {
+		record := map[string]interface{}{"userId": "123e4567-e89b-12d3-a456-426614174000"}
+		entry, err := buildJSONInsert("ks.users", record, nil, types, "ks", nil)
+		require.NoError(t, err)
+		assert.Equal(t, `INSERT INTO ks.users ("userId") VALUES (?)`, entry.query)
+	})
+}
+
+func TestJSONExportSink(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "users.jsonl")
+	columns := &db.TokenRangePage{ColumnNames: []string{"name", "id"}}
+	sink, name, err := newExportSink(context.Background(), "json", output, map[string]string{}, -1, columns)
+	require.NoError(t, err)
+	assert.Contains(t, name, "JSON Lines")
+
+	page := &db.TokenRangePage{
+		ColumnNames: []string{"name", "id"},
+		Rows: []map[string]interface{}{
+			{"id": 1, "name": "alice"},
+			{"id": 2, "name": nil},
+		},
+	}
+	require.NoError(t, sink.WritePage(page))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"name\":\"alice\",\"id\":1}\n{\"name\":null,\"id\":2}\n", string(data))
+}
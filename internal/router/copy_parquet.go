@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -23,14 +24,14 @@ func isNullValue(val interface{}) bool {
 }
 
 // executeCopyToParquet executes COPY TO operation for Parquet format
-func (h *MetaCommandHandler) executeCopyToParquet(table string, columns []string, filename string, options map[string]string) interface{} {
+func (h *MetaCommandHandler) executeCopyToParquet(ctx context.Context, table string, columns []string, filename string, options map[string]string) interface{} {
 	logger.DebugfToFile("CopyToParquet", "Starting Parquet export for table: %s, filename: %s", table, filename)
 
 	// Check if partitioning is requested
 	partitionColumns := options["PARTITION"]
 	if partitionColumns != "" {
 		// Use partitioned writer for directory output
-		return h.executeCopyToParquetPartitioned(table, columns, filename, options)
+		return h.executeCopyToParquetPartitioned(ctx, table, columns, filename, options)
 	}
 
 	// Build SELECT query
@@ -75,13 +76,13 @@ func (h *MetaCommandHandler) executeCopyToParquet(table string, columns []string
 
 	// Execute query - use streaming for better UDT handling
 	// Streaming mode properly scans UDT data into maps instead of strings
-	result := h.session.ExecuteStreamingQuery(query)
+	result := h.session.ExecuteStreamingQueryContext(ctx, query)
 
 	// Handle streaming result (always use streaming for UDT support)
 	switch v := result.(type) {
 	case db.StreamingQueryResult:
 		// For streaming results, we need to iterate through the data
-		defer v.Iterator.Close()
+		defer func() { _ = v.Close() }()
 
 		// Get headers and column types from the streaming result
 		if len(v.Headers) == 0 {
@@ -156,8 +157,10 @@ func (h *MetaCommandHandler) executeCopyToParquet(table string, columns []string
 			}
 		}
 
-		for v.Iterator.Scan(scanDest...) {
-
+		for ctx.Err() == nil {
+			if ok, _ := v.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.Scan(scanDest...) }); !ok {
+				break
+			}
 			// Build row map from scanned values
 			cleanedRow := make(map[string]interface{})
 			for i := range cleanHeaders {
@@ -195,6 +198,9 @@ func (h *MetaCommandHandler) executeCopyToParquet(table string, columns []string
 			rowCount++
 		}
 
+		if ctx.Err() != nil {
+			return fmt.Sprintf("COPY cancelled after %d rows exported to %s", rowCount, filename)
+		}
 		if err := v.Iterator.Close(); err != nil {
 			return fmt.Sprintf("Error during query execution: %v", err)
 		}
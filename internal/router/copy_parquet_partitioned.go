@@ -1,18 +1,20 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"github.com/axonops/cqlai/internal/db"
 	"github.com/axonops/cqlai/internal/logger"
 	"github.com/axonops/cqlai/internal/parquet"
 )
 
 // executeCopyToParquetPartitioned executes COPY TO operation with partitioning
-func (h *MetaCommandHandler) executeCopyToParquetPartitioned(table string, columns []string, outputDir string, options map[string]string) interface{} {
+func (h *MetaCommandHandler) executeCopyToParquetPartitioned(ctx context.Context, table string, columns []string, outputDir string, options map[string]string) interface{} {
 	logger.DebugfToFile("CopyToParquetPartitioned", "Starting partitioned export for table: %s, outputDir: %s", table, outputDir)
 
 	// Parse partition columns
@@ -49,11 +51,11 @@ func (h *MetaCommandHandler) executeCopyToParquetPartitioned(table string, colum
 	}
 
 	// Execute query with streaming
-	result := h.session.ExecuteStreamingQuery(query)
+	result := h.session.ExecuteStreamingQueryContext(ctx, query)
 
 	switch v := result.(type) {
 	case db.StreamingQueryResult:
-		defer v.Iterator.Close()
+		defer func() { _ = v.Close() }()
 
 		if len(v.Headers) == 0 {
 			return "No columns found in result"
@@ -120,7 +122,11 @@ func (h *MetaCommandHandler) executeCopyToParquetPartitioned(table string, colum
 			scanDest[i] = new(interface{})
 		}
 
-		for v.Iterator.Scan(scanDest...) {
+		for ctx.Err() == nil {
+			if ok, _ := v.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.Scan(scanDest...) }); !ok {
+				break
+			}
+
 			// Convert scanned values to map
 			rowData := make(map[string]interface{})
 			for i, colName := range cleanHeaders {
@@ -160,6 +166,10 @@ func (h *MetaCommandHandler) executeCopyToParquetPartitioned(table string, colum
 			return fmt.Sprintf("Error flushing writer: %v", err)
 		}
 
+		if ctx.Err() != nil {
+			return fmt.Sprintf("COPY cancelled after %d rows exported to %s", rowCount, cleanPath)
+		}
+
 		// Get partition info for summary
 		partitionInfo := writer.GetPartitionInfo()
 		partitionCount := len(partitionInfo)
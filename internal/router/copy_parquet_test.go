@@ -54,7 +54,7 @@ func TestExecuteCopyToParquet(t *testing.T) {
 			"FORMAT": "parquet",
 		}
 
-		result := handler.executeCopyToParquet("users", []string{}, outputPath, options)
+		result := handler.executeCopyToParquet(context.Background(), "users", []string{}, outputPath, options)
 
 		// Check result type and message
 		t.Logf("Result: %v (type: %T)", result, result)
@@ -95,7 +95,7 @@ func TestExecuteCopyToParquet(t *testing.T) {
 			"FORMAT": "parquet",
 		}
 
-		result := handler.executeCopyToParquet("users", []string{"id", "name"}, outputPath, options)
+		result := handler.executeCopyToParquet(context.Background(), "users", []string{"id", "name"}, outputPath, options)
 
 		assert.Contains(t, result, "Exported 2 rows")
 
@@ -131,7 +131,7 @@ func TestExecuteCopyToParquet(t *testing.T) {
 					"COMPRESSION": compression,
 				}
 
-				result := handler.executeCopyToParquet("data_table", []string{}, outputPath, options)
+				result := handler.executeCopyToParquet(context.Background(), "data_table", []string{}, outputPath, options)
 
 				assert.Contains(t, result, "Exported 3 rows")
 
@@ -162,7 +162,7 @@ func TestExecuteCopyToParquet(t *testing.T) {
 			"FORMAT": "parquet",
 		}
 
-		result := handler.executeCopyToParquet("empty_table", []string{}, outputPath, options)
+		result := handler.executeCopyToParquet(context.Background(), "empty_table", []string{}, outputPath, options)
 
 		assert.Equal(t, "No data to export", result)
 
@@ -196,7 +196,7 @@ func TestHandleCopyWithParquet(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "format_test")
 		command := "COPY users TO '" + outputPath + "' WITH FORMAT='PARQUET'"
 
-		result := handler.handleCopy(command)
+		result := handler.handleCopy(context.Background(), command)
 
 		assert.Contains(t, result, "Exported 1 rows")
 		assert.Contains(t, result, "(Parquet format)")
@@ -211,7 +211,7 @@ func TestHandleCopyWithParquet(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "csv_test.csv")
 		command := "COPY users TO '" + outputPath + "'"
 
-		result := handler.handleCopy(command)
+		result := handler.handleCopy(context.Background(), command)
 
 		// Should use CSV format by default
 		assert.Contains(t, result, "Exported 1 rows")
@@ -0,0 +1,543 @@
+package router
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/logger"
+	"github.com/axonops/cqlai/internal/parquet"
+)
+
+// tokenRangeSplitsPerWorker controls how finely the ring is divided for a parallel export.
+// Having many more ranges than workers keeps every worker busy and makes a retry cheap.
+const tokenRangeSplitsPerWorker = 16
+
+// maxReportedFailedRanges limits how many failed ranges are listed in the COPY summary
+const maxReportedFailedRanges = 5
+
+// tokenRangeExportPlan describes a table that can be exported with parallel token range scans
+type tokenRangeExportPlan struct {
+	keyspace      string
+	table         string
+	partitionKeys []string
+}
+
+// tokenRangeFailure records a range that could not be exported after all retries
+type tokenRangeFailure struct {
+	tokenRange db.TokenRange
+	err        error
+}
+
+// exportSink receives pages of exported rows and writes them in the requested format
+type exportSink interface {
+	WritePage(page *db.TokenRangePage) error
+	Close() error
+}
+
+// resolveTableName splits a possibly keyspace-qualified table name, falling back to the current keyspace
+func (h *MetaCommandHandler) resolveTableName(table string) (string, string) {
+	parts := strings.SplitN(table, ".", 2)
+	if len(parts) == 2 {
+		return strings.Trim(parts[0], `"`), strings.Trim(parts[1], `"`)
+	}
+
+	keyspace := ""
+	if h.sessionManager != nil {
+		keyspace = h.sessionManager.CurrentKeyspace()
+	}
+	if keyspace == "" && h.session != nil {
+		keyspace = h.session.Keyspace()
+	}
+	return keyspace, strings.Trim(table, `"`)
+}
+
+// planTokenRangeExport checks whether a COPY TO can be split into parallel token range scans.
+// It returns false when the export should use a single sequential query instead.
+func (h *MetaCommandHandler) planTokenRangeExport(table string, options map[string]string) (*tokenRangeExportPlan, bool) {
+	if h.session == nil || options["LIMIT"] != "" || options["PARTITION"] != "" {
+		return nil, false
+	}
+
+	keyspace, tableName := h.resolveTableName(table)
+	if keyspace == "" {
+		return nil, false
+	}
+
+	partitionKeys, err := h.session.PartitionKeyColumns(keyspace, tableName)
+	if err != nil {
+		logger.DebugfToFile("CopyToTokenRange", "Falling back to sequential export: %v", err)
+		return nil, false
+	}
+
+	if !h.session.SupportsTokenRangeScan() {
+		logger.DebugToFile("CopyToTokenRange", "Partitioner is not Murmur3, falling back to sequential export")
+		return nil, false
+	}
+
+	return &tokenRangeExportPlan{
+		keyspace:      keyspace,
+		table:         tableName,
+		partitionKeys: partitionKeys,
+	}, true
+}
+
+// parseTokenOption parses a BEGINTOKEN/ENDTOKEN option value
+func parseTokenOption(value string, defaultToken int64) (int64, error) {
+	if value == "" {
+		return defaultToken, nil
+	}
+	token, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid token %q: %v", value, err)
+	}
+	return token, nil
+}
+
+// tokenRangePageResult is a page fetched by a worker, tagged with the range it belongs to
+type tokenRangePageResult struct {
+	rangeIndex int
+	page       *db.TokenRangePage
+}
+
+// executeCopyToTokenRanges exports a table by scanning token sub-ranges concurrently.
+// File exports record their progress in a checkpoint so RESUME=true can pick up an interrupted run.
+// Cancelling ctx stops the workers and saves the checkpoint.
+func (h *MetaCommandHandler) executeCopyToTokenRanges(ctx context.Context, plan *tokenRangeExportPlan, columns []string, filename string, format string, options map[string]string) interface{} {
+	maxRequests, _ := strconv.Atoi(options["MAXREQUESTS"])
+	if maxRequests < 1 {
+		maxRequests = 6
+	}
+	maxAttempts, _ := strconv.Atoi(options["MAXATTEMPTS"])
+	if maxAttempts < 1 {
+		maxAttempts = 5
+	}
+	pageSize, _ := strconv.Atoi(options["PAGESIZE"])
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+
+	isStdout := strings.ToUpper(filename) == "STDOUT"
+	resume := isResumeRequested(options)
+	if resume && isStdout {
+		return "Error: RESUME requires an output file"
+	}
+	if resume && format == "parquet" {
+		return "Error: RESUME is not supported for Parquet exports because the file is only written when the export completes"
+	}
+
+	// Parquet output can't be resumed, so only CSV file exports are checkpointed
+	useCheckpoint := !isStdout && format != "parquet"
+	tableName := plan.keyspace + "." + plan.table
+	cpPath := checkpointPath(filename, options)
+
+	var checkpoint *copyCheckpoint
+	if useCheckpoint && resume {
+		var err error
+		checkpoint, err = loadCopyCheckpoint(cpPath)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		if checkpoint != nil {
+			if err := checkpoint.validate("export", tableName, columns, format); err != nil {
+				return fmt.Sprintf("Error: cannot resume: %v (delete %s to start over)", err, cpPath)
+			}
+		} else {
+			logger.DebugfToFile("CopyToTokenRange", "No checkpoint at %s, starting from the beginning", cpPath)
+		}
+	}
+	resuming := checkpoint != nil
+
+	if !resuming {
+		beginToken, err := parseTokenOption(options["BEGINTOKEN"], db.MinToken)
+		if err != nil {
+			return fmt.Sprintf("Error: BEGINTOKEN: %v", err)
+		}
+		endToken, err := parseTokenOption(options["ENDTOKEN"], db.MaxToken)
+		if err != nil {
+			return fmt.Sprintf("Error: ENDTOKEN: %v", err)
+		}
+		if beginToken >= endToken {
+			return fmt.Sprintf("Error: BEGINTOKEN (%d) must be less than ENDTOKEN (%d)", beginToken, endToken)
+		}
+		ranges := db.SplitTokenRange(beginToken, endToken, maxRequests*tokenRangeSplitsPerWorker)
+		checkpoint = newExportCheckpoint(tableName, columns, format, filename, ranges)
+	}
+
+	query := db.BuildTokenRangeQuery(plan.keyspace, plan.table, columns, plan.partitionKeys)
+	resultColumns, err := h.session.TokenRangeColumns(ctx, query)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	resumeOffset := int64(-1)
+	if resuming {
+		resumeOffset = checkpoint.Bytes
+	}
+	sink, outputName, err := newExportSink(ctx, format, filename, options, resumeOffset, resultColumns)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	pending := checkpoint.pendingRanges()
+	logger.DebugfToFile("CopyToTokenRange", "Exporting %s with %d of %d ranges pending, %d workers: %s",
+		tableName, len(pending), len(checkpoint.Ranges), maxRequests, query)
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Workers only read the checkpoint's page states before the writer starts updating them
+	rangeChan := make(chan int, len(pending))
+	for _, idx := range pending {
+		rangeChan <- idx
+	}
+	close(rangeChan)
+
+	startStates := make(map[int][]byte, len(pending))
+	for _, idx := range pending {
+		startStates[idx] = checkpoint.Ranges[idx].PageState
+	}
+
+	pageChan := make(chan tokenRangePageResult, maxRequests*2)
+	var failuresMu sync.Mutex
+	var failures []tokenRangeFailure
+	var wg sync.WaitGroup
+
+	for i := 0; i < maxRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range rangeChan {
+				r := db.TokenRange{Start: checkpoint.Ranges[idx].Start, End: checkpoint.Ranges[idx].End}
+				if err := h.exportTokenRange(workerCtx, query, idx, r, startStates[idx], pageSize, maxAttempts, pageChan); err != nil {
+					failuresMu.Lock()
+					failures = append(failures, tokenRangeFailure{tokenRange: r, err: err})
+					failuresMu.Unlock()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(pageChan)
+	}()
+
+	// Pages are written from this goroutine only, so sinks and the checkpoint don't need locking
+	var rowCount int64
+	var writeErr error
+	committedBytes := checkpoint.Bytes
+	lastProgress := int64(0)
+	lastSave := time.Now()
+	saveCheckpoint := func() {
+		checkpoint.Bytes = committedBytes
+		if err := checkpoint.save(cpPath); err != nil {
+			logger.DebugfToFile("CopyToTokenRange", "Failed to save checkpoint %s: %v", cpPath, err)
+		}
+		lastSave = time.Now()
+	}
+
+	for result := range pageChan {
+		if writeErr != nil {
+			continue // Drain remaining pages so workers can exit
+		}
+		if err := sink.WritePage(result.page); err != nil {
+			writeErr = err
+			cancel()
+			continue
+		}
+		rowCount += int64(len(result.page.Rows))
+
+		state := &checkpoint.Ranges[result.rangeIndex]
+		state.PageState = result.page.PageState
+		state.Done = result.page.PageState == nil
+		checkpoint.Rows += int64(len(result.page.Rows))
+		if offsetSink, ok := sink.(interface{ Offset() int64 }); ok {
+			committedBytes = offsetSink.Offset()
+		}
+		if useCheckpoint && time.Since(lastSave) >= checkpointSaveInterval {
+			saveCheckpoint()
+		}
+
+		if !isStdout && rowCount-lastProgress >= 100000 {
+			fmt.Printf("\rExported %d rows...", rowCount)
+			lastProgress = rowCount
+		}
+	}
+	if !isStdout && lastProgress > 0 {
+		fmt.Println() // New line after progress updates
+	}
+
+	if err := sink.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+
+	cancelled := ctx.Err() != nil
+	if writeErr != nil || len(failures) > 0 || cancelled {
+		if useCheckpoint {
+			saveCheckpoint()
+		}
+	} else if useCheckpoint {
+		removeCopyCheckpoint(cpPath)
+	}
+
+	resumeHint := ""
+	if useCheckpoint {
+		resumeHint = fmt.Sprintf("\nProgress saved to %s, run the same COPY WITH RESUME=true to continue", cpPath)
+	}
+
+	if writeErr != nil {
+		return fmt.Sprintf("Error writing export: %v%s", writeErr, resumeHint)
+	}
+
+	if cancelled {
+		return fmt.Sprintf("COPY cancelled after %d rows exported to %s%s", rowCount, outputName, resumeHint)
+	}
+
+	if len(failures) > 0 {
+		details := fmt.Sprintf("Exported %d rows to %s, but %d of %d token ranges failed after %d attempts:",
+			rowCount, outputName, len(failures), len(checkpoint.Ranges), maxAttempts)
+		for i, f := range failures {
+			if i >= maxReportedFailedRanges {
+				details += fmt.Sprintf("\n  ... and %d more", len(failures)-maxReportedFailedRanges)
+				break
+			}
+			details += fmt.Sprintf("\n  %s: %v", f.tokenRange, f.err)
+		}
+		return details + resumeHint
+	}
+
+	if isStdout {
+		return nil
+	}
+	if resuming {
+		return fmt.Sprintf("Exported %d rows to %s (resumed %d of %d token ranges, %d rows in total)",
+			rowCount, outputName, len(pending), len(checkpoint.Ranges), checkpoint.Rows)
+	}
+	return fmt.Sprintf("Exported %d rows to %s (%d token ranges, %d workers)", rowCount, outputName, len(checkpoint.Ranges), maxRequests)
+}
+
+// exportTokenRange pages through one token range starting at pageState, retrying a failed page up
+// to maxAttempts times. Retries resume from the last completed page so rows are never written twice.
+func (h *MetaCommandHandler) exportTokenRange(ctx context.Context, query string, rangeIndex int, r db.TokenRange, pageState []byte, pageSize int, maxAttempts int, pages chan<- tokenRangePageResult) error {
+	attempts := 0
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		page, err := h.session.FetchTokenRangePage(ctx, query, r, pageSize, pageState)
+		if err != nil {
+			attempts++
+			logger.DebugfToFile("CopyToTokenRange", "Range %s failed (attempt %d/%d): %v", r, attempts, maxAttempts, err)
+			if attempts >= maxAttempts {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempts) * 500 * time.Millisecond):
+			}
+			continue
+		}
+
+		select {
+		case pages <- tokenRangePageResult{rangeIndex: rangeIndex, page: page}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if page.PageState == nil {
+			return nil
+		}
+		pageState = page.PageState
+	}
+}
+
+// newExportSink creates the writer for a token range export and returns the name to report.
+// The output, including a CSV header or the Parquet schema, is created from columns before any
+// rows arrive, so an empty table still produces a file. A resumeOffset of zero or more reopens
+// an existing CSV file and discards anything written after that offset, which removes rows the
+// checkpoint doesn't account for.
+func newExportSink(ctx context.Context, format string, filename string, options map[string]string, resumeOffset int64, columns *db.TokenRangePage) (exportSink, string, error) {
+	isStdout := strings.ToUpper(filename) == "STDOUT"
+
+	switch format {
+	case "parquet":
+		output := "-"
+		if !isStdout {
+			output = filepath.Clean(filename)
+			if !strings.HasSuffix(output, ".parquet") {
+				output += ".parquet"
+			}
+		}
+		sink, err := newParquetExportSink(output, options, columns)
+		if err != nil {
+			return nil, "", err
+		}
+		return sink, output + " (Parquet format)", nil
+	default:
+		// CSV and JSON Lines are both plain appendable text files
+		var writer io.WriteCloser
+		switch {
+		case isStdout:
+			writer = nopCloser{os.Stdout}
+		case resumeOffset >= 0:
+			file, err := openForResume(filename, resumeOffset)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to reopen file: %w", err)
+			}
+			writer = file
+		default:
+			var err error
+			writer, err = parquet.CreateWriter(ctx, filename)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to create file: %w", err)
+			}
+		}
+
+		if format == "json" {
+			return newJSONExportSink(writer, max(resumeOffset, 0)), filename + " (JSON Lines format)", nil
+		}
+
+		counter := &countingWriter{w: writer, n: max(resumeOffset, 0)}
+		csvWriter := csv.NewWriter(counter)
+		if delimiter := options["DELIMITER"]; delimiter != "" {
+			csvWriter.Comma = rune(delimiter[0])
+		}
+		// A resumed export already has its header
+		if strings.ToLower(options["HEADER"]) == "true" && resumeOffset <= 0 {
+			err := csvWriter.Write(columns.ColumnNames)
+			if err == nil {
+				csvWriter.Flush()
+				err = csvWriter.Error()
+			}
+			if err != nil {
+				_ = writer.Close()
+				return nil, "", fmt.Errorf("failed to write header: %w", err)
+			}
+		}
+		return &csvExportSink{
+			file:    writer,
+			counter: counter,
+			writer:  csvWriter,
+			nullVal: options["NULLVAL"],
+		}, filename, nil
+	}
+}
+
+// openForResume opens an existing export file truncated to offset, positioned for appending
+func openForResume(filename string, offset int64) (*os.File, error) {
+	file, err := os.OpenFile(filepath.Clean(filename), os.O_WRONLY, 0) // #nosec G304 - file path is user input but cleaned
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(offset); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// csvExportSink writes exported pages as CSV rows
+type csvExportSink struct {
+	file    io.WriteCloser
+	counter *countingWriter
+	writer  *csv.Writer
+	nullVal string
+}
+
+// WritePage writes one page of rows
+func (s *csvExportSink) WritePage(page *db.TokenRangePage) error {
+	for _, rowMap := range page.Rows {
+		row := make([]string, len(page.ColumnNames))
+		for i, colName := range page.ColumnNames {
+			if val, ok := rowMap[colName]; ok && val != nil {
+				row[i] = formatCSVValue(val)
+			} else {
+				row[i] = s.nullVal
+			}
+		}
+		if err := s.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// Offset returns the number of bytes flushed to the output so far
+func (s *csvExportSink) Offset() int64 {
+	return s.counter.n
+}
+
+// Close flushes the CSV writer and closes the output file
+func (s *csvExportSink) Close() error {
+	s.writer.Flush()
+	err := s.writer.Error()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// parquetExportSink writes exported pages to a Parquet file
+type parquetExportSink struct {
+	writer *parquet.ParquetCaptureWriter
+}
+
+// newParquetExportSink creates the Parquet writer with the schema of the exported columns
+func newParquetExportSink(output string, options map[string]string, columns *db.TokenRangePage) (*parquetExportSink, error) {
+	writerOptions := parquet.DefaultWriterOptions()
+	writerOptions.ChunkSize = 50000
+	if chunkSize := options["CHUNKSIZE"]; chunkSize != "" {
+		if size, err := parseSize(chunkSize); err == nil {
+			writerOptions.ChunkSize = size
+		}
+	}
+
+	writer, err := parquet.NewParquetCaptureWriterWithTypeInfo(output, columns.ColumnNames, columns.ColumnTypes, columns.ColumnTypeInfos, writerOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Parquet writer: %w", err)
+	}
+	if compression := options["COMPRESSION"]; compression != "" {
+		if err := writer.SetCompression(strings.ToLower(compression)); err != nil {
+			logger.DebugfToFile("CopyToTokenRange", "Failed to set compression %s: %v", compression, err)
+		}
+	}
+	return &parquetExportSink{writer: writer}, nil
+}
+
+// WritePage writes one page of rows
+func (s *parquetExportSink) WritePage(page *db.TokenRangePage) error {
+	return s.writer.WriteRows(page.Rows)
+}
+
+// Close finalises the Parquet file
+func (s *parquetExportSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+// describeOffline answers DESCRIBE from the schema snapshot of an offline session
+func (p *CommandParser) describeOffline(parts, upperParts []string) interface{} {
+	snapshot := p.session.GetSchemaCache().Snapshot()
+
+	switch upperParts[1] {
+	case "KEYSPACES":
+		return strings.Join(snapshot.KeyspaceNames(), "\n")
+	case "SCHEMA":
+		var statements []string
+		for i := range snapshot.Keyspaces {
+			statements = append(statements, db.FormatKeyspaceSchema(&snapshot.Keyspaces[i]))
+		}
+		return strings.Join(statements, "\n\n")
+	case "CLUSTER":
+		return "DESCRIBE CLUSTER is not available offline"
+	case "TABLES":
+		return p.describeOfflineNames(snapshot, "TABLE")
+	case "TYPES":
+		return p.describeOfflineNames(snapshot, "TYPE")
+	case "FUNCTIONS":
+		return p.describeOfflineNames(snapshot, "FUNCTION")
+	case "AGGREGATES":
+		return p.describeOfflineNames(snapshot, "AGGREGATE")
+	case "KEYSPACE":
+		if len(parts) < 3 {
+			return "Syntax error: DESCRIBE KEYSPACE requires a keyspace name"
+		}
+		ks := snapshot.Keyspace(offlineName(parts[2]))
+		if ks == nil {
+			return fmt.Sprintf("Keyspace '%s' not found", parts[2])
+		}
+		return db.FormatKeyspaceSchema(ks)
+	case "TABLE", "TYPE", "FUNCTION", "AGGREGATE", "INDEX":
+		if len(parts) < 3 {
+			return fmt.Sprintf("Syntax error: DESCRIBE %s requires a %s name", upperParts[1], strings.ToLower(upperParts[1]))
+		}
+		return p.describeOfflineObject(snapshot, upperParts[1], parts[2])
+	case "MATERIALIZED":
+		if len(upperParts) >= 3 && upperParts[2] == "VIEWS" {
+			return p.describeOfflineNames(snapshot, "VIEW")
+		}
+		if len(upperParts) >= 4 && upperParts[2] == "VIEW" {
+			return p.describeOfflineObject(snapshot, "VIEW", parts[3])
+		}
+		return "Syntax error: Expected DESCRIBE MATERIALIZED VIEW or DESCRIBE MATERIALIZED VIEWS"
+	default:
+		if ks := snapshot.Keyspace(offlineName(parts[1])); ks != nil {
+			return db.FormatKeyspaceSchema(ks)
+		}
+		return p.describeOfflineObject(snapshot, "TABLE", parts[1])
+	}
+}
+
+// describeOfflineNames lists the tables, types, functions, aggregates or views of the
+// current keyspace
+func (p *CommandParser) describeOfflineNames(snapshot *db.SchemaSnapshot, kind string) interface{} {
+	ks := snapshot.Keyspace(p.session.Keyspace())
+	if ks == nil {
+		return "No keyspace selected. Use 'USE keyspace_name' to select a keyspace."
+	}
+	names := ks.ObjectNames(kind)
+	if len(names) == 0 {
+		return fmt.Sprintf("No %ss found in keyspace %s", strings.ToLower(kind), ks.Name)
+	}
+	return strings.Join(names, "\n")
+}
+
+// describeOfflineObject describes a [keyspace.]name object of the snapshot
+func (p *CommandParser) describeOfflineObject(snapshot *db.SchemaSnapshot, kind, name string) interface{} {
+	keyspace := p.session.Keyspace()
+	object := name
+	if before, after, ok := strings.Cut(name, "."); ok {
+		keyspace, object = before, after
+	}
+	ks := snapshot.Keyspace(offlineName(keyspace))
+	if ks == nil {
+		if keyspace == "" {
+			return "No keyspace selected. Use 'USE keyspace_name' to select a keyspace."
+		}
+		return fmt.Sprintf("Keyspace '%s' not found", keyspace)
+	}
+	statement, err := ks.DescribeObject(kind, offlineName(object))
+	if err != nil {
+		return fmt.Sprintf("'%s' not found", name)
+	}
+	return statement
+}
+
+// offlineName turns a CQL identifier into a schema name: quoted identifiers keep
+// their case, others are lower-cased
+func offlineName(identifier string) string {
+	if strings.HasPrefix(identifier, `"`) {
+		return strings.Trim(identifier, `"`)
+	}
+	return strings.ToLower(strings.Trim(identifier, "'"))
+}
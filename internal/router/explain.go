@@ -0,0 +1,49 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+const explainUsage = "Usage: EXPLAIN <cql statement>"
+
+// handleExplain handles EXPLAIN <cql>, which lints a statement against the schema
+// without running it
+func (h *MetaCommandHandler) handleExplain(command string) interface{} {
+	stmt := strings.TrimSpace(trimCommandKeyword(command))
+	if stmt == "" {
+		return explainUsage
+	}
+	return h.session.AnalyzeQuery(stmt).String()
+}
+
+// handleLint handles LINT [WARN|CONFIRM|OFF]
+func (h *MetaCommandHandler) handleLint(command string) interface{} {
+	parts := strings.Fields(strings.TrimSuffix(strings.TrimSpace(command), ";"))
+
+	switch len(parts) {
+	case 1:
+		return describeLintMode(h.session.LintMode())
+	case 2:
+		if err := h.session.SetLintMode(parts[1]); err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return describeLintMode(h.session.LintMode())
+	default:
+		return "Usage: LINT [WARN|CONFIRM|OFF]"
+	}
+}
+
+// describeLintMode explains what a lint mode does
+func describeLintMode(mode string) string {
+	switch mode {
+	case db.LintConfirm:
+		return "Lint is CONFIRM (risky statements need confirmation)"
+	case db.LintOff:
+		return "Lint is OFF"
+	default:
+		return "Lint is WARN (anti-patterns are reported with the result)"
+	}
+}
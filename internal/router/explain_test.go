@@ -0,0 +1,28 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleExplain(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, explainUsage, handler.HandleMetaCommand("EXPLAIN"))
+	assert.Equal(t, "No anti-patterns found", handler.HandleMetaCommand("EXPLAIN SELECT * FROM system.local;"))
+	assert.Equal(t, "Table: app.users (not found in the schema)\nNo anti-patterns found",
+		handler.HandleMetaCommand("EXPLAIN SELECT * FROM app.users"))
+}
+
+func TestHandleLint(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "Lint is WARN (anti-patterns are reported with the result)", handler.HandleMetaCommand("LINT"))
+	assert.Equal(t, "Lint is CONFIRM (risky statements need confirmation)", handler.HandleMetaCommand("LINT confirm"))
+	assert.Equal(t, db.LintConfirm, handler.session.LintMode())
+	assert.Equal(t, "Lint is OFF", handler.HandleMetaCommand("LINT OFF;"))
+	assert.Contains(t, handler.HandleMetaCommand("LINT strict"), "unknown lint mode")
+	assert.Equal(t, "Usage: LINT [WARN|CONFIRM|OFF]", handler.HandleMetaCommand("LINT ON NOW"))
+}
@@ -7,6 +7,21 @@ import (
 	"github.com/axonops/cqlai/internal/db"
 )
 
+// textResult returns data, whose first row holds the column names, as a result table
+// of text columns
+func textResult(data [][]string) db.QueryResult {
+	types := make([]string, len(data[0]))
+	for i := range types {
+		types[i] = "text"
+	}
+	return db.QueryResult{
+		Data:        data,
+		Headers:     data[0],
+		ColumnTypes: types,
+		RowCount:    len(data) - 1,
+	}
+}
+
 // formatPrimaryKey formats partition and clustering keys for display
 func (p *CommandParser) formatPrimaryKey(partitionKeys []string, clusteringKeys []string) string {
 	if len(partitionKeys) == 0 {
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+// showIndexes handles SHOW INDEXES [[keyspace.]table], listing the secondary indexes
+// of a table or of the current keyspace
+func (h *MetaCommandHandler) showIndexes(name string) interface{} {
+	keyspace, table := h.session.Keyspace(), ""
+	if name != "" {
+		var ok bool
+		if keyspace, table, ok = db.SplitTableName(name, keyspace); !ok {
+			return "Error: no keyspace selected; use SHOW INDEXES keyspace.table or USE a keyspace"
+		}
+	} else if keyspace == "" {
+		return "Error: no keyspace selected; use SHOW INDEXES keyspace.table or USE a keyspace"
+	}
+
+	indexes, err := h.session.TableIndexes(keyspace, table)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(indexes) == 0 {
+		if table != "" {
+			return fmt.Sprintf("No indexes on %s.%s", keyspace, table)
+		}
+		return fmt.Sprintf("No indexes in keyspace %s", keyspace)
+	}
+	return formatIndexes(indexes)
+}
+
+// formatIndexes renders indexes as a result table
+func formatIndexes(indexes []db.TableIndex) db.QueryResult {
+	data := [][]string{{"table", "index", "type", "target", "options", "serves"}}
+	for _, index := range indexes {
+		data = append(data, []string{
+			index.Table, index.Name, index.Type(), index.Target(), index.IndexOptions(), index.Serves(),
+		})
+	}
+	return textResult(data)
+}
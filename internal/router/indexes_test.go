@@ -0,0 +1,31 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowIndexes(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Contains(t, handler.HandleMetaCommand("SHOW INDEXES"), "no keyspace selected")
+	assert.Equal(t, "Error: not connected to database", handler.HandleMetaCommand("SHOW INDEXES app.users;"))
+	assert.Equal(t, "Usage: SHOW INDEXES [[keyspace.]table]", handler.HandleMetaCommand("SHOW INDEXES a b"))
+}
+
+func TestFormatIndexes(t *testing.T) {
+	result := formatIndexes([]db.TableIndex{{
+		Table:      "users",
+		Name:       "users_email_idx",
+		Kind:       "CUSTOM",
+		Column:     "email",
+		ColumnType: "text",
+		Options:    map[string]string{"class_name": "StorageAttachedIndex", "target": "email", "case_sensitive": "false"},
+	}})
+
+	assert.Equal(t, 1, result.RowCount)
+	assert.Equal(t, []string{"table", "index", "type", "target", "options", "serves"}, result.Data[0])
+	assert.Equal(t, []string{"users", "users_email_idx", "SAI", "email", "case_sensitive=false", "email = (matches analyzed terms)"}, result.Data[1])
+}
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"strings"
+)
+
+const loginUsage = "Usage: LOGIN <username> [<password>]"
+
+// LoginCommand represents a parsed LOGIN command. The UI prompts for the password
+// when none was given, then re-authenticates the session as the new role.
+type LoginCommand struct {
+	Username string
+	Password string
+}
+
+// ParseLoginCommand parses LOGIN <username> [<password>] (exported for testing).
+// Either may be quoted; a quoted password can contain spaces.
+func ParseLoginCommand(input string) interface{} {
+	args := strings.TrimSpace(trimCommandKeyword(input))
+	if args == "" {
+		return loginUsage
+	}
+
+	username, password := args, ""
+	if idx := strings.IndexAny(args, " \t"); idx >= 0 {
+		username, password = args[:idx], strings.TrimSpace(args[idx+1:])
+	}
+	username = unquoteCredential(username)
+	if username == "" {
+		return loginUsage
+	}
+	if password != "" && !isQuoted(password) && strings.ContainsAny(password, " \t") {
+		return loginUsage
+	}
+	return &LoginCommand{Username: username, Password: unquoteCredential(password)}
+}
+
+// isQuoted reports whether s is wrapped in matching single or double quotes
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]
+}
+
+// unquoteCredential removes the quotes around a username or password. Doubled
+// quotes inside are unescaped as in CQL string literals.
+func unquoteCredential(s string) string {
+	if !isQuoted(s) {
+		return s
+	}
+	quote := s[:1]
+	return strings.ReplaceAll(s[1:len(s)-1], quote+quote, quote)
+}
+
+// RedactLoginPassword removes the password from a LOGIN command so it is not saved
+// in the history. Other commands are returned unchanged.
+func RedactLoginPassword(command string) string {
+	trimmed := strings.TrimSpace(command)
+	if !strings.EqualFold(firstWord(trimmed), "LOGIN") {
+		return command
+	}
+	// Keep only the username, also when the command does not parse
+	args := strings.Fields(trimCommandKeyword(trimmed))
+	if len(args) <= 1 {
+		return command
+	}
+	return "LOGIN " + args[0]
+}
+
+// firstWord returns the first whitespace-separated word of s
+func firstWord(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLoginCommand(t *testing.T) {
+	assert.Equal(t, &LoginCommand{Username: "alice"}, ParseLoginCommand("LOGIN alice"))
+	assert.Equal(t, &LoginCommand{Username: "alice", Password: "secret"}, ParseLoginCommand("login alice secret;"))
+	assert.Equal(t, &LoginCommand{Username: "alice", Password: "two words"}, ParseLoginCommand("LOGIN 'alice' 'two words'"))
+	assert.Equal(t, &LoginCommand{Username: "bob", Password: "it's"}, ParseLoginCommand(`LOGIN "bob" 'it''s'`))
+	assert.Equal(t, loginUsage, ParseLoginCommand("LOGIN"))
+	assert.Equal(t, loginUsage, ParseLoginCommand("LOGIN alice secret extra"))
+}
+
+func TestRedactLoginPassword(t *testing.T) {
+	assert.Equal(t, "LOGIN alice", RedactLoginPassword("LOGIN alice secret"))
+	assert.Equal(t, "LOGIN alice", RedactLoginPassword("login alice 'two words';"))
+	assert.Equal(t, "LOGIN alice", RedactLoginPassword("LOGIN alice"))
+	assert.Equal(t, "LOGIN alice", RedactLoginPassword("LOGIN alice secret extra"))
+	assert.Equal(t, "SELECT * FROM users;", RedactLoginPassword("SELECT * FROM users;"))
+	assert.Equal(t, "LOGINS", RedactLoginPassword("LOGINS"))
+}
+
+func TestProcessCommandLogin(t *testing.T) {
+	assert.Equal(t, "Not connected. Use CONNECT <profile> to connect to a cluster.",
+		ProcessCommand(context.Background(), "LOGIN alice", nil, nil))
+}
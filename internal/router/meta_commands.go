@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"github.com/axonops/cqlai/internal/db"
@@ -19,33 +22,42 @@ import (
 
 // MetaCommandHandler handles non-CQL meta commands
 type MetaCommandHandler struct {
-	session                  *db.Session
-	sessionManager           *session.Manager
-	expandMode               bool
-	captureFile              string
-	captureOutput            io.WriteCloser
-	captureFormat            string // "text", "json", "csv", or "parquet"
-	csvWriter                *csv.Writer
-	parquetWriter            *parquet.ParquetCaptureWriter
-	captureHeaders           []string // Store headers for parquet writer
-	partitionedWriter        *parquet.PartitionedParquetWriter // For partitioned Parquet capture
-	captureOptions           map[string]string // Capture options (compression, partition, etc.)
-	capturePartitionColumns  []string // Partition columns for capture
-	captureColumnTypes       []string // Column types for partitioned capture
+	session                 *db.Session
+	sessionManager          *session.Manager
+	expandMode              bool
+	vectorExpand            atomic.Bool // VECTOR EXPAND: show vectors in full; read by the table view while commands run
+	captureFile             string
+	captureOutput           io.WriteCloser
+	captureFormat           string // "text", "json", "csv", or "parquet"
+	csvWriter               *csv.Writer
+	parquetWriter           *parquet.ParquetCaptureWriter
+	captureHeaders          []string                          // Store headers for parquet writer
+	partitionedWriter       *parquet.PartitionedParquetWriter // For partitioned Parquet capture
+	captureOptions          map[string]string                 // Capture options (compression, partition, etc.)
+	capturePartitionColumns []string                          // Partition columns for capture
+	captureColumnTypes      []string                          // Column types for partitioned capture
+	splitStatements         StatementSplitter                 // Splits migration files for MIGRATE
 }
 
 // NewMetaCommandHandler creates a new meta command handler
 func NewMetaCommandHandler(session *db.Session, sessionMgr *session.Manager) *MetaCommandHandler {
 	return &MetaCommandHandler{
-		session:        session,
-		sessionManager: sessionMgr,
-		expandMode:     false,
-		captureFormat:  "text",
+		session:         session,
+		sessionManager:  sessionMgr,
+		expandMode:      false,
+		captureFormat:   "text",
+		splitStatements: statementSplitter,
 	}
 }
 
 // HandleMetaCommand processes meta commands that aren't CQL
 func (h *MetaCommandHandler) HandleMetaCommand(command string) interface{} {
+	return h.HandleMetaCommandContext(context.Background(), command)
+}
+
+// HandleMetaCommandContext processes meta commands that aren't CQL. Cancelling
+// ctx stops long-running commands such as SOURCE and COPY.
+func (h *MetaCommandHandler) HandleMetaCommandContext(ctx context.Context, command string) interface{} {
 	upperCommand := strings.ToUpper(strings.TrimSpace(command))
 	parts := strings.Fields(upperCommand)
 
@@ -56,24 +68,48 @@ func (h *MetaCommandHandler) HandleMetaCommand(command string) interface{} {
 	switch parts[0] {
 	case "CONSISTENCY":
 		return h.handleConsistency(command)
+	case "SERIAL":
+		return h.handleSerialConsistency(command)
 	case "SHOW":
-		return h.handleShow(command)
+		return h.handleShow(ctx, command)
 	case "TRACING":
 		return h.handleTracing(command)
 	case "PAGING":
 		return h.handlePaging(command)
+	case "TIMEOUT":
+		return h.handleTimeout(command)
+	case "RETRY":
+		return h.handleRetry(command)
+	case "EXPLAIN":
+		return h.handleExplain(command)
+	case "LINT":
+		return h.handleLint(command)
+	case "VECTOR":
+		return h.handleVector(ctx, command)
+	case "SCHEMA":
+		return h.handleSchema(command)
+	case "MIGRATE":
+		return h.handleMigrate(ctx, command)
 	case "AUTOFETCH":
 		return h.handleAutoFetch(command)
 	case "EXPAND":
 		return h.handleExpand(command)
 	case "SOURCE":
-		return h.handleSource(command)
+		return h.handleSource(ctx, command)
 	case "CAPTURE":
 		return h.handleCapture(command)
 	case "COPY":
-		return h.handleCopy(command)
+		return h.handleCopy(ctx, command)
 	case "HELP":
 		return h.handleHelp()
+	case "SET", "\\SET":
+		return h.handleSet(command)
+	case "UNSET", "\\UNSET":
+		return h.handleUnset(command)
+	case "PREPARE":
+		return h.handlePrepare(ctx, command)
+	case "EXECUTE":
+		return h.handleExecute(ctx, command)
 	default:
 		return fmt.Sprintf("Unknown meta command: %s", parts[0])
 	}
@@ -107,10 +143,60 @@ func (h *MetaCommandHandler) handleConsistency(command string) interface{} {
 	return "Usage: CONSISTENCY [level]\nValid levels: ANY, ONE, TWO, THREE, QUORUM, ALL, LOCAL_QUORUM, EACH_QUORUM, LOCAL_ONE"
 }
 
+// handleSerialConsistency handles SERIAL CONSISTENCY command
+func (h *MetaCommandHandler) handleSerialConsistency(command string) interface{} {
+	parts := strings.Fields(strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(command), ";")))
+
+	if len(parts) < 2 || parts[1] != "CONSISTENCY" {
+		return "Usage: SERIAL CONSISTENCY [level]\nValid levels: SERIAL, LOCAL_SERIAL"
+	}
+
+	if len(parts) == 2 {
+		// Show current serial consistency
+		return fmt.Sprintf("Current serial consistency level: %s", h.session.SerialConsistency())
+	}
+
+	// Handle both "SERIAL CONSISTENCY LOCAL_SERIAL" and "SERIAL CONSISTENCY LOCAL SERIAL"
+	level := strings.Join(parts[2:], "_")
+	if err := h.session.SetSerialConsistency(level); err != nil {
+		return fmt.Sprintf("Error setting serial consistency: %v", err)
+	}
+	return fmt.Sprintf("Serial consistency level set to %s", level)
+}
+
 // handleShow handles SHOW commands
-func (h *MetaCommandHandler) handleShow(command string) interface{} {
+func (h *MetaCommandHandler) handleShow(ctx context.Context, command string) interface{} {
 	upperCommand := strings.ToUpper(command)
 
+	if parts := strings.Fields(strings.TrimSuffix(command, ";")); len(parts) >= 2 && strings.EqualFold(parts[1], "PREPARED") {
+		if len(parts) > 3 {
+			return "Usage: SHOW PREPARED [name]"
+		}
+		name := ""
+		if len(parts) == 3 {
+			name = parts[2]
+		}
+		return h.showPrepared(name)
+	}
+
+	if parts := strings.Fields(strings.TrimSuffix(command, ";")); len(parts) >= 2 && strings.EqualFold(parts[1], "INDEXES") {
+		if len(parts) > 3 {
+			return "Usage: SHOW INDEXES [[keyspace.]table]"
+		}
+		name := ""
+		if len(parts) == 3 {
+			name = parts[2]
+		}
+		return h.showIndexes(name)
+	}
+
+	if parts := strings.Fields(strings.TrimSuffix(upperCommand, ";")); len(parts) >= 2 && parts[1] == "SCHEMA" {
+		if len(parts) != 3 || parts[2] != "VERSIONS" {
+			return "Usage: SHOW SCHEMA VERSIONS"
+		}
+		return h.showSchemaVersions(ctx)
+	}
+
 	if strings.Contains(upperCommand, "VERSION") {
 		// Show Cassandra version
 		iter := h.session.Query("SELECT release_version FROM system.local").Iter()
@@ -132,6 +218,18 @@ func (h *MetaCommandHandler) handleShow(command string) interface{} {
 			result := fmt.Sprintf("Connected to: %s\n", host)
 			result += fmt.Sprintf("Datacenter: %s\n", datacenter)
 			result += fmt.Sprintf("Rack: %s", rack)
+			if contactPoints := h.session.ContactPoints(); len(contactPoints) > 0 {
+				result += fmt.Sprintf("\nContact points: %s", strings.Join(contactPoints, ", "))
+			}
+			if localDC := h.session.LocalDC(); localDC != "" {
+				result += fmt.Sprintf("\nLocal datacenter: %s", localDC)
+			}
+			if coordinator := h.session.LastCoordinator(); coordinator != nil {
+				result += fmt.Sprintf("\nLast query coordinator: %s", coordinator.ConnectAddressAndPort())
+				if dc := coordinator.DataCenter(); dc != "" {
+					result += fmt.Sprintf(" (%s/%s)", dc, coordinator.Rack())
+				}
+			}
 			return result
 		}
 		_ = iter.Close()
@@ -146,14 +244,20 @@ func (h *MetaCommandHandler) handleShow(command string) interface{} {
 		}
 		result := fmt.Sprintf("Current keyspace: %s\n", currentKeyspace)
 		result += fmt.Sprintf("Consistency: %s\n", h.session.Consistency())
+		result += fmt.Sprintf("Serial consistency: %s\n", h.session.SerialConsistency())
 		result += fmt.Sprintf("Page size: %d\n", h.session.PageSize())
+		result += fmt.Sprintf("Request timeout: %s\n", db.FormatTimeout(h.session.RequestTimeout()))
+		result += fmt.Sprintf("Protocol version: %d\n", h.session.ProtocolVersion())
+		result += fmt.Sprintf("Compression: %s\n", h.session.Compression())
+		result += fmt.Sprintf("Retry policy: %s\n", h.session.RetrySettings().PolicyString())
+		result += fmt.Sprintf("Speculative execution: %s\n", h.session.RetrySettings().SpeculativeString())
 		result += fmt.Sprintf("Tracing: %v\n", h.session.Tracing())
 		result += fmt.Sprintf("Auto-fetch: %v\n", h.session.AutoFetch())
 		result += fmt.Sprintf("Expand mode: %v", h.expandMode)
 		return result
 	}
 
-	return "Usage: SHOW VERSION | SHOW HOST | SHOW SESSION"
+	return "Usage: SHOW VERSION | SHOW HOST | SHOW SESSION | SHOW PREPARED [name] | SHOW INDEXES [table] | SHOW SCHEMA VERSIONS"
 }
 
 // handleTracing handles TRACING command
@@ -192,6 +296,10 @@ func (h *MetaCommandHandler) handlePaging(command string) interface{} {
 		}
 		return fmt.Sprintf("Current page size: %d", pageSize)
 	case 2:
+		if strings.EqualFold(strings.TrimSuffix(parts[1], ";"), "STATE") {
+			return h.pagingState()
+		}
+
 		// Check if it's "PAGING OFF"
 		if strings.ToUpper(parts[1]) == "OFF" {
 			// Set to 0 to disable client-side paging (uses server defaults)
@@ -212,7 +320,49 @@ func (h *MetaCommandHandler) handlePaging(command string) interface{} {
 		h.session.SetPageSize(pageSize)
 		return fmt.Sprintf("Page size set to %d", pageSize)
 	default:
-		return "Usage: PAGING [size] | PAGING OFF"
+		return "Usage: PAGING [size] | PAGING OFF | PAGING STATE"
+	}
+}
+
+// pagingState shows the token that resumes the last result after the last row read
+func (h *MetaCommandHandler) pagingState() interface{} {
+	token, query, err := h.session.PagingStateToken()
+	if err != nil {
+		return fmt.Sprintf("No paging state: %v", err)
+	}
+	return fmt.Sprintf("Paging state: %s\nResume with: %s RESUME '%s';", token, query, token)
+}
+
+// handleTimeout handles TIMEOUT command for the per-request timeout
+func (h *MetaCommandHandler) handleTimeout(command string) interface{} {
+	parts := strings.Fields(strings.TrimSuffix(strings.TrimSpace(command), ";"))
+
+	switch len(parts) {
+	case 1:
+		timeout := h.session.RequestTimeout()
+		if timeout == 0 {
+			return "Request timeout disabled"
+		}
+		return fmt.Sprintf("Current request timeout: %s", db.FormatTimeout(timeout))
+	case 2:
+		var timeout time.Duration
+		if !strings.EqualFold(parts[1], "OFF") {
+			parsed, err := db.ParseTimeout(parts[1])
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err)
+			}
+			timeout = parsed
+		}
+
+		if err := h.session.SetRequestTimeout(timeout); err != nil {
+			return fmt.Sprintf("Error setting timeout: %v", err)
+		}
+		if timeout == 0 {
+			return "Request timeout disabled"
+		}
+		return fmt.Sprintf("Request timeout set to %s", db.FormatTimeout(timeout))
+	default:
+		return "Usage: TIMEOUT [seconds] | TIMEOUT OFF"
 	}
 }
 
@@ -270,7 +420,7 @@ func (h *MetaCommandHandler) handleExpand(command string) interface{} {
 }
 
 // handleSource handles SOURCE command to execute CQL from file
-func (h *MetaCommandHandler) handleSource(command string) interface{} {
+func (h *MetaCommandHandler) handleSource(ctx context.Context, command string) interface{} {
 	parts := strings.Fields(command)
 
 	if len(parts) < 2 {
@@ -301,15 +451,20 @@ func (h *MetaCommandHandler) handleSource(command string) interface{} {
 	results := []string{}
 	successCount := 0
 	errorCount := 0
+	cancelled := false
 
 	for _, stmt := range statements {
 		stmt = strings.TrimSpace(stmt)
 		if stmt == "" {
 			continue
 		}
+		if ctx.Err() != nil {
+			cancelled = true
+			break
+		}
 
 		// Execute the statement
-		result := ProcessCommand(stmt+";", h.session, h.sessionManager)
+		result := ProcessCommand(ctx, stmt+";", h.session, h.sessionManager)
 		logger.DebugfToFile("SOURCE", "Result type for '%s': %T", stmt, result)
 
 		// Check if it's an error
@@ -347,15 +502,15 @@ func (h *MetaCommandHandler) handleSource(command string) interface{} {
 					}
 				case db.StreamingQueryResult:
 					// For streaming results, we need to fetch all rows
-					defer v.Iterator.Close()
+					defer func() { _ = v.Close() }()
 
 					rows := [][]string{}
 					rawRows := []map[string]interface{}{}
 
 					// Fetch rows from iterator
-					for {
+					for ctx.Err() == nil {
 						row := make(map[string]interface{})
-						if !v.Iterator.MapScan(row) {
+						if ok, _ := v.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.MapScan(row) }); !ok {
 							break
 						}
 
@@ -398,6 +553,9 @@ func (h *MetaCommandHandler) handleSource(command string) interface{} {
 	}
 
 	summary := fmt.Sprintf("\nExecuted %d statements from %s", successCount+errorCount, filename)
+	if cancelled {
+		summary = fmt.Sprintf("\nSOURCE cancelled after %d statements from %s", successCount+errorCount, filename)
+	}
 	if errorCount > 0 {
 		summary += fmt.Sprintf(" (%d successful, %d failed)", successCount, errorCount)
 	}
@@ -418,7 +576,7 @@ type batchEntry struct {
 
 // executeBatchWithValues executes a batch of INSERT queries using prepared statements
 // and returns the number of errors
-func (h *MetaCommandHandler) executeBatchWithValues(entries []batchEntry) int {
+func (h *MetaCommandHandler) executeBatchWithValues(ctx context.Context, entries []batchEntry) int {
 	if len(entries) == 0 {
 		return 0
 	}
@@ -429,12 +587,12 @@ func (h *MetaCommandHandler) executeBatchWithValues(entries []batchEntry) int {
 		batch.Query(entry.query, entry.values...)
 	}
 
-	err := h.session.ExecuteBatch(batch)
+	err := h.session.ExecuteBatchContext(ctx, batch)
 	if err != nil {
 		// If batch fails, try individual queries to count actual errors
 		errors := 0
 		for _, entry := range entries {
-			if execErr := h.session.Query(entry.query, entry.values...).Exec(); execErr != nil {
+			if execErr := h.session.Query(entry.query, entry.values...).ExecContext(ctx); execErr != nil {
 				errors++
 			}
 		}
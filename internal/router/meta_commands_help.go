@@ -38,10 +38,33 @@ func (h *MetaCommandHandler) handleHelp() interface{} {
 		{"Session", "CONSISTENCY [level]", "Show/set consistency level"},
 		{"", "  ONE, QUORUM, ALL", "Common consistency levels"},
 		{"", "  LOCAL_ONE, LOCAL_QUORUM", "Datacenter-aware levels"},
+		{"", "SERIAL CONSISTENCY [level]", "Show/set LWT serial consistency"},
 		{"", "TRACING ON|OFF", "Enable/disable query tracing"},
 		{"", "PAGING [size]", "Set result page size"},
+		{"", "PAGING STATE", "Token to resume the last result with SELECT ... RESUME"},
+		{"", "TIMEOUT [seconds]|OFF", "Show/set the request timeout"},
+		{"", "... USING TIMEOUT <n>", "Time limit for one statement"},
+		{"", "RETRY [policy [n]]|OFF", "Show/set the retry policy"},
+		{"", "RETRY SPECULATIVE <n> <delay>", "Start extra executions of slow statements"},
+		{"", "EXPLAIN <cql>", "Check a statement for anti-patterns without running it"},
+		{"", "LINT [WARN|CONFIRM|OFF]", "Show/set query linting"},
 		{"", "AUTOFETCH ON|OFF", "Auto-fetch all pages without scroll pauses"},
 		{"", "EXPAND ON|OFF", "Toggle vertical output format"},
+		{"", "SET [name [=] value]", "List/set variables for :name and ? markers"},
+		{"", "UNSET name", "Remove a query variable"},
+		{"", "PREPARE name AS <cql>;", "Prepare a named statement"},
+		{"", "EXECUTE name (v1, ...)", "Execute a prepared statement"},
+		{"", "SHOW PREPARED [name]", "Show bind markers, routing key and columns"},
+		{"", "VECTOR SEARCH <t> <col> ...", "ANN query from a file or VALUE [...]"},
+		{"", "VECTOR QUERY <t> <col> ...", "Print the ANN query without running it"},
+		{"", "VECTOR EXPAND ON|OFF", "Show vectors in full in the table view"},
+		{"", "SCHEMA DIFF <src> <dst>", "Compare keyspaces, profiles or schema files"},
+		{"", "SCHEMA DIFF ... STATEMENTS", "Print the CQL that turns <src> into <dst>"},
+		{"", "SCHEMA SAVE '<file>' [ks ...]", "Save the schema to a JSON snapshot"},
+		{"", "SCHEMA LOAD '<file>'", "Use a saved schema; works offline without a connection"},
+		{"", "MIGRATE STATUS ['<dir>']", "Show applied and pending migrations"},
+		{"", "MIGRATE UP ['<dir>'] [TO v]", "Apply pending numbered .cql migrations"},
+		{"", "MIGRATE DOWN ['<dir>'] [TO v]", "Revert the last migration (or down to v)"},
 
 		// Output Control
 		{"─────────", "─────────", "─────────────"},
@@ -61,19 +84,28 @@ func (h *MetaCommandHandler) handleHelp() interface{} {
 		// Information
 		{"─────────", "─────────", "─────────────"},
 		{"Info", "SHOW VERSION", "Show Cassandra version"},
-		{"", "SHOW HOST", "Show connection details"},
+		{"", "SHOW HOST", "Connection and last coordinator"},
 		{"", "SHOW SESSION", "Display session settings"},
+		{"", "SHOW INDEXES [table]", "List 2i/SASI/SAI indexes and what they serve"},
+		{"", "SHOW SCHEMA VERSIONS", "Schema version of each node; spots disagreement"},
+		{"", "CONNECT [profile]", "Switch to a connection profile"},
+		{"", "DISCONNECT", "Close the current connection"},
+		{"", "LOGIN <user> [password]", "Re-authenticate as another role"},
 
 		// File Operations
 		{"─────────", "─────────", "─────────────"},
 		{"Files", "SOURCE 'file'", "Execute CQL from file"},
-		{"", "COPY <table> TO 'file'", "Export table data to CSV/Parquet"},
-		{"", "COPY <table> FROM 'file'", "Import CSV/Parquet to table"},
+		{"", "COPY <table> TO 'file'", "Export table data to CSV/JSON/Parquet"},
+		{"", "COPY <table> FROM 'file'", "Import CSV/JSON/Parquet to table"},
 		{"", "  WITH FORMAT='parquet'", "Use Apache Parquet format"},
+		{"", "  WITH FORMAT='json'", "JSON Lines (one object per row)"},
 		{"", "  WITH HEADER=true", "First row has column names"},
 		{"", "  WITH DELIMITER=','", "Field separator (CSV only)"},
 		{"", "  WITH MAXROWS=n", "Max rows to import (-1=all)"},
 		{"", "  WITH SKIPROWS=n", "Skip first n rows (CSV only)"},
+		{"", "  WITH MAXREQUESTS=n", "Parallel token ranges/workers"},
+		{"", "  WITH BEGINTOKEN/ENDTOKEN=t", "Export a token range only"},
+		{"", "  WITH RESUME=true", "Continue from last checkpoint"},
 
 		// Keyboard Shortcuts
 		{"─────────", "─────────", "─────────────"},
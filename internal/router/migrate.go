@@ -0,0 +1,187 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/logger"
+)
+
+// DefaultMigrationsDir is the directory MIGRATE reads when none is given
+const DefaultMigrationsDir = "migrations"
+
+const migrateUsage = `Usage:
+  MIGRATE STATUS ['<dir>']
+  MIGRATE UP ['<dir>'] [TO <version>]
+  MIGRATE DOWN ['<dir>'] [TO <version>]
+      Applies or reverts the numbered .cql files of <dir> (default: migrations),
+      e.g. 0001_create_users.cql or 0001_create_users.up.cql with
+      0001_create_users.down.cql. Applied versions are recorded in the
+      cqlai_migrations table of the current keyspace. DOWN without TO reverts
+      the last migration. Migrations can't contain USE; qualify table names
+      with their keyspace instead.`
+
+// handleMigrate handles MIGRATE STATUS|UP|DOWN ['<dir>'] [TO <version>]
+func (h *MetaCommandHandler) handleMigrate(ctx context.Context, command string) interface{} {
+	args := tokenizeArgs(strings.TrimSpace(trimCommandKeyword(command)))
+	if len(args) == 0 {
+		return migrateUsage
+	}
+	action := strings.ToUpper(args[0])
+	args = args[1:]
+
+	dir := DefaultMigrationsDir
+	if len(args) > 0 && !strings.EqualFold(args[0], "TO") {
+		dir = expandSchemaPath(unquoteArg(args[0]))
+		args = args[1:]
+	}
+	target := int64(-1)
+	switch {
+	case len(args) == 2 && strings.EqualFold(args[0], "TO") && action != "STATUS":
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Sprintf("Error: invalid migration version %s", args[1])
+		}
+		target = version
+	case len(args) != 0:
+		return migrateUsage
+	}
+
+	if action != "STATUS" && action != "UP" && action != "DOWN" {
+		return migrateUsage
+	}
+	if h.splitStatements == nil {
+		return "Error: MIGRATE is not available"
+	}
+	keyspace := h.session.Keyspace()
+	if keyspace == "" {
+		return "Error: no keyspace selected; USE the keyspace that keeps the cqlai_migrations table"
+	}
+
+	migrations, err := db.LoadMigrations(dir)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	// STATUS only reads: a keyspace without the table has nothing applied yet
+	hasTable := true
+	if action == "STATUS" {
+		hasTable, err = h.session.MigrationsTableExists(ctx, keyspace)
+	} else {
+		err = h.session.EnsureMigrationsTable(ctx, keyspace)
+	}
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	applied := map[int64]db.AppliedMigration{}
+	if hasTable {
+		if applied, err = h.session.AppliedMigrations(ctx, keyspace); err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+	}
+	statuses := db.MigrationStatuses(migrations, applied)
+
+	if action == "STATUS" {
+		return formatMigrationStatus(statuses)
+	}
+	if err := db.CheckMigrationChecksums(statuses); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	var run []db.Migration
+	if action == "UP" {
+		run = db.PendingMigrations(migrations, applied, target)
+	} else {
+		if run, err = db.RevertibleMigrations(migrations, applied, target); err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+	}
+	if len(run) == 0 {
+		if action == "UP" {
+			return "No pending migrations"
+		}
+		return "No migrations to revert"
+	}
+
+	return h.runMigrations(ctx, keyspace, run, action == "DOWN")
+}
+
+// runMigrations applies (or reverts) migrations in order and reports each of them.
+// It stops at the first failure; the migrations before it stay applied (or reverted).
+func (h *MetaCommandHandler) runMigrations(ctx context.Context, keyspace string, run []db.Migration, down bool) interface{} {
+	verb := "Applied"
+	if down {
+		verb = "Reverted"
+	}
+	defer h.refreshSchemaCache()
+
+	var lines []string
+	for _, m := range run {
+		file := m.UpFile
+		if down {
+			file = m.DownFile
+		}
+		statements, err := readMigration(file, h.splitStatements)
+		if err == nil {
+			start := time.Now()
+			err = h.session.ApplyMigration(ctx, keyspace, m, statements, down)
+			if err == nil {
+				lines = append(lines, fmt.Sprintf("%s %d %s (%d statements, %s)",
+					verb, m.Version, m.Name, len(statements), time.Since(start).Round(time.Millisecond)))
+				continue
+			}
+		}
+		if len(lines) > 0 {
+			return fmt.Sprintf("Error: %v\nCompleted before the failure:\n%s", err, strings.Join(lines, "\n"))
+		}
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readMigration reads the statements of a migration file
+func readMigration(file string, splitStatements StatementSplitter) ([]string, error) {
+	content, err := os.ReadFile(file) // #nosec G304 - Migration file of the user's directory
+	if err != nil {
+		return nil, err
+	}
+	statements, err := splitStatements(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return statements, nil
+}
+
+// refreshSchemaCache reloads the schema cache after migrations changed the schema,
+// unless the schema watcher already follows the changes
+func (h *MetaCommandHandler) refreshSchemaCache() {
+	if h.session.WatchingSchema() {
+		return
+	}
+	if cache := h.session.GetSchemaCache(); cache != nil {
+		if err := cache.Refresh(); err != nil {
+			logger.DebugfToFile("MIGRATE", "Failed to refresh schema cache: %v", err)
+		}
+	}
+}
+
+// formatMigrationStatus renders migration states as a result table
+func formatMigrationStatus(statuses []db.MigrationStatus) interface{} {
+	if len(statuses) == 0 {
+		return "No migrations found"
+	}
+
+	data := [][]string{{"version", "name", "state", "applied_at"}}
+	for _, status := range statuses {
+		appliedAt := ""
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		data = append(data, []string{strconv.FormatInt(status.Version, 10), status.Name, status.State, appliedAt})
+	}
+	return textResult(data)
+}
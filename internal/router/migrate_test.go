@@ -0,0 +1,46 @@
+package router
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateErrors(t *testing.T) {
+	handler := &MetaCommandHandler{session: db.NewOfflineSession(offlineSnapshot(), "")}
+
+	assert.Equal(t, migrateUsage, handler.HandleMetaCommand("MIGRATE"))
+	assert.Equal(t, migrateUsage, handler.HandleMetaCommand("MIGRATE SIDEWAYS"))
+	assert.Equal(t, migrateUsage, handler.HandleMetaCommand("MIGRATE STATUS TO 3"))
+	assert.Equal(t, migrateUsage, handler.HandleMetaCommand("MIGRATE UP 'dir' TO"))
+	assert.Equal(t, "Error: invalid migration version x", handler.HandleMetaCommand("MIGRATE UP TO x"))
+	assert.Equal(t, "Error: MIGRATE is not available", handler.HandleMetaCommand("MIGRATE UP"))
+
+	handler.splitStatements = func(text string) ([]string, error) { return strings.Split(text, ";"), nil }
+	assert.Contains(t, handler.HandleMetaCommand("MIGRATE STATUS;"), "Error: no keyspace selected")
+
+	handler.session = db.NewOfflineSession(offlineSnapshot(), "app")
+	result, ok := handler.HandleMetaCommand("MIGRATE UP '" + filepath.Join(t.TempDir(), "missing") + "'").(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(result, "Error: "), result)
+	assert.Contains(t, result, "missing")
+}
+
+func TestFormatMigrationStatus(t *testing.T) {
+	assert.Equal(t, "No migrations found", formatMigrationStatus(nil))
+
+	appliedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	result, ok := formatMigrationStatus([]db.MigrationStatus{
+		{Version: 1, Name: "create_users", State: db.MigrationApplied, AppliedAt: appliedAt},
+		{Version: 2, Name: "add_email", State: db.MigrationPending},
+	}).(db.QueryResult)
+	require.True(t, ok)
+	assert.Equal(t, 2, result.RowCount)
+	assert.Equal(t, []string{"1", "create_users", "applied", "2026-01-02 03:04:05"}, result.Data[1])
+	assert.Equal(t, []string{"2", "add_email", "pending", ""}, result.Data[2])
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlePagingState(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "No paging state: there is no paged result; run a SELECT first", handler.HandleMetaCommand("PAGING STATE"))
+	assert.Equal(t, "No paging state: there is no paged result; run a SELECT first", handler.HandleMetaCommand("paging state;"))
+	assert.Equal(t, "Usage: PAGING [size] | PAGING OFF | PAGING STATE", handler.HandleMetaCommand("PAGING STATE NOW"))
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+var (
+	prepareCommandPattern = regexp.MustCompile(`(?is)^PREPARE\s+([A-Za-z_][A-Za-z0-9_]*)\s+AS\s+(.+)$`)
+	executeCommandPattern = regexp.MustCompile(`(?is)^EXECUTE\s+([A-Za-z_][A-Za-z0-9_]*)\s*(?:\((.*)\))?$`)
+)
+
+// handlePrepare handles PREPARE name AS <statement>
+func (h *MetaCommandHandler) handlePrepare(ctx context.Context, command string) interface{} {
+	matches := prepareCommandPattern.FindStringSubmatch(strings.TrimSuffix(strings.TrimSpace(command), ";"))
+	if matches == nil {
+		return "Usage: PREPARE name AS <statement>\nExample: PREPARE by_id AS SELECT * FROM users WHERE id = ?"
+	}
+
+	prepared, err := h.session.Prepare(ctx, matches[1], strings.TrimSpace(matches[2]))
+	if err != nil {
+		return err
+	}
+
+	markers := make([]string, len(prepared.BindColumns))
+	for i, col := range prepared.BindColumns {
+		markers[i] = col.Name + " " + col.Type
+	}
+	if len(markers) == 0 {
+		return fmt.Sprintf("Prepared %s (no bind markers)", prepared.Name)
+	}
+	return fmt.Sprintf("Prepared %s (%d bind markers: %s)", prepared.Name, len(markers), strings.Join(markers, ", "))
+}
+
+// handleExecute handles EXECUTE name [(value, ...)]
+func (h *MetaCommandHandler) handleExecute(ctx context.Context, command string) interface{} {
+	matches := executeCommandPattern.FindStringSubmatch(strings.TrimSuffix(strings.TrimSpace(command), ";"))
+	if matches == nil {
+		return "Usage: EXECUTE name [(value, ...)]\nExample: EXECUTE by_id (42)"
+	}
+
+	literals, err := db.SplitCQLLiterals(matches[2])
+	if err != nil {
+		return fmt.Errorf("invalid EXECUTE values: %v", err)
+	}
+	return h.session.ExecutePreparedContext(ctx, matches[1], literals)
+}
+
+// showPrepared handles SHOW PREPARED [name]
+func (h *MetaCommandHandler) showPrepared(name string) interface{} {
+	statements := h.session.PreparedStatements()
+	if name != "" {
+		prepared, ok := h.session.PreparedStatement(name)
+		if !ok {
+			return fmt.Sprintf("No prepared statement named %s", name)
+		}
+		statements = []*db.PreparedStatement{prepared}
+	}
+	if len(statements) == 0 {
+		return "No prepared statements. Use PREPARE name AS <statement> to add one."
+	}
+
+	sections := make([]string, len(statements))
+	for i, prepared := range statements {
+		sections[i] = formatPreparedStatement(prepared)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// formatPreparedStatement renders the metadata of a prepared statement
+func formatPreparedStatement(prepared *db.PreparedStatement) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", prepared.Name, prepared.Query)
+	if prepared.Table != "" {
+		fmt.Fprintf(&sb, "\n  Table: %s.%s", prepared.Keyspace, prepared.Table)
+	}
+	fmt.Fprintf(&sb, "\n  Bind markers: %s", formatPreparedColumns(prepared.BindColumns))
+	if len(prepared.BindColumns) > 0 {
+		if routingKey := prepared.RoutingKeyColumns(); routingKey != nil {
+			fmt.Fprintf(&sb, "\n  Routing key: %s", strings.Join(routingKey, ", "))
+		} else {
+			sb.WriteString("\n  Routing key: none (partition key not fully bound)")
+		}
+	}
+	fmt.Fprintf(&sb, "\n  Result columns: %s", formatPreparedColumns(prepared.ResultColumns))
+	return sb.String()
+}
+
+func formatPreparedColumns(columns []db.PreparedColumn) string {
+	if len(columns) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = col.Name + " " + col.Type
+	}
+	return strings.Join(parts, ", ")
+}
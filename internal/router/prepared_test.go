@@ -0,0 +1,50 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPreparedStatement(t *testing.T) {
+	prepared := &db.PreparedStatement{
+		Name:     "by_id",
+		Query:    "SELECT id, name FROM users WHERE id = ?",
+		Keyspace: "app",
+		Table:    "users",
+		BindColumns: []db.PreparedColumn{
+			{Name: "id", Type: "int"},
+		},
+		ResultColumns: []db.PreparedColumn{
+			{Name: "id", Type: "int"},
+			{Name: "name", Type: "text"},
+		},
+		RoutingKey: []int{0},
+	}
+
+	assert.Equal(t, "by_id: SELECT id, name FROM users WHERE id = ?\n"+
+		"  Table: app.users\n"+
+		"  Bind markers: id int\n"+
+		"  Routing key: id\n"+
+		"  Result columns: id int, name text", formatPreparedStatement(prepared))
+
+	prepared.RoutingKey = nil
+	assert.Contains(t, formatPreparedStatement(prepared), "Routing key: none (partition key not fully bound)")
+}
+
+func TestPreparedCommandsWithoutStatements(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "No prepared statements. Use PREPARE name AS <statement> to add one.", handler.HandleMetaCommand("SHOW PREPARED"))
+	assert.Equal(t, "No prepared statement named by_id", handler.HandleMetaCommand("SHOW PREPARED by_id;"))
+	assert.Contains(t, handler.HandleMetaCommand("PREPARE by_id SELECT 1"), "Usage: PREPARE name AS <statement>")
+	assert.Contains(t, handler.HandleMetaCommand("EXECUTE"), "Usage: EXECUTE name")
+
+	result := handler.HandleMetaCommandContext(context.Background(), "EXECUTE by_id (42)")
+	assert.EqualError(t, result.(error), "no prepared statement named by_id, use PREPARE by_id AS <statement>")
+
+	result = handler.HandleMetaCommandContext(context.Background(), "EXECUTE by_id (42 43)")
+	assert.Contains(t, result.(error).Error(), "invalid EXECUTE values")
+}
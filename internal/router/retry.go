@@ -0,0 +1,70 @@
+package router
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+const retryUsage = "Usage: RETRY [SIMPLE|EXPONENTIAL|DOWNGRADING [retries] | OFF]\n" +
+	"       RETRY SPECULATIVE <attempts> <delay> | RETRY SPECULATIVE OFF"
+
+// handleRetry handles the RETRY command, which shows or changes how failed and slow
+// statements are retried
+func (h *MetaCommandHandler) handleRetry(command string) interface{} {
+	parts := strings.Fields(strings.TrimSuffix(strings.TrimSpace(command), ";"))
+	if h.session == nil {
+		return "Not connected to database"
+	}
+	settings := h.session.RetrySettings()
+
+	if len(parts) == 1 {
+		return fmt.Sprintf("Retry policy: %s\nSpeculative execution: %s\nRetries of the last statement: %d",
+			settings.PolicyString(), settings.SpeculativeString(), h.session.LastRetries())
+	}
+
+	if strings.EqualFold(parts[1], "SPECULATIVE") {
+		switch {
+		case len(parts) == 3 && strings.EqualFold(parts[2], "OFF"):
+			settings.SpeculativeAttempts, settings.SpeculativeDelay = 0, 0
+		case len(parts) == 4:
+			attempts, err := strconv.Atoi(parts[2])
+			if err != nil || attempts < 1 {
+				return fmt.Sprintf("Error: invalid number of speculative attempts %q", parts[2])
+			}
+			delay, err := db.ParseTimeout(parts[3])
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err)
+			}
+			settings.SpeculativeAttempts, settings.SpeculativeDelay = attempts, delay
+		default:
+			return retryUsage
+		}
+		if err := h.session.SetRetrySettings(settings); err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return fmt.Sprintf("Speculative execution: %s", h.session.RetrySettings().SpeculativeString())
+	}
+
+	if len(parts) > 3 {
+		return retryUsage
+	}
+	policy, err := db.ParseRetryPolicy(parts[1])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	settings.Policy, settings.NumRetries = policy, 0
+	if len(parts) == 3 {
+		retries, err := strconv.Atoi(parts[2])
+		if err != nil || retries < 1 || policy == db.RetryPolicyNone {
+			return retryUsage
+		}
+		settings.NumRetries = retries
+	}
+	if err := h.session.SetRetrySettings(settings); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("Retry policy: %s", h.session.RetrySettings().PolicyString())
+}
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleRetry(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "Retry policy: none\nSpeculative execution: OFF\nRetries of the last statement: 0",
+		handler.HandleMetaCommand("RETRY"))
+	assert.Equal(t, "Retry policy: simple, 3 retries", handler.HandleMetaCommand("RETRY SIMPLE"))
+	assert.Equal(t, "Retry policy: exponential, 5 retries, backoff 100ms to 10s",
+		handler.HandleMetaCommand("retry exponential 5;"))
+	assert.Equal(t, "Retry policy: downgrading consistency, 2 retries", handler.HandleMetaCommand("RETRY DOWNGRADING 2"))
+	assert.Equal(t, "Retry policy: none", handler.HandleMetaCommand("RETRY OFF"))
+
+	assert.Equal(t, "Speculative execution: 2 extra attempts, 150ms apart",
+		handler.HandleMetaCommand("RETRY SPECULATIVE 2 150ms"))
+	assert.Equal(t, "Speculative execution: OFF", handler.HandleMetaCommand("RETRY SPECULATIVE OFF"))
+
+	assert.Contains(t, handler.HandleMetaCommand("RETRY SOMETIMES"), "unknown retry policy")
+	assert.Contains(t, handler.HandleMetaCommand("RETRY SPECULATIVE 0 1s"), "invalid number of speculative attempts")
+	assert.Equal(t, retryUsage, handler.HandleMetaCommand("RETRY SIMPLE many"))
+	assert.Equal(t, retryUsage, handler.HandleMetaCommand("RETRY SPECULATIVE 2"))
+}
@@ -1,9 +1,12 @@
 package router
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"github.com/axonops/cqlai/internal/config"
 	"github.com/axonops/cqlai/internal/db"
 	"github.com/axonops/cqlai/internal/logger"
@@ -13,10 +16,20 @@ import (
 
 var metaHandler *MetaCommandHandler
 var sessionManager *session.Manager
+var statementSplitter StatementSplitter
 
-// InitRouter initializes the router with a session manager
-func InitRouter(mgr *session.Manager) {
+// StatementSplitter splits CQL text into statements
+type StatementSplitter func(text string) ([]string, error)
+
+// InitRouter initializes the router with a session manager and the splitter MIGRATE
+// reads migration files with. The splitter lives in the batch package, which imports
+// the router, so it is handed over here.
+func InitRouter(mgr *session.Manager, split StatementSplitter) {
 	sessionManager = mgr
+	statementSplitter = split
+	if metaHandler != nil {
+		metaHandler.splitStatements = split
+	}
 }
 
 // GetMetaHandler returns the current meta command handler
@@ -95,8 +108,65 @@ func stripComments(input string) string {
 	return strings.TrimSpace(result.String())
 }
 
-// ProcessCommand processes a user command.
-func ProcessCommand(command string, session *db.Session, sessionMgr *session.Manager) interface{} {
+// ProcessCommand processes a user command. Cancelling ctx stops a running
+// query, paging or COPY and returns a *db.CancelledError or cancellation message.
+func ProcessCommand(ctx context.Context, command string, session *db.Session, sessionMgr *session.Manager) interface{} {
+	// Warnings of the previous statement don't belong to this command
+	session.ResetLastWarnings()
+
+	if !supportsStatementTimeout(command) {
+		return processCommand(ctx, command, session, sessionMgr)
+	}
+
+	command, timeout, err := db.ExtractStatementTimeout(command)
+	if err != nil {
+		return err
+	}
+	if timeout == 0 {
+		return processCommand(ctx, command, session, sessionMgr)
+	}
+
+	logger.DebugfToFile("ProcessCommand", "Statement timeout %s", timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	result := processCommand(ctx, command, session, sessionMgr)
+	if streaming, ok := result.(db.StreamingQueryResult); ok {
+		// The remaining pages are fetched with ctx, so the deadline covers them too and
+		// is released with the result
+		streaming.OnClose(cancel)
+		return streaming
+	}
+	defer cancel()
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result
+	}
+	switch v := result.(type) {
+	case error:
+		return fmt.Errorf("statement timed out after %s", timeout)
+	case string:
+		// COPY reports how far it got and how to resume
+		if strings.HasPrefix(v, "COPY cancelled") {
+			return strings.Replace(v, "COPY cancelled", "COPY timed out", 1)
+		}
+	}
+	return result
+}
+
+// supportsStatementTimeout reports whether a command accepts a USING TIMEOUT clause
+func supportsStatementTimeout(command string) bool {
+	fields := strings.Fields(strings.ToUpper(command))
+	if len(fields) == 0 || !strings.Contains(strings.ToUpper(command), "TIMEOUT") {
+		return false
+	}
+	switch fields[0] {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "BEGIN", "COPY", "EXECUTE":
+		return true
+	}
+	return false
+}
+
+// processCommand processes a user command once any statement timeout has been applied
+func processCommand(ctx context.Context, command string, session *db.Session, sessionMgr *session.Manager) interface{} {
 	// Initialize meta handler if needed
 	if metaHandler == nil {
 		metaHandler = NewMetaCommandHandler(session, sessionMgr)
@@ -125,9 +195,24 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 	trimmedCommand := strings.TrimSuffix(strings.TrimSpace(command), ";")
 	upperCommand := strings.ToUpper(trimmedCommand)
 	isMetaCommand := false
-	metaCommands := []string{"DESCRIBE", "DESC", "CONSISTENCY", "OUTPUT", "PAGING", "AUTOFETCH", "TRACING", "SOURCE", "COPY", "SHOW", "EXPAND", "CAPTURE", "HELP", "SAVE"}
+	metaCommands := []string{"DESCRIBE", "DESC", "CONSISTENCY", "OUTPUT", "PAGING", "AUTOFETCH", "TRACING", "SOURCE", "COPY", "SHOW", "EXPAND", "CAPTURE", "HELP", "SAVE", "CONNECT", "DISCONNECT", "SERIAL", "SET", "UNSET", "\\SET", "\\UNSET", "PREPARE", "EXECUTE", "TIMEOUT", "RETRY", "LOGIN", "EXPLAIN", "LINT", "VECTOR", "SCHEMA", "MIGRATE"}
+
+	// A LOGIN password must not end up in the debug log
+	logger.DebugfToFile("ProcessCommand", "Called with: '%s', trimmed: '%s', upper: '%s'",
+		RedactLoginPassword(command), RedactLoginPassword(trimmedCommand), RedactLoginPassword(upperCommand))
+
+	// After DISCONNECT there is no session until the next CONNECT
+	if session == nil && !allowedWhileDisconnected(upperCommand) {
+		return "Not connected. Use CONNECT <profile> to connect to a cluster."
+	}
 
-	logger.DebugfToFile("ProcessCommand", "Called with: '%s', trimmed: '%s', upper: '%s'", command, trimmedCommand, upperCommand)
+	// An offline session only knows the schema of its snapshot
+	if session.IsOffline() && !allowedWhileOffline(upperCommand) {
+		return "Offline: working from a schema snapshot. Use CONNECT <profile> to run statements."
+	}
+	if session.IsOffline() && strings.HasPrefix(upperCommand, "USE ") {
+		return useOffline(trimmedCommand, session)
+	}
 
 	for _, meta := range metaCommands {
 		// Check for word boundary: command equals meta OR starts with "meta "
@@ -142,7 +227,9 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 	if (upperCommand == "SHOW" || strings.HasPrefix(upperCommand, "SHOW ")) &&
 		!strings.Contains(upperCommand, "VERSION") &&
 		!strings.Contains(upperCommand, "HOST") &&
-		!strings.Contains(upperCommand, "SESSION") {
+		!strings.Contains(upperCommand, "SESSION") &&
+		!strings.Contains(upperCommand, "PREPARED") &&
+		!strings.Contains(upperCommand, "INDEXES") {
 		// SHOW commands that aren't meta-commands should be treated as CQL
 		isMetaCommand = false
 	}
@@ -150,7 +237,7 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 	if isMetaCommand {
 		// Parse as meta-command
 		logger.DebugToFile("ProcessCommand", "Routing to parseMetaCommand")
-		return parseMetaCommand(command, session, sessionMgr)
+		return parseMetaCommand(ctx, command, session, sessionMgr)
 	} else {
 		// Check if we need to transform SELECT to SELECT JSON
 		if sessionManager != nil && sessionManager.GetOutputFormat() == config.OutputFormatJSON {
@@ -160,15 +247,16 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 				modifiedCommand := db.ConvertToJSONQuery(command)
 				if modifiedCommand != command {
 					logger.DebugfToFile("ProcessCommand", "Transformed query to: %s", modifiedCommand)
-					return session.ExecuteCQLQuery(modifiedCommand)
+					return executeQuery(ctx, modifiedCommand, session, sessionMgr)
 				}
 			}
 		}
 		// Execute as regular CQL query
 		logger.DebugToFile("ProcessCommand", "Routing to executeCQLQuery")
-		result := session.ExecuteCQLQuery(command)
+		result := executeQuery(ctx, command, session, sessionMgr)
 
-		// Refresh schema cache if this was a DDL command
+		// Wait for the nodes to agree on a schema change, then refresh the schema cache
+		checkSchemaAgreementIfNeeded(ctx, command, result, session)
 		refreshSchemaCacheIfNeeded(command, session)
 
 		// Check if we should capture the result
@@ -193,15 +281,15 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 				}
 			case db.StreamingQueryResult:
 				// For streaming results, we need to fetch all rows
-				defer v.Iterator.Close()
+				defer func() { _ = v.Close() }()
 
 				rows := [][]string{}
 				rawRows := []map[string]interface{}{}
 
 				// Fetch rows from iterator
-				for {
+				for ctx.Err() == nil {
 					row := make(map[string]interface{})
-					if !v.Iterator.MapScan(row) {
+					if ok, _ := v.NextRow(ctx, func(iter *gocql.Iter) bool { return iter.MapScan(row) }); !ok {
 						break
 					}
 
@@ -225,6 +313,10 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 					rawRows = append(rawRows, row)
 				}
 
+				if ctx.Err() != nil {
+					return &db.CancelledError{Rows: int64(len(rows))}
+				}
+
 				// Write to capture file
 				if len(rows) > 0 {
 					switch {
@@ -246,6 +338,14 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 					RawData:     rawRows,
 				}
 				logger.DebugfToFile("ProcessCommand", "Converted result type: %T, Data length: %d", result, len(result.(db.QueryResult).Data))
+			case db.LWTResult:
+				// Parquet files have a fixed schema, so conditional results are not captured there
+				switch metaHandler.captureFormat {
+				case "text":
+					_ = metaHandler.WriteCaptureText(command, v.String())
+				case "json", "csv":
+					_ = metaHandler.WriteCaptureResultWithRawData(command, v.Headers, v.Rows, v.RawData)
+				}
 			}
 		}
 
@@ -254,7 +354,7 @@ func ProcessCommand(command string, session *db.Session, sessionMgr *session.Man
 }
 
 // parseMetaCommand parses and executes meta-commands
-func parseMetaCommand(command string, session *db.Session, sessionMgr *session.Manager) interface{} {
+func parseMetaCommand(ctx context.Context, command string, session *db.Session, sessionMgr *session.Manager) interface{} {
 	// Strip trailing semicolon if present (meta-commands don't need them)
 	command = strings.TrimSpace(command)
 	command = strings.TrimSuffix(command, ";")
@@ -280,18 +380,42 @@ func parseMetaCommand(command string, session *db.Session, sessionMgr *session.M
 		return cmd
 	}
 
+	// Handle CONNECT/DISCONNECT - the UI swaps the session
+	if strings.HasPrefix(upperCommand, "CONNECT") || strings.HasPrefix(upperCommand, "DISCONNECT") {
+		return ParseConnectCommand(command)
+	}
+
+	// Handle LOGIN - the UI prompts for a missing password
+	if strings.HasPrefix(upperCommand, "LOGIN") {
+		return ParseLoginCommand(command)
+	}
+
 	// Handle simple meta commands with the meta handler
 	if strings.HasPrefix(upperCommand, "SHOW") ||
 		strings.HasPrefix(upperCommand, "TRACING") ||
 		strings.HasPrefix(upperCommand, "PAGING") ||
+		strings.HasPrefix(upperCommand, "TIMEOUT") ||
+		strings.HasPrefix(upperCommand, "RETRY") ||
+		strings.HasPrefix(upperCommand, "EXPLAIN") ||
+		strings.HasPrefix(upperCommand, "LINT") ||
+		strings.HasPrefix(upperCommand, "VECTOR") ||
+		strings.HasPrefix(upperCommand, "SCHEMA") ||
+		strings.HasPrefix(upperCommand, "MIGRATE") ||
 		strings.HasPrefix(upperCommand, "AUTOFETCH") ||
 		strings.HasPrefix(upperCommand, "EXPAND") ||
 		strings.HasPrefix(upperCommand, "SOURCE") ||
 		strings.HasPrefix(upperCommand, "CAPTURE") ||
 		strings.HasPrefix(upperCommand, "COPY") ||
 		strings.HasPrefix(upperCommand, "HELP") ||
-		strings.HasPrefix(upperCommand, "CONSISTENCY") {
-		return metaHandler.HandleMetaCommand(command)
+		strings.HasPrefix(upperCommand, "CONSISTENCY") ||
+		strings.HasPrefix(upperCommand, "SERIAL") ||
+		strings.HasPrefix(upperCommand, "SET") ||
+		strings.HasPrefix(upperCommand, "UNSET") ||
+		strings.HasPrefix(upperCommand, "\\SET") ||
+		strings.HasPrefix(upperCommand, "\\UNSET") ||
+		strings.HasPrefix(upperCommand, "PREPARE") ||
+		strings.HasPrefix(upperCommand, "EXECUTE") {
+		return metaHandler.HandleMetaCommandContext(ctx, command)
 	}
 
 	// Use the new CommandParser for DESCRIBE, LIST, GRANT, REVOKE commands
@@ -366,9 +490,23 @@ func isDDLCommand(command string) bool {
 	return false
 }
 
-// refreshSchemaCacheIfNeeded refreshes the schema cache if the command modified schema
+// checkSchemaAgreementIfNeeded checks that every node reports the same schema version
+// after a successful schema change. The driver has already waited for agreement, so
+// this only reads the versions once to report a disagreement as a warning of the
+// statement.
+func checkSchemaAgreementIfNeeded(ctx context.Context, command string, result interface{}, session *db.Session) {
+	if _, failed := result.(error); failed || session == nil || !db.IsSchemaChange(command) {
+		return
+	}
+	if _, err := session.CheckSchemaAgreement(ctx); err != nil {
+		logger.DebugfToFile("ProcessCommand", "Schema agreement check failed: %v", err)
+	}
+}
+
+// refreshSchemaCacheIfNeeded refreshes the schema cache if the command modified schema.
+// A running schema watcher reloads just the changed objects from the cluster's events.
 func refreshSchemaCacheIfNeeded(command string, session *db.Session) {
-	if session == nil {
+	if session == nil || session.WatchingSchema() {
 		return
 	}
 
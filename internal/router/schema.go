@@ -0,0 +1,227 @@
+package router
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/config"
+	"github.com/axonops/cqlai/internal/db"
+)
+
+const schemaUsage = `Usage:
+  SCHEMA DIFF <source> <target> [STATEMENTS]
+      Each side is a keyspace of this session, a connection profile (profile or
+      profile:keyspace) or a schema file ('schema.json').
+      STATEMENTS prints the CQL that turns <source> into <target>.
+  SCHEMA SAVE '<file>' [keyspace ...]
+      Saves the schema (keyspaces, tables, types, functions, ...) to a JSON file.
+  SCHEMA LOAD '<file>'
+      Uses a saved schema for completion, DESCRIBE, EXPLAIN and AI; without a
+      connection cqlai works offline from it.`
+
+// SchemaLoadCommand is returned by SCHEMA LOAD without a connection. The UI starts
+// an offline session from the snapshot.
+type SchemaLoadCommand struct {
+	Filename string
+	Snapshot *db.SchemaSnapshot
+}
+
+// handleSchema handles the SCHEMA command family
+func (h *MetaCommandHandler) handleSchema(command string) interface{} {
+	args := tokenizeArgs(strings.TrimSpace(trimCommandKeyword(command)))
+	if len(args) == 0 {
+		return schemaUsage
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "DIFF":
+		return h.schemaDiff(args[1:])
+	case "SAVE":
+		return h.schemaSave(args[1:])
+	case "LOAD":
+		return h.schemaLoad(args[1:])
+	default:
+		return schemaUsage
+	}
+}
+
+// schemaDiff handles SCHEMA DIFF <source> <target> [STATEMENTS]
+func (h *MetaCommandHandler) schemaDiff(args []string) interface{} {
+	statements := false
+	if len(args) == 3 && strings.EqualFold(args[2], "STATEMENTS") {
+		statements = true
+		args = args[:2]
+	}
+	if len(args) != 2 {
+		return "Usage: SCHEMA DIFF <source> <target> [STATEMENTS]"
+	}
+
+	source, err := h.loadSchemaSide(args[0])
+	if err != nil {
+		return fmt.Sprintf("Error: %s: %v", args[0], err)
+	}
+	target, err := h.loadSchemaSide(args[1])
+	if err != nil {
+		return fmt.Sprintf("Error: %s: %v", args[1], err)
+	}
+
+	diff := db.DiffSchemas(source, target)
+	if len(diff.Changes) == 0 {
+		return fmt.Sprintf("No differences between %s and %s", args[0], args[1])
+	}
+	if statements {
+		return strings.Join(diff.Statements(), "\n\n")
+	}
+	return formatSchemaDiff(diff)
+}
+
+// schemaSave handles SCHEMA SAVE '<file>' [keyspace ...]
+func (h *MetaCommandHandler) schemaSave(args []string) interface{} {
+	if len(args) == 0 {
+		return "Usage: SCHEMA SAVE '<file>' [keyspace ...]"
+	}
+	filename := expandSchemaPath(unquoteArg(args[0]))
+
+	keyspaces := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		keyspaces[i] = strings.Trim(arg, `"`)
+	}
+	snapshot, err := h.session.SchemaSnapshot(keyspaces...)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if err := snapshot.Save(filename); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("Saved the schema of %d keyspaces (%d tables) to %s",
+		len(snapshot.Keyspaces), snapshot.CountTables(), filename)
+}
+
+// schemaLoad handles SCHEMA LOAD '<file>'
+func (h *MetaCommandHandler) schemaLoad(args []string) interface{} {
+	if len(args) != 1 {
+		return "Usage: SCHEMA LOAD '<file>'"
+	}
+	filename := expandSchemaPath(unquoteArg(args[0]))
+
+	snapshot, err := db.LoadSchemaSnapshot(filename)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if h.session == nil {
+		return &SchemaLoadCommand{Filename: filename, Snapshot: snapshot}
+	}
+	h.session.UseSchemaSnapshot(snapshot)
+	return DescribeSchemaLoad(filename, snapshot)
+}
+
+// DescribeSchemaLoad describes a loaded schema snapshot
+func DescribeSchemaLoad(filename string, snapshot *db.SchemaSnapshot) string {
+	message := fmt.Sprintf("Loaded the schema of %d keyspaces (%d tables) from %s",
+		len(snapshot.Keyspaces), snapshot.CountTables(), filename)
+	if snapshot.Source != "" {
+		message += fmt.Sprintf("\nSaved from %s at %s", snapshot.Source, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return message
+}
+
+// allowedWhileOffline reports whether a command can run against a schema snapshot
+func allowedWhileOffline(upperCommand string) bool {
+	if allowedWhileDisconnected(upperCommand) {
+		return true
+	}
+	for _, cmd := range []string{"DESCRIBE", "DESC", "SCHEMA", "EXPLAIN", "LINT", "USE", "SHOW INDEXES"} {
+		if upperCommand == cmd || strings.HasPrefix(upperCommand, cmd+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// useOffline switches the keyspace of an offline session
+func useOffline(command string, session *db.Session) string {
+	fields := strings.Fields(command)
+	if len(fields) != 2 {
+		return "Usage: USE <keyspace>"
+	}
+	keyspace := strings.Trim(fields[1], `"`)
+	if !strings.HasPrefix(fields[1], `"`) {
+		keyspace = strings.ToLower(keyspace)
+	}
+	if err := session.SetKeyspace(keyspace); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return "Now using keyspace " + keyspace
+}
+
+// loadSchemaSide reads one side of SCHEMA DIFF: a schema file (quoted or ending in
+// .json), a connection profile with an optional :keyspace, or a keyspace
+func (h *MetaCommandHandler) loadSchemaSide(side string) (*db.SchemaSnapshot, error) {
+	if strings.HasPrefix(side, "'") || strings.HasSuffix(strings.ToLower(side), ".json") {
+		return db.LoadSchemaSnapshot(expandSchemaPath(unquoteArg(side)))
+	}
+
+	profile, keyspace, hasKeyspace := strings.Cut(side, ":")
+	if hasKeyspace || h.isProfile(profile) {
+		return h.profileSchema(profile, keyspace)
+	}
+
+	return h.session.SchemaSnapshot(strings.Trim(side, `"`))
+}
+
+// isProfile reports whether name is a configured connection profile
+func (h *MetaCommandHandler) isProfile(name string) bool {
+	configFile := ""
+	if h.session != nil {
+		configFile = h.session.ConfigFile()
+	}
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		return false
+	}
+	_, ok := cfg.Profiles[name]
+	return ok
+}
+
+// profileSchema connects to a connection profile and reads the schema of keyspace,
+// the profile's keyspace, or every keyspace
+func (h *MetaCommandHandler) profileSchema(profile, keyspace string) (*db.SchemaSnapshot, error) {
+	configFile := ""
+	if h.session != nil {
+		configFile = h.session.ConfigFile()
+	}
+	session, err := db.NewProfileSession(configFile, profile)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	if keyspace == "" {
+		keyspace = session.Keyspace()
+	}
+	if keyspace == "" {
+		return session.SchemaSnapshot()
+	}
+	return session.SchemaSnapshot(keyspace)
+}
+
+// expandSchemaPath expands a leading ~/ in a schema file name
+func expandSchemaPath(filename string) string {
+	if strings.HasPrefix(filename, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, filename[2:])
+		}
+	}
+	return filename
+}
+
+// formatSchemaDiff renders the changes of a schema diff as a result table
+func formatSchemaDiff(diff db.SchemaDiff) db.QueryResult {
+	data := [][]string{{"object", "name", "change", "detail"}}
+	for _, change := range diff.Changes {
+		data = append(data, []string{change.Object, change.Name, change.Change, change.Detail})
+	}
+	return textResult(data)
+}
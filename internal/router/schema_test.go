@@ -0,0 +1,114 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSchemaFile(t *testing.T, dir, name string, snapshot db.SchemaSnapshot) string {
+	t.Helper()
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	filename := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(filename, data, 0600))
+	return filename
+}
+
+func TestSchemaDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+	users := db.TableSnapshot{
+		Name:          "users",
+		Columns:       []db.ColumnInfo{{Name: "id", DataType: "uuid", Kind: "partition_key"}},
+		PartitionKeys: []string{"id"},
+	}
+	keyspace := db.KeyspaceSnapshot{
+		Name:          "app",
+		Replication:   map[string]string{"class": "SimpleStrategy", "replication_factor": "1"},
+		DurableWrites: true,
+		Tables:        []db.TableSnapshot{users},
+	}
+	before := writeSchemaFile(t, dir, "before.json", db.SchemaSnapshot{Version: 1, Keyspaces: []db.KeyspaceSnapshot{keyspace}})
+
+	users.Columns = append(users.Columns, db.ColumnInfo{Name: "email", DataType: "text", Kind: "regular", Position: -1})
+	keyspace.Tables = []db.TableSnapshot{users}
+	after := writeSchemaFile(t, dir, "after.json", db.SchemaSnapshot{Version: 1, Keyspaces: []db.KeyspaceSnapshot{keyspace}})
+
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	result, ok := handler.HandleMetaCommand("SCHEMA DIFF '" + before + "' '" + after + "'").(db.QueryResult)
+	require.True(t, ok)
+	assert.Equal(t, []string{"object", "name", "change", "detail"}, result.Data[0])
+	assert.Equal(t, []string{"COLUMN", "app.users.email", "added", "text"}, result.Data[1])
+
+	assert.Equal(t, "ALTER TABLE app.users ADD email text;",
+		handler.HandleMetaCommand("SCHEMA DIFF '"+before+"' '"+after+"' STATEMENTS;"))
+	assert.Equal(t, "No differences between '"+after+"' and '"+after+"'",
+		handler.HandleMetaCommand("SCHEMA DIFF '"+after+"' '"+after+"'"))
+}
+
+func TestSchemaDiffErrors(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, schemaUsage, handler.HandleMetaCommand("SCHEMA"))
+	assert.Equal(t, "Usage: SCHEMA DIFF <source> <target> [STATEMENTS]", handler.HandleMetaCommand("SCHEMA DIFF app"))
+	assert.Contains(t, handler.HandleMetaCommand("SCHEMA DIFF 'missing.json' app"), "Error: 'missing.json':")
+	assert.Contains(t, handler.HandleMetaCommand("SCHEMA DIFF a_keyspace b_keyspace"), "not connected to database")
+}
+
+func offlineSnapshot() *db.SchemaSnapshot {
+	return &db.SchemaSnapshot{Version: 1, Source: "10.0.0.1", Keyspaces: []db.KeyspaceSnapshot{{
+		Name:          "app",
+		Replication:   map[string]string{"class": "SimpleStrategy", "replication_factor": "1"},
+		DurableWrites: true,
+		Tables: []db.TableSnapshot{{
+			Name:          "users",
+			Columns:       []db.ColumnInfo{{Name: "id", DataType: "uuid", Kind: "partition_key"}},
+			PartitionKeys: []string{"id"},
+		}},
+	}}}
+}
+
+func TestSchemaSaveLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schema.json")
+	handler := &MetaCommandHandler{session: db.NewOfflineSession(offlineSnapshot(), "app")}
+
+	assert.Equal(t, "Saved the schema of 1 keyspaces (1 tables) to "+filename,
+		handler.HandleMetaCommand("SCHEMA SAVE '"+filename+"'"))
+	assert.Contains(t, handler.HandleMetaCommand("SCHEMA SAVE '"+filename+"' missing"), "Error: keyspace missing not found")
+	assert.Equal(t, "Usage: SCHEMA SAVE '<file>' [keyspace ...]", handler.HandleMetaCommand("SCHEMA SAVE"))
+
+	assert.Contains(t, handler.HandleMetaCommand("SCHEMA LOAD '"+filename+"'"),
+		"Loaded the schema of 1 keyspaces (1 tables) from "+filename+"\nSaved from 10.0.0.1 at ")
+	assert.Contains(t, handler.HandleMetaCommand("SCHEMA LOAD 'missing.json'"), "Error: open missing.json")
+
+	disconnected := &MetaCommandHandler{}
+	cmd, ok := disconnected.HandleMetaCommand("SCHEMA LOAD '" + filename + "'").(*SchemaLoadCommand)
+	require.True(t, ok)
+	assert.Equal(t, filename, cmd.Filename)
+	assert.Equal(t, "users", cmd.Snapshot.Keyspaces[0].Tables[0].Name)
+}
+
+func TestProcessCommandOffline(t *testing.T) {
+	defer func(previous *MetaCommandHandler) { metaHandler = previous }(metaHandler)
+	metaHandler = nil
+	session := db.NewOfflineSession(offlineSnapshot(), "")
+
+	assert.Equal(t, "Offline: working from a schema snapshot. Use CONNECT <profile> to run statements.",
+		ProcessCommand(context.Background(), "SELECT * FROM app.users", session, nil))
+	assert.Equal(t, "Now using keyspace app", ProcessCommand(context.Background(), "USE app;", session, nil))
+	assert.Contains(t, ProcessCommand(context.Background(), "USE missing", session, nil), "Error: keyspace missing not found")
+
+	assert.Equal(t, "app", ProcessCommand(context.Background(), "DESCRIBE KEYSPACES", session, nil))
+	assert.Equal(t, "users", ProcessCommand(context.Background(), "DESCRIBE TABLES", session, nil))
+	assert.Equal(t, "CREATE TABLE app.users (\n    id uuid PRIMARY KEY\n);",
+		ProcessCommand(context.Background(), "DESC TABLE Users", session, nil))
+	assert.Contains(t, ProcessCommand(context.Background(), "DESCRIBE app", session, nil), "CREATE KEYSPACE app WITH")
+	assert.Equal(t, "'app.missing' not found", ProcessCommand(context.Background(), "DESCRIBE TABLE app.missing", session, nil))
+}
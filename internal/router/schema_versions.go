@@ -0,0 +1,35 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axonops/cqlai/internal/db"
+)
+
+// showSchemaVersions handles SHOW SCHEMA VERSIONS, listing the schema version of every
+// node. Nodes that disagree are also reported as a warning.
+func (h *MetaCommandHandler) showSchemaVersions(ctx context.Context) interface{} {
+	nodes, err := h.session.CheckSchemaAgreement(ctx)
+	if nodes == nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return formatSchemaVersions(nodes)
+}
+
+// formatSchemaVersions renders node schema versions as a result table
+func formatSchemaVersions(nodes []db.NodeSchemaVersion) db.QueryResult {
+	data := [][]string{{"address", "data_center", "rack", "host_id", "schema_version"}}
+	for _, node := range nodes {
+		address := node.Address
+		if node.Local {
+			address += " (coordinator)"
+		}
+		version := node.SchemaVersion
+		if version == "" {
+			version = "unknown"
+		}
+		data = append(data, []string{address, node.DataCenter, node.Rack, node.HostID, version})
+	}
+	return textResult(data)
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowSchemaVersions(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "Usage: SHOW SCHEMA VERSIONS", handler.HandleMetaCommand("SHOW SCHEMA"))
+	assert.Equal(t, "Error: not connected to database", handler.HandleMetaCommand("SHOW SCHEMA VERSIONS;"))
+
+	result := formatSchemaVersions([]db.NodeSchemaVersion{
+		{Address: "10.0.0.1", DataCenter: "dc1", Rack: "r1", HostID: "h1", SchemaVersion: "aaaa", Local: true},
+		{Address: "10.0.0.2", DataCenter: "dc1", Rack: "r2", HostID: "h2"},
+	})
+	assert.Equal(t, 2, result.RowCount)
+	assert.Equal(t, []string{"address", "data_center", "rack", "host_id", "schema_version"}, result.Headers)
+	assert.Equal(t, []string{"10.0.0.1 (coordinator)", "dc1", "r1", "h1", "aaaa"}, result.Data[1])
+	assert.Equal(t, []string{"10.0.0.2", "dc1", "r2", "h2", "unknown"}, result.Data[2])
+}
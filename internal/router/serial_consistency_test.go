@@ -0,0 +1,18 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSerialConsistency(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "Current serial consistency level: SERIAL", handler.HandleMetaCommand("SERIAL CONSISTENCY"))
+	assert.Equal(t, "Serial consistency level set to LOCAL_SERIAL", handler.HandleMetaCommand("serial consistency local serial;"))
+	assert.Equal(t, "LOCAL_SERIAL", handler.session.SerialConsistency())
+	assert.Contains(t, handler.HandleMetaCommand("SERIAL CONSISTENCY ONE"), "Error setting serial consistency")
+	assert.Contains(t, handler.HandleMetaCommand("SERIAL"), "Usage: SERIAL CONSISTENCY")
+}
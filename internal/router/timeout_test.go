@@ -0,0 +1,25 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleTimeout(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, "Request timeout disabled", handler.HandleMetaCommand("TIMEOUT"))
+	assert.Contains(t, handler.HandleMetaCommand("TIMEOUT soon"), "invalid timeout")
+	assert.Contains(t, handler.HandleMetaCommand("TIMEOUT 30"), "Error setting timeout: not connected")
+	assert.Equal(t, "Usage: TIMEOUT [seconds] | TIMEOUT OFF", handler.HandleMetaCommand("TIMEOUT 30 60"))
+}
+
+func TestSupportsStatementTimeout(t *testing.T) {
+	assert.True(t, supportsStatementTimeout("SELECT * FROM t USING TIMEOUT 5"))
+	assert.True(t, supportsStatementTimeout("copy t TO 'f.csv' USING TIMEOUT 5"))
+	assert.False(t, supportsStatementTimeout("SELECT * FROM t"))
+	assert.False(t, supportsStatementTimeout("TIMEOUT 5"))
+	assert.False(t, supportsStatementTimeout("SET t = 'USING TIMEOUT 5'"))
+}
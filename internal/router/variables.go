@@ -0,0 +1,126 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/session"
+)
+
+// handleSet handles SET [name [=] value] and its \set alias. Without arguments it
+// lists the variables; values are CQL literals bound to :name or ? markers.
+func (h *MetaCommandHandler) handleSet(command string) interface{} {
+	if h.sessionManager == nil {
+		return "Session manager not initialized"
+	}
+
+	args := strings.TrimSpace(trimCommandKeyword(command))
+	if args == "" {
+		return h.listVariables()
+	}
+
+	name, value := splitVariableAssignment(args)
+	if value == "" {
+		return "Usage: SET name = value\nExample: SET uid = 42, SET name = 'alice', SET ids = [1, 2, 3]"
+	}
+	if _, err := db.ParseCQLLiteral(value); err != nil {
+		return fmt.Sprintf("Error: invalid value for %s: %v", name, err)
+	}
+	if err := h.sessionManager.SetVariable(name, value); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("%s = %s", name, value)
+}
+
+// handleUnset handles UNSET name and its \unset alias
+func (h *MetaCommandHandler) handleUnset(command string) interface{} {
+	if h.sessionManager == nil {
+		return "Session manager not initialized"
+	}
+
+	parts := strings.Fields(trimCommandKeyword(command))
+	if len(parts) != 1 {
+		return "Usage: UNSET name"
+	}
+	name := strings.TrimPrefix(parts[0], ":")
+	if !h.sessionManager.UnsetVariable(name) {
+		return fmt.Sprintf("Variable %s is not set", name)
+	}
+	return fmt.Sprintf("Variable %s removed", name)
+}
+
+// listVariables renders the current variables, one per line
+func (h *MetaCommandHandler) listVariables() string {
+	names := h.sessionManager.VariableNames()
+	if len(names) == 0 {
+		return "No variables set"
+	}
+	lines := make([]string, len(names))
+	for i, name := range names {
+		value, _ := h.sessionManager.Variable(name)
+		lines[i] = fmt.Sprintf("%s = %s", name, value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// trimCommandKeyword removes the command keyword and trailing semicolon from a meta-command
+func trimCommandKeyword(command string) string {
+	command = strings.TrimSuffix(strings.TrimSpace(command), ";")
+	if idx := strings.IndexAny(command, " \t"); idx >= 0 {
+		return command[idx+1:]
+	}
+	return ""
+}
+
+// splitVariableAssignment splits "name = value" or "name value" into its name and value
+func splitVariableAssignment(args string) (string, string) {
+	end := strings.IndexAny(args, " \t=")
+	if end < 0 {
+		return strings.TrimPrefix(args, ":"), ""
+	}
+	name := strings.TrimPrefix(args[:end], ":")
+	value := strings.TrimSpace(args[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return name, value
+}
+
+// bindVariables rewrites the bind markers of a statement to ? and returns the values of
+// the variables they refer to. markers is empty if the statement has no bind markers.
+func bindVariables(query string, sessionMgr *session.Manager) (string, []string, error) {
+	rewritten, markers := db.ExtractBindMarkers(query)
+	if len(markers) == 0 {
+		return query, nil, nil
+	}
+
+	values := make([]string, len(markers))
+	for i, name := range markers {
+		var value string
+		var ok bool
+		if sessionMgr != nil {
+			value, ok = sessionMgr.Variable(name)
+		}
+		if !ok {
+			if name[0] >= '0' && name[0] <= '9' {
+				return "", nil, fmt.Errorf("no value for bind marker ? number %s, use SET %s = <value>", name, name)
+			}
+			return "", nil, fmt.Errorf("undefined variable :%s, use SET %s = <value>", name, name)
+		}
+		values[i] = value
+	}
+	return rewritten, values, nil
+}
+
+// executeQuery executes a CQL statement, binding any :name or ? markers to the
+// session variables
+func executeQuery(ctx context.Context, query string, session *db.Session, sessionMgr *session.Manager) interface{} {
+	rewritten, values, err := bindVariables(query, sessionMgr)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return session.ExecuteCQLQueryContext(ctx, query)
+	}
+	return session.ExecuteBoundQueryContext(ctx, rewritten, values)
+}
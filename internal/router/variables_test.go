@@ -0,0 +1,49 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/axonops/cqlai/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSetAndUnset(t *testing.T) {
+	handler := &MetaCommandHandler{sessionManager: session.NewManager(nil)}
+
+	assert.Equal(t, "No variables set", handler.HandleMetaCommand("SET"))
+	assert.Equal(t, "uid = 42", handler.HandleMetaCommand("SET uid = 42;"))
+	assert.Equal(t, "name = 'alice'", handler.HandleMetaCommand("\\set :name 'alice'"))
+	assert.Equal(t, "1 = [1, 2]", handler.HandleMetaCommand("set 1=[1, 2]"))
+	assert.Equal(t, "1 = [1, 2]\nname = 'alice'\nuid = 42", handler.HandleMetaCommand("SET"))
+
+	assert.Contains(t, handler.HandleMetaCommand("SET uid"), "Usage: SET name = value")
+	assert.Contains(t, handler.HandleMetaCommand("SET uid = 'oops"), "invalid value for uid")
+	assert.Contains(t, handler.HandleMetaCommand("SET 0bad = 1"), "invalid variable name")
+
+	assert.Equal(t, "Variable uid removed", handler.HandleMetaCommand("UNSET uid"))
+	assert.Equal(t, "Variable uid is not set", handler.HandleMetaCommand("\\unset :uid"))
+	assert.Equal(t, "Usage: UNSET name", handler.HandleMetaCommand("UNSET"))
+}
+
+func TestBindVariables(t *testing.T) {
+	mgr := session.NewManager(nil)
+	require.NoError(t, mgr.SetVariable("uid", "42"))
+	require.NoError(t, mgr.SetVariable("1", "'alice'"))
+
+	query, values, err := bindVariables("SELECT * FROM users WHERE id = :uid AND name = ?", mgr)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? AND name = ?", query)
+	assert.Equal(t, []string{"42", "'alice'"}, values)
+
+	query, values, err = bindVariables("SELECT * FROM users", mgr)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users", query)
+	assert.Empty(t, values)
+
+	_, _, err = bindVariables("SELECT * FROM users WHERE id = :missing", mgr)
+	assert.EqualError(t, err, "undefined variable :missing, use SET missing = <value>")
+
+	_, _, err = bindVariables("SELECT * FROM users WHERE a = ? AND b = ?", mgr)
+	assert.EqualError(t, err, "no value for bind marker ? number 2, use SET 2 = <value>")
+}
@@ -0,0 +1,227 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/parquet"
+)
+
+const vectorUsage = `Usage:
+  VECTOR SEARCH <table> <column> FROM '<file>' | VALUE [x, y, ...]
+      [FIELD name] [ROW n] [COLUMNS a, b] [LIMIT n] [SIMILARITY COSINE|EUCLIDEAN|DOT_PRODUCT]
+  VECTOR QUERY <same arguments>   Print the ANN query without running it
+  VECTOR EXPAND [ON|OFF]          Show vectors in full or compacted in the table view`
+
+// vectorOptionKeywords are the keywords that start an option of VECTOR SEARCH
+var vectorOptionKeywords = map[string]bool{
+	"FROM": true, "VALUE": true, "FIELD": true, "ROW": true,
+	"COLUMNS": true, "LIMIT": true, "SIMILARITY": true,
+}
+
+// vectorSearchArgs are the parsed arguments of VECTOR SEARCH and VECTOR QUERY
+type vectorSearchArgs struct {
+	table      string
+	column     string
+	file       string
+	value      string
+	field      string
+	row        int
+	columns    []string
+	limit      int
+	similarity string
+}
+
+// handleVector handles the VECTOR command family
+func (h *MetaCommandHandler) handleVector(ctx context.Context, command string) interface{} {
+	args := strings.TrimSpace(trimCommandKeyword(command))
+	sub, rest := args, ""
+	if idx := strings.IndexAny(args, " \t"); idx >= 0 {
+		sub, rest = args[:idx], strings.TrimSpace(args[idx+1:])
+	}
+
+	switch strings.ToUpper(sub) {
+	case "SEARCH", "QUERY":
+		search, err := h.buildVectorSearch(rest)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		if strings.EqualFold(sub, "QUERY") {
+			return search.Query()
+		}
+		return h.session.ExecuteCQLQueryContext(ctx, search.Query())
+	case "EXPAND":
+		return h.handleVectorExpand(strings.ToUpper(rest))
+	default:
+		return vectorUsage
+	}
+}
+
+// handleVectorExpand handles VECTOR EXPAND [ON|OFF]
+func (h *MetaCommandHandler) handleVectorExpand(mode string) interface{} {
+	switch mode {
+	case "":
+	case "ON":
+		h.vectorExpand.Store(true)
+	case "OFF":
+		h.vectorExpand.Store(false)
+	default:
+		return "Usage: VECTOR EXPAND [ON|OFF]"
+	}
+	if h.vectorExpand.Load() {
+		return "Vector expand is ON (vectors are shown in full)"
+	}
+	return "Vector expand is OFF (vectors show their dimension and first/last elements)"
+}
+
+// IsVectorExpanded returns whether the table view shows vectors in full
+func (h *MetaCommandHandler) IsVectorExpanded() bool {
+	return h.vectorExpand.Load()
+}
+
+// buildVectorSearch parses the arguments of VECTOR SEARCH, reads the query vector and
+// resolves the table against the schema
+func (h *MetaCommandHandler) buildVectorSearch(args string) (db.VectorSearch, error) {
+	parsed, err := parseVectorSearchArgs(args)
+	if err != nil {
+		return db.VectorSearch{}, err
+	}
+
+	similarity, err := db.ParseSimilarity(parsed.similarity)
+	if err != nil {
+		return db.VectorSearch{}, err
+	}
+
+	var vector []float32
+	if parsed.file != "" {
+		vector, err = readVectorFile(parsed.file, parsed.field, parsed.row)
+	} else {
+		vector, err = db.ParseVectorText(parsed.value, parsed.field, parsed.row)
+	}
+	if err != nil {
+		return db.VectorSearch{}, fmt.Errorf("cannot read the query vector: %w", err)
+	}
+
+	search := db.VectorSearch{
+		Column:     parsed.column,
+		Columns:    parsed.columns,
+		Vector:     vector,
+		Limit:      parsed.limit,
+		Similarity: similarity,
+	}
+	if err := h.session.ResolveVectorSearch(&search, parsed.table); err != nil {
+		return db.VectorSearch{}, err
+	}
+	return search, nil
+}
+
+// parseVectorSearchArgs parses <table> <column> followed by the VECTOR SEARCH options
+func parseVectorSearchArgs(args string) (vectorSearchArgs, error) {
+	tokens := tokenizeArgs(args)
+	if len(tokens) < 2 {
+		return vectorSearchArgs{}, fmt.Errorf("table and column are required")
+	}
+	parsed := vectorSearchArgs{table: tokens[0], column: tokens[1], row: 1}
+
+	for i := 2; i < len(tokens); i++ {
+		keyword := strings.ToUpper(tokens[i])
+		if !vectorOptionKeywords[keyword] {
+			return vectorSearchArgs{}, fmt.Errorf("unexpected %q", tokens[i])
+		}
+
+		// COLUMNS takes every token up to the next option
+		if keyword == "COLUMNS" {
+			var list []string
+			for i+1 < len(tokens) && !vectorOptionKeywords[strings.ToUpper(tokens[i+1])] {
+				i++
+				list = append(list, tokens[i])
+			}
+			for _, col := range strings.Split(strings.Join(list, " "), ",") {
+				if col = strings.TrimSpace(col); col != "" {
+					parsed.columns = append(parsed.columns, col)
+				}
+			}
+			if len(parsed.columns) == 0 {
+				return vectorSearchArgs{}, fmt.Errorf("COLUMNS needs at least one column")
+			}
+			continue
+		}
+
+		if i+1 >= len(tokens) {
+			return vectorSearchArgs{}, fmt.Errorf("%s needs a value", keyword)
+		}
+		i++
+		value := tokens[i]
+
+		switch keyword {
+		case "FROM":
+			parsed.file = unquoteArg(value)
+		case "VALUE":
+			parsed.value = unquoteArg(value)
+		case "FIELD":
+			parsed.field = unquoteArg(value)
+		case "ROW", "LIMIT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return vectorSearchArgs{}, fmt.Errorf("%s must be a positive number", keyword)
+			}
+			if keyword == "ROW" {
+				parsed.row = n
+			} else {
+				parsed.limit = n
+			}
+		case "SIMILARITY":
+			parsed.similarity = value
+		}
+	}
+
+	if (parsed.file == "") == (parsed.value == "") {
+		return vectorSearchArgs{}, fmt.Errorf("give the query vector with either FROM '<file>' or VALUE [...]")
+	}
+	return parsed, nil
+}
+
+// readVectorFile reads a query vector from a text/JSON file, or from column field of
+// row of a Parquet file
+func readVectorFile(filename, field string, row int) ([]float32, error) {
+	if strings.HasPrefix(filename, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			filename = filepath.Join(home, filename[2:])
+		}
+	}
+
+	if !strings.EqualFold(filepath.Ext(filename), ".parquet") {
+		content, err := os.ReadFile(filename) // #nosec G304 - User-provided vector filename
+		if err != nil {
+			return nil, err
+		}
+		return db.ParseVectorText(string(content), field, row)
+	}
+
+	reader, err := parquet.NewParquetReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if row > 1 {
+		if _, err := reader.SkipRows(int64(row - 1)); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := reader.ReadBatch(1)
+	if err == io.EOF {
+		return nil, fmt.Errorf("ROW %d is out of range (%d rows)", row, reader.GetRowCount())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db.VectorFromRecord(rows[0], field)
+}
@@ -0,0 +1,83 @@
+package router
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/parquet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVectorSearchArgs(t *testing.T) {
+	parsed, err := parseVectorSearchArgs("app.docs embedding FROM 'my vectors.json' field emb ROW 2 COLUMNS id, title LIMIT 5 SIMILARITY euclidean")
+	require.NoError(t, err)
+	assert.Equal(t, vectorSearchArgs{
+		table:      "app.docs",
+		column:     "embedding",
+		file:       "my vectors.json",
+		field:      "emb",
+		row:        2,
+		columns:    []string{"id", "title"},
+		limit:      5,
+		similarity: "euclidean",
+	}, parsed)
+
+	parsed, err = parseVectorSearchArgs("docs embedding VALUE [0.1, 0.2,  0.3]")
+	require.NoError(t, err)
+	assert.Equal(t, "[0.1, 0.2,  0.3]", parsed.value)
+	assert.Equal(t, 1, parsed.row)
+
+	for _, args := range []string{
+		"docs",
+		"docs embedding",
+		"docs embedding FROM 'a.json' VALUE [1]",
+		"docs embedding VALUE [1] LIMIT",
+		"docs embedding VALUE [1] LIMIT 0",
+		"docs embedding VALUE [1] COLUMNS",
+		"docs embedding VALUE [1] WHERE id = 1",
+	} {
+		_, err := parseVectorSearchArgs(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestReadVectorFile(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "query.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`[{"v": [1, 2]}, {"v": [3, 4]}]`), 0600))
+	vector, err := readVectorFile(jsonPath, "v", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{3, 4}, vector)
+
+	parquetPath := filepath.Join(dir, "query.parquet")
+	writer, err := parquet.NewParquetCaptureWriter(parquetPath, []string{"id", "v"}, []string{"int", "list<float>"}, parquet.DefaultWriterOptions())
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRow(map[string]interface{}{"id": int32(1), "v": []float32{1, 2}}))
+	require.NoError(t, writer.WriteRow(map[string]interface{}{"id": int32(2), "v": []float32{5, 6}}))
+	require.NoError(t, writer.Close())
+
+	vector, err = readVectorFile(parquetPath, "v", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{5, 6}, vector)
+
+	_, err = readVectorFile(parquetPath, "v", 3)
+	assert.ErrorContains(t, err, "out of range")
+}
+
+func TestHandleVector(t *testing.T) {
+	handler := &MetaCommandHandler{session: &db.Session{}}
+
+	assert.Equal(t, vectorUsage, handler.HandleMetaCommand("VECTOR"))
+	assert.Equal(t, "Error: not connected to database", handler.HandleMetaCommand("VECTOR QUERY docs v VALUE [1, 2]"))
+	assert.Contains(t, handler.HandleMetaCommand("VECTOR SEARCH docs v VALUE [1, 2] SIMILARITY manhattan"), "unknown similarity")
+
+	assert.Equal(t, "Vector expand is ON (vectors are shown in full)", handler.HandleMetaCommand("VECTOR EXPAND ON"))
+	assert.True(t, handler.IsVectorExpanded())
+	assert.Contains(t, handler.HandleMetaCommand("VECTOR EXPAND off;"), "Vector expand is OFF")
+	assert.False(t, handler.IsVectorExpanded())
+	assert.Equal(t, "Usage: VECTOR EXPAND [ON|OFF]", handler.HandleMetaCommand("VECTOR EXPAND maybe"))
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"sync"
 
 	"github.com/axonops/cqlai/internal/config"
@@ -12,6 +13,10 @@ import (
 // Keyspace names must start with a letter or underscore, followed by alphanumerics/underscores
 var validKeyspacePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
+// validVariablePattern matches query variable names: identifiers for :name markers,
+// or numbers for positional ? markers
+var validVariablePattern = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*|[1-9][0-9]*)$`)
+
 // Manager handles application-level session state
 // This is separate from the database session
 type Manager struct {
@@ -19,6 +24,7 @@ type Manager struct {
 	currentKeyspace     string
 	requireConfirmation bool
 	outputFormat        config.OutputFormat
+	variables           map[string]string // Query variables (name -> CQL literal)
 }
 
 // NewManager creates a new session manager
@@ -41,6 +47,7 @@ func NewManager(cfg *config.Config) *Manager {
 		currentKeyspace:     keyspace,
 		requireConfirmation: cfg != nil && cfg.RequireConfirmation,
 		outputFormat:        outputFormat,
+		variables:           make(map[string]string),
 	}
 }
 
@@ -100,3 +107,44 @@ func (m *Manager) SetOutputFormat(format config.OutputFormat) error {
 	m.outputFormat = format
 	return nil
 }
+
+// SetVariable sets a query variable to a CQL literal
+// Returns an error if the variable name is invalid
+func (m *Manager) SetVariable(name, value string) error {
+	if !validVariablePattern.MatchString(name) {
+		return fmt.Errorf("invalid variable name: %q (must be an identifier or a positive number)", name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.variables[name] = value
+	return nil
+}
+
+// UnsetVariable removes a query variable and reports whether it was set
+func (m *Manager) UnsetVariable(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.variables[name]
+	delete(m.variables, name)
+	return ok
+}
+
+// Variable returns the CQL literal of a query variable
+func (m *Manager) Variable(name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.variables[name]
+	return value, ok
+}
+
+// VariableNames returns the names of all query variables in sorted order
+func (m *Manager) VariableNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.variables))
+	for name := range m.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
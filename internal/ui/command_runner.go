@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/logger"
+	"github.com/axonops/cqlai/internal/router"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// autoFetchBatchSize is the number of rows fetched per step when AUTOFETCH is ON
+const autoFetchBatchSize = 10000
+
+// commandResultMsg is sent when a command running in the background finishes
+type commandResultMsg struct {
+	command   string
+	result    interface{}
+	startTime time.Time
+}
+
+// autoFetchMsg carries the next batch of rows fetched in the background when AUTOFETCH is ON
+type autoFetchMsg struct {
+	command string
+	result  db.StreamingQueryResult
+	rows    [][]string
+	hasMore bool
+	err     error
+}
+
+// runCommand executes a command in the background so the UI stays responsive
+// and Ctrl+C can cancel it. The result arrives as a commandResultMsg.
+func (m *MainModel) runCommand(command string) tea.Cmd {
+	// Add command to history viewport
+	m.fullHistoryContent += "\n" + m.styles.AccentText.Render("> "+router.RedactLoginPassword(command))
+	m.updateHistoryWrapping()
+	m.historyViewport.GotoBottom()
+	m.warningBanner = ""
+
+	// The context stays live after the command returns because streaming
+	// results keep paging with it; the table then owns it. Otherwise it lives
+	// until Ctrl+C or the next command.
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.queryCtx = ctx
+	m.cancelQuery = cancel
+	m.setQueryRunning(true, "Running... (Ctrl+C to cancel)")
+
+	dbSession, sessionMgr := m.session, m.sessionManager
+	start := time.Now()
+	return func() tea.Msg {
+		result := router.ProcessCommand(ctx, command, dbSession, sessionMgr)
+		return commandResultMsg{command: command, result: result, startTime: start}
+	}
+}
+
+// handleCommandResult displays the result of a command started by runCommand
+func (m *MainModel) handleCommandResult(msg commandResultMsg) (*MainModel, tea.Cmd) {
+	m.setQueryRunning(false, "")
+	m.lastQueryTime = time.Since(msg.startTime)
+
+	// Capture trace data if tracing is enabled and this was a query that returns results
+	m.captureTraceData(msg.command)
+
+	// Warnings raised while the statement ran, by the server (tombstones, batch size)
+	// or by a consistency downgrade
+	warnings := m.session.TakeWarnings()
+	for _, warning := range warnings {
+		m.fullHistoryContent += "\n" + m.styles.WarnText.Render("Warning: "+warning)
+	}
+	m.warningBanner = warningBannerText(warnings)
+	for _, line := range m.session.TakeCustomPayload() {
+		m.fullHistoryContent += "\n" + m.styles.MutedText.Render("Custom payload: "+line)
+	}
+
+	logger.DebugfToFile("HandleEnterKey", "Result type: %T", msg.result)
+	return m.processCommandResult(msg.command, msg.result, msg.startTime)
+}
+
+// warningBannerText summarizes the warnings of a statement in one line
+func warningBannerText(warnings []string) string {
+	switch len(warnings) {
+	case 0:
+		return ""
+	case 1:
+		return "Warning: " + warnings[0]
+	default:
+		return fmt.Sprintf("%d warnings: %s (F2: show all)", len(warnings), warnings[0])
+	}
+}
+
+// cancelRunningCommand handles Ctrl+C while a command or AUTOFETCH is running
+func (m *MainModel) cancelRunningCommand() (*MainModel, tea.Cmd) {
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+	}
+	if m.slidingWindow != nil && m.slidingWindow.cancel != nil {
+		// AUTOFETCH pages with the context the table owns
+		m.slidingWindow.cancel()
+	}
+	m.input.Placeholder = "Cancelling..."
+	return m, nil
+}
+
+// setQueryRunning switches the input between its normal state and the
+// placeholder shown while a command runs
+func (m *MainModel) setQueryRunning(running bool, placeholder string) {
+	m.queryRunning = running
+	if running {
+		m.input.Reset()
+		m.input.Placeholder = placeholder
+	} else {
+		m.input.Placeholder = "Enter CQL command..."
+	}
+}
+
+// queryContext returns the context of the last command, or a background
+// context if no command has been run yet
+func (m *MainModel) queryContext() context.Context {
+	if m.queryCtx != nil {
+		return m.queryCtx
+	}
+	return context.Background()
+}
+
+// autoFetchRows loads the next batch of a streaming result in the background
+func (m *MainModel) autoFetchRows(command string, v db.StreamingQueryResult) tea.Cmd {
+	ctx := m.queryContext()
+	streamingResult := m.slidingWindow.streamingResult
+	return func() tea.Msg {
+		rows, hasMore, err := streamingResult.LoadMore(ctx, autoFetchBatchSize)
+		return autoFetchMsg{command: command, result: v, rows: rows, hasMore: hasMore, err: err}
+	}
+}
+
+// handleAutoFetch adds a batch fetched by autoFetchRows and either fetches the
+// next one or displays the result once all rows are loaded or Ctrl+C was pressed
+func (m *MainModel) handleAutoFetch(msg autoFetchMsg) (*MainModel, tea.Cmd) {
+	if m.slidingWindow == nil {
+		m.setQueryRunning(false, "")
+		return m, nil
+	}
+
+	for _, row := range msg.rows {
+		m.slidingWindow.AddRow(row)
+	}
+	logger.DebugfToFile("HandleEnterKey", "Auto-fetched %d more rows, total: %d",
+		len(msg.rows), m.slidingWindow.TotalRowsSeen)
+
+	if msg.err == nil && msg.hasMore && m.queryContext().Err() == nil {
+		return m, m.autoFetchRows(msg.command, msg.result)
+	}
+
+	cancelled := m.queryContext().Err() != nil
+	m.slidingWindow.hasMoreData = false
+	m.slidingWindow.closeStreamingResult()
+	m.setQueryRunning(false, "")
+
+	switch {
+	case cancelled:
+		m.fullHistoryContent += "\n" + m.styles.WarnText.Render(
+			fmt.Sprintf("Query cancelled after %d rows. Showing partial results.", m.slidingWindow.TotalRowsSeen))
+	case msg.err != nil:
+		logger.DebugfToFile("HandleEnterKey", "Error during auto-fetch: %v", msg.err)
+		m.fullHistoryContent += "\n" + m.styles.ErrorText.Render(fmt.Sprintf("Error: %v", msg.err))
+	default:
+		logger.DebugfToFile("HandleEnterKey", "Auto-fetch complete, total rows: %d", m.slidingWindow.TotalRowsSeen)
+	}
+
+	return m.displayStreamingQueryResult(msg.command, msg.result)
+}
@@ -3,13 +3,59 @@ package completion
 import (
 	"strconv"
 	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
 )
 
 // completionCache stores database metadata for faster completions
 type completionCache struct {
 	keyspaces []string
-	tables    map[string][]string // keyspace -> tables
-	columns   map[string][]string // keyspace.table -> columns
+	tables    map[string][]string        // keyspace -> tables
+	columns   map[string][]string        // keyspace.table -> columns
+	indexed   map[string]map[string]bool // keyspace.table -> columns with a secondary index
+}
+
+// InvalidateSchema drops the cached names a schema event made stale, so they are
+// fetched again on the next completion. An event without a Target drops everything.
+func (ce *CompletionEngine) InvalidateSchema(event db.SchemaEvent) {
+	switch event.Target {
+	case "":
+		ce.cache.keyspaces = nil
+		ce.cache.tables = make(map[string][]string)
+		ce.cache.columns = make(map[string][]string)
+		ce.cache.indexed = make(map[string]map[string]bool)
+	case db.SchemaTargetKeyspace:
+		ce.cache.keyspaces = nil
+		if event.Change == db.SchemaDropped {
+			delete(ce.cache.tables, event.Keyspace)
+			ce.invalidateTables(event.Keyspace, "")
+		}
+	case db.SchemaTargetTable:
+		if event.Change != db.SchemaUpdated {
+			delete(ce.cache.tables, event.Keyspace)
+		}
+		ce.invalidateTables(event.Keyspace, event.Name)
+	}
+}
+
+// invalidateTables drops the cached columns of a table, or of every table of the
+// keyspace when table is empty. Column lookups lowercase table names, so keys are
+// compared case-insensitively.
+func (ce *CompletionEngine) invalidateTables(keyspace, table string) {
+	matches := func(key string) bool {
+		ks, name, _ := strings.Cut(key, ".")
+		return ks == keyspace && (table == "" || strings.EqualFold(name, table))
+	}
+	for key := range ce.cache.columns {
+		if matches(key) {
+			delete(ce.cache.columns, key)
+		}
+	}
+	for key := range ce.cache.indexed {
+		if matches(key) {
+			delete(ce.cache.indexed, key)
+		}
+	}
 }
 
 // getKeyspaceNames returns cached keyspace names or fetches them
@@ -75,34 +121,71 @@ func (ce *CompletionEngine) getTablesForKeyspace(keyspace string) []string {
 	return ce.cache.tables[keyspace]
 }
 
-// getTypeNames returns type names (placeholder for now)
+// getTypeNames returns type names of a loaded schema snapshot
 func (ce *CompletionEngine) getTypeNames() []string {
-	// TODO: Implement type name caching
-	return []string{}
+	// TODO: Implement type name caching for live sessions
+	return ce.getSnapshotNames("TYPE")
 }
 
-// getFunctionNames returns function names (placeholder for now)
+// getFunctionNames returns function names of a loaded schema snapshot
 func (ce *CompletionEngine) getFunctionNames() []string {
-	// TODO: Implement function name caching
-	return []string{}
+	// TODO: Implement function name caching for live sessions
+	return ce.getSnapshotNames("FUNCTION")
 }
 
-// getAggregateNames returns aggregate names (placeholder for now)
+// getAggregateNames returns aggregate names of a loaded schema snapshot
 func (ce *CompletionEngine) getAggregateNames() []string {
-	// TODO: Implement aggregate name caching
-	return []string{}
+	// TODO: Implement aggregate name caching for live sessions
+	return ce.getSnapshotNames("AGGREGATE")
 }
 
-// getIndexNames returns index names (placeholder for now)
+// getIndexNames returns index names of a loaded schema snapshot
 func (ce *CompletionEngine) getIndexNames() []string {
-	// TODO: Implement index name caching
-	return []string{}
+	// TODO: Implement index name caching for live sessions
+	return ce.getSnapshotNames("INDEX")
 }
 
-// getViewNames returns view names (placeholder for now)
+// getViewNames returns view names of a loaded schema snapshot
 func (ce *CompletionEngine) getViewNames() []string {
-	// TODO: Implement view name caching
-	return []string{}
+	// TODO: Implement view name caching for live sessions
+	return ce.getSnapshotNames("VIEW")
+}
+
+// schemaSnapshot returns the schema snapshot loaded with SCHEMA LOAD or --schema-file,
+// or nil when the schema comes from the cluster
+func (ce *CompletionEngine) schemaSnapshot() *db.SchemaSnapshot {
+	if ce.session == nil || ce.session.GetSchemaCache() == nil {
+		return nil
+	}
+	return ce.session.GetSchemaCache().Snapshot()
+}
+
+// getSnapshotNames returns the names of one kind of object (TYPE, FUNCTION, ...) of the
+// current keyspace from a loaded schema snapshot
+func (ce *CompletionEngine) getSnapshotNames(kind string) []string {
+	snapshot := ce.schemaSnapshot()
+	if snapshot == nil || ce.sessionManager == nil {
+		return []string{}
+	}
+	ks := snapshot.Keyspace(ce.sessionManager.CurrentKeyspace())
+	if ks == nil {
+		return []string{}
+	}
+	return ks.ObjectNames(kind)
+}
+
+// snapshotColumns returns the columns of a table of a loaded schema snapshot
+func (ce *CompletionEngine) snapshotColumns(keyspace, table string) ([]db.ColumnInfo, bool) {
+	snapshot := ce.schemaSnapshot()
+	if snapshot == nil {
+		return nil, false
+	}
+	if ks := snapshot.Keyspace(keyspace); ks != nil {
+		if t := ks.Table(table); t != nil {
+			return t.Columns, true
+		}
+	}
+	return nil, true
 }
 
 // refreshKeyspaceCache updates the keyspace cache
@@ -110,6 +193,10 @@ func (ce *CompletionEngine) refreshKeyspaceCache() {
 	if ce.session == nil {
 		return
 	}
+	if snapshot := ce.schemaSnapshot(); snapshot != nil {
+		ce.cache.keyspaces = snapshot.KeyspaceNames()
+		return
+	}
 	
 	iter := ce.session.Query("SELECT keyspace_name FROM system_schema.keyspaces").Iter()
 	ce.cache.keyspaces = []string{}
@@ -126,6 +213,13 @@ func (ce *CompletionEngine) refreshTableCache(keyspace string) {
 	if ce.session == nil {
 		return
 	}
+	if snapshot := ce.schemaSnapshot(); snapshot != nil {
+		ce.cache.tables[keyspace] = []string{}
+		if ks := snapshot.Keyspace(keyspace); ks != nil {
+			ce.cache.tables[keyspace] = ks.ObjectNames("TABLE")
+		}
+		return
+	}
 	
 	iter := ce.session.Query(
 		"SELECT table_name FROM system_schema.tables WHERE keyspace_name = ?",
@@ -147,14 +241,20 @@ func (ce *CompletionEngine) refreshColumnCache(keyspace, table string) {
 		return
 	}
 	
+	cacheKey := keyspace + "." + table
+	ce.cache.columns[cacheKey] = []string{}
+	if columns, ok := ce.snapshotColumns(keyspace, table); ok {
+		for _, col := range columns {
+			ce.cache.columns[cacheKey] = append(ce.cache.columns[cacheKey], col.Name)
+		}
+		return
+	}
+
 	iter := ce.session.Query(
 		"SELECT column_name FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?",
 		keyspace, table,
 	).Iter()
 	
-	cacheKey := keyspace + "." + table
-	ce.cache.columns[cacheKey] = []string{}
-	
 	var columnName string
 	for iter.Scan(&columnName) {
 		ce.cache.columns[cacheKey] = append(ce.cache.columns[cacheKey], columnName)
@@ -198,6 +298,52 @@ func (ce *CompletionEngine) getColumnNamesForCurrentTable(words []string, fromIn
 	return ce.cache.columns[cacheKey]
 }
 
+// getWhereColumnsForCurrentTable returns the column names for a WHERE condition on the
+// table in the FROM clause, with the columns that have a secondary index first
+func (ce *CompletionEngine) getWhereColumnsForCurrentTable(words []string, fromIndex int) []string {
+	columns := ce.getColumnNamesForCurrentTable(words, fromIndex)
+	if len(columns) == 0 || ce.session == nil {
+		return columns
+	}
+
+	currentKeyspace := ""
+	if ce.sessionManager != nil {
+		currentKeyspace = ce.sessionManager.CurrentKeyspace()
+	}
+	keyspace, table, ok := db.SplitTableName(words[fromIndex+1], currentKeyspace)
+	if !ok {
+		return columns
+	}
+
+	cacheKey := keyspace + "." + table
+	indexed, ok := ce.cache.indexed[cacheKey]
+	if !ok {
+		var err error
+		if indexed, err = ce.session.IndexedColumns(keyspace, table); err != nil {
+			// Cached too, so the failing lookup isn't repeated on every keystroke; a
+			// schema change of the table clears it
+			indexed = map[string]bool{}
+		}
+		ce.cache.indexed[cacheKey] = indexed
+	}
+	if len(indexed) == 0 {
+		return columns
+	}
+
+	ordered := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if indexed[col] {
+			ordered = append(ordered, col)
+		}
+	}
+	for _, col := range columns {
+		if !indexed[col] {
+			ordered = append(ordered, col)
+		}
+	}
+	return ordered
+}
+
 // getColumnNamesForTable returns column names for a specific table
 func (ce *CompletionEngine) getColumnNamesForTable(tableName string) []string {
 	currentKeyspace := ""
@@ -247,18 +393,24 @@ func (ce *CompletionEngine) getColumnTypeTemplate(tableName string, columnNames
 		return ""
 	}
 	
-	// Query column types from system_schema
-	iter := ce.session.Query(
-		"SELECT column_name, type FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?",
-		currentKeyspace, tableName,
-	).Iter()
-	
 	columnTypes := make(map[string]string)
-	var columnName, columnType string
-	for iter.Scan(&columnName, &columnType) {
-		columnTypes[columnName] = columnType
+	if columns, ok := ce.snapshotColumns(currentKeyspace, tableName); ok {
+		for _, col := range columns {
+			columnTypes[col.Name] = col.DataType
+		}
+	} else {
+		// Query column types from system_schema
+		iter := ce.session.Query(
+			"SELECT column_name, type FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?",
+			currentKeyspace, tableName,
+		).Iter()
+
+		var columnName, columnType string
+		for iter.Scan(&columnName, &columnType) {
+			columnTypes[columnName] = columnType
+		}
+		_ = iter.Close()
 	}
-	_ = iter.Close()
 	
 	// Build the template
 	var templates []string
@@ -46,6 +46,12 @@ var ConsistencyLevels = []string{
 	"LOCAL_SERIAL",
 }
 
+// Serial consistency levels for lightweight transactions
+var SerialConsistencyLevels = []string{
+	"SERIAL",
+	"LOCAL_SERIAL",
+}
+
 // Data types for CREATE TABLE
 var CQLDataTypes = []string{
 	"ascii", "bigint", "blob", "boolean", "counter",
@@ -124,6 +130,21 @@ var TopLevelCommands = []string{
 	"CAPTURE",
 	"EXPAND",
 	"COPY",
+	"CONNECT",
+	"DISCONNECT",
+	"SERIAL",
+	"SET",
+	"UNSET",
+	"PREPARE",
+	"EXECUTE",
+	"TIMEOUT",
+	"RETRY",
+	"LOGIN",
+	"EXPLAIN",
+	"LINT",
+	"VECTOR",
+	"SCHEMA",
+	"MIGRATE",
 }
 
 // DescribeObjects are the objects that can be described
@@ -147,7 +168,7 @@ var ResourceTypes = []string{
 
 // ShowCommands for SHOW command completions
 var ShowCommands = []string{
-	"VERSION", "HOST", "SESSION",
+	"VERSION", "HOST", "SESSION", "PREPARED", "INDEXES", "SCHEMA",
 }
 
 // OutputFormats for OUTPUT command
@@ -346,27 +367,42 @@ var TopLevelKeywords = []string{
 	"APPLY",
 	"BEGIN",
 	"CAPTURE",
+	"CONNECT",
 	"CONSISTENCY",
 	"COPY",
 	"CREATE",
 	"DELETE",
 	"DESCRIBE",
 	"DESC",
+	"DISCONNECT",
 	"DROP",
+	"EXECUTE",
 	"EXPAND",
+	"EXPLAIN",
 	"GRANT",
 	"HELP",
 	"INSERT",
+	"LINT",
 	"LIST",
+	"LOGIN",
+	"MIGRATE",
 	"OUTPUT",
 	"PAGING",
 	"AUTOFETCH",
+	"PREPARE",
+	"RETRY",
 	"REVOKE",
+	"SCHEMA",
 	"SELECT",
+	"SERIAL",
+	"SET",
 	"SHOW",
 	"SOURCE",
+	"TIMEOUT",
 	"TRACING",
 	"TRUNCATE",
+	"UNSET",
 	"UPDATE",
 	"USE",
+	"VECTOR",
 }
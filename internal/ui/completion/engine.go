@@ -24,6 +24,7 @@ func NewCompletionEngine(dbSession *db.Session, sessionMgr *session.Manager) *Co
 		cache: &completionCache{
 			tables:  make(map[string][]string),
 			columns: make(map[string][]string),
+			indexed: make(map[string]map[string]bool),
 		},
 	}
 }
@@ -443,6 +444,12 @@ func (ce *CompletionEngine) getCompletionsForContext(words []string, afterSpace
 	firstWord := words[0]
 
 	switch firstWord {
+	case "EXPLAIN":
+		// EXPLAIN takes a statement, so complete it as one
+		if wordPos == 1 {
+			return []string{"SELECT", "UPDATE", "DELETE", "BEGIN"}
+		}
+		return ce.getCompletionsForContext(words[1:], afterSpace)
 	case "SELECT":
 		return ce.getSelectCompletions(words, wordPos)
 	case "INSERT":
@@ -495,10 +502,88 @@ func (ce *CompletionEngine) getCompletionsForContext(words []string, afterSpace
 		if wordPos == 1 {
 			return ConsistencyLevels
 		}
+	case "SERIAL":
+		if wordPos == 1 {
+			return []string{"CONSISTENCY"}
+		}
+		if wordPos == 2 {
+			return SerialConsistencyLevels
+		}
 	case "AUTOFETCH":
 		if wordPos == 1 {
 			return []string{"ON", "OFF"}
 		}
+	case "EXECUTE":
+		if wordPos == 1 && ce.session != nil {
+			statements := ce.session.PreparedStatements()
+			names := make([]string, len(statements))
+			for i, prepared := range statements {
+				names[i] = prepared.Name
+			}
+			return names
+		}
+	case "TIMEOUT":
+		if wordPos == 1 {
+			return []string{"OFF"}
+		}
+	case "PAGING":
+		if wordPos == 1 {
+			return []string{"STATE", "OFF"}
+		}
+	case "LINT":
+		if wordPos == 1 {
+			return []string{"WARN", "CONFIRM", "OFF"}
+		}
+	case "VECTOR":
+		if wordPos == 1 {
+			return []string{"SEARCH", "QUERY", "EXPAND"}
+		}
+		switch words[1] {
+		case "EXPAND":
+			if wordPos == 2 {
+				return []string{"ON", "OFF"}
+			}
+		case "SEARCH", "QUERY":
+			switch {
+			case wordPos == 2:
+				return ce.getTableAndKeyspaceTableNames()
+			case wordPos > 3 && words[wordPos-1] == "SIMILARITY":
+				return []string{"COSINE", "EUCLIDEAN", "DOT_PRODUCT"}
+			case wordPos > 3:
+				return []string{"FROM", "VALUE", "FIELD", "ROW", "COLUMNS", "LIMIT", "SIMILARITY"}
+			}
+		}
+	case "SCHEMA":
+		switch {
+		case wordPos == 1:
+			return []string{"DIFF", "LOAD", "SAVE"}
+		case wordPos >= 3 && strings.EqualFold(words[1], "SAVE"):
+			return ce.getKeyspaceNames()
+		case !strings.EqualFold(words[1], "DIFF"):
+			return nil
+		case wordPos == 2 || wordPos == 3:
+			return ce.getKeyspaceNames()
+		case wordPos == 4:
+			return []string{"STATEMENTS"}
+		}
+	case "MIGRATE":
+		switch {
+		case wordPos == 1:
+			return []string{"STATUS", "UP", "DOWN"}
+		case (wordPos == 2 || wordPos == 3) && !strings.EqualFold(words[1], "STATUS") && !strings.EqualFold(words[wordPos-1], "TO"):
+			return []string{"TO"}
+		}
+	case "RETRY":
+		if wordPos == 1 {
+			return []string{"SIMPLE", "EXPONENTIAL", "DOWNGRADING", "SPECULATIVE", "OFF"}
+		}
+		if wordPos == 2 && strings.EqualFold(words[1], "SPECULATIVE") {
+			return []string{"OFF"}
+		}
+	case "SET", "UNSET":
+		if wordPos == 1 && ce.sessionManager != nil {
+			return ce.sessionManager.VariableNames()
+		}
 	case "COPY":
 		if debugFile, err := os.OpenFile("cqlai_debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err == nil {
 			fmt.Fprintf(debugFile, "[DEBUG] getCompletionsForContext: Routing to getCopyCompletions\n")
@@ -1,10 +1,16 @@
 package completion
 
 // getShowCompletions returns completions for SHOW commands
-func (ce *CompletionEngine) getShowCompletions(_ []string, wordPos int) []string {
+func (ce *CompletionEngine) getShowCompletions(words []string, wordPos int) []string {
 	if wordPos == 1 {
 		return ShowCommands
 	}
+	if wordPos == 2 && words[1] == "INDEXES" {
+		return ce.getTableAndKeyspaceTableNames()
+	}
+	if wordPos == 2 && words[1] == "SCHEMA" {
+		return []string{"VERSIONS"}
+	}
 	return []string{}
 }
 
@@ -81,12 +81,12 @@ func (ce *CompletionEngine) getSelectCompletions(words []string, wordPos int) []
 	case "WHERE":
 		// After WHERE, suggest column names if we know the table
 		if debugFile, err := os.OpenFile("cqlai_debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err == nil {
-			fmt.Fprintf(debugFile, "[DEBUG] select.go: lastWord=WHERE, calling getColumnNamesForCurrentTable with fromIndex=%d\n", fromIndex)
+			fmt.Fprintf(debugFile, "[DEBUG] select.go: lastWord=WHERE, calling getWhereColumnsForCurrentTable with fromIndex=%d\n", fromIndex)
 			defer debugFile.Close()
 		}
-		columns := ce.getColumnNamesForCurrentTable(words, fromIndex)
+		columns := ce.getWhereColumnsForCurrentTable(words, fromIndex)
 		if debugFile, err := os.OpenFile("cqlai_debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err == nil {
-			fmt.Fprintf(debugFile, "[DEBUG] select.go: getColumnNamesForCurrentTable returned %d columns\n", len(columns))
+			fmt.Fprintf(debugFile, "[DEBUG] select.go: getWhereColumnsForCurrentTable returned %d columns\n", len(columns))
 			defer debugFile.Close()
 		}
 		return columns
@@ -211,7 +211,7 @@ func (ce *CompletionEngine) getSelectCompletions(words []string, wordPos int) []
 	if hasWhere && whereIndex >= 0 && wordPos > whereIndex {
 		// Check if we're in the middle of WHERE conditions
 		if lastWord == "AND" || lastWord == "OR" {
-			return ce.getColumnNamesForCurrentTable(words, fromIndex)
+			return ce.getWhereColumnsForCurrentTable(words, fromIndex)
 		}
 
 		// Check for comparison operators
@@ -221,12 +221,12 @@ func (sce *SimpleCompletionEngine) getListCompletions(words []string, endsWithSp
 
 func (sce *SimpleCompletionEngine) getShowCompletions(words []string, endsWithSpace bool) []string {
 	if len(words) == 1 && endsWithSpace {
-		return []string{"VERSION", "HOST", "SESSION"}
+		return []string{"VERSION", "HOST", "SESSION", "PREPARED", "INDEXES", "SCHEMA"}
 	}
 	if len(words) == 2 && !endsWithSpace {
 		suggestions := []string{}
 		second := strings.ToLower(words[1])
-		for _, obj := range []string{"VERSION", "HOST", "SESSION"} {
+		for _, obj := range []string{"VERSION", "HOST", "SESSION", "PREPARED", "INDEXES", "SCHEMA"} {
 			if strings.HasPrefix(strings.ToLower(obj), second) && strings.ToLower(obj) != second {
 				suggestions = append(suggestions, obj)
 			}
@@ -632,11 +632,11 @@ func (sce *SimpleCompletionEngine) getDeleteCompletions(words []string, endsWith
 // getTopLevelKeywords returns all top-level CQL keywords
 func (sce *SimpleCompletionEngine) getTopLevelKeywords() []string {
 	return []string{
-		"ALTER", "APPLY", "ASCII", "ASSUME", "BEGIN", "CAPTURE", "CONSISTENCY",
-		"COPY", "CREATE", "DELETE", "DESC", "DESCRIBE", "DROP", "EXECUTE", "EXIT",
-		"EXPAND", "EXPLAIN", "GRANT", "HELP", "INSERT", "LIST", "OUTPUT", "PAGING",
-		"QUIT", "REVOKE", "SELECT", "SHOW", "SOURCE", "TRACING", "TRUNCATE",
-		"UPDATE", "USE",
+		"ALTER", "APPLY", "ASCII", "ASSUME", "BEGIN", "CAPTURE", "CONNECT", "CONSISTENCY",
+		"COPY", "CREATE", "DELETE", "DESC", "DESCRIBE", "DISCONNECT", "DROP", "EXECUTE", "EXIT",
+		"EXPAND", "EXPLAIN", "GRANT", "HELP", "INSERT", "LINT", "LIST", "LOGIN", "MIGRATE", "OUTPUT", "PAGING",
+		"PREPARE", "QUIT", "RETRY", "REVOKE", "SCHEMA", "SELECT", "SERIAL", "SET", "SHOW", "SOURCE", "TIMEOUT", "TRACING", "TRUNCATE",
+		"UNSET", "UPDATE", "USE", "VECTOR",
 	}
 }
 
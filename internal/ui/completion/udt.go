@@ -94,6 +94,10 @@ func (ce *CompletionEngine) getUDTFieldsForColumn(tableName, columnName string)
 		return nil
 	}
 
+	if snapshot := ce.schemaSnapshot(); snapshot != nil {
+		return snapshotUDTFields(snapshot, currentKeyspace, tableName, columnName)
+	}
+
 	// Get column type information
 	var columnType string
 	query := `SELECT type FROM system_schema.columns
@@ -132,6 +136,31 @@ func (ce *CompletionEngine) getUDTFieldsForColumn(tableName, columnName string)
 	return fieldNames
 }
 
+// snapshotUDTFields returns the field names of a UDT column from a schema snapshot
+func snapshotUDTFields(snapshot *db.SchemaSnapshot, keyspace, tableName, columnName string) []string {
+	ks := snapshot.Keyspace(keyspace)
+	if ks == nil || ks.Table(tableName) == nil {
+		return nil
+	}
+	for _, col := range ks.Table(tableName).Columns {
+		if col.Name != columnName {
+			continue
+		}
+		typeInfo, err := db.ParseCQLType(col.DataType)
+		if err != nil || typeInfo.BaseType != "udt" {
+			return nil
+		}
+		if typeInfo.Keyspace != "" {
+			ks = snapshot.Keyspace(typeInfo.Keyspace)
+		}
+		if ks == nil || ks.Type(typeInfo.UDTName) == nil {
+			return nil
+		}
+		return ks.Type(typeInfo.UDTName).FieldNames
+	}
+	return nil
+}
+
 // isUDTFieldAccess checks if the input is trying to access a UDT field
 func (ce *CompletionEngine) isUDTFieldAccess(input string) bool {
 	// Look for pattern: word.partial_word at the end of input
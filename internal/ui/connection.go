@@ -0,0 +1,321 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axonops/cqlai/internal/config"
+	"github.com/axonops/cqlai/internal/db"
+	"github.com/axonops/cqlai/internal/logger"
+	"github.com/axonops/cqlai/internal/router"
+	"github.com/axonops/cqlai/internal/ui/completion"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionOptionsFromConfig builds the database session options for a resolved configuration
+func sessionOptionsFromConfig(cfg *config.Config, options ConnectionOptions) db.SessionOptions {
+	return db.SessionOptions{
+		Hosts:             cfg.ContactPoints(),
+		Port:              cfg.Port,
+		LocalDC:           cfg.LocalDC,
+		Keyspace:          cfg.Keyspace,
+		Username:          cfg.Username,
+		Password:          cfg.Password,
+		PasswordCommand:   cfg.PasswordCommand,
+		Consistency:       cfg.Consistency,
+		SerialConsistency: cfg.SerialConsistency,
+		SSL:               cfg.SSL,
+		ConnectTimeout:    options.ConnectTimeout,
+		RequestTimeout:    options.RequestTimeout,
+		ProtocolVersion:   options.ProtocolVersion,
+		ConfigFile:        options.ConfigFile,
+		ReplayIdempotent:  cfg.ReplayIdempotent,
+	}
+}
+
+// connectionStateMsg is sent when the health monitor reports that the connection was
+// lost or restored
+type connectionStateMsg struct {
+	state db.ConnectionState
+}
+
+// startHealthMonitor checks the current session in the background and reports changes
+// of the connection state on m.connStates. A negative healthCheckInterval disables it.
+func (m *MainModel) startHealthMonitor() {
+	if m.session == nil || m.session.IsOffline() || m.connStates == nil {
+		return
+	}
+	interval := db.DefaultHealthCheckInterval
+	if m.config != nil {
+		if m.config.HealthCheckInterval < 0 {
+			return
+		}
+		if m.config.HealthCheckInterval > 0 {
+			interval = time.Duration(m.config.HealthCheckInterval) * time.Second
+		}
+	}
+
+	states := m.connStates
+	m.session.StartHealthMonitor(interval, func(state db.ConnectionState) {
+		// Never block the monitor; only the latest state matters
+		select {
+		case states <- state:
+		default:
+		}
+	})
+}
+
+// waitForConnectionState waits for the next connection state reported by the health monitor
+func waitForConnectionState(states <-chan db.ConnectionState) tea.Cmd {
+	return func() tea.Msg {
+		return connectionStateMsg{state: <-states}
+	}
+}
+
+// handleConnectionState shows a lost or restored connection in the status bar and history
+func (m *MainModel) handleConnectionState(msg connectionStateMsg) (*MainModel, tea.Cmd) {
+	m.statusBar.Reconnecting = msg.state == db.ConnectionReconnecting
+	if m.statusBar.Reconnecting {
+		m.fullHistoryContent += "\n" + m.styles.ErrorText.Render("Connection lost, reconnecting...")
+	} else {
+		m.fullHistoryContent += "\n" + m.styles.SuccessText.Render("Reconnected")
+	}
+	m.updateHistoryWrapping()
+	m.historyViewport.GotoBottom()
+	return m, waitForConnectionState(m.connStates)
+}
+
+// schemaEventMsg is sent when the schema watcher applied a schema change pushed by the
+// cluster, made by this or another client
+type schemaEventMsg struct {
+	event db.SchemaEvent
+}
+
+// startSchemaWatcher keeps the schema cache of the current session in step with the
+// cluster and reports each applied change on m.schemaEvents
+func (m *MainModel) startSchemaWatcher() {
+	if m.session == nil || m.session.IsOffline() || m.schemaEvents == nil {
+		return
+	}
+
+	events := m.schemaEvents
+	m.session.StartSchemaWatcher(func(event db.SchemaEvent) {
+		// Never block the watcher; when the UI falls behind, make it drop everything
+		select {
+		case events <- event:
+		default:
+			select {
+			case <-events:
+			default:
+			}
+			events <- db.SchemaEvent{}
+		}
+	})
+}
+
+// waitForSchemaEvent waits for the next schema change applied by the schema watcher
+func waitForSchemaEvent(events <-chan db.SchemaEvent) tea.Cmd {
+	return func() tea.Msg {
+		return schemaEventMsg{event: <-events}
+	}
+}
+
+// handleSchemaEvent drops the completion names a schema change made stale
+func (m *MainModel) handleSchemaEvent(msg schemaEventMsg) (*MainModel, tea.Cmd) {
+	if m.completionEngine != nil {
+		m.completionEngine.InvalidateSchema(msg.event)
+	}
+	return m, waitForSchemaEvent(m.schemaEvents)
+}
+
+// handleConnectCommand switches the shell to the named connection profile
+func (m *MainModel) handleConnectCommand(cmd *router.ConnectCommand) (*MainModel, tea.Cmd) {
+	cfg, err := config.LoadConfig(m.connOptions.ConfigFile)
+	if err != nil {
+		return m.processErrorResult(fmt.Errorf("failed to load configuration: %v", err))
+	}
+
+	if cmd.Profile == "" {
+		names := cfg.ProfileNames()
+		if len(names) == 0 {
+			return m.showConnectionMessage("No connection profiles configured. Add a \"profiles\" section to cqlai.json.")
+		}
+		return m.showConnectionMessage("Available profiles: " + strings.Join(names, ", ") + "\nUsage: CONNECT <profile>")
+	}
+
+	if err := cfg.ApplyProfile(cmd.Profile); err != nil {
+		return m.processErrorResult(err)
+	}
+
+	// Connect in the background, as opening a driver session can take a while, and
+	// before closing the current session so a failed CONNECT leaves it usable
+	m.setQueryRunning(true, "Connecting to profile "+cmd.Profile+"...")
+	options := sessionOptionsFromConfig(cfg, m.connOptions)
+	profile := cmd.Profile
+	return m, func() tea.Msg {
+		dbSession, err := db.NewSessionWithOptions(options)
+		return connectResultMsg{profile: profile, cfg: cfg, session: dbSession, err: err}
+	}
+}
+
+// connectResultMsg is sent when a CONNECT started by handleConnectCommand finishes
+type connectResultMsg struct {
+	profile string
+	cfg     *config.Config
+	session *db.Session
+	err     error
+}
+
+// handleConnectResult switches to the session a CONNECT opened, or shows why it failed
+func (m *MainModel) handleConnectResult(msg connectResultMsg) (*MainModel, tea.Cmd) {
+	m.setQueryRunning(false, "")
+	if msg.err != nil {
+		return m.processErrorResult(fmt.Errorf("failed to connect to profile %s: %v", msg.profile, msg.err))
+	}
+	logger.DebugfToFile("Connect", "Connected to profile %s", msg.profile)
+
+	m.closeSession()
+	m.setSession(msg.session, msg.cfg.Keyspace, msg.profile)
+	m.statusBar.Host = strings.Join(msg.cfg.ContactPoints(), ",")
+
+	return m.showConnectionMessage(fmt.Sprintf("Connected to profile %s (%s, Cassandra %s)",
+		msg.profile, strings.Join(msg.session.ContactPoints(), ", "), msg.session.CassandraVersion()))
+}
+
+// handleDisconnectCommand closes the current session without leaving the shell
+func (m *MainModel) handleDisconnectCommand() (*MainModel, tea.Cmd) {
+	if m.session == nil {
+		return m.showConnectionMessage("Not connected")
+	}
+
+	m.closeSession()
+	m.setSession(nil, "", "")
+	m.statusBar.Host = "(disconnected)"
+	m.statusBar.Version = ""
+
+	return m.showConnectionMessage("Disconnected. Use CONNECT <profile> to connect again.")
+}
+
+// handleSchemaLoadCommand starts an offline session from the schema snapshot SCHEMA
+// LOAD read while disconnected
+func (m *MainModel) handleSchemaLoadCommand(cmd *router.SchemaLoadCommand) (*MainModel, tea.Cmd) {
+	m.setSession(db.NewOfflineSession(cmd.Snapshot, ""), "", "")
+	m.statusBar.Host = "(offline)"
+	m.statusBar.Version = ""
+
+	return m.showConnectionMessage(router.DescribeSchemaLoad(cmd.Filename, cmd.Snapshot) +
+		"\nWorking offline. Use CONNECT <profile> to run statements.")
+}
+
+// closeSession releases the current database session and any results still reading from it
+func (m *MainModel) closeSession() {
+	if m.slidingWindow != nil {
+		m.slidingWindow.closeStreamingResult()
+	}
+	m.slidingWindow = nil
+	if m.session != nil {
+		m.session.Close()
+	}
+}
+
+// setSession installs a new database session (nil when disconnected) and
+// rebuilds everything that was bound to the previous one
+func (m *MainModel) setSession(dbSession *db.Session, keyspace, profile string) {
+	m.session = dbSession
+	router.SetSession(dbSession)
+
+	if m.sessionManager != nil {
+		if err := m.sessionManager.SetKeyspace(keyspace); err != nil {
+			logger.DebugfToFile("Connect", "Failed to set keyspace %q: %v", keyspace, err)
+		}
+	}
+	m.statusBar.Keyspace = keyspace
+	m.completionEngine = completion.NewCompletionEngine(dbSession, m.sessionManager)
+
+	// Results and traces belong to the previous cluster
+	m.hasTable = false
+	m.hasTrace = false
+	m.viewMode = "history"
+	m.topBar.HasQueryData = false
+	m.topBar.HasMoreData = false
+	m.topBar.Profile = profile
+	m.topBar.Disconnected = dbSession == nil
+	m.topBar.Offline = dbSession.IsOffline()
+	m.statusBar.Reconnecting = false
+	m.startHealthMonitor()
+	m.startSchemaWatcher()
+}
+
+// showConnectionMessage adds a CONNECT/DISCONNECT status line to the history
+func (m *MainModel) showConnectionMessage(message string) (*MainModel, tea.Cmd) {
+	m.fullHistoryContent += "\n" + m.styles.SuccessText.Render(message)
+	m.updateHistoryWrapping()
+	m.historyViewport.GotoBottom()
+	m.input.Reset()
+	return m, nil
+}
+
+// handleLoginCommand re-authenticates the session as another role, first asking for
+// the password if the LOGIN did not include one
+func (m *MainModel) handleLoginCommand(cmd *router.LoginCommand) (*MainModel, tea.Cmd) {
+	if cmd.Password == "" {
+		m.loginPrompt = cmd
+		m.input.Reset()
+		m.input.EchoMode = textinput.EchoPassword
+		m.input.EchoCharacter = '*'
+		m.input.Placeholder = fmt.Sprintf("Password for %s (Esc to cancel)", cmd.Username)
+		return m, nil
+	}
+	return m.login(cmd.Username, cmd.Password)
+}
+
+// handleLoginPromptKeyboard reads the password of a LOGIN without echoing it
+func (m *MainModel) handleLoginPromptKeyboard(msg tea.KeyMsg) (*MainModel, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		username, password := m.loginPrompt.Username, m.input.Value()
+		m.endLoginPrompt()
+		return m.login(username, password)
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.endLoginPrompt()
+		return m.showConnectionMessage("Login cancelled")
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// endLoginPrompt puts the input back into its normal state after a password prompt
+func (m *MainModel) endLoginPrompt() {
+	m.loginPrompt = nil
+	m.input.Reset()
+	m.input.EchoMode = textinput.EchoNormal
+	m.input.Placeholder = "Enter CQL command..."
+}
+
+// loginResultMsg is sent when a LOGIN started by login finishes
+type loginResultMsg struct {
+	username string
+	err      error
+}
+
+// login switches the session to another role in the background, as opening the new
+// driver session can take a while. Results, history and settings are kept.
+func (m *MainModel) login(username, password string) (*MainModel, tea.Cmd) {
+	m.setQueryRunning(true, "Logging in as "+username+"...")
+	dbSession := m.session
+	return m, func() tea.Msg {
+		return loginResultMsg{username: username, err: dbSession.Login(username, password)}
+	}
+}
+
+// handleLoginResult shows the outcome of a LOGIN started by login
+func (m *MainModel) handleLoginResult(msg loginResultMsg) (*MainModel, tea.Cmd) {
+	m.setQueryRunning(false, "")
+	if msg.err != nil {
+		return m.processErrorResult(msg.err)
+	}
+	return m.showConnectionMessage("Logged in as " + msg.username)
+}
@@ -9,11 +9,24 @@ import (
 
 // handleKeyboardInput handles keyboard input events
 func (m *MainModel) handleKeyboardInput(msg tea.KeyMsg) (*MainModel, tea.Cmd) {
+	// While a command runs in the background, only Ctrl+C (cancel) is handled
+	if m.queryRunning {
+		if msg.Type == tea.KeyCtrlC {
+			return m.cancelRunningCommand()
+		}
+		return m, nil
+	}
+
 	// Check for save modal first (highest priority)
 	if m.saveModalActive {
 		return m.handleSaveModalKeyboard(msg)
 	}
 
+	// A LOGIN is waiting for its password
+	if m.loginPrompt != nil {
+		return m.handleLoginPromptKeyboard(msg)
+	}
+
 	// Check for AI CQL modal (high priority)
 	if m.aiCQLModal != nil && m.aiCQLModal.Active {
 		return m.handleAICQLModal(msg)
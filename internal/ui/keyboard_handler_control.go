@@ -43,9 +43,9 @@ func (m *MainModel) handleCtrlC() (*MainModel, tea.Cmd) {
 
 	// If we're in the middle of paging, cancel it
 	if m.slidingWindow != nil && m.slidingWindow.hasMoreData {
-		// Clear the "more data" state
+		// Stop the query behind the paged result and clear the "more data" state
 		m.slidingWindow.hasMoreData = false
-		m.slidingWindow.streamingResult = nil
+		m.slidingWindow.closeStreamingResult()
 		m.input.Placeholder = "Enter CQL command..."
 		m.input.Focus()
 		// Exit navigation mode if active
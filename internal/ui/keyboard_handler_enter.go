@@ -4,9 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
-	"github.com/axonops/cqlai/internal/logger"
+	"github.com/axonops/cqlai/internal/db"
 	"github.com/axonops/cqlai/internal/router"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -104,22 +103,38 @@ func (m *MainModel) handleEnterKey() (*MainModel, tea.Cmd) {
 	// Empty commands in multi-line mode should be treated as CQL to continue multi-line input
 	isCQLStatement := (command == "" && m.multiLineMode) ||
 		(!strings.HasPrefix(upperCommand, "DESCRIBE") &&
-		!strings.HasPrefix(upperCommand, "DESC ") &&
-		!strings.HasPrefix(upperCommand, "CONSISTENCY") &&
-		!strings.HasPrefix(upperCommand, "OUTPUT") &&
-		!strings.HasPrefix(upperCommand, "PAGING") &&
-		!strings.HasPrefix(upperCommand, "AUTOFETCH") &&
-		!strings.HasPrefix(upperCommand, "TRACING") &&
-		!strings.HasPrefix(upperCommand, "SOURCE") &&
-		!strings.HasPrefix(upperCommand, "CAPTURE") &&
-		!strings.HasPrefix(upperCommand, "EXPAND") &&
-		!strings.HasPrefix(upperCommand, "SHOW") &&
-		!strings.HasPrefix(upperCommand, "HELP") &&
-		!strings.HasPrefix(upperCommand, "SAVE") &&
-		!strings.HasPrefix(upperCommand, "CLEAR") &&
-		!strings.HasPrefix(upperCommand, "CLS") &&
-		!strings.HasPrefix(upperCommand, "EXIT") &&
-		!strings.HasPrefix(upperCommand, "QUIT"))
+			!strings.HasPrefix(upperCommand, "DESC ") &&
+			!strings.HasPrefix(upperCommand, "CONSISTENCY") &&
+			!strings.HasPrefix(upperCommand, "SERIAL CONSISTENCY") &&
+			!strings.HasPrefix(upperCommand, "SET") &&
+			!strings.HasPrefix(upperCommand, "UNSET") &&
+			!strings.HasPrefix(upperCommand, "\\SET") &&
+			!strings.HasPrefix(upperCommand, "\\UNSET") &&
+			!strings.HasPrefix(upperCommand, "EXECUTE") &&
+			!strings.HasPrefix(upperCommand, "OUTPUT") &&
+			!strings.HasPrefix(upperCommand, "PAGING") &&
+			!strings.HasPrefix(upperCommand, "TIMEOUT") &&
+			!strings.HasPrefix(upperCommand, "RETRY") &&
+			!strings.HasPrefix(upperCommand, "EXPLAIN") &&
+			!strings.HasPrefix(upperCommand, "LINT") &&
+			!strings.HasPrefix(upperCommand, "VECTOR") &&
+			!strings.HasPrefix(upperCommand, "SCHEMA") &&
+			!strings.HasPrefix(upperCommand, "MIGRATE") &&
+			!strings.HasPrefix(upperCommand, "AUTOFETCH") &&
+			!strings.HasPrefix(upperCommand, "TRACING") &&
+			!strings.HasPrefix(upperCommand, "SOURCE") &&
+			!strings.HasPrefix(upperCommand, "CAPTURE") &&
+			!strings.HasPrefix(upperCommand, "EXPAND") &&
+			!strings.HasPrefix(upperCommand, "SHOW") &&
+			!strings.HasPrefix(upperCommand, "HELP") &&
+			!strings.HasPrefix(upperCommand, "SAVE") &&
+			!strings.HasPrefix(upperCommand, "CONNECT") &&
+			!strings.HasPrefix(upperCommand, "DISCONNECT") &&
+			!strings.HasPrefix(upperCommand, "LOGIN") &&
+			!strings.HasPrefix(upperCommand, "CLEAR") &&
+			!strings.HasPrefix(upperCommand, "CLS") &&
+			!strings.HasPrefix(upperCommand, "EXIT") &&
+			!strings.HasPrefix(upperCommand, "QUIT"))
 
 	// For CQL statements, check for semicolon (skip for AI-generated commands)
 	if isCQLStatement {
@@ -170,14 +185,29 @@ func (m *MainModel) handleEnterKey() (*MainModel, tea.Cmd) {
 		return m, nil
 	}
 
-	// Add to history
-	m.commandHistory = append(m.commandHistory, command)
+	// In lint confirm mode, statements with anti-patterns need confirmation too
+	if isCQLStatement && m.session != nil && m.session.LintMode() == db.LintConfirm {
+		if analysis := m.session.AnalyzeQuery(command); len(analysis.Findings) > 0 {
+			m.modal = NewRiskyQueryModal(command, analysis.Findings)
+
+			m.fullHistoryContent += "\n" + m.styles.AccentText.Render("> "+command)
+			m.updateHistoryWrapping()
+			m.historyViewport.GotoBottom()
+
+			m.input.Reset()
+			return m, nil
+		}
+	}
+
+	// Add to history, without the password of a LOGIN
+	historyCommand := router.RedactLoginPassword(command)
+	m.commandHistory = append(m.commandHistory, historyCommand)
 	m.historyIndex = -1
-	m.lastCommand = command
+	m.lastCommand = historyCommand
 
 	// Save to persistent history
 	if m.historyManager != nil {
-		if err := m.historyManager.SaveCommand(command); err != nil {
+		if err := m.historyManager.SaveCommand(historyCommand); err != nil {
 			// Log error but don't fail command execution
 			fmt.Fprintf(os.Stderr, "Warning: could not save command to history: %v\n", err)
 		}
@@ -189,19 +219,6 @@ func (m *MainModel) handleEnterKey() (*MainModel, tea.Cmd) {
 		return model, cmd
 	}
 
-	start := time.Now()
-	result := router.ProcessCommand(command, m.session, m.sessionManager)
-	m.lastQueryTime = time.Since(start)
-
-	// Add command to history viewport
-	m.fullHistoryContent += "\n" + m.styles.AccentText.Render("> "+command)
-	m.updateHistoryWrapping()
-	m.historyViewport.GotoBottom()
-	
-	// Capture trace data if tracing is enabled and this was a query that returns results
-	m.captureTraceData(command)
-
-	// Handle different result types
-	logger.DebugfToFile("HandleEnterKey", "Result type (2nd location): %T", result)
-	return m.processCommandResult(command, result, start)
+	// Run the command in the background; the result arrives as a commandResultMsg
+	return m, m.runCommand(command)
 }
\ No newline at end of file
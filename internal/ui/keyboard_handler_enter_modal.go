@@ -3,10 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
-	"time"
 
-	"github.com/axonops/cqlai/internal/logger"
-	"github.com/axonops/cqlai/internal/router"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -32,20 +29,8 @@ func (m *MainModel) handleModalConfirmation(_ string) (*MainModel, tea.Cmd) {
 			}
 		}
 
-		// Process the command
-		start := time.Now()
-		result := router.ProcessCommand(command, m.session, m.sessionManager)
-		m.lastQueryTime = time.Since(start)
-
-		// Add command to full history and viewport
-		m.fullHistoryContent += "\n" + m.styles.AccentText.Render("> "+command)
-		m.updateHistoryWrapping()
-		m.historyViewport.GotoBottom()
-
-		// Handle different result types
-		logger.DebugfToFile("HandleEnterKey", "Result type: %T", result)
-		updatedModel, cmd := m.processCommandResult(command, result, start)
-		return updatedModel, cmd
+		// Process the command in the background
+		return m, m.runCommand(command)
 		
 	} else { // "Cancel" button
 		// Cancel the command
@@ -3,6 +3,7 @@ package ui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -61,10 +62,20 @@ func (m *MainModel) processCommandResult(command string, result interface{}, sta
 		m.historyViewport.GotoBottom()
 		m.input.Reset()
 		return m, nil
+	case *router.ConnectCommand:
+		return m.handleConnectCommand(v)
+	case *router.DisconnectCommand:
+		return m.handleDisconnectCommand()
+	case *router.SchemaLoadCommand:
+		return m.handleSchemaLoadCommand(v)
+	case *router.LoginCommand:
+		return m.handleLoginCommand(v)
 	case db.StreamingQueryResult:
 		return m.processStreamingQueryResult(command, v, startTime)
 	case db.QueryResult:
 		return m.processQueryResult(command, v)
+	case db.LWTResult:
+		return m.processLWTResult(v)
 	case [][]string:
 		return m.processTableResult(command, v)
 	case string:
@@ -89,6 +100,10 @@ func (m *MainModel) processStreamingQueryResult(command string, v db.StreamingQu
 	if m.config != nil && m.config.MaxMemoryMB > 0 {
 		maxMemoryMB = m.config.MaxMemoryMB
 	}
+	if m.slidingWindow != nil {
+		// The previous result is no longer paged through
+		m.slidingWindow.closeStreamingResult()
+	}
 	m.slidingWindow = NewSlidingWindowTable(10000, maxMemoryMB)
 	m.slidingWindow.Headers = streamingResult.Headers
 	m.slidingWindow.ColumnNames = v.ColumnNames
@@ -131,27 +146,24 @@ func (m *MainModel) processStreamingQueryResult(command string, v db.StreamingQu
 		return m, nil
 	}
 
-	// Store the streaming result in sliding window for progressive loading
+	// Store the streaming result in sliding window for progressive loading. The window
+	// now owns the command's context, so the next command doesn't cancel its paging.
 	m.slidingWindow.streamingResult = streamingResult
+	m.slidingWindow.cancel, m.cancelQuery = m.cancelQuery, nil
 	m.slidingWindow.hasMoreData = hasMore
 
-	// If auto-fetch is enabled, fetch all remaining pages immediately
+	// If auto-fetch is enabled, fetch all remaining pages in the background so Ctrl+C can stop it
 	if m.session != nil && m.session.AutoFetch() && m.slidingWindow.hasMoreData {
 		logger.DebugToFile("HandleEnterKey", "Auto-fetch enabled, loading all remaining rows")
-
-		// Load all remaining rows
-		for m.slidingWindow.hasMoreData {
-			loadedRows := m.slidingWindow.LoadMoreRows(10000) // Load in large batches
-			if loadedRows == 0 {
-				break
-			}
-			logger.DebugfToFile("HandleEnterKey", "Auto-fetched %d more rows, total: %d",
-				loadedRows, m.slidingWindow.TotalRowsSeen)
-		}
-		logger.DebugfToFile("HandleEnterKey", "Auto-fetch complete, total rows: %d",
-			m.slidingWindow.TotalRowsSeen)
+		m.setQueryRunning(true, "Fetching all rows... (Ctrl+C to cancel)")
+		return m, m.autoFetchRows(command, v)
 	}
 
+	return m.displayStreamingQueryResult(command, v)
+}
+
+// displayStreamingQueryResult captures and displays the rows loaded from a streaming result
+func (m *MainModel) displayStreamingQueryResult(command string, v db.StreamingQueryResult) (*MainModel, tea.Cmd) {
 	// Write initial rows to capture file if capturing
 	metaHandler := router.GetMetaHandler()
 	if metaHandler != nil && metaHandler.IsCapturing() && len(m.slidingWindow.Rows) > 0 {
@@ -192,6 +204,20 @@ func (m *MainModel) processStreamingQueryResult(command string, v db.StreamingQu
 	}
 }
 
+// jsonWarnings returns the warnings of the last statement as a {"warnings": [...]}
+// line for the JSON output format, or "" if there were none
+func (m *MainModel) jsonWarnings() string {
+	warnings := m.session.LastWarnings()
+	if len(warnings) == 0 {
+		return ""
+	}
+	jsonBytes, err := json.Marshal(map[string][]string{"warnings": warnings})
+	if err != nil {
+		return ""
+	}
+	return string(jsonBytes) + "\n"
+}
+
 // displayExpandFormat displays results in expanded vertical format
 func (m *MainModel) displayExpandFormat(headers []string, columnTypes []string) (*MainModel, tea.Cmd) {
 	// EXPAND format - use table viewport for pagination support
@@ -370,6 +396,8 @@ func (m *MainModel) displayJSONFormat(headers []string, columnTypes []string, co
 			}
 		}
 
+		jsonStr += m.jsonWarnings()
+
 		// Add to history
 		if jsonStr != "" {
 			m.fullHistoryContent += "\n" + jsonStr
@@ -415,6 +443,8 @@ func (m *MainModel) displayJSONFormat(headers []string, columnTypes []string, co
 			}
 		}
 
+		jsonStr += m.jsonWarnings()
+
 		// Add notice about more data if applicable
 		if m.slidingWindow.hasMoreData {
 			jsonStr += "\n" + m.styles.MutedText.Render(
@@ -553,6 +583,7 @@ func (m *MainModel) processQueryResult(command string, v db.QueryResult) (*MainM
 					}
 				}
 			}
+			jsonOutput += m.jsonWarnings()
 			
 			// Add JSON output to history content
 			if jsonOutput != "" {
@@ -687,8 +718,34 @@ func (m *MainModel) processStringResult(command string, v string) (*MainModel, t
 	return m, nil
 }
 
+// processLWTResult shows the outcome of a conditional statement, including the
+// existing values when it was not applied
+func (m *MainModel) processLWTResult(v db.LWTResult) (*MainModel, tea.Cmd) {
+	m.tableHeaders = nil
+	m.columnWidths = nil
+	m.hasTable = false
+	m.viewMode = "history"
+	m.topBar.HasQueryData = false
+
+	style := m.styles.SuccessText
+	if !v.Applied {
+		style = m.styles.WarnText
+	}
+	m.fullHistoryContent += "\n" + style.Render(wrapLongLines(v.String(), m.historyViewport.Width))
+	m.updateHistoryWrapping()
+	m.historyViewport.GotoBottom()
+
+	m.input.Reset()
+	return m, nil
+}
+
 // processErrorResult handles error type results
 func (m *MainModel) processErrorResult(v error) (*MainModel, tea.Cmd) {
+	var cancelled *db.CancelledError
+	if errors.As(v, &cancelled) {
+		return m.processCancelledResult(cancelled)
+	}
+
 	// Error result - add to history
 	m.tableHeaders = nil
 	m.columnWidths = nil
@@ -703,4 +760,17 @@ func (m *MainModel) processErrorResult(v error) (*MainModel, tea.Cmd) {
 	
 	m.input.Reset()
 	return m, nil
-}
\ No newline at end of file
+}
+
+// processCancelledResult reports a query stopped with Ctrl+C
+func (m *MainModel) processCancelledResult(v *db.CancelledError) (*MainModel, tea.Cmd) {
+	m.topBar.HasQueryData = false
+	m.hasTable = false
+	m.viewMode = "history"
+	m.fullHistoryContent += "\n" + m.styles.WarnText.Render(fmt.Sprintf("Query cancelled after %d rows", v.Rows))
+	m.updateHistoryWrapping()
+	m.historyViewport.GotoBottom()
+
+	m.input.Reset()
+	return m, nil
+}
@@ -23,8 +23,8 @@ func (m *MainModel) captureTraceData(command string) {
 			// Add summary info as a header to the trace content
 			summaryLine := ""
 			if traceInfo != nil {
-				summaryLine = fmt.Sprintf("Trace Session - Coordinator: %s | Total Duration: %d μs\n",
-					traceInfo.Coordinator, traceInfo.Duration)
+				summaryLine = fmt.Sprintf("Trace Session - Coordinator: %s | Total Duration: %d μs | Retries: %d\n",
+					traceInfo.Coordinator, traceInfo.Duration, traceInfo.Retries)
 			}
 			
 			// Combine headers and data into a single table structure
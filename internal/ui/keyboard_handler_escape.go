@@ -74,7 +74,7 @@ func (m *MainModel) handleEscapeKey() (*MainModel, tea.Cmd) {
 			logger.DebugfToFile("ESC", "In nav mode with paging - cancelling paging")
 			// Clear the "more data" state and reset to normal nav mode
 			m.slidingWindow.hasMoreData = false
-			m.slidingWindow.streamingResult = nil
+			m.slidingWindow.closeStreamingResult()
 			// Stay in navigation mode but update the placeholder
 			m.input.Placeholder = "[NAV MODE] Alt+↑↓←→=scroll | j/k=line | d/u=½page | g/G=top/bottom | </>=10cols | ESC=exit"
 			// Add a message to history to indicate paging was cancelled
@@ -128,7 +128,7 @@ func (m *MainModel) handleEscapeKey() (*MainModel, tea.Cmd) {
 		logger.DebugfToFile("ESC", "Clearing paging state instead of toggling nav")
 		// Clear the "more data" state and reset input placeholder
 		m.slidingWindow.hasMoreData = false
-		m.slidingWindow.streamingResult = nil
+		m.slidingWindow.closeStreamingResult()
 		m.input.Placeholder = "Enter CQL command..."
 		// Add a message to history to indicate paging was cancelled
 		m.fullHistoryContent += "\n" + m.styles.MutedText.Render("Paging cancelled. Showing partial results.")
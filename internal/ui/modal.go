@@ -1,6 +1,9 @@
 package ui
 
 import (
+	"strings"
+
+	"github.com/axonops/cqlai/internal/db"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -38,6 +41,25 @@ func NewConfirmationModal(command string) Modal {
 	}
 }
 
+// NewRiskyQueryModal creates a confirmation modal for a statement the query linter
+// found anti-patterns in
+func NewRiskyQueryModal(command string, findings []db.LintFinding) Modal {
+	messages := make([]string, len(findings))
+	for i, finding := range findings {
+		messages[i] = "• " + finding.Message
+	}
+	return Modal{
+		Type:     ModalConfirmDangerous,
+		Title:    "⚠️  Confirm Risky Query",
+		Message:  strings.Join(messages, "\n"),
+		Command:  command,
+		Choices:  []string{"Cancel", "Execute"},
+		Selected: 0,
+		Width:    72,
+		Height:   10 + 2*len(findings),
+	}
+}
+
 // NextChoice moves to the next choice
 func (m *Modal) NextChoice() {
 	m.Selected = (m.Selected + 1) % len(m.Choices)
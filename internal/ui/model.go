@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -22,21 +23,28 @@ import (
 
 // ConnectionOptions holds command-line connection options
 type ConnectionOptions struct {
-	Host                string
-	Port                int
-	Keyspace            string
-	Username            string
-	Password            string
-	RequireConfirmation bool
-	ConnectTimeout      int    // Connection timeout in seconds
-	RequestTimeout      int    // Request timeout in seconds
-	Debug               bool   // Enable debug logging
-	ConfigFile          string // Path to custom config file
-	SSL                    bool   // Enable SSL/TLS connection
-	SSLHostVerification    *bool  // Override SSL host verification (nil = use config)
-	SSLInsecureSkipVerify  *bool  // Override SSL insecure skip verify (nil = use config)
-	Consistency            string // Default consistency level (e.g., "QUORUM")
-	PageSize            int    // Page size for results
+	Host                  string // Host or comma-separated list of contact points
+	Port                  int
+	LocalDC               string // Local datacenter for DC-aware routing
+	Keyspace              string
+	Username              string
+	Password              string
+	RequireConfirmation   bool
+	ConnectTimeout        int                      // Connection timeout in seconds
+	RequestTimeout        int                      // Request timeout in seconds
+	ProtocolVersion       int                      // Native protocol version (0 = negotiate)
+	Debug                 bool                     // Enable debug logging
+	ConfigFile            string                   // Path to custom config file
+	Profile               string                   // Named connection profile from the config file
+	SSL                   bool                     // Enable SSL/TLS connection
+	SSLHostVerification   *bool                    // Override SSL host verification (nil = use config)
+	SSLInsecureSkipVerify *bool                    // Override SSL insecure skip verify (nil = use config)
+	Consistency           string                   // Default consistency level (e.g., "QUORUM")
+	SerialConsistency     string                   // Serial consistency level for LWTs (SERIAL or LOCAL_SERIAL)
+	PageSize              int                      // Page size for results
+	Variables             map[string]string        // Query variables from --var (name -> CQL literal)
+	SchemaFile            string                   // Schema snapshot used instead of reading the schema, and offline
+	SplitStatements       router.StatementSplitter // Splits migration files for MIGRATE (the splitter lives in the batch package)
 }
 
 // AIMessage represents a single message in the AI conversation
@@ -85,8 +93,11 @@ type MainModel struct {
 	fullHistoryContent       string // Full history content (not limited by viewport)
 	clipboardBuffer          string // Buffer for cut/copy operations (Ctrl+K, Ctrl+U, Ctrl+Y)
 	session                  *db.Session
-	sessionManager           *session.Manager // Application state manager
-	config                   *config.Config   // Full configuration
+	sessionManager           *session.Manager        // Application state manager
+	config                   *config.Config          // Full configuration
+	connOptions              ConnectionOptions       // Command-line connection options, reused by CONNECT
+	connStates               chan db.ConnectionState // Connection state changes reported by the health monitor
+	schemaEvents             chan db.SchemaEvent     // Schema changes applied by the schema watcher
 	aiConfig                 *config.AIConfig // AI configuration
 	styles                   *Styles
 	ready                    bool
@@ -134,8 +145,14 @@ type MainModel struct {
 	saveModalFormat         int                 // Selected format index (0: CSV, 1: JSON, 2: ASCII)
 	saveModalFilename       string              // Filename being entered
 	saveModalInput          textinput.Model     // Text input for filename
-	aiHistoryIndex          int                 // Current position in AI history
-	
+
+	// LOGIN waiting for its password to be typed
+	loginPrompt *router.LoginCommand
+
+	// Warnings of the last statement, shown over the results when they hide the history
+	warningBanner  string
+	aiHistoryIndex int // Current position in AI history
+
 	// Tracing support
 	traceViewport            viewport.Model      // Viewport for trace results
 	hasTrace                 bool                // Whether we have trace data to display
@@ -153,6 +170,11 @@ type MainModel struct {
 	windowWidth              int                 // Terminal window width
 	windowHeight             int                 // Terminal window height
 
+	// Background command execution
+	queryRunning bool               // Whether a command or AUTOFETCH is running in the background
+	queryCtx     context.Context    // Context of the last command, used by its streaming result
+	cancelQuery  context.CancelFunc // Cancels the last command (Ctrl+C)
+
 	// Multi-line mode
 	multiLineMode   bool     // Whether we're in multi-line mode
 	multiLineBuffer []string // Buffer for multi-line commands
@@ -300,9 +322,20 @@ func NewMainModelWithConnectionOptions(options ConnectionOptions) (*MainModel, e
 		logger.SetDebugEnabled(true)
 	}
 
+	// Apply the connection profile before command-line overrides
+	if options.Profile != "" {
+		if err := cfg.ApplyProfile(options.Profile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Override with command-line options
 	if options.Host != "" {
 		cfg.Host = options.Host
+		cfg.Hosts = nil
+	}
+	if options.LocalDC != "" {
+		cfg.LocalDC = options.LocalDC
 	}
 	if options.Port != 0 {
 		cfg.Port = options.Port
@@ -325,6 +358,9 @@ func NewMainModelWithConnectionOptions(options ConnectionOptions) (*MainModel, e
 	if options.Consistency != "" {
 		cfg.Consistency = options.Consistency
 	}
+	if options.SerialConsistency != "" {
+		cfg.SerialConsistency = options.SerialConsistency
+	}
 	// Override page size from CLI flag
 	if options.PageSize > 0 {
 		cfg.PageSize = options.PageSize
@@ -349,27 +385,33 @@ func NewMainModelWithConnectionOptions(options ConnectionOptions) (*MainModel, e
 		}
 	}
 
-	dbSession, err := db.NewSessionWithOptions(db.SessionOptions{
-		Host:           cfg.Host,
-		Port:           cfg.Port,
-		Keyspace:       cfg.Keyspace,
-		Username:       cfg.Username,
-		Password:       cfg.Password,
-		Consistency:    cfg.Consistency,
-		SSL:            cfg.SSL,
-		ConnectTimeout: options.ConnectTimeout,
-		RequestTimeout: options.RequestTimeout,
-		ConfigFile:     options.ConfigFile,
-	})
+	sessionOptions := sessionOptionsFromConfig(cfg, options)
+	sessionOptions.SchemaFile = options.SchemaFile
+	dbSession, err := db.NewSessionWithOptions(sessionOptions)
 	if err != nil {
-		return nil, err
+		if options.SchemaFile == "" {
+			return nil, err
+		}
+		// The schema file lets cqlai start without the cluster
+		snapshot, loadErr := db.LoadSchemaSnapshot(options.SchemaFile)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not connect (%v); working offline from %s\n", err, options.SchemaFile)
+		dbSession = db.NewOfflineSession(snapshot, cfg.Keyspace)
 	}
 
 	// Create session manager for application state
 	sessionMgr := session.NewManager(cfg)
+	for name, value := range options.Variables {
+		if err := sessionMgr.SetVariable(name, value); err != nil {
+			dbSession.Close()
+			return nil, err
+		}
+	}
 
 	// Initialize router with session manager
-	router.InitRouter(sessionMgr)
+	router.InitRouter(sessionMgr, options.SplitStatements)
 
 	completionEngine := completion.NewCompletionEngine(dbSession, sessionMgr)
 
@@ -407,18 +449,28 @@ func NewMainModelWithConnectionOptions(options ConnectionOptions) (*MainModel, e
 
 	// Initialize status bar with actual connection values
 	statusBar := NewStatusBarModel()
-	statusBar.Host = cfg.Host
+	statusBar.Host = strings.Join(cfg.ContactPoints(), ",")
+	if dbSession.IsOffline() {
+		statusBar.Host = "(offline)"
+	}
 	statusBar.Username = cfg.Username
 	statusBar.Keyspace = cfg.Keyspace
 	statusBar.Consistency = dbSession.Consistency()
 
+	topBar := NewTopBarModel()
+	topBar.Profile = options.Profile
+	topBar.Offline = dbSession.IsOffline()
+
 	return &MainModel{
-		topBar:                    NewTopBarModel(),
+		topBar:                    topBar,
 		statusBar:                 statusBar,
 		input:                     ti,
 		session:                   dbSession,
 		sessionManager:            sessionMgr,
 		config:                    cfg,
+		connOptions:               options,
+		connStates:                make(chan db.ConnectionState, 1),
+		schemaEvents:              make(chan db.SchemaEvent, 64),
 		aiConfig:                  cfg.AI,
 		styles:                    styles,
 		commandHistory:            commandHistory,
@@ -458,7 +510,9 @@ func (m *MainModel) Init() tea.Cmd {
 	// Standard button event mode but we'll try to be more specific
 	fmt.Print("\x1b[?1000h") // Enable basic mouse tracking
 	fmt.Print("\x1b[?1006h") // Use SGR encoding for larger coordinates
-	return textinput.Blink
+	m.startHealthMonitor()
+	m.startSchemaWatcher()
+	return tea.Batch(textinput.Blink, waitForConnectionState(m.connStates), waitForSchemaEvent(m.schemaEvents))
 }
 
 // Update updates the main model.
@@ -524,6 +578,30 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		updatedModel, cmd := m.handleMouseInput(msg)
 		return updatedModel, cmd
 
+	case commandResultMsg:
+		updatedModel, cmd := m.handleCommandResult(msg)
+		return updatedModel, cmd
+
+	case autoFetchMsg:
+		updatedModel, cmd := m.handleAutoFetch(msg)
+		return updatedModel, cmd
+
+	case loginResultMsg:
+		updatedModel, cmd := m.handleLoginResult(msg)
+		return updatedModel, cmd
+
+	case connectResultMsg:
+		updatedModel, cmd := m.handleConnectResult(msg)
+		return updatedModel, cmd
+
+	case connectionStateMsg:
+		updatedModel, cmd := m.handleConnectionState(msg)
+		return updatedModel, cmd
+
+	case schemaEventMsg:
+		updatedModel, cmd := m.handleSchemaEvent(msg)
+		return updatedModel, cmd
+
 	case AICQLResultMsg:
 		// Handle AI CQL generation result
 		logger.DebugfToFile("AI", "Received AI result message")
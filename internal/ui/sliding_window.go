@@ -27,6 +27,7 @@ type SlidingWindowTable struct {
 	
 	// Streaming result for loading more data
 	streamingResult *db.StreamingResult // Store the streaming result if still available
+	cancel          context.CancelFunc  // Cancels the context the streaming result pages with
 	hasMoreData     bool                // Whether more data can be fetched
 	
 	// Indicators for UI
@@ -183,7 +184,7 @@ func (swt *SlidingWindowTable) LoadMoreRows(maxRows int) int {
 	if err != nil {
 		logger.DebugfToFile("SlidingWindowTable", "Error loading more rows: %v", err)
 		swt.hasMoreData = false
-		swt.streamingResult = nil
+		swt.closeStreamingResult()
 		return 0
 	}
 
@@ -194,7 +195,7 @@ func (swt *SlidingWindowTable) LoadMoreRows(maxRows int) int {
 
 	swt.hasMoreData = hasMore
 	if !hasMore {
-		swt.streamingResult = nil
+		swt.closeStreamingResult()
 	}
 
 	logger.DebugfToFile("SlidingWindowTable", "Loaded %d more rows", len(rows))
@@ -232,6 +233,19 @@ func (swt *SlidingWindowTable) MarkRowsAsCaptured(count int) {
 	}
 }
 
+// closeStreamingResult stops reading the streaming result, if any, drops it and
+// cancels the query behind it
+func (swt *SlidingWindowTable) closeStreamingResult() {
+	if swt.streamingResult != nil {
+		_ = swt.streamingResult.Close()
+	}
+	swt.streamingResult = nil
+	if swt.cancel != nil {
+		swt.cancel()
+		swt.cancel = nil
+	}
+}
+
 // Reset clears the sliding window
 func (swt *SlidingWindowTable) Reset() {
 	swt.Rows = make([][]string, 0)
@@ -240,7 +254,7 @@ func (swt *SlidingWindowTable) Reset() {
 	swt.CurrentMemory = 0
 	swt.DataDroppedAtStart = false
 	swt.DataAvailableAtEnd = false
-	swt.streamingResult = nil
+	swt.closeStreamingResult()
 	swt.hasMoreData = false
 	swt.LastCapturedRow = 0
 }
\ No newline at end of file
@@ -20,6 +20,8 @@ type StatusBarModel struct {
 	Keyspace     string
 	Version      string
 	OutputFormat string
+	Reconnecting bool // Connection lost and the health monitor is reconnecting
+	Retries      int  // Retries of the last statement
 }
 
 // NewStatusBarModel creates a new StatusBarModel.
@@ -82,6 +84,11 @@ func (m StatusBarModel) View(width int, styles *Styles, currentView string) stri
 		tracingStyle = tracingOnStyle
 	}
 
+	hostDisplay := hostStyle.Render(m.Host)
+	if m.Reconnecting {
+		hostDisplay = tracingOnStyle.Render(m.Host + " (reconnecting)")
+	}
+
 	// Version style
 	versionStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#B8B8B8"))
@@ -99,7 +106,7 @@ func (m StatusBarModel) View(width int, styles *Styles, currentView string) stri
 	// Then add the rest in order
 	statusText += labelStyle.Render("User: ") + hostStyle.Render(usernameDisplay) +
 		separatorStyle.Render(" │ ") +
-		labelStyle.Render("Host: ") + hostStyle.Render(m.Host) +
+		labelStyle.Render("Host: ") + hostDisplay +
 		separatorStyle.Render(" │ ") +
 		labelStyle.Render("KS: ") + keyspaceStyle.Render(keyspaceDisplay) +
 		separatorStyle.Render(" │ ") +
@@ -109,6 +116,12 @@ func (m StatusBarModel) View(width int, styles *Styles, currentView string) stri
 		separatorStyle.Render(" │ ") +
 		labelStyle.Render("Trace: ") + tracingStyle.Render(tracingState)
 
+	// Retries only matter when the last statement needed them
+	if m.Retries > 0 {
+		statusText += separatorStyle.Render(" │ ") +
+			labelStyle.Render("Retries: ") + consistencyStyle.Render(fmt.Sprintf("%d", m.Retries))
+	}
+
 
 	// Apply style to the entire bar without forced background
 	barStyle := lipgloss.NewStyle().
@@ -5,9 +5,15 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/axonops/cqlai/internal/db"
 	"github.com/axonops/cqlai/internal/logger"
+	"github.com/axonops/cqlai/internal/router"
 )
 
+// vectorEdgeElements is how many of the first and last elements of a vector the
+// table view shows
+const vectorEdgeElements = 3
+
 // formatTableForViewport formats a 2D array of strings as a table for the viewport
 func (m *MainModel) formatTableForViewport(data [][]string) string {
 	if len(data) == 0 {
@@ -18,9 +24,12 @@ func (m *MainModel) formatTableForViewport(data [][]string) string {
 	needsRebuild := m.cachedTableLines == nil || !m.isSameTableData(data)
 
 	if needsRebuild {
+		// The cached data keeps full values; only the rendering is compacted
+		display := m.compactVectorColumns(data)
+
 		// Calculate column widths (using rune count for proper Unicode handling)
-		colWidths := make([]int, len(data[0]))
-		for _, row := range data {
+		colWidths := make([]int, len(display[0]))
+		for _, row := range display {
 			for i, cell := range row {
 				plainCell := stripAnsi(cell)
 				cellWidth := len([]rune(plainCell)) // Count runes, not bytes
@@ -39,7 +48,7 @@ func (m *MainModel) formatTableForViewport(data [][]string) string {
 		}
 
 		// Handle dynamic width adjustment
-		if m.initialColumnWidths != nil && len(m.initialColumnWidths) == len(data[0]) {
+		if m.initialColumnWidths != nil && len(m.initialColumnWidths) == len(display[0]) {
 			// We have existing widths - expand them if needed (but never shrink)
 			for i := range colWidths {
 				if colWidths[i] > m.initialColumnWidths[i] {
@@ -61,7 +70,7 @@ func (m *MainModel) formatTableForViewport(data [][]string) string {
 
 		// Check if any cell has multi-line content
 		hasMultiLine := false
-		for _, row := range data {
+		for _, row := range display {
 			for _, cell := range row {
 				if strings.Contains(cell, "\n") || len([]rune(stripAnsi(cell))) > 80 {
 					hasMultiLine = true
@@ -76,10 +85,10 @@ func (m *MainModel) formatTableForViewport(data [][]string) string {
 		var fullLines []string
 		if hasMultiLine {
 			// Use multi-line capable table builder
-			fullLines = m.buildFullTableMultiline(data, colWidths)
+			fullLines = m.buildFullTableMultiline(display, colWidths)
 		} else {
 			// Use regular table builder for better performance on simple tables
-			fullLines = m.buildFullTable(data, colWidths)
+			fullLines = m.buildFullTable(display, colWidths)
 		}
 
 		// Cache the rendered lines
@@ -116,6 +125,42 @@ func (m *MainModel) formatTableForViewport(data [][]string) string {
 	return ""
 }
 
+// compactVectorColumns shortens the cells of vector columns to their dimension and
+// first/last elements, unless VECTOR EXPAND is on
+func (m *MainModel) compactVectorColumns(data [][]string) [][]string {
+	if metaHandler := router.GetMetaHandler(); metaHandler != nil && metaHandler.IsVectorExpanded() {
+		return data
+	}
+
+	// Column types left over from an earlier result don't describe this one
+	if len(data) < 2 || len(m.columnTypes) != len(data[0]) {
+		return data
+	}
+
+	var vectorCols []int
+	for i, colType := range m.columnTypes {
+		if colType == "vector" || strings.HasPrefix(colType, "vector<") {
+			vectorCols = append(vectorCols, i)
+		}
+	}
+	if len(vectorCols) == 0 {
+		return data
+	}
+
+	compacted := make([][]string, len(data))
+	compacted[0] = data[0]
+	for r, row := range data[1:] {
+		newRow := append([]string(nil), row...)
+		for _, i := range vectorCols {
+			if i < len(newRow) {
+				newRow[i] = db.CompactVector(newRow[i], vectorEdgeElements)
+			}
+		}
+		compacted[r+1] = newRow
+	}
+	return compacted
+}
+
 // isSameTableData checks if the table data has changed
 func (m *MainModel) isSameTableData(data [][]string) bool {
 	if m.lastTableData == nil || data == nil {
@@ -312,8 +357,8 @@ func (m *MainModel) refreshTraceView() {
 	if m.traceInfo != nil {
 		// Highlight "Trace Session" with accent color
 		highlightedTitle := m.styles.AccentText.Bold(true).Render("Trace Session")
-		summaryLine = fmt.Sprintf("%s - Coordinator: %s | Total Duration: %d μs\n",
-			highlightedTitle, m.traceInfo.Coordinator, m.traceInfo.Duration)
+		summaryLine = fmt.Sprintf("%s - Coordinator: %s | Total Duration: %d μs | Retries: %d\n",
+			highlightedTitle, m.traceInfo.Coordinator, m.traceInfo.Duration, m.traceInfo.Retries)
 	}
 
 	// Temporarily swap in trace data and settings
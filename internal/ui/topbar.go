@@ -15,6 +15,10 @@ type TopBarModel struct {
 	HasQueryData bool
 	AutoFetch    bool
 	HasMoreData  bool  // Indicates if there's more data to fetch
+	Profile      string // Active connection profile, if any
+	Disconnected bool   // Set after DISCONNECT until the next CONNECT
+	Offline      bool   // Working from a schema snapshot without a cluster
+	Role         string // Role the session is authenticated as, if any
 }
 
 // NewTopBarModel creates a new TopBarModel.
@@ -53,6 +57,14 @@ func (m TopBarModel) View(width int, styles *Styles, viewMode string) string {
 	autoFetchOffStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#5F5F5F"))
 
+	profileStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#87D7FF")).
+		Bold(true)
+
+	disconnectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FF5F5F")).
+		Bold(true)
+
 	// Start with the mode
 	var modeText string
 	switch viewMode {
@@ -67,6 +79,22 @@ func (m TopBarModel) View(width int, styles *Styles, viewMode string) string {
 	}
 	content := labelStyle.Render("Mode: ") + modeStyle.Render(modeText)
 
+	// Add connection profile status
+	if m.Disconnected {
+		content += separatorStyle.Render(" │ ") + disconnectedStyle.Render("DISCONNECTED")
+	} else if m.Offline {
+		content += separatorStyle.Render(" │ ") + disconnectedStyle.Render("OFFLINE")
+	} else if m.Profile != "" {
+		content += separatorStyle.Render(" │ ") +
+			labelStyle.Render("Profile: ") + profileStyle.Render(m.Profile)
+	}
+
+	// Add the authenticated role
+	if !m.Disconnected && m.Role != "" {
+		content += separatorStyle.Render(" │ ") +
+			labelStyle.Render("Role: ") + profileStyle.Render(m.Role)
+	}
+
 	// Add AutoFetch status
 	autoFetchState := "OFF"
 	autoFetchStyle := autoFetchOffStyle
@@ -17,6 +17,7 @@ func (m *MainModel) View() string {
 	m.topBar.LastCommand = m.lastCommand
 	if m.session != nil {
 		m.topBar.AutoFetch = m.session.AutoFetch()
+		m.topBar.Role = m.session.Username()
 	}
 	if m.slidingWindow != nil {
 		m.topBar.HasMoreData = m.slidingWindow.hasMoreData
@@ -33,6 +34,7 @@ func (m *MainModel) View() string {
 		m.statusBar.Consistency = m.session.Consistency()
 		m.statusBar.PagingSize = m.session.PageSize()
 		m.statusBar.Version = m.session.CassandraVersion()
+		m.statusBar.Retries = m.session.LastRetries()
 		// Get the current output format
 		if m.sessionManager != nil {
 			switch m.sessionManager.GetOutputFormat() {
@@ -300,6 +302,27 @@ func (m *MainModel) View() string {
 		layerManager.AddLayer(layer)
 	}
 
+	// Results hide the history, so show the warnings of the statement above them
+	if m.warningBanner != "" && m.viewMode != "history" && m.viewMode != "ai" {
+		text := " ⚠ " + m.warningBanner + " "
+		if maxWidth := screenWidth - 1; maxWidth > 4 && lipgloss.Width(text) > maxWidth {
+			text = string([]rune(text)[:maxWidth-4]) + "... "
+		}
+		content := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(m.styles.Warn).
+			Bold(true).
+			Render(text)
+		layerManager.AddLayer(Layer{
+			Content: content,
+			X:       0,
+			Y:       1,
+			Width:   lipgloss.Width(content),
+			Height:  1,
+			ZIndex:  50,
+		})
+	}
+
 	// If history modal is showing, add as a layer
 	if m.showHistoryModal && len(m.commandHistory) > 0 {
 		historyModal := NewHistoryModal(m.commandHistory, m.historyModalIndex, viewportWidth)
@@ -406,7 +429,7 @@ func (m *MainModel) getWelcomeMessage() string {
 	welcome.WriteString("\n\n")
 
 	// Connection status
-	if m.session != nil && m.session.Session != nil {
+	if m.session.Connected() {
 		welcome.WriteString(m.styles.SuccessText.Render("✓ Connected to Cassandra"))
 		welcome.WriteString("\n")
 		currentKeyspace := ""
@@ -417,6 +440,11 @@ func (m *MainModel) getWelcomeMessage() string {
 			welcome.WriteString(m.styles.MutedText.Render(fmt.Sprintf("  Keyspace: %s", currentKeyspace)))
 			welcome.WriteString("\n")
 		}
+	} else if m.session.IsOffline() {
+		welcome.WriteString(m.styles.AccentText.Render("● Offline: working from a schema snapshot"))
+		welcome.WriteString("\n")
+		welcome.WriteString(m.styles.MutedText.Render("  DESCRIBE, EXPLAIN and completion use the snapshot; CONNECT <profile> to run statements"))
+		welcome.WriteString("\n")
 	} else {
 		welcome.WriteString(m.styles.ErrorText.Render("✗ Not connected to Cassandra"))
 		welcome.WriteString("\n")
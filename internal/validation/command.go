@@ -31,7 +31,9 @@ func ValidateCommandSyntax(command string) error {
 		"DESCRIBE", "DESC", "CONSISTENCY", "OUTPUT",
 		"PAGING", "AUTOFETCH", "TRACING", "SOURCE",
 		"COPY", "SHOW", "EXPAND", "CAPTURE",
-		"HELP", "SAVE",
+		"HELP", "SAVE", "CONNECT", "DISCONNECT",
+		"SERIAL", "SET", "UNSET", "\\SET", "\\UNSET",
+		"PREPARE", "EXECUTE", "TIMEOUT", "RETRY", "LOGIN", "EXPLAIN", "LINT", "VECTOR", "SCHEMA", "MIGRATE",
 	}
 
 	// Check if command starts with any valid keyword